@@ -0,0 +1,203 @@
+package analytics
+
+import (
+	"errors"
+	"math"
+
+	"gitee.com/quant1x/engine/factors"
+)
+
+const (
+	// QuantileGroups 分组数, 按因子值从小到大分成5组, Q5是因子值最大的一组
+	QuantileGroups = 5
+)
+
+var (
+	ErrNoDates = errors.New("没有需要评估的日期")
+)
+
+// DailyMetric 每日的因子绩效, 收益周期是第一个周期
+type DailyMetric struct {
+	Date      string  `name:"日期" dataframe:"date"`
+	Samples   int     `name:"样本数" dataframe:"samples"`
+	Coverage  float64 `name:"覆盖率" dataframe:"coverage"`
+	IC        float64 `name:"IC" dataframe:"ic"`
+	RankIC    float64 `name:"RankIC" dataframe:"rank_ic"`
+	Q1        float64 `name:"Q1收益" dataframe:"q1"`
+	Q2        float64 `name:"Q2收益" dataframe:"q2"`
+	Q3        float64 `name:"Q3收益" dataframe:"q3"`
+	Q4        float64 `name:"Q4收益" dataframe:"q4"`
+	Q5        float64 `name:"Q5收益" dataframe:"q5"`
+	LongShort float64 `name:"多空收益" dataframe:"long_short"`
+	Turnover  float64 `name:"Q5换手率" dataframe:"turnover"`
+}
+
+// DecayMetric IC随收益周期的衰减
+type DecayMetric struct {
+	Horizon    int     `name:"周期" dataframe:"horizon"`
+	Days       int     `name:"天数" dataframe:"days"`
+	MeanIC     float64 `name:"IC均值" dataframe:"mean_ic"`
+	ICIR       float64 `name:"ICIR" dataframe:"icir"`
+	MeanRankIC float64 `name:"RankIC均值" dataframe:"mean_rank_ic"`
+	RankICIR   float64 `name:"RankICIR" dataframe:"rank_icir"`
+	PositiveIC float64 `name:"IC为正占比" dataframe:"positive_ic"`
+}
+
+// Summary 汇总
+type Summary struct {
+	Horizon    int
+	MeanIC     float64
+	ICIR       float64
+	MeanRankIC float64
+	RankICIR   float64
+	Quantiles  []float64 // 每组的平均收益
+	LongShort  float64   // Q5-Q1的平均收益
+	Turnover   float64
+	Coverage   float64
+}
+
+// Report 因子评估报告
+type Report struct {
+	Feature string
+	Field   string
+	Days    []DailyMetric
+	Decay   []DecayMetric
+	Summary Summary
+}
+
+// 截面因子值和收益, 和证券代码对齐
+func crossSection(codes []string, values, returns map[string]float64) (x, y []float64, samples int) {
+	x = make([]float64, len(codes))
+	y = make([]float64, len(codes))
+	for i, code := range codes {
+		v, ok := values[code]
+		if !ok {
+			v = math.NaN()
+		}
+		r, ok := returns[code]
+		if !ok {
+			r = math.NaN()
+		}
+		x[i], y[i] = v, r
+		if valid(v) && valid(r) {
+			samples++
+		}
+	}
+	return
+}
+
+// Evaluate 评估特征的一个数值字段对远期收益的预测能力
+func Evaluate(panel factors.FeaturePanel, field string, returns *ForwardReturns) (*Report, error) {
+	dates := panel.Dates()
+	codes := panel.Codes()
+	horizons := returns.Horizons()
+	if len(dates) == 0 || len(horizons) == 0 {
+		return nil, ErrNoDates
+	}
+	report := &Report{Feature: panel.Key(), Field: field}
+	ics := make(map[int][]float64, len(horizons))
+	rankICs := make(map[int][]float64, len(horizons))
+	var previous map[string]struct{}
+	for _, date := range dates {
+		values, err := panel.Field(date, field)
+		if err != nil {
+			return nil, err
+		}
+		// 分组收益和换手率只看第一个周期
+		var x, y []float64
+		var samples int
+		for i, h := range horizons {
+			hx, hy, n := crossSection(codes, values, returns.Get(h, date))
+			ics[h] = append(ics[h], Pearson(hx, hy))
+			rankICs[h] = append(rankICs[h], Spearman(hx, hy))
+			if i == 0 {
+				x, y, samples = hx, hy, n
+			}
+		}
+		h := horizons[0]
+		day := DailyMetric{
+			Date:    date,
+			Samples: samples,
+			IC:      ics[h][len(ics[h])-1],
+			RankIC:  rankICs[h][len(rankICs[h])-1],
+		}
+		covered := 0
+		for _, v := range x {
+			if valid(v) {
+				covered++
+			}
+		}
+		if len(codes) > 0 {
+			day.Coverage = float64(covered) / float64(len(codes))
+		}
+		groups := Quantiles(x, QuantileGroups)
+		means := QuantileMeans(groups, y, QuantileGroups)
+		day.Q1, day.Q2, day.Q3, day.Q4, day.Q5 = means[0], means[1], means[2], means[3], means[4]
+		day.LongShort = day.Q5 - day.Q1
+		current := map[string]struct{}{}
+		for i, g := range groups {
+			if g == QuantileGroups-1 {
+				current[codes[i]] = struct{}{}
+			}
+		}
+		day.Turnover = Turnover(previous, current)
+		if len(current) > 0 {
+			previous = current
+		}
+		report.Days = append(report.Days, day)
+	}
+	for _, h := range horizons {
+		decay := DecayMetric{Horizon: h}
+		var std float64
+		decay.MeanIC, std, decay.Days = MeanStd(ics[h])
+		decay.ICIR = informationRatio(decay.MeanIC, std)
+		decay.MeanRankIC, std, _ = MeanStd(rankICs[h])
+		decay.RankICIR = informationRatio(decay.MeanRankIC, std)
+		positive := 0
+		for _, v := range ics[h] {
+			if valid(v) && v > 0 {
+				positive++
+			}
+		}
+		if decay.Days > 0 {
+			decay.PositiveIC = float64(positive) / float64(decay.Days)
+		}
+		report.Decay = append(report.Decay, decay)
+	}
+	report.Summary = summarize(report, horizons[0])
+	return report, nil
+}
+
+// 信息比率, 不足2天或者标准差为0时返回NaN
+func informationRatio(mean, std float64) float64 {
+	if !valid(std) || std == 0 {
+		return math.NaN()
+	}
+	return mean / std
+}
+
+func summarize(report *Report, horizon int) Summary {
+	summary := Summary{Horizon: horizon, Quantiles: make([]float64, QuantileGroups)}
+	if len(report.Decay) > 0 {
+		decay := report.Decay[0]
+		summary.MeanIC, summary.ICIR = decay.MeanIC, decay.ICIR
+		summary.MeanRankIC, summary.RankICIR = decay.MeanRankIC, decay.RankICIR
+	}
+	column := func(get func(v DailyMetric) float64) float64 {
+		list := make([]float64, len(report.Days))
+		for i, v := range report.Days {
+			list[i] = get(v)
+		}
+		mean, _, _ := MeanStd(list)
+		return mean
+	}
+	summary.Quantiles[0] = column(func(v DailyMetric) float64 { return v.Q1 })
+	summary.Quantiles[1] = column(func(v DailyMetric) float64 { return v.Q2 })
+	summary.Quantiles[2] = column(func(v DailyMetric) float64 { return v.Q3 })
+	summary.Quantiles[3] = column(func(v DailyMetric) float64 { return v.Q4 })
+	summary.Quantiles[4] = column(func(v DailyMetric) float64 { return v.Q5 })
+	summary.LongShort = column(func(v DailyMetric) float64 { return v.LongShort })
+	summary.Turnover = column(func(v DailyMetric) float64 { return v.Turnover })
+	summary.Coverage = column(func(v DailyMetric) float64 { return v.Coverage })
+	return summary
+}
@@ -0,0 +1,167 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+)
+
+// 配对的有效样本, 去掉任意一边是NaN或Inf的数据
+func pairs(x, y []float64) (xs, ys []float64) {
+	n := min(len(x), len(y))
+	xs = make([]float64, 0, n)
+	ys = make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		if !valid(x[i]) || !valid(y[i]) {
+			continue
+		}
+		xs = append(xs, x[i])
+		ys = append(ys, y[i])
+	}
+	return
+}
+
+func valid(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// Pearson 皮尔逊相关系数, 有效样本少于3个或方差为0时返回NaN
+func Pearson(x, y []float64) float64 {
+	xs, ys := pairs(x, y)
+	n := len(xs)
+	if n < 3 {
+		return math.NaN()
+	}
+	var mx, my float64
+	for i := 0; i < n; i++ {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx /= float64(n)
+	my /= float64(n)
+	var sxy, sxx, syy float64
+	for i := 0; i < n; i++ {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return math.NaN()
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+// Rank 排名, 从1开始, 相同的值取平均排名
+func Rank(x []float64) []float64 {
+	n := len(x)
+	index := make([]int, n)
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		return x[index[i]] < x[index[j]]
+	})
+	ranks := make([]float64, n)
+	for i := 0; i < n; {
+		j := i + 1
+		for j < n && x[index[j]] == x[index[i]] {
+			j++
+		}
+		// 第i到j-1名的平均值
+		avg := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			ranks[index[k]] = avg
+		}
+		i = j
+	}
+	return ranks
+}
+
+// Spearman 斯皮尔曼秩相关系数
+func Spearman(x, y []float64) float64 {
+	xs, ys := pairs(x, y)
+	return Pearson(Rank(xs), Rank(ys))
+}
+
+// Quantiles 按因子值从小到大分成n组, 返回每个样本的组号, 从0开始, 无效值为-1
+func Quantiles(x []float64, n int) []int {
+	groups := make([]int, len(x))
+	var index []int
+	for i, v := range x {
+		groups[i] = -1
+		if valid(v) {
+			index = append(index, i)
+		}
+	}
+	if len(index) == 0 || n <= 0 {
+		return groups
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		return x[index[i]] < x[index[j]]
+	})
+	total := len(index)
+	for rank, i := range index {
+		groups[i] = rank * n / total
+	}
+	return groups
+}
+
+// QuantileMeans 每组收益的平均值, 没有样本的组为NaN
+func QuantileMeans(groups []int, returns []float64, n int) []float64 {
+	sums := make([]float64, n)
+	counts := make([]int, n)
+	for i, g := range groups {
+		if g < 0 || g >= n || i >= len(returns) || !valid(returns[i]) {
+			continue
+		}
+		sums[g] += returns[i]
+		counts[g]++
+	}
+	means := make([]float64, n)
+	for i := range means {
+		if counts[i] == 0 {
+			means[i] = math.NaN()
+			continue
+		}
+		means[i] = sums[i] / float64(counts[i])
+	}
+	return means
+}
+
+// Turnover 换手率, 当前组合中不在上一期组合的比例
+func Turnover(previous, current map[string]struct{}) float64 {
+	if len(previous) == 0 || len(current) == 0 {
+		return math.NaN()
+	}
+	changed := 0
+	for code := range current {
+		if _, ok := previous[code]; !ok {
+			changed++
+		}
+	}
+	return float64(changed) / float64(len(current))
+}
+
+// MeanStd 有效值的平均值和标准差
+func MeanStd(x []float64) (mean, std float64, count int) {
+	for _, v := range x {
+		if valid(v) {
+			mean += v
+			count++
+		}
+	}
+	if count == 0 {
+		return math.NaN(), math.NaN(), 0
+	}
+	mean /= float64(count)
+	if count < 2 {
+		return mean, math.NaN(), count
+	}
+	for _, v := range x {
+		if valid(v) {
+			std += (v - mean) * (v - mean)
+		}
+	}
+	std = math.Sqrt(std / float64(count-1))
+	return mean, std, count
+}
@@ -0,0 +1,56 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPearsonSpearman(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5, math.NaN()}
+	y := []float64{2, 4, 6, 8, 10, 12}
+	if v := Pearson(x, y); math.Abs(v-1) > 1e-12 {
+		t.Errorf("Pearson = %v, want 1", v)
+	}
+	// 单调但非线性, 秩相关为1
+	y2 := []float64{1, 8, 27, 64, 125, 1}
+	if v := Spearman(x, y2); math.Abs(v-1) > 1e-12 {
+		t.Errorf("Spearman = %v, want 1", v)
+	}
+	if v := Pearson([]float64{1, 1, 1}, []float64{1, 2, 3}); !math.IsNaN(v) {
+		t.Errorf("Pearson of constant = %v, want NaN", v)
+	}
+}
+
+func TestRank(t *testing.T) {
+	got := Rank([]float64{10, 30, 20, 20})
+	want := []float64{1, 4, 2.5, 2.5}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Rank = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestQuantiles(t *testing.T) {
+	x := []float64{5, 1, math.NaN(), 4, 2, 3, 10, 9, 8, 7, 6}
+	groups := Quantiles(x, 5)
+	if groups[2] != -1 || groups[1] != 0 || groups[6] != 4 {
+		t.Errorf("groups = %v", groups)
+	}
+	returns := []float64{0.05, 0.01, 0.5, 0.04, 0.02, 0.03, 0.10, 0.09, 0.08, 0.07, 0.06}
+	means := QuantileMeans(groups, returns, 5)
+	if math.Abs(means[0]-0.015) > 1e-12 || math.Abs(means[4]-0.095) > 1e-12 {
+		t.Errorf("means = %v", means)
+	}
+}
+
+func TestTurnover(t *testing.T) {
+	previous := map[string]struct{}{"a": {}, "b": {}}
+	current := map[string]struct{}{"b": {}, "c": {}, "d": {}, "e": {}}
+	if v := Turnover(previous, current); v != 0.75 {
+		t.Errorf("Turnover = %v, want 0.75", v)
+	}
+	if v := Turnover(nil, current); !math.IsNaN(v) {
+		t.Errorf("Turnover = %v, want NaN", v)
+	}
+}
@@ -0,0 +1,113 @@
+package analytics
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/gox/api"
+)
+
+// 报告的文件名前缀
+func (r *Report) name() string {
+	return fmt.Sprintf("factor.%s.%s", r.Feature, r.Field)
+}
+
+// SaveReport 保存评估报告, 每日绩效和IC衰减各一个csv文件, 另外生成一个html文件
+func SaveReport(report *Report, date string) (files []string, err error) {
+	filename := cache.BacktestFilename(report.name(), date)
+	decayFilename := cache.BacktestFilename(report.name()+".decay", date)
+	htmlFilename := filename + ".html"
+	if err = api.SlicesToCsv(filename, report.Days, true); err != nil {
+		return nil, err
+	}
+	if err = api.SlicesToCsv(decayFilename, report.Decay, true); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(filepath.Dir(htmlFilename), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(htmlFilename)
+	if err != nil {
+		return nil, err
+	}
+	err = reportTemplate.Execute(file, report)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []string{filename, decayFilename, htmlFilename}, nil
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"number":  formatNumber,
+	"percent": formatPercent,
+	"bar": func(v float64) float64 {
+		// 分组收益柱状图的宽度, 1%对应100像素
+		if !valid(v) {
+			return 0
+		}
+		return math.Min(math.Abs(v)*10000, 400)
+	},
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>因子评估 {{.Feature}}.{{.Field}}</title>
+<style>
+body { font-family: sans-serif; margin: 24px; }
+table { border-collapse: collapse; margin-bottom: 24px; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th { background: #f0f0f0; }
+.bar { display: inline-block; height: 12px; }
+.up { background: #d9534f; }
+.down { background: #5cb85c; }
+</style>
+</head>
+<body>
+<h2>因子评估: {{.Feature}}.{{.Field}}</h2>
+{{with .Summary}}
+<h3>汇总 (收益周期{{.Horizon}}天)</h3>
+<table>
+<tr><th>IC均值</th><th>ICIR</th><th>RankIC均值</th><th>RankICIR</th><th>多空收益</th><th>Q5换手率</th><th>覆盖率</th></tr>
+<tr><td>{{number .MeanIC}}</td><td>{{number .ICIR}}</td><td>{{number .MeanRankIC}}</td><td>{{number .RankICIR}}</td><td>{{percent .LongShort}}</td><td>{{percent .Turnover}}</td><td>{{percent .Coverage}}</td></tr>
+</table>
+<h3>分组收益</h3>
+<table>
+<tr><th>分组</th><th>平均收益</th><th></th></tr>
+{{range $i, $v := .Quantiles}}<tr><td>Q{{inc $i}}</td><td>{{percent $v}}</td><td style="text-align:left"><span class="bar {{if ge $v 0.0}}up{{else}}down{{end}}" style="width:{{bar $v}}px"></span></td></tr>
+{{end}}</table>
+{{end}}
+<h3>IC衰减</h3>
+<table>
+<tr><th>周期</th><th>天数</th><th>IC均值</th><th>ICIR</th><th>RankIC均值</th><th>RankICIR</th><th>IC为正占比</th></tr>
+{{range .Decay}}<tr><td>{{.Horizon}}</td><td>{{.Days}}</td><td>{{number .MeanIC}}</td><td>{{number .ICIR}}</td><td>{{number .MeanRankIC}}</td><td>{{number .RankICIR}}</td><td>{{percent .PositiveIC}}</td></tr>
+{{end}}</table>
+<h3>每日绩效</h3>
+<table>
+<tr><th>日期</th><th>样本数</th><th>覆盖率</th><th>IC</th><th>RankIC</th><th>Q1</th><th>Q2</th><th>Q3</th><th>Q4</th><th>Q5</th><th>多空</th><th>换手率</th></tr>
+{{range .Days}}<tr><td>{{.Date}}</td><td>{{.Samples}}</td><td>{{percent .Coverage}}</td><td>{{number .IC}}</td><td>{{number .RankIC}}</td><td>{{percent .Q1}}</td><td>{{percent .Q2}}</td><td>{{percent .Q3}}</td><td>{{percent .Q4}}</td><td>{{percent .Q5}}</td><td>{{percent .LongShort}}</td><td>{{percent .Turnover}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func formatNumber(v float64) string {
+	if !valid(v) {
+		return "-"
+	}
+	return fmt.Sprintf("%.4f", v)
+}
+
+func formatPercent(v float64) string {
+	if !valid(v) {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", v*100)
+}
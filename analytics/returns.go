@@ -0,0 +1,103 @@
+package analytics
+
+import (
+	"math"
+	"slices"
+
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/factors"
+	"gitee.com/quant1x/gox/api"
+)
+
+var (
+	// DefaultHorizons 默认的远期收益周期, 单位是交易日
+	DefaultHorizons = []int{1, 5, 10, 20}
+)
+
+// ForwardReturns 远期收益
+//
+//	缓存日期D的特征是用D的上一个交易日(特征日期)的数据计算的, 收盘之后才能得到信号,
+//	收益从D的开盘价开始计算, 持有h个交易日到第h个交易日收盘
+type ForwardReturns struct {
+	horizons []int
+	values   map[int]map[string]map[string]float64 // 周期 -> 缓存日期 -> 证券代码 -> 收益率
+}
+
+// LoadForwardReturns 从宽表K线计算远期收益
+//
+//	第一天用 收盘价/开盘价, 之后用 收盘价/昨收 逐日累乘, 避免除权除息造成的价格跳空
+func LoadForwardReturns(dates, codes []string, horizons []int) *ForwardReturns {
+	if len(horizons) == 0 {
+		horizons = DefaultHorizons
+	}
+	r := &ForwardReturns{
+		horizons: slices.Clone(horizons),
+		values:   make(map[int]map[string]map[string]float64, len(horizons)),
+	}
+	for _, h := range r.horizons {
+		r.values[h] = make(map[string]map[string]float64, len(dates))
+		for _, date := range dates {
+			r.values[h][date] = map[string]float64{}
+		}
+	}
+	featureDates := make([]string, len(dates))
+	for i, date := range dates {
+		_, featureDates[i] = cache.CorrectDate(date)
+	}
+	for _, securityCode := range codes {
+		var rows []factors.SecurityFeature
+		err := api.CsvToSlices(cache.WideFilename(securityCode), &rows)
+		if err != nil || len(rows) == 0 {
+			continue
+		}
+		index := make(map[string]int, len(rows))
+		for i, v := range rows {
+			index[v.Date] = i
+		}
+		for i, date := range dates {
+			offset, ok := index[featureDates[i]]
+			if !ok {
+				continue
+			}
+			for _, h := range r.horizons {
+				if v := forwardReturn(rows, offset, h); valid(v) {
+					r.values[h][date][securityCode] = v
+				}
+			}
+		}
+	}
+	return r
+}
+
+// 从offset的下一个交易日开盘买入, 持有h天的收益率
+func forwardReturn(rows []factors.SecurityFeature, offset, h int) float64 {
+	if h <= 0 || offset+h >= len(rows) {
+		return math.NaN()
+	}
+	entry := rows[offset+1]
+	if entry.Open <= 0 {
+		return math.NaN()
+	}
+	value := entry.Close / entry.Open
+	for i := offset + 2; i <= offset+h; i++ {
+		lastClose := rows[i].LastClose
+		if lastClose <= 0 {
+			lastClose = rows[i-1].Close
+		}
+		if lastClose <= 0 {
+			return math.NaN()
+		}
+		value *= rows[i].Close / lastClose
+	}
+	return value - 1
+}
+
+// Horizons 收益周期
+func (r *ForwardReturns) Horizons() []int {
+	return slices.Clone(r.horizons)
+}
+
+// Get 缓存日期的截面收益, 证券代码 -> 收益率
+func (r *ForwardReturns) Get(horizon int, date string) map[string]float64 {
+	return r.values[horizon][date]
+}
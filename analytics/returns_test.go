@@ -0,0 +1,41 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+
+	"gitee.com/quant1x/engine/factors"
+)
+
+func TestForwardReturn(t *testing.T) {
+	rows := []factors.SecurityFeature{
+		{Date: "2024-01-02", Open: 9.8, Close: 10},
+		{Date: "2024-01-03", Open: 10.5, Close: 11, LastClose: 10},
+		// 10送10, 昨收除权
+		{Date: "2024-01-04", Open: 5.6, Close: 6.05, LastClose: 5.5},
+	}
+	// 信号在01-02收盘之后, 01-03开盘买入
+	if v, want := forwardReturn(rows, 0, 1), 11/10.5-1; math.Abs(v-want) > 1e-12 {
+		t.Errorf("forwardReturn(1) = %v, want %v", v, want)
+	}
+	if v, want := forwardReturn(rows, 0, 2), 11/10.5*1.1-1; math.Abs(v-want) > 1e-12 {
+		t.Errorf("forwardReturn(2) = %v, want %v", v, want)
+	}
+	if v := forwardReturn(rows, 0, 3); !math.IsNaN(v) {
+		t.Errorf("forwardReturn(3) = %v, want NaN", v)
+	}
+}
+
+func TestInformationRatio(t *testing.T) {
+	if v := informationRatio(0.05, 0.1); math.Abs(v-0.5) > 1e-12 {
+		t.Errorf("informationRatio = %v, want 0.5", v)
+	}
+	// 每天的IC都相同
+	if v := informationRatio(0.05, 0); !math.IsNaN(v) {
+		t.Errorf("informationRatio of zero std = %v, want NaN", v)
+	}
+	// 只有1天
+	if v := informationRatio(0.05, math.NaN()); !math.IsNaN(v) {
+		t.Errorf("informationRatio of one day = %v, want NaN", v)
+	}
+}
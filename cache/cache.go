@@ -80,9 +80,9 @@ func initCache() {
 	}
 	// 检查配置文件并加载配置
 	if !found {
-		config.GlobalConfig = config.ReadConfig(GetRootPath())
+		config.SetGlobalConfig(config.ReadConfig(GetRootPath()))
 	} else {
-		config.GlobalConfig = tmpConfig
+		config.SetGlobalConfig(tmpConfig)
 	}
 
 	// 启动性能分析
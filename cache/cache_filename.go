@@ -127,6 +127,13 @@ func SnapshotFilename(securityCode string, date string) string {
 	return filename
 }
 
+// SnapshotRecordFilename 快照录制文件, 每个交易日一个文件, 目录结构${snapshot}/${YYYY}/${YYYYMMDD}.jsonl.gz
+func SnapshotRecordFilename(date string) string {
+	date = exchange.FixTradeDate(date, FilenameDate)
+	filename := fmt.Sprintf("%s/%s/%s.jsonl.gz", GetSnapshotPath(), date[0:4], date)
+	return filename
+}
+
 // ChipsFilename 筹码分布文件
 func ChipsFilename(securityCode string) string {
 	idCode := CacheId(securityCode)
@@ -135,6 +142,20 @@ func ChipsFilename(securityCode string) string {
 	return filename
 }
 
+// BillBoardFilename 龙虎榜文件, 每个交易日一个文件, 目录结构${billboard}/${YYYY}/${YYYYMMDD}.csv
+func BillBoardFilename(date string) string {
+	date = exchange.FixTradeDate(date, FilenameDate)
+	filename := fmt.Sprintf("%s/%s/%s.csv", GetBillBoardPath(), date[0:4], date)
+	return filename
+}
+
+// NoticeFilename 公告事件文件, 每个交易日一个文件, 目录结构${notices}/${YYYY}/${YYYYMMDD}.csv
+func NoticeFilename(date string) string {
+	date = exchange.FixTradeDate(date, FilenameDate)
+	filename := fmt.Sprintf("%s/%s/%s.csv", GetNoticePath(), date[0:4], date)
+	return filename
+}
+
 // BacktestFilename 回测结果文件
 func BacktestFilename(factorName, date string) string {
 	date = exchange.FixTradeDate(date, FilenameDate)
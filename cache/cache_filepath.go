@@ -11,15 +11,17 @@ const (
 	cacheMinutePath = "minutes" // 分时路径
 	cacheInfoPath   = "info"    // 信息路径
 	//cacheTickPath     = "tick"     // tick路径
-	cacheXdxrPath     = "xdxr"     // 除权除息路径
-	cacheWidePath     = "wide"     // 宽表路径
-	cacheFinancePath  = "finance"  // 财务信息路径
-	cacheSnapshotPath = "snapshot" // 快照数据路径
-	cacheHoldingPath  = "holding"  // 流通股东数据路径
-	cacheFundFlowPath = "fund"     // 资金流向
-	cacheTransPath    = "trans"    // 成交数据
-	cacheChipsPath    = "chips"    // 筹码分布
-	backtestPath      = "backtest" // 回测结果
+	cacheXdxrPath      = "xdxr"      // 除权除息路径
+	cacheWidePath      = "wide"      // 宽表路径
+	cacheFinancePath   = "finance"   // 财务信息路径
+	cacheSnapshotPath  = "snapshot"  // 快照数据路径
+	cacheHoldingPath   = "holding"   // 流通股东数据路径
+	cacheFundFlowPath  = "fund"      // 资金流向
+	cacheTransPath     = "trans"     // 成交数据
+	cacheChipsPath     = "chips"     // 筹码分布
+	cacheBillBoardPath = "billboard" // 龙虎榜
+	cacheNoticePath    = "notices"   // 公告事件
+	backtestPath       = "backtest"  // 回测结果
 )
 
 // GetMetaPath 元数据路径
@@ -96,6 +98,16 @@ func GetChipsPath() string {
 	return filepath.Join(GetRootPath(), cacheChipsPath)
 }
 
+// GetBillBoardPath 龙虎榜路径
+func GetBillBoardPath() string {
+	return filepath.Join(GetRootPath(), cacheBillBoardPath)
+}
+
+// GetNoticePath 公告事件路径
+func GetNoticePath() string {
+	return filepath.Join(GetRootPath(), cacheNoticePath)
+}
+
 // GetBacktestCachePath 回测结果路径
 func GetBacktestCachePath() string {
 	return filepath.Join(GetRootPath(), backtestPath)
@@ -25,15 +25,16 @@ func defaultQmtCachePath() string {
 }
 
 func lazyInitQmt() {
-	traderParameter := &config.GlobalConfig.Trader
-	traderParameter.OrderPath = strings.TrimSpace(traderParameter.OrderPath)
-	if len(traderParameter.OrderPath) > 0 && api.CheckFilepath(traderParameter.OrderPath, true) == nil {
+	globalConfig := config.GetGlobalConfig()
+	orderPath := strings.TrimSpace(globalConfig.Trader.OrderPath)
+	if len(orderPath) > 0 && api.CheckFilepath(orderPath, true) == nil {
 		// 如果配置了路径且有效
-		qmtOrderPath = traderParameter.OrderPath
+		qmtOrderPath = orderPath
 	} else {
 		qmtOrderPath = defaultQmtCachePath()
 	}
-	traderParameter.OrderPath = qmtOrderPath
+	globalConfig.Trader.OrderPath = qmtOrderPath
+	config.SetGlobalConfig(globalConfig)
 }
 
 func initMiniQmt() {
@@ -34,7 +34,9 @@ type Handover interface {
 	ChangingOverDate(date string)
 }
 
+// Depend 依赖接口
 type Depend interface {
+	// DependOn 依赖的上游数据类型, 更新和修复时上游的数据先执行
 	DependOn() []Kind
 }
 
@@ -49,6 +51,8 @@ var (
 )
 
 // Register 注册插件
+//
+//	插件实现了Depend接口时, 依赖关系成环的插件拒绝注册
 func Register(plugin DataAdapter) error {
 	pluginMutex.Lock()
 	defer pluginMutex.Unlock()
@@ -56,6 +60,9 @@ func Register(plugin DataAdapter) error {
 	if ok {
 		return ErrAlreadyExists
 	}
+	if err := checkCycle(plugin.Kind(), DependOn(plugin)); err != nil {
+		return err
+	}
 	mapDataPlugins[plugin.Kind()] = plugin
 	return nil
 }
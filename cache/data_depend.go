@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	ErrDependencyCycle = errors.New("the plugin dependencies form a cycle")
+)
+
+// DependOn 数据插件依赖的上游数据类型, 没有实现Depend接口的插件没有依赖
+func DependOn(plugin any) []Kind {
+	depend, ok := plugin.(Depend)
+	if !ok {
+		return nil
+	}
+	return depend.DependOn()
+}
+
+// 检查kind加入依赖图之后是否成环, 调用方需要持有pluginMutex
+//
+//	已注册的插件可能依赖还没有注册的插件, 所以每次注册都要检查
+func checkCycle(kind Kind, deps []Kind) error {
+	visited := map[Kind]bool{}
+	var walk func(k Kind) bool
+	walk = func(k Kind) bool {
+		if k == kind {
+			return true
+		}
+		if visited[k] {
+			return false
+		}
+		visited[k] = true
+		plugin, ok := mapDataPlugins[k]
+		if !ok {
+			return false
+		}
+		return slices.ContainsFunc(DependOn(plugin), walk)
+	}
+	if slices.ContainsFunc(deps, walk) {
+		return fmt.Errorf("%w: %d", ErrDependencyCycle, kind)
+	}
+	return nil
+}
+
+// 依赖图的快照, 包含全部已注册的插件和plugins
+func dependencyGraph(plugins []DataAdapter) map[Kind][]Kind {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+	edges := make(map[Kind][]Kind, len(mapDataPlugins)+len(plugins))
+	for kind, plugin := range mapDataPlugins {
+		edges[kind] = DependOn(plugin)
+	}
+	for _, plugin := range plugins {
+		edges[plugin.Kind()] = DependOn(plugin)
+	}
+	return edges
+}
+
+// 按依赖关系分层, 第0层不依赖kinds中的其它类型
+//
+//	依赖按edges传递, 不在kinds中的中间类型不会切断依赖关系
+func dependencyLevels(edges map[Kind][]Kind, kinds []Kind) [][]Kind {
+	selected := make(map[Kind]bool, len(kinds))
+	for _, kind := range kinds {
+		selected[kind] = true
+	}
+	// upstream[k]: k的上游中被选中的类型的最大层级, 没有时为-1
+	upstream := map[Kind]int{}
+	var depth func(k Kind) int
+	depth = func(k Kind) int {
+		if v, ok := upstream[k]; ok {
+			return v
+		}
+		// 先占位, 防御未注册插件之间的环
+		upstream[k] = -1
+		level := -1
+		for _, dep := range edges[k] {
+			v := depth(dep)
+			if selected[dep] {
+				v++
+			}
+			level = max(level, v)
+		}
+		upstream[k] = level
+		return level
+	}
+	var levels [][]Kind
+	for _, kind := range kinds {
+		level := depth(kind) + 1
+		for len(levels) <= level {
+			levels = append(levels, nil)
+		}
+		if !slices.Contains(levels[level], kind) {
+			levels[level] = append(levels[level], kind)
+		}
+	}
+	for _, v := range levels {
+		slices.Sort(v)
+	}
+	return levels
+}
+
+// 全部直接或间接依赖kinds的类型, 不包含kinds本身
+func dependencyDownstream(edges map[Kind][]Kind, kinds []Kind) []Kind {
+	dirty := make(map[Kind]bool, len(kinds))
+	for _, kind := range kinds {
+		dirty[kind] = true
+	}
+	var list []Kind
+	// 依赖图没有环, 反复扫描直到没有新的下游类型
+	for changed := true; changed; {
+		changed = false
+		for kind, deps := range edges {
+			if dirty[kind] || !slices.ContainsFunc(deps, func(k Kind) bool { return dirty[k] }) {
+				continue
+			}
+			dirty[kind] = true
+			list = append(list, kind)
+			changed = true
+		}
+	}
+	slices.Sort(list)
+	return list
+}
+
+// Levels 按依赖关系把数据插件分层
+//
+//	同一层的插件互不依赖, 可以并行执行; 每一层只依赖前面的层
+func Levels(plugins []DataAdapter) [][]DataAdapter {
+	edges := dependencyGraph(plugins)
+	mapPlugins := make(map[Kind]DataAdapter, len(plugins))
+	kinds := make([]Kind, 0, len(plugins))
+	for _, plugin := range plugins {
+		if _, ok := mapPlugins[plugin.Kind()]; ok {
+			continue
+		}
+		mapPlugins[plugin.Kind()] = plugin
+		kinds = append(kinds, plugin.Kind())
+	}
+	var levels [][]DataAdapter
+	for _, v := range dependencyLevels(edges, kinds) {
+		if len(v) == 0 {
+			continue
+		}
+		level := make([]DataAdapter, 0, len(v))
+		for _, kind := range v {
+			level = append(level, mapPlugins[kind])
+		}
+		levels = append(levels, level)
+	}
+	return levels
+}
+
+// Downstream 全部直接或间接依赖plugins的已注册插件, 不包含plugins本身, 按类型排序
+//
+//	修复某个数据之后, 下游的数据都需要重新计算
+func Downstream(plugins []DataAdapter) (list []DataAdapter) {
+	edges := dependencyGraph(plugins)
+	kinds := make([]Kind, 0, len(plugins))
+	for _, plugin := range plugins {
+		kinds = append(kinds, plugin.Kind())
+	}
+	for _, kind := range dependencyDownstream(edges, kinds) {
+		if plugin := GetDataAdapter(kind); plugin != nil {
+			list = append(list, plugin)
+		}
+	}
+	return
+}
+
+// FilterPlugins 按照类型标志位过滤数据插件
+func FilterPlugins(plugins []DataAdapter, mask Kind) (list []DataAdapter) {
+	for _, plugin := range plugins {
+		if plugin.Kind()&mask == mask {
+			list = append(list, plugin)
+		}
+	}
+	return
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+const testMaskDepend Kind = 0x7000000000000000
+
+type testDependPlugin struct {
+	DataSummary
+	deps []Kind
+}
+
+func (p testDependPlugin) Print(code string, date ...string) {
+	_ = code
+	_ = date
+}
+
+func (p testDependPlugin) DependOn() []Kind {
+	return p.deps
+}
+
+func newTestDependPlugin(kind Kind, deps ...Kind) DataAdapter {
+	return testDependPlugin{DataSummary: Summary(testMaskDepend|kind, "", "", DefaultDataProvider), deps: deps}
+}
+
+func TestDependencyLevels(t *testing.T) {
+	// 1 <- 2 <- 3 <- 5, 1 <- 4
+	edges := map[Kind][]Kind{
+		1: nil,
+		2: {1},
+		3: {2},
+		4: {1},
+		5: {3, 4},
+	}
+	got := dependencyLevels(edges, []Kind{5, 4, 3, 2, 1})
+	want := [][]Kind{{1}, {2, 4}, {3}, {5}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("levels = %v, want %v", got, want)
+	}
+	// 2不在范围内, 3仍然排在1之后
+	got = dependencyLevels(edges, []Kind{3, 1, 4})
+	want = [][]Kind{{1}, {3, 4}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("levels = %v, want %v", got, want)
+	}
+}
+
+func TestDependencyDownstream(t *testing.T) {
+	edges := map[Kind][]Kind{
+		1: nil,
+		2: {1},
+		3: {2},
+		4: {1},
+		5: {3, 4},
+		6: nil,
+	}
+	if got := dependencyDownstream(edges, []Kind{2}); !slices.Equal(got, []Kind{3, 5}) {
+		t.Fatalf("downstream = %v", got)
+	}
+	if got := dependencyDownstream(edges, []Kind{1}); !slices.Equal(got, []Kind{2, 3, 4, 5}) {
+		t.Fatalf("downstream = %v", got)
+	}
+	if got := dependencyDownstream(edges, []Kind{6}); len(got) != 0 {
+		t.Fatalf("downstream = %v", got)
+	}
+}
+
+func TestRegisterDependencyCycle(t *testing.T) {
+	// 先注册的插件依赖还没有注册的插件
+	if err := Register(newTestDependPlugin(1, testMaskDepend|3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register(newTestDependPlugin(2, testMaskDepend|1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register(newTestDependPlugin(3, testMaskDepend|2)); !errors.Is(err, ErrDependencyCycle) {
+		t.Fatalf("err = %v, want %v", err, ErrDependencyCycle)
+	}
+	if GetDataAdapter(testMaskDepend|3) != nil {
+		t.Fatal("成环的插件不应该注册成功")
+	}
+	if err := Register(newTestDependPlugin(4, testMaskDepend|4)); !errors.Is(err, ErrDependencyCycle) {
+		t.Fatalf("err = %v, want %v", err, ErrDependencyCycle)
+	}
+	if err := Register(newTestDependPlugin(5, testMaskDepend|2)); err != nil {
+		t.Fatal(err)
+	}
+	downstream := Downstream([]DataAdapter{GetDataAdapter(testMaskDepend | 1)})
+	var kinds []Kind
+	for _, v := range downstream {
+		kinds = append(kinds, v.Kind())
+	}
+	if !slices.Equal(kinds, []Kind{testMaskDepend | 2, testMaskDepend | 5}) {
+		t.Fatalf("downstream = %x", kinds)
+	}
+	levels := Levels([]DataAdapter{GetDataAdapter(testMaskDepend | 5), GetDataAdapter(testMaskDepend | 1)})
+	if len(levels) != 2 || levels[0][0].Kind() != testMaskDepend|1 || levels[1][0].Kind() != testMaskDepend|5 {
+		t.Fatalf("levels = %v", levels)
+	}
+}
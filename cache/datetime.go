@@ -1,9 +1,8 @@
 package cache
 
 import (
-	"time"
-
 	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/clock"
 )
 
 const (
@@ -18,7 +17,7 @@ const (
 )
 
 func Today() string {
-	now := time.Now()
+	now := clock.Now()
 	return now.Format(CACHE_DATE)
 }
 
@@ -48,13 +47,13 @@ func CorrectDate(date string) (cacheDate, resourcesDate string) {
 
 // DefaultCanReadDate 获取默认可以读缓存文件的日期
 func DefaultCanReadDate() string {
-	dateOfReadingData := exchange.GetCurrentDate()
+	dateOfReadingData := clock.CurrentDate()
 	return dateOfReadingData
 }
 
 // DefaultCanUpdateDate 获取默认可以更新缓存文件的日期
 func DefaultCanUpdateDate() string {
-	currentDate := exchange.GetCurrentDate()
+	currentDate := clock.CurrentDate()
 	dateOfUpdatingData := exchange.NextTradeDate(currentDate)
 	return dateOfUpdatingData
 }
@@ -0,0 +1,84 @@
+package clock
+
+import (
+	"time"
+
+	"gitee.com/quant1x/data/exchange"
+)
+
+const (
+	// 交易日数据初始化的时间, 之前读取前一个交易日的数据
+	marketInitTime = "09:00:00"
+)
+
+// Timestamp 当前时钟的时间, 格式HH:MM:SS, 和交易时段的配置格式一致
+func Timestamp() string {
+	return Now().Format(time.TimeOnly)
+}
+
+// Today 当前时钟的日期, 格式和exchange.Today一致
+func Today() string {
+	if IsReal() {
+		return exchange.Today()
+	}
+	return exchange.FixTradeDate(Now().Format(time.DateOnly))
+}
+
+// DateIsTradingDay 当前时钟的日期是否交易日
+func DateIsTradingDay() bool {
+	if IsReal() {
+		return exchange.DateIsTradingDay()
+	}
+	today := Today()
+	return len(exchange.TradingDateRange(today, today)) > 0
+}
+
+// CurrentlyDay 当前时钟的交易日, 非交易日是前一个交易日
+func CurrentlyDay() string {
+	if IsReal() {
+		return exchange.GetCurrentlyDay()
+	}
+	return currentlyDay(Today())
+}
+
+// LastTradeDate 当前时钟最近的交易日, 包括当天
+func LastTradeDate() string {
+	if IsReal() {
+		return exchange.LastTradeDate()
+	}
+	return currentlyDay(Today())
+}
+
+// CurrentDate 当前时钟可以读取数据的日期, 交易日数据初始化之前是前一个交易日
+func CurrentDate() string {
+	if IsReal() {
+		return exchange.GetCurrentDate()
+	}
+	today := Today()
+	if DateIsTradingDay() && Timestamp() >= marketInitTime {
+		return today
+	}
+	return previousTradeDate(today)
+}
+
+// CanUpdateInRealtime 当前时钟是否可以实时更新数据, 以及交易所的状态
+func CanUpdateInRealtime() (updateInRealTime bool, status int) {
+	return exchange.CanUpdateInRealtime(Now())
+}
+
+// date是交易日返回date, 否则返回之前最近的交易日
+func currentlyDay(date string) string {
+	if len(exchange.TradingDateRange(date, date)) > 0 {
+		return date
+	}
+	return previousTradeDate(date)
+}
+
+// date之前的一个交易日
+func previousTradeDate(date string) string {
+	dates := exchange.LastNDate(date, 1)
+	if len(dates) == 0 {
+		return date
+	}
+	return dates[0]
+}
@@ -0,0 +1,128 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 时钟
+//
+//	引擎中和交易时间有关的判断都从当前时钟取时间, 默认是系统时钟,
+//	单元测试和模拟可以切换成固定时钟、加速时钟或者手动时钟, 在任何时间确定地执行任意一个交易时段
+type Clock interface {
+	// Now 当前时间
+	Now() time.Time
+}
+
+// 系统时钟
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Real 系统时钟
+func Real() Clock {
+	return realClock{}
+}
+
+// 固定时钟
+type fixedClock struct {
+	tm time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.tm
+}
+
+// Fixed 固定时钟, 时间始终是tm
+func Fixed(tm time.Time) Clock {
+	return fixedClock{tm: tm}
+}
+
+// 加速时钟
+type acceleratedClock struct {
+	origin time.Time // 虚拟的开始时间
+	start  time.Time // 创建时钟的系统时间
+	speed  float64   // 倍速
+	now    func() time.Time
+}
+
+func (c acceleratedClock) Now() time.Time {
+	elapsed := c.now().Sub(c.start)
+	return c.origin.Add(time.Duration(float64(elapsed) * c.speed))
+}
+
+// Accelerated 加速时钟, 从origin开始按speed倍速前进, speed不大于0时视为1倍速
+func Accelerated(origin time.Time, speed float64) Clock {
+	if speed <= 0 {
+		speed = 1
+	}
+	return acceleratedClock{origin: origin, start: time.Now(), speed: speed, now: time.Now}
+}
+
+// Manual 手动时钟, 时间由调用方推进, 回放时跟随录制的时间
+type Manual struct {
+	mutex sync.RWMutex
+	tm    time.Time
+}
+
+// NewManual 创建手动时钟, 初始时间是tm
+func NewManual(tm time.Time) *Manual {
+	return &Manual{tm: tm}
+}
+
+func (m *Manual) Now() time.Time {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.tm
+}
+
+// Set 设置当前时间
+func (m *Manual) Set(tm time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.tm = tm
+}
+
+// Advance 时间前进d
+func (m *Manual) Advance(d time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.tm = m.tm.Add(d)
+}
+
+var (
+	__mutexClock sync.RWMutex
+	__clock      Clock = realClock{}
+)
+
+// Set 切换当前时钟, 返回之前的时钟, clock为nil时恢复系统时钟
+func Set(clock Clock) Clock {
+	if clock == nil {
+		clock = realClock{}
+	}
+	__mutexClock.Lock()
+	defer __mutexClock.Unlock()
+	previous := __clock
+	__clock = clock
+	return previous
+}
+
+// Get 获取当前时钟
+func Get() Clock {
+	__mutexClock.RLock()
+	defer __mutexClock.RUnlock()
+	return __clock
+}
+
+// IsReal 当前时钟是否系统时钟
+func IsReal() bool {
+	_, ok := Get().(realClock)
+	return ok
+}
+
+// Now 当前时钟的时间
+func Now() time.Time {
+	return Get().Now()
+}
@@ -0,0 +1,99 @@
+package clock
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFixedAndManual(t *testing.T) {
+	tm := time.Date(2024, 1, 2, 9, 25, 3, 0, time.Local)
+	if got := Fixed(tm).Now(); !got.Equal(tm) {
+		t.Errorf("Fixed.Now() = %v, want %v", got, tm)
+	}
+	m := NewManual(tm)
+	m.Advance(time.Minute)
+	if got := m.Now(); !got.Equal(tm.Add(time.Minute)) {
+		t.Errorf("Manual.Advance = %v", got)
+	}
+	m.Set(tm)
+	if got := m.Now(); !got.Equal(tm) {
+		t.Errorf("Manual.Set = %v", got)
+	}
+}
+
+func TestAccelerated(t *testing.T) {
+	origin := time.Date(2024, 1, 2, 9, 30, 0, 0, time.Local)
+	wall := time.Now()
+	c := acceleratedClock{origin: origin, start: wall, speed: 60, now: func() time.Time { return wall.Add(2 * time.Second) }}
+	if got, want := c.Now(), origin.Add(2*time.Minute); !got.Equal(want) {
+		t.Errorf("Accelerated.Now() = %v, want %v", got, want)
+	}
+	if got := Accelerated(origin, 0).(acceleratedClock).speed; got != 1 {
+		t.Errorf("Accelerated speed = %v, want 1", got)
+	}
+}
+
+func TestSet(t *testing.T) {
+	tm := time.Date(2024, 1, 2, 10, 0, 0, 0, time.Local)
+	previous := Set(Fixed(tm))
+	defer Set(previous)
+	if IsReal() {
+		t.Error("IsReal() = true after Set(Fixed)")
+	}
+	if got := Now(); !got.Equal(tm) {
+		t.Errorf("Now() = %v, want %v", got, tm)
+	}
+	if got := Timestamp(); got != "10:00:00" {
+		t.Errorf("Timestamp() = %s, want 10:00:00", got)
+	}
+	Set(nil)
+	if !IsReal() {
+		t.Error("IsReal() = false after Set(nil)")
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	tests := []struct {
+		tm           time.Time
+		tradingDay   bool
+		currentlyDay string
+		currentDate  string
+	}{
+		{time.Date(2024, 1, 2, 10, 0, 0, 0, time.Local), true, "2024-01-02", "2024-01-02"},
+		{time.Date(2024, 1, 2, 8, 30, 0, 0, time.Local), true, "2024-01-02", "2023-12-29"},
+		{time.Date(2024, 1, 6, 10, 0, 0, 0, time.Local), false, "2024-01-05", "2024-01-05"},
+	}
+	for _, v := range tests {
+		previous := Set(Fixed(v.tm))
+		tradingDay, currentlyDay, currentDate := DateIsTradingDay(), CurrentlyDay(), CurrentDate()
+		Set(previous)
+		if tradingDay != v.tradingDay || currentlyDay != v.currentlyDay || currentDate != v.currentDate {
+			t.Errorf("%v: trading-day=%t currently-day=%s current-date=%s, want %t %s %s", v.tm, tradingDay, currentlyDay, currentDate, v.tradingDay, v.currentlyDay, v.currentDate)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	origin := time.Date(2024, 1, 2, 9, 30, 0, 0, time.Local)
+	c, err := Select("Fixed", "2024-01-02 09:30:00", 0)
+	if err != nil || !c.Now().Equal(origin) {
+		t.Errorf("Select(fixed) = %v, %v", c, err)
+	}
+	c, err = Select(ModeAccelerated, "2024-01-02", 60)
+	if err != nil || c.Now().Before(origin.Add(-9*time.Hour-30*time.Minute)) {
+		t.Errorf("Select(accelerated) = %v, %v", c, err)
+	}
+	if c, err = Select("", "", 0); err != nil || c != Real() {
+		t.Errorf("Select(\"\") = %v, %v", c, err)
+	}
+	if _, err = Select(ModeFixed, "", 0); !errors.Is(err, ErrOrigin) {
+		t.Errorf("Select(fixed, \"\") error = %v, want ErrOrigin", err)
+	}
+	if _, err = Select(ModeFixed, "09:30", 0); !errors.Is(err, ErrOrigin) {
+		t.Errorf("Select(fixed, \"09:30\") error = %v, want ErrOrigin", err)
+	}
+	if _, err = Select("slow", "", 0); !errors.Is(err, ErrMode) {
+		t.Errorf("Select(slow) error = %v, want ErrMode", err)
+	}
+}
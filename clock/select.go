@@ -0,0 +1,63 @@
+package clock
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// 时钟模式
+const (
+	ModeReal        = "real"        // 系统时钟
+	ModeFixed       = "fixed"       // 固定时钟
+	ModeAccelerated = "accelerated" // 加速时钟
+)
+
+var (
+	ErrMode   = errors.New("invalid clock mode")   // 时钟模式错误
+	ErrOrigin = errors.New("invalid clock origin") // 时钟的开始时间格式错误
+)
+
+// Select 按模式创建时钟
+//
+//	origin支持YYYY-MM-DD HH:MM:SS和YYYY-MM-DD两种格式, 固定时钟必须指定origin,
+//	加速时钟的origin为空时从当前时间开始, speed不大于0时视为1倍速. mode为空时是系统时钟
+func Select(mode, origin string, speed float64) (Clock, error) {
+	mode = strings.TrimSpace(strings.ToLower(mode))
+	switch mode {
+	case "", ModeReal:
+		return Real(), nil
+	case ModeFixed, ModeAccelerated:
+	default:
+		return nil, ErrMode
+	}
+	tm, err := parseOrigin(origin)
+	if err != nil {
+		return nil, err
+	}
+	if mode == ModeFixed {
+		if tm.IsZero() {
+			return nil, ErrOrigin
+		}
+		return Fixed(tm), nil
+	}
+	if tm.IsZero() {
+		tm = time.Now()
+	}
+	return Accelerated(tm, speed), nil
+}
+
+// 解析时钟的开始时间, 为空返回零值
+func parseOrigin(origin string) (time.Time, error) {
+	origin = strings.TrimSpace(origin)
+	if len(origin) == 0 {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.DateTime, time.DateOnly} {
+		tm, err := time.ParseInLocation(layout, origin, time.Local)
+		if err == nil {
+			return tm, nil
+		}
+	}
+	return time.Time{}, ErrOrigin
+}
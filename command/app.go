@@ -4,11 +4,16 @@ import (
 	"fmt"
 	"os"
 	goruntime "runtime"
+	"slices"
 	"strings"
+	"time"
 	_ "unsafe" // For go:linkname
 
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/engine/config"
 	"gitee.com/quant1x/engine/models"
 	"gitee.com/quant1x/engine/tracker"
+	"gitee.com/quant1x/engine/trader"
 	"gitee.com/quant1x/gox/logger"
 	"gitee.com/quant1x/gox/runtime"
 	"gitee.com/quant1x/num"
@@ -51,6 +56,9 @@ var (
 	businessDebug  = runtime.Debug()        // 业务调试开关
 	cpuAvx2        = false                  // AVX2加速状态
 	cpuNum         = goruntime.NumCPU() / 2 // cpu数量
+	clockMode      = ""                     // 时钟模式
+	clockOrigin    = ""                     // 时钟的开始时间
+	clockSpeed     = float64(1)             // 加速时钟的倍速
 )
 
 var (
@@ -75,6 +83,12 @@ func initSubCommands() {
 	initTools()
 	initService()
 	initBackTest()
+	initPaperBroker()
+	initHalt()
+	initOptimize()
+	initDoctor()
+	initReplay()
+	initEventStudy()
 }
 
 // InitCommands 公开初始化函数
@@ -106,6 +120,8 @@ func GlobalFlags() *cli.Command {
 			num.SetAvx2Enabled(cpuAvx2)
 			// 设置CPU最大核数
 			runtime.GoMaxProcs(cpuNum)
+			// 切换时钟
+			selectClock(cmd)
 		},
 		PersistentPostRun: func(cmd *cli.Command, args []string) {
 			//
@@ -117,14 +133,61 @@ func GlobalFlags() *cli.Command {
 	engineCmd.PersistentFlags().BoolVar(&businessDebug, "debug", businessDebug, "打开业务调试开关, 慎重使用!")
 	engineCmd.PersistentFlags().BoolVar(&cpuAvx2, "avx2", false, "Avx2 加速开关")
 	engineCmd.PersistentFlags().IntVar(&cpuNum, "cpu", cpuNum, "设置CPU最大核数")
+	engineCmd.PersistentFlags().StringVar(&clockMode, "clock", clockMode, "时钟模式, real|fixed|accelerated, 默认取配置")
+	engineCmd.PersistentFlags().StringVar(&clockOrigin, "clock-origin", clockOrigin, "时钟的开始时间, YYYY-MM-DD HH:MM:SS")
+	engineCmd.PersistentFlags().Float64Var(&clockSpeed, "clock-speed", clockSpeed, "加速时钟的倍速")
 	engineCmd.AddCommand(CmdVersion, CmdSafes, CmdBestIP, CmdConfig, CmdTools)
-	engineCmd.AddCommand(CmdUpdate, CmdRepair, CmdPrint)
+	engineCmd.AddCommand(CmdUpdate, CmdRepair, CmdPrint, CmdDoctor)
 	engineCmd.AddCommand(CmdBackTesting, CmdRules, CmdTracker)
-	engineCmd.AddCommand(cmdBackTest)
-	engineCmd.AddCommand(CmdService)
+	engineCmd.AddCommand(cmdBackTest, CmdOptimize, CmdEventStudy)
+	engineCmd.AddCommand(CmdService, CmdPaperBroker, CmdReplay)
+	engineCmd.AddCommand(CmdHalt, CmdResume)
 	return engineCmd
 }
 
+// 按命令行参数和配置切换时钟, 命令行参数优先
+func selectClock(cmd *cli.Command) {
+	parameter := config.ClockConfig()
+	flags := cmd.Flags()
+	if flags.Changed("clock") {
+		parameter.Mode = clockMode
+	}
+	if flags.Changed("clock-origin") {
+		parameter.Origin = clockOrigin
+	}
+	if flags.Changed("clock-speed") {
+		parameter.Speed = clockSpeed
+	}
+	c, err := clock.Select(parameter.Mode, parameter.Origin, parameter.Speed)
+	if err != nil {
+		logger.Errorf("时钟参数错误: mode=%s, origin=%s, %+v", parameter.Mode, parameter.Origin, err)
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if c != clock.Real() && !fakeClockIsAllowed(cmd) {
+		logger.Errorf("时钟模式[%s]只能用于回放、回测或者模拟盘: command=%s", parameter.Mode, cmd.CommandPath())
+		fmt.Printf("时钟模式[%s]只能用于%s、%s或者%s交易通道\n", parameter.Mode, replayCommand, backTestCommand, trader.BrokerPaper)
+		os.Exit(1)
+	}
+	clock.Set(c)
+	if !clock.IsReal() {
+		logger.Warnf("时钟切换为%s, 当前时间: %s", parameter.Mode, clock.Now().Format(time.DateTime))
+	}
+}
+
+// 允许非系统时钟的命令, 回放和回测不会向真实的交易通道下单
+var fakeClockCommands = []string{replayCommand, backTestCommand, "backtesting"}
+
+// 非系统时钟只能用于回放、回测或者模拟盘, 避免按虚假的时间向真实的交易通道下单
+func fakeClockIsAllowed(cmd *cli.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if slices.Contains(fakeClockCommands, c.Name()) {
+			return true
+		}
+	}
+	return trader.BrokerName(config.TraderConfig()) == trader.BrokerPaper
+}
+
 func parseFlagError(err error) (flag, value string) {
 	before, _, ok := strings.Cut(err.Error(), "flag:")
 	if !ok {
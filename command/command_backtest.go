@@ -3,12 +3,17 @@ package command
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/analytics"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/datasource/base"
+	"gitee.com/quant1x/engine/factors"
+	"gitee.com/quant1x/engine/market"
 	"gitee.com/quant1x/engine/storages"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/logger"
@@ -28,6 +33,11 @@ var (
 	cmdBackTest *cli.Command = nil
 )
 
+var (
+	flagFactor   = cmdFlag[string]{Name: "factor", Value: "", Usage: "因子评估, 特征.字段, 多个用逗号分隔, 比如history.ma5,misc.bdl"}
+	flagHorizons = cmdFlag[string]{Name: "horizons", Value: "1,5,10,20", Usage: "因子评估的远期收益周期, 单位是交易日"}
+)
+
 func initBackTest() {
 	cmdBackTest = &cli.Command{
 		Use:     backTestCommand,
@@ -54,7 +64,9 @@ func initBackTest() {
 			consecutiveEmptyLines := strings.Repeat("\r\n", 2)
 			fmt.Printf("%s数据: %s => %s"+consecutiveEmptyLines, backTestDescription, dates[0], dates[count-1])
 			base.UpdateBeginDateOfHistoricalTradingData(dates[0])
-			if flagAll.Value {
+			if len(flagFactor.Value) > 0 {
+				handleFactorAnalytics(dates, flagFactor.Value, flagHorizons.Value)
+			} else if flagAll.Value {
 				//handleBacktestAll(dates)
 			} else if len(flagBaseData.Value) > 0 {
 				//all, keywords := parseFields(flagBaseData.Value)
@@ -90,6 +102,8 @@ func initBackTest() {
 	commandInit(cmdBackTest, &flagAll)
 	commandInit(cmdBackTest, &flagStartDate)
 	commandInit(cmdBackTest, &flagEndDate)
+	commandInit(cmdBackTest, &flagFactor)
+	commandInit(cmdBackTest, &flagHorizons)
 
 	// 1. 基础数据
 	plugins := cache.Plugins(cache.PluginMaskBaseData)
@@ -150,3 +164,52 @@ func handleBacktestFeaturesWithPlugins(dates []string, plugins []cache.DataAdapt
 	}
 
 }
+
+// 因子评估 - 特征字段对远期收益的预测能力
+func handleFactorAnalytics(dates []string, factorList, horizonList string) {
+	var horizons []int
+	for _, v := range strings.Split(horizonList, ",") {
+		h, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || h <= 0 {
+			fmt.Printf("无效的收益周期: %s\n", v)
+			return
+		}
+		horizons = append(horizons, h)
+	}
+	codes := market.GetCodeList()
+	fmt.Printf("计算远期收益, 周期%v, %d个证券代码\n", horizons, len(codes))
+	returns := analytics.LoadForwardReturns(dates, codes, horizons)
+	_, keywords := parseFields(factorList)
+	for _, v := range keywords {
+		key, field, found := strings.Cut(v, ".")
+		if !found {
+			fmt.Printf("无效的因子: %s, 格式是特征.字段\n", v)
+			continue
+		}
+		panel, err := factors.NewFeaturePanel(key, dates, codes, 0, field)
+		if err != nil {
+			fmt.Printf("%s: %+v\n", v, err)
+			continue
+		}
+		report, err := analytics.Evaluate(panel, field, returns)
+		if err != nil {
+			fmt.Printf("%s: %+v\n", v, err)
+			continue
+		}
+		summary := report.Summary
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"因子", "周期", "IC均值", "ICIR", "RankIC均值", "RankICIR", "多空收益", "换手率", "覆盖率"})
+		table.Append([]string{v, strconv.Itoa(summary.Horizon),
+			fmt.Sprintf("%.4f", summary.MeanIC), fmt.Sprintf("%.4f", summary.ICIR),
+			fmt.Sprintf("%.4f", summary.MeanRankIC), fmt.Sprintf("%.4f", summary.RankICIR),
+			fmt.Sprintf("%.2f%%", summary.LongShort*100), fmt.Sprintf("%.2f%%", summary.Turnover*100),
+			fmt.Sprintf("%.2f%%", summary.Coverage*100)})
+		table.Render()
+		files, err := analytics.SaveReport(report, clock.Today())
+		if err != nil {
+			fmt.Printf("%s: 保存报告失败, %+v\n", v, err)
+			continue
+		}
+		fmt.Printf("因子评估报告已保存到文件: %s\n", strings.Join(files, ", "))
+	}
+}
@@ -10,11 +10,14 @@ import (
 )
 
 var (
-	strategyCode uint64 // 策略ID
-	securityCode string // 证券代码
-	topN         int    // 统计前N
-	days         int    // 统计多少天
-	date         string // 回测日期
+	strategyCode uint64  // 策略ID
+	securityCode string  // 证券代码
+	topN         int     // 统计前N
+	days         int     // 统计多少天
+	date         string  // 回测日期
+	portfolio    bool    // 组合回测
+	capital      float64 // 组合回测初始资金
+	intraday     bool    // 日内策略回测
 )
 
 // CmdBackTesting 回测
@@ -24,8 +27,12 @@ var CmdBackTesting = &cmder.Command{
 	Run: func(cmd *cmder.Command, args []string) {
 		securityCode = strings.TrimSpace(securityCode)
 		securityCode = exchange.CorrectSecurityCode(securityCode)
-		if len(securityCode) > 0 {
+		if intraday {
+			tracker.IntradayBackTesting(strategyCode, days, securityCode)
+		} else if len(securityCode) > 0 {
 			tracker.CheckStrategy(strategyCode, securityCode, date)
+		} else if portfolio {
+			tracker.PortfolioBackTesting(strategyCode, days, capital)
 		} else {
 			tracker.BackTesting(strategyCode, days, topN)
 		}
@@ -38,4 +45,7 @@ func initBackTesting() {
 	CmdBackTesting.Flags().Uint64Var(&strategyCode, "strategy", 0, "策略ID")
 	CmdBackTesting.Flags().StringVar(&securityCode, "code", "", "证券代码")
 	CmdBackTesting.Flags().StringVar(&date, "date", "", "日期")
+	CmdBackTesting.Flags().BoolVar(&portfolio, "portfolio", false, "组合回测, 收盘选股次日开盘买入, 模拟资金、费用、T+1和卖出策略")
+	CmdBackTesting.Flags().Float64Var(&capital, "capital", 100000.00, "组合回测初始资金")
+	CmdBackTesting.Flags().BoolVar(&intraday, "intraday", false, "日内策略回测, 用缓存的分钟K线回放, 可以用--code指定证券")
 }
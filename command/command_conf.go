@@ -14,7 +14,7 @@ var CmdConfig = &cmder.Command{
 	Use:   "config",
 	Short: "显示配置信息",
 	Run: func(cmd *cmder.Command, args []string) {
-		data, err := yaml.Marshal(config.GlobalConfig)
+		data, err := yaml.Marshal(config.GetGlobalConfig())
 		if err != nil {
 			fmt.Println(err)
 		} else {
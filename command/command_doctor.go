@@ -0,0 +1,128 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/market"
+	"gitee.com/quant1x/engine/storages"
+	"gitee.com/quant1x/gox/api"
+	"gitee.com/quant1x/pkg/tablewriter"
+	cmder "github.com/spf13/cobra"
+)
+
+const (
+	doctorCommand     = "doctor"
+	doctorDescription = "基础数据质量检查"
+)
+
+var (
+	doctorReport string // 检查报告的文件名
+	doctorQueue  string // 修复队列的文件名
+)
+
+var (
+	// CmdDoctor 基础数据质量检查
+	CmdDoctor *cmder.Command = nil
+)
+
+func initDoctor() {
+	CmdDoctor = &cmder.Command{
+		Use:     doctorCommand,
+		Example: Application + " " + doctorCommand + " --start=20240102 --base=day,trans --queue=repair.csv",
+		Short:   doctorDescription,
+		Long:    doctorDescription + ", 检查缺少的交易日、开高低收不一致、成交量为0、未复权的跳空、CSV表头不一致和F10季报的前视偏差, 修复队列可以用" + repairCommand + " --queue修复",
+		Run: func(cmd *cmder.Command, args []string) {
+			beginDate := exchange.FixTradeDate(flagStartDate.Value)
+			endDate := cache.DefaultCanReadDate()
+			if len(flagEndDate.Value) > 0 {
+				endDate = exchange.FixTradeDate(flagEndDate.Value)
+			}
+			dates := exchange.TradingDateRange(beginDate, endDate)
+			if len(dates) == 0 {
+				fmt.Printf("start=%s ~ end=%s 休市, 没有数据\n", beginDate, endDate)
+				return
+			}
+			var plugins []cache.DataAdapter
+			all, keywords := parseFields(flagBaseData.Value)
+			if !all && len(keywords) > 0 {
+				plugins = cache.PluginsWithName(cache.PluginMaskBaseData, keywords...)
+				if len(plugins) == 0 {
+					fmt.Printf("没有找到名字是[%s]的数据插件\n", strings.Join(keywords, ","))
+					return
+				}
+			}
+			fmt.Printf("%s: %s => %s"+strings.Repeat("\r\n", 2), doctorDescription, dates[0], dates[len(dates)-1])
+			report := storages.Doctor(1, market.GetCodeList(), dates, plugins, cpuNum)
+			printDoctorReport(&report)
+			filename := doctorReport
+			if len(filename) == 0 {
+				date := exchange.FixTradeDate(report.End, cache.FilenameDate)
+				filename = filepath.Join(cache.GetVariablePath(), "doctor."+date+".csv")
+			}
+			if err := api.SlicesToCsv(filename, report.Issues); err != nil {
+				fmt.Println(err)
+				return
+			}
+			fmt.Printf("\t==> 检查报告: %s\n", filename)
+			if len(doctorQueue) > 0 {
+				tasks := report.RepairQueue()
+				if err := storages.SaveRepairQueue(doctorQueue, tasks); err != nil {
+					fmt.Println(err)
+					return
+				}
+				fmt.Printf("\t==> 修复队列: %s, %d项, 执行: %s %s --queue=%s\n", doctorQueue, len(tasks), Application, repairCommand, doctorQueue)
+			}
+		},
+	}
+	commandInit(CmdDoctor, &flagStartDate)
+	commandInit(CmdDoctor, &flagEndDate)
+	plugins := cache.Plugins(cache.PluginMaskBaseData)
+	flagBaseData.Usage = getPluginsUsage(plugins)
+	commandInit(CmdDoctor, &flagBaseData)
+	CmdDoctor.Flags().StringVar(&doctorReport, "report", "", "检查报告的文件名, 默认在var目录下")
+	CmdDoctor.Flags().StringVar(&doctorQueue, "queue", "", "输出可以自动修复的问题到修复队列文件")
+}
+
+// 按数据集和问题类型汇总输出
+func printDoctorReport(report *storages.DoctorReport) {
+	type summary struct {
+		dataset, issue string
+		count, codes   int
+		repairable     bool
+	}
+	var list []*summary
+	codes := map[string]map[string]bool{}
+	for _, v := range report.Issues {
+		idx := slices.IndexFunc(list, func(e *summary) bool { return e.dataset == v.Dataset && e.issue == v.Type })
+		if idx < 0 {
+			list = append(list, &summary{dataset: v.Dataset, issue: v.Type, repairable: v.Repairable})
+			idx = len(list) - 1
+		}
+		s := list[idx]
+		s.count++
+		key := s.dataset + "/" + s.issue
+		if codes[key] == nil {
+			codes[key] = map[string]bool{}
+		}
+		if !codes[key][v.Code] {
+			codes[key][v.Code] = true
+			s.codes++
+		}
+	}
+	fmt.Printf("\n%s ~ %s, 证券%d个, 检查[%s], 不支持[%s]\n", report.Begin, report.End, report.Codes,
+		strings.Join(report.Checked, ","), strings.Join(report.Skipped, ","))
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"数据集", "问题", "问题数", "证券数", "可修复"})
+	for _, v := range list {
+		table.Append([]string{v.dataset, v.issue, strconv.Itoa(v.count), strconv.Itoa(v.codes), strconv.FormatBool(v.repairable)})
+	}
+	table.Render()
+}
@@ -0,0 +1,99 @@
+package command
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/eventstudy"
+	"gitee.com/quant1x/engine/factors"
+	"gitee.com/quant1x/pkg/tablewriter"
+	cmder "github.com/spf13/cobra"
+)
+
+const (
+	eventStudyCommand     = "event-study"
+	eventStudyDescription = "公告事件研究"
+)
+
+var (
+	eventStudyEvents    string // 事件类型
+	eventStudyBefore    int    // 事件日之前的交易日数
+	eventStudyAfter     int    // 事件日之后的交易日数
+	eventStudyBenchmark string // 参考指数
+)
+
+var (
+	// CmdEventStudy 公告事件研究
+	CmdEventStudy *cmder.Command = nil
+)
+
+func initEventStudy() {
+	CmdEventStudy = &cmder.Command{
+		Use:     eventStudyCommand,
+		Example: Application + " " + eventStudyCommand + " --event=buyback,reduce,preview --start=20240102 --end=20240628 --before=5 --after=10",
+		Short:   eventStudyDescription,
+		Long:    eventStudyDescription + ", 统计公告事件前后个股相对参考指数的平均超额收益, 支持的事件: " + strings.Join(factors.NoticeEvents(), ","),
+		Run: func(cmd *cmder.Command, args []string) {
+			beginDate := exchange.FixTradeDate(flagStartDate.Value)
+			endDate := cache.DefaultCanReadDate()
+			if len(flagEndDate.Value) > 0 {
+				endDate = exchange.FixTradeDate(flagEndDate.Value)
+			}
+			_, events := parseFields(eventStudyEvents)
+			fmt.Printf("%s: %s => %s, 窗口[-%d, %d]\n", eventStudyDescription, beginDate, endDate, eventStudyBefore, eventStudyAfter)
+			results, err := eventstudy.Study(eventstudy.Options{
+				Begin:     beginDate,
+				End:       endDate,
+				Events:    events,
+				Before:    eventStudyBefore,
+				After:     eventStudyAfter,
+				Benchmark: eventStudyBenchmark,
+			})
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			for _, result := range results {
+				printEventStudyResult(result)
+			}
+		},
+	}
+	CmdEventStudy.Flags().StringVar(&eventStudyEvents, "event", defaultFlagAll, "事件类型, 多个用逗号分隔, all表示全部: "+strings.Join(factors.NoticeEvents(), ","))
+	CmdEventStudy.Flags().IntVar(&eventStudyBefore, "before", 5, "事件日之前的交易日数")
+	CmdEventStudy.Flags().IntVar(&eventStudyAfter, "after", 10, "事件日之后的交易日数")
+	CmdEventStudy.Flags().StringVar(&eventStudyBenchmark, "benchmark", "", "参考指数, 默认取回测参数的参考指数")
+	commandInit(CmdEventStudy, &flagStartDate)
+	commandInit(CmdEventStudy, &flagEndDate)
+}
+
+// 输出一类事件的研究结果
+func printEventStudyResult(result eventstudy.Result) {
+	number := func(v float64) string {
+		if math.IsNaN(v) {
+			return "-"
+		}
+		return strconv.FormatFloat(v, 'f', 4, 64)
+	}
+	fmt.Printf("\n事件: %s, 样本数: %d, 跳过: %d\n", result.Event, result.Events, result.Skipped)
+	if result.Events == 0 {
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"偏移", "AAR%", "CAAR%", "t值"})
+	for _, v := range result.Points {
+		table.Append([]string{
+			strconv.Itoa(v.Offset),
+			number(v.AAR),
+			number(v.CAAR),
+			number(v.TStat),
+		})
+	}
+	table.Render()
+	fmt.Printf("\t==> CAR: %s%%, t值: %s, 胜率: %s%%\n", number(result.CAR), number(result.TStat), number(result.WinRate))
+}
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"fmt"
+
+	"gitee.com/quant1x/engine/trader"
+	cmder "github.com/spf13/cobra"
+)
+
+var (
+	haltList       bool   // 输出暂停列表
+	haltStrategyId uint64 // 策略ID
+	haltBuyOnly    bool   // 仅暂停买入
+	haltSellOnly   bool   // 仅暂停卖出
+	haltReason     string // 暂停原因
+	resumeAll      bool   // 恢复全部
+)
+
+var (
+	// CmdHalt 暂停交易
+	CmdHalt *cmder.Command = nil
+	// CmdResume 恢复交易
+	CmdResume *cmder.Command = nil
+)
+
+// 从命令行参数确定暂停范围
+func haltScope() (trader.HaltScope, error) {
+	count := 0
+	scope := trader.HaltGlobal
+	if haltStrategyId > 0 {
+		scope = trader.HaltStrategy
+		count++
+	}
+	if haltBuyOnly {
+		scope = trader.HaltBuy
+		count++
+	}
+	if haltSellOnly {
+		scope = trader.HaltSell
+		count++
+	}
+	if count > 1 {
+		return scope, fmt.Errorf("--strategy, --buy, --sell 只能选择一个")
+	}
+	return scope, nil
+}
+
+func initHalt() {
+	CmdHalt = &cmder.Command{
+		Use:     "halt",
+		Example: Application + " halt --strategy=1 --reason=策略异常",
+		Short:   "暂停交易, 并撤销范围内未完成的委托",
+		Run: func(cmd *cmder.Command, args []string) {
+			if haltList {
+				trader.GetHaltList()
+				return
+			}
+			scope, err := haltScope()
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			err = trader.Halt(scope, haltStrategyId, haltReason)
+			if err != nil {
+				fmt.Println(err)
+			}
+			trader.GetHaltList()
+		},
+	}
+	CmdHalt.Flags().BoolVar(&haltList, "list", false, "显示暂停交易列表")
+	CmdHalt.Flags().Uint64Var(&haltStrategyId, "strategy", 0, "暂停指定策略ID")
+	CmdHalt.Flags().BoolVar(&haltBuyOnly, "buy", false, "仅暂停买入")
+	CmdHalt.Flags().BoolVar(&haltSellOnly, "sell", false, "仅暂停卖出")
+	CmdHalt.Flags().StringVar(&haltReason, "reason", "", "暂停原因")
+
+	CmdResume = &cmder.Command{
+		Use:     "resume",
+		Example: Application + " resume --strategy=1",
+		Short:   "恢复交易",
+		Run: func(cmd *cmder.Command, args []string) {
+			var err error
+			if resumeAll {
+				err = trader.ResumeAll()
+			} else {
+				var scope trader.HaltScope
+				scope, err = haltScope()
+				if err == nil {
+					err = trader.Resume(scope, haltStrategyId)
+				}
+			}
+			if err != nil {
+				fmt.Println(err)
+			}
+			trader.GetHaltList()
+		},
+	}
+	CmdResume.Flags().BoolVar(&resumeAll, "all", false, "恢复全部交易")
+	CmdResume.Flags().Uint64Var(&haltStrategyId, "strategy", 0, "恢复指定策略ID")
+	CmdResume.Flags().BoolVar(&haltBuyOnly, "buy", false, "恢复买入")
+	CmdResume.Flags().BoolVar(&haltSellOnly, "sell", false, "恢复卖出")
+}
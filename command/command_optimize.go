@@ -0,0 +1,132 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/optimize"
+	"gitee.com/quant1x/pkg/tablewriter"
+	cmder "github.com/spf13/cobra"
+)
+
+const (
+	optimizeCommand     = "optimize"
+	optimizeDescription = "策略规则参数优化"
+)
+
+var (
+	optimizeStrategyId uint64  // 策略ID
+	optimizeSpace      string  // 搜索空间
+	optimizeMethod     string  // 搜索方法
+	optimizeTrials     int     // 试验次数
+	optimizeTrain      int     // 样本内交易日数
+	optimizeTest       int     // 样本外交易日数
+	optimizeMetric     string  // 评价指标
+	optimizeMinTrades  int     // 最少卖出笔数
+	optimizeCapital    float64 // 初始资金
+	optimizeSeed       int64   // 随机数种子
+)
+
+var (
+	// CmdOptimize 参数优化
+	CmdOptimize *cmder.Command = nil
+)
+
+func initOptimize() {
+	CmdOptimize = &cmder.Command{
+		Use:     optimizeCommand,
+		Example: Application + " " + optimizeCommand + " --strategy=1 --space=volume_ratio.max=1.5:3.0:0.5,open_change_rate.min=-3:0:1 --method=grid --start=20240102",
+		Short:   optimizeDescription,
+		Long:    optimizeDescription + ", 在组合回测上按滚动窗口搜索最优参数, 输出样本内和样本外的表现",
+		Run: func(cmd *cmder.Command, args []string) {
+			space, err := optimize.ParseSpace(optimizeSpace)
+			if err != nil {
+				fmt.Println(err)
+				_ = cmd.Usage()
+				return
+			}
+			beginDate := exchange.FixTradeDate(flagStartDate.Value)
+			endDate := cache.DefaultCanReadDate()
+			if len(flagEndDate.Value) > 0 {
+				endDate = exchange.FixTradeDate(flagEndDate.Value)
+			}
+			dates := exchange.TradingDateRange(beginDate, endDate)
+			if len(dates) == 0 {
+				fmt.Printf("start=%s ~ end=%s 休市, 没有数据\n", beginDate, endDate)
+				return
+			}
+			fmt.Printf("%s: 策略%d, %s => %s, %d个交易日, 并发数%d\n", optimizeDescription, optimizeStrategyId, dates[0], dates[len(dates)-1], len(dates), cpuNum)
+			result, err := optimize.WalkForward(optimize.Options{
+				StrategyId: optimizeStrategyId,
+				Dates:      dates,
+				Space:      space,
+				Method:     optimizeMethod,
+				Trials:     optimizeTrials,
+				Train:      optimizeTrain,
+				Test:       optimizeTest,
+				Metric:     optimizeMetric,
+				MinTrades:  optimizeMinTrades,
+				Capital:    optimizeCapital,
+				Workers:    cpuNum,
+				Seed:       optimizeSeed,
+				Progress: func(fold, total int) {
+					fmt.Printf("\t==> 窗口 %d/%d 完成\n", fold, total)
+				},
+			})
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			printOptimizeResult(result)
+		},
+	}
+	CmdOptimize.Flags().Uint64Var(&optimizeStrategyId, "strategy", 1, "策略ID")
+	CmdOptimize.Flags().StringVar(&optimizeSpace, "space", "", "搜索空间, 参数名=最小值:最大值[:步长], 多个用逗号分隔, 范围参数加.min或.max后缀")
+	CmdOptimize.Flags().StringVar(&optimizeMethod, "method", optimize.MethodBayes, "搜索方法: grid, random, bayes")
+	CmdOptimize.Flags().IntVar(&optimizeTrials, "trials", 50, "每个窗口的试验次数, 网格搜索忽略")
+	CmdOptimize.Flags().IntVar(&optimizeTrain, "train", 60, "样本内交易日数")
+	CmdOptimize.Flags().IntVar(&optimizeTest, "test", 20, "样本外交易日数, 也是窗口滚动的步长")
+	CmdOptimize.Flags().StringVar(&optimizeMetric, "metric", optimize.MetricSharpe, "评价指标: sharpe, return, annual, calmar, winrate")
+	CmdOptimize.Flags().IntVar(&optimizeMinTrades, "min-trades", 1, "样本内最少的卖出笔数")
+	CmdOptimize.Flags().Float64Var(&optimizeCapital, "capital", 100000.00, "组合回测初始资金")
+	CmdOptimize.Flags().Int64Var(&optimizeSeed, "seed", 1, "随机数种子")
+	commandInit(CmdOptimize, &flagStartDate)
+	commandInit(CmdOptimize, &flagEndDate)
+}
+
+// 输出参数优化结果
+func printOptimizeResult(result *optimize.Result) {
+	number := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 4, 64)
+	}
+	fmt.Printf("\n策略: %s, 评价指标: %s\n", result.Strategy, result.Metric)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"窗口", "样本内", "样本外", "试验次数", "最优参数", "样本内得分", "样本外得分", "样本内收益%", "样本外收益%", "样本外回撤%", "样本外笔数"})
+	for i, v := range result.Folds {
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			v.TrainBegin + "~" + v.TrainEnd,
+			v.TestBegin + "~" + v.TestEnd,
+			strconv.Itoa(v.Trials),
+			v.Best.String(),
+			number(v.InSample.Score),
+			number(v.OutOfSample.Score),
+			number(v.InSample.Summary.TotalReturn),
+			number(v.OutOfSample.Summary.TotalReturn),
+			number(v.OutOfSample.Summary.MaxDrawdown),
+			strconv.Itoa(v.OutOfSample.Summary.Trades),
+		})
+	}
+	table.Render()
+	fmt.Printf("\t==> 样本外/样本内 得分比: %s\n", number(result.Efficiency()))
+	final := result.Final
+	fmt.Printf("\t==> 最近%s~%s 最优参数: %s, 得分: %s, 收益率: %s%%, 最大回撤: %s%%\n",
+		final.Summary.Begin, final.Summary.End, final.Params.String(), number(final.Score),
+		number(final.Summary.TotalReturn), number(final.Summary.MaxDrawdown))
+	fmt.Println()
+	fmt.Print(result.Yaml)
+}
@@ -0,0 +1,34 @@
+package command
+
+import (
+	"fmt"
+
+	"gitee.com/quant1x/engine/trader"
+	cmder "github.com/spf13/cobra"
+)
+
+var (
+	paperAddress string  // 模拟盘监听地址
+	paperCapital float64 // 模拟盘初始资金
+)
+
+var (
+	// CmdPaperBroker 模拟盘
+	CmdPaperBroker *cmder.Command = nil
+)
+
+func initPaperBroker() {
+	CmdPaperBroker = &cmder.Command{
+		Use:     "paper-broker",
+		Example: Application + " paper-broker --addr=127.0.0.1:18168 --capital=1000000",
+		Short:   "模拟盘, 提供和miniQMT代理相同的接口",
+		Run: func(cmd *cmder.Command, args []string) {
+			broker := trader.NewPaperBroker(paperCapital)
+			if err := trader.ServePaperBroker(paperAddress, broker); err != nil {
+				fmt.Println(err)
+			}
+		},
+	}
+	CmdPaperBroker.Flags().StringVar(&paperAddress, "addr", "127.0.0.1:18168", "监听地址")
+	CmdPaperBroker.Flags().Float64Var(&paperCapital, "capital", trader.PaperDefaultCapital, "初始资金, 仅首次创建模拟账户时有效")
+}
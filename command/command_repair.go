@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -24,6 +25,10 @@ var (
 	CmdRepair *cmder.Command = nil
 )
 
+var (
+	repairQueue string // 修复队列的文件名
+)
+
 func initRepair() {
 	CmdRepair = &cmder.Command{
 		Use:     repairCommand,
@@ -35,6 +40,11 @@ func initRepair() {
 		Short: repairDescription,
 		Long:  repairDescription,
 		Run: func(cmd *cmder.Command, args []string) {
+			if len(repairQueue) > 0 {
+				// 修复队列自带日期
+				handleRepairQueue(repairQueue)
+				return
+			}
 			beginDate := exchange.FixTradeDate(flagStartDate.Value)
 			endDate := cache.DefaultCanReadDate()
 			if len(flagEndDate.Value) > 0 {
@@ -94,6 +104,7 @@ func initRepair() {
 	plugins = cache.Plugins(cache.PluginMaskFeature)
 	flagFeatures.Usage = getPluginsUsage(plugins)
 	commandInit(CmdRepair, &flagFeatures)
+	CmdRepair.Flags().StringVar(&repairQueue, "queue", "", "按"+doctorCommand+"输出的修复队列修复数据")
 }
 
 func handleRepairAll(dates []string) {
@@ -112,7 +123,9 @@ func handleRepairAllDataSets(dates []string) {
 	barIndex++
 	for _, date := range dates {
 		//cacheDate, featureDate := cache.CorrectDate(date)
-		storages.DataSetUpdate(barIndex, date, plugins, cache.OpRepair)
+		if err := storages.DataSetUpdate(barIndex, date, plugins, cache.OpRepair); err != nil {
+			logger.Errorf("%s[%s]: %+v", moduleName, date, err)
+		}
 		bar.Add(1)
 	}
 	bar.Wait()
@@ -121,16 +134,31 @@ func handleRepairAllDataSets(dates []string) {
 }
 
 // 修复 - 指定的基础数据
+//
+//	下游的基础数据和特征数据随之重新计算
 func handleRepairDataSetsWithPlugins(dates []string, plugins []cache.DataAdapter) {
 	moduleName := "修复数据"
 	logger.Info(moduleName + ", 任务开始")
+	downstream := cache.Downstream(plugins)
+	plugins = append(plugins, cache.FilterPlugins(downstream, cache.PluginMaskBaseData)...)
+	features := cache.FilterPlugins(downstream, cache.PluginMaskFeature)
 	count := len(dates)
 	barIndex := 1
 	bar := progressbar.NewBar(barIndex, "执行["+moduleName+"]", count)
 	for _, date := range dates {
-		//cacheDate, featureDate := cache.CorrectDate(date)
 		//barIndex++
-		storages.DataSetUpdate(barIndex+1, date, plugins, cache.OpRepair)
+		if err := storages.DataSetUpdate(barIndex+1, date, plugins, cache.OpRepair); err != nil {
+			logger.Errorf("%s[%s]: %+v", moduleName, date, err)
+		}
+		if len(features) > 0 {
+			featureBarIndex := barIndex + 1
+			cacheDate, featureDate := cache.CorrectDate(date)
+			cb, err := storages.FeaturesUpdate(&featureBarIndex, cacheDate, featureDate, features, cache.OpRepair)
+			if err != nil {
+				logger.Errorf("%s[%s]: %+v", moduleName, date, err)
+			}
+			cb()
+		}
 		bar.Add(1)
 	}
 	bar.Wait()
@@ -150,7 +178,10 @@ func handleRepairAllFeatures(dates []string) {
 	barIndex++
 	for _, date := range dates {
 		cacheDate, featureDate := cache.CorrectDate(date)
-		cb := storages.FeaturesUpdate(&barIndex, cacheDate, featureDate, plugins, cache.OpRepair)
+		cb, err := storages.FeaturesUpdate(&barIndex, cacheDate, featureDate, plugins, cache.OpRepair)
+		if err != nil {
+			logger.Errorf("%s[%s]: %+v", moduleName, date, err)
+		}
 		bar.Add(1)
 		cb()
 	}
@@ -160,16 +191,22 @@ func handleRepairAllFeatures(dates []string) {
 }
 
 // 修复 - 指定的特征数据
+//
+//	下游的特征数据随之重新计算
 func handleRepairFeaturesWithPlugins(dates []string, plugins []cache.DataAdapter) {
 	moduleName := "修复数据"
 	logger.Info(moduleName + ", 任务开始")
+	plugins = append(plugins, cache.FilterPlugins(cache.Downstream(plugins), cache.PluginMaskFeature)...)
 	count := len(dates)
 	barIndex := 1
 	bar := progressbar.NewBar(barIndex, "执行["+moduleName+"]", count)
 	barIndex++
 	for _, date := range dates {
 		cacheDate, featureDate := cache.CorrectDate(date)
-		cb := storages.FeaturesUpdate(&barIndex, cacheDate, featureDate, plugins, cache.OpRepair)
+		cb, err := storages.FeaturesUpdate(&barIndex, cacheDate, featureDate, plugins, cache.OpRepair)
+		if err != nil {
+			logger.Errorf("%s[%s]: %+v", moduleName, date, err)
+		}
 		bar.Add(1)
 		cb()
 	}
@@ -177,3 +214,42 @@ func handleRepairFeaturesWithPlugins(dates []string, plugins []cache.DataAdapter
 	logger.Info(moduleName+", 任务执行完毕.", time.Now())
 	fmt.Println()
 }
+
+// 修复 - 按修复队列修复指定证券的基础数据, 然后重新计算下游的特征数据
+func handleRepairQueue(filename string) {
+	moduleName := "修复队列"
+	logger.Info(moduleName + ", 任务开始")
+	tasks, err := storages.LoadRepairQueue(filename)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(tasks) == 0 {
+		fmt.Printf("修复队列[%s]为空\n", filename)
+		return
+	}
+	beginDate := slices.MinFunc(tasks, func(a, b storages.RepairTask) int {
+		return strings.Compare(a.Date, b.Date)
+	}).Date
+	fmt.Printf("修复数据: %s, %d项, 最早日期%s"+strings.Repeat("\r\n", 2), filename, len(tasks), beginDate)
+	base.UpdateBeginDateOfHistoricalTradingData(beginDate)
+	barIndex := 1
+	dates, plugins := storages.RepairWithQueue(barIndex, tasks)
+	features := cache.FilterPlugins(cache.Downstream(plugins), cache.PluginMaskFeature)
+	if len(features) > 0 {
+		bar := progressbar.NewBar(barIndex, "执行["+moduleName+":特征数据]", len(dates))
+		for _, date := range dates {
+			featureBarIndex := barIndex + 1
+			cacheDate, featureDate := cache.CorrectDate(date)
+			cb, err := storages.FeaturesUpdate(&featureBarIndex, cacheDate, featureDate, features, cache.OpRepair)
+			if err != nil {
+				logger.Errorf("%s[%s]: %+v", moduleName, date, err)
+			}
+			bar.Add(1)
+			cb()
+		}
+		bar.Wait()
+	}
+	logger.Info(moduleName+", 任务执行完毕.", time.Now())
+	fmt.Println()
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/models"
+	"gitee.com/quant1x/engine/services"
+	"gitee.com/quant1x/engine/trader"
+	"gitee.com/quant1x/gox/api"
+	cmder "github.com/spf13/cobra"
+)
+
+const (
+	replayCommand     = "replay"
+	replayDescription = "回放录制的快照"
+)
+
+var (
+	replaySpeed                        = "1x"                       // 回放倍速
+	replayBegin                        = ""                         // 开始时间
+	replayEnd                          = ""                         // 结束时间
+	replayStrategyCodes                = "1"                        // 策略编号
+	replayCapital                      = trader.PaperDefaultCapital // 模拟盘初始资金
+	CmdReplay           *cmder.Command = nil                        // 回放录制的快照
+)
+
+func initReplay() {
+	CmdReplay = &cmder.Command{
+		Use:     replayCommand,
+		Example: Application + " " + replayCommand + " --date=20240102 --speed=10x --begin=09:25:00 --no=1",
+		Short:   replayDescription,
+		Long:    replayDescription + ", 按录制的时间顺序回放盘中快照, 驱动实时跟踪和交易任务, 委托在沙箱的模拟盘中撮合",
+		Run: func(cmd *cmder.Command, args []string) {
+			date := cache.DefaultCanReadDate()
+			if len(flagDate.Value) > 0 {
+				date = exchange.FixTradeDate(flagDate.Value)
+			}
+			speed, err := services.ParseReplaySpeed(replaySpeed)
+			if err != nil {
+				fmt.Printf("倍速[%s]格式错误: %+v\n", replaySpeed, err)
+				return
+			}
+			var strategyCodes []uint64
+			for _, strategyNumber := range strings.Split(replayStrategyCodes, ",") {
+				code := api.ParseUint(strings.TrimSpace(strategyNumber))
+				if _, err := models.CheckoutStrategy(code); err != nil {
+					fmt.Printf("策略编号%d, 不存在\n", code)
+					continue
+				}
+				strategyCodes = append(strategyCodes, code)
+			}
+			parameter := services.ReplayParameter{
+				Date:       date,
+				Begin:      replayBegin,
+				End:        replayEnd,
+				Speed:      speed,
+				Capital:    replayCapital,
+				Strategies: strategyCodes,
+			}
+			count, err := services.Replay(parameter)
+			if err != nil {
+				fmt.Printf("回放%s失败: %+v\n", date, err)
+				return
+			}
+			fmt.Printf("回放%s结束, 快照%d批\n", date, count)
+		},
+	}
+	commandInit(CmdReplay, &flagDate)
+	CmdReplay.Flags().StringVar(&replaySpeed, "speed", replaySpeed, "回放倍速, 比如10x, 0表示不等待")
+	CmdReplay.Flags().StringVar(&replayBegin, "begin", replayBegin, "开始时间, HH:MM:SS")
+	CmdReplay.Flags().StringVar(&replayEnd, "end", replayEnd, "结束时间, HH:MM:SS")
+	CmdReplay.Flags().StringVar(&replayStrategyCodes, "no", replayStrategyCodes, "策略编号, 多个用逗号分隔")
+	CmdReplay.Flags().Float64Var(&replayCapital, "capital", replayCapital, "回放模拟盘的初始资金")
+}
@@ -5,6 +5,9 @@ import (
 	"slices"
 	"strings"
 
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/factors"
 	"gitee.com/quant1x/engine/tools"
 	"gitee.com/quant1x/num"
 	"gitee.com/quant1x/pkg/tools/tail"
@@ -17,8 +20,9 @@ const (
 )
 
 var (
-	CmdTools    *cmder.Command = nil // 工具集合
-	cmdToolTail *cmder.Command = nil // tail工具
+	CmdTools       *cmder.Command = nil // 工具集合
+	cmdToolTail    *cmder.Command = nil // tail工具
+	cmdToolConvert *cmder.Command = nil // 特征缓存格式转换
 )
 
 func initTools() {
@@ -31,7 +35,8 @@ func initTools() {
 		},
 	}
 	toolsInitTail()
-	CmdTools.AddCommand(cmdToolTail)
+	toolsInitConvert()
+	CmdTools.AddCommand(cmdToolTail, cmdToolConvert)
 }
 
 func toolsInitTail() {
@@ -80,3 +85,35 @@ func toolsInitTail() {
 	})
 	cmdToolTail.Flags().BoolVarP(&taiConfig.Follow, commandDefaultLongFlag, "f", false, "一直等待新数据添加到文件")
 }
+
+func toolsInitConvert() {
+	var startDate, endDate string
+	cmdToolConvert = &cmder.Command{
+		Use:     "convert",
+		Example: Application + " tool convert --start=2024-01-02 --end=2024-12-31",
+		Short:   "特征缓存转换成列式存储",
+		Run: func(cmd *cmder.Command, args []string) {
+			beginDate := exchange.FixTradeDate(startDate)
+			lastDate := cache.DefaultCanReadDate()
+			if len(endDate) > 0 {
+				lastDate = exchange.FixTradeDate(endDate)
+			}
+			dates := exchange.TradingDateRange(beginDate, lastDate)
+			if len(dates) == 0 {
+				fmt.Printf("start=%s ~ end=%s 休市, 没有数据\n", beginDate, lastDate)
+				return
+			}
+			total := 0
+			for _, date := range dates {
+				count, err := factors.ConvertFeatureStorage(date)
+				if err != nil {
+					fmt.Printf("%s: 转换失败, %+v\n", date, err)
+				}
+				total += count
+			}
+			fmt.Printf("特征缓存转换完成: %s => %s, 共%d个文件\n", dates[0], dates[len(dates)-1], total)
+		},
+	}
+	cmdToolConvert.Flags().StringVar(&startDate, "start", exchange.LastTradeDate(), "开始日期")
+	cmdToolConvert.Flags().StringVar(&endDate, "end", "", "结束日期")
+}
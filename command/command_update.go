@@ -4,7 +4,9 @@ import (
 	"fmt"
 
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/datasource"
 	"gitee.com/quant1x/engine/storages"
+	"gitee.com/quant1x/gox/logger"
 	cmder "github.com/spf13/cobra"
 )
 
@@ -17,6 +19,8 @@ var (
 	// CmdUpdate 更新数据
 	CmdUpdate *cmder.Command = nil
 	barIndex                 = 1
+	// 录制更新时下载的行情数据, 用于回放
+	flagRecord = cmdFlag[bool]{Name: "record", Value: false, Usage: "录制行情数据到回放目录"}
 )
 
 func initUpdate() {
@@ -31,6 +35,10 @@ func initUpdate() {
 		Long:  updateDescription,
 		Run: func(cmd *cmder.Command, args []string) {
 			fmt.Println()
+			if flagRecord.Value {
+				datasource.StartRecording()
+				fmt.Println("录制行情数据到:", datasource.ReplayPath())
+			}
 			currentDate := cache.DefaultCanUpdateDate()
 			cacheDate, featureDate := cache.CorrectDate(currentDate)
 			if flagAll.Value {
@@ -55,6 +63,7 @@ func initUpdate() {
 		},
 	}
 	commandInit(CmdUpdate, &flagAll)
+	commandInit(CmdUpdate, &flagRecord)
 
 	// 1. 基础数据
 	plugins := cache.Plugins(cache.PluginMaskBaseData)
@@ -84,7 +93,9 @@ func handleUpdateBaseData(date string) {
 	mask := cache.PluginMaskBaseData
 	plugins := cache.Plugins(mask)
 	// 2. 执行操作
-	storages.DataSetUpdate(barIndex, date, plugins, cache.OpUpdate)
+	if err := storages.DataSetUpdate(barIndex, date, plugins, cache.OpUpdate); err != nil {
+		logger.Errorf("更新基础数据[%s]: %+v", date, err)
+	}
 }
 
 // 更新基础数据
@@ -95,7 +106,9 @@ func handleUpdateBaseDataWithKeywords(cacheDate, featureDate string, keywords ..
 		mask := cache.PluginMaskBaseData
 		plugins = cache.Plugins(mask)
 	}
-	storages.DataSetUpdate(barIndex, featureDate, plugins, cache.OpUpdate)
+	if err := storages.DataSetUpdate(barIndex, featureDate, plugins, cache.OpUpdate); err != nil {
+		logger.Errorf("更新基础数据[%s]: %+v", featureDate, err)
+	}
 	_ = cacheDate
 }
 
@@ -107,5 +120,7 @@ func handleUpdateFeaturesWithKeywords(cacheDate, featureDate string, keywords ..
 		mask := cache.PluginMaskFeature
 		plugins = cache.Plugins(mask)
 	}
-	storages.FeaturesUpdate(&barIndex, cacheDate, featureDate, plugins, cache.OpUpdate)
+	if _, err := storages.FeaturesUpdate(&barIndex, cacheDate, featureDate, plugins, cache.OpUpdate); err != nil {
+		logger.Errorf("更新特征数据[%s]: %+v", featureDate, err)
+	}
 }
@@ -2,9 +2,11 @@ package config
 
 import (
 	"embed"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/logger"
@@ -41,18 +43,55 @@ type Quant1XConfig struct {
 	Data    DataParameter    `yaml:"data"`    // 数据源
 	Runtime RuntimeParameter `yaml:"runtime"` // 运行时参数
 	Trader  TraderParameter  `yaml:"trader"`  // 预览交易参数
+	Models  []ModelParameter `yaml:"models"`  // 声明式策略
 }
 
 // GetConfigFilename 获取配置文件路径
 func GetConfigFilename() string {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
 	return quant1XConfigFilename
 }
 
+var (
+	// ErrConfigNotFound 配置文件不存在
+	ErrConfigNotFound = errors.New("配置文件不存在")
+)
+
 var (
 	// GlobalConfig engine配置信息
-	GlobalConfig Quant1XConfig
+	//
+	//	启动时初始化, 运行中可以被ReloadConfig替换, 并发读取要用GetGlobalConfig
+	GlobalConfig   Quant1XConfig
+	globalMutex    sync.RWMutex
+	reloadHandlers []func()
 )
 
+// GetGlobalConfig 获取配置的副本, 可以和ReloadConfig并发执行
+func GetGlobalConfig() Quant1XConfig {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+	return GlobalConfig
+}
+
+// SetGlobalConfig 替换配置, 和ReloadConfig一样通知从配置派生的缓存刷新
+func SetGlobalConfig(config Quant1XConfig) {
+	globalMutex.Lock()
+	GlobalConfig = config
+	handlers := reloadHandlers
+	globalMutex.Unlock()
+	for _, handler := range handlers {
+		handler()
+	}
+}
+
+// OnReload 注册重新加载配置之后的回调, 用于刷新从配置派生的缓存
+func OnReload(handler func()) {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+	reloadHandlers = append(reloadHandlers, handler)
+}
+
 // LoadConfig 加载配置文件
 func LoadConfig() (config Quant1XConfig, found bool) {
 	for _, v := range listConfigFile {
@@ -104,8 +143,46 @@ func parseYamlConfig(filename string, config *Quant1XConfig) error {
 		}
 		config.BaseDir = strings.TrimSpace(config.BaseDir)
 		if len(config.BaseDir) > 0 {
+			globalMutex.Lock()
 			quant1XConfigFilename = filename
+			globalMutex.Unlock()
 		}
 	}
 	return nil
 }
+
+// ReloadConfig 重新加载配置文件
+//
+//	解析失败时保留当前配置. 交易参数和策略规则在下一次读取时生效, 注册了OnReload的缓存同时刷新.
+//	缓存根路径、订单路径、账户路径、交易通道、定时任务、HTTP服务和声明式策略在启动时确定, 需要重启才能生效
+func ReloadConfig() error {
+	filename := GetConfigFilename()
+	if len(filename) == 0 || !api.FileExist(filename) {
+		filename = ""
+		for _, v := range listConfigFile {
+			target, err := homedir.Expand(v)
+			if err == nil && api.FileExist(target) {
+				filename = target
+				break
+			}
+		}
+	}
+	if len(filename) == 0 {
+		return ErrConfigNotFound
+	}
+	var tmpConfig Quant1XConfig
+	if err := parseYamlConfig(filename, &tmpConfig); err != nil {
+		return err
+	}
+	globalMutex.Lock()
+	tmpConfig.BaseDir = GlobalConfig.BaseDir
+	tmpConfig.Trader.OrderPath = GlobalConfig.Trader.OrderPath
+	GlobalConfig = tmpConfig
+	handlers := reloadHandlers
+	globalMutex.Unlock()
+	for _, handler := range handlers {
+		handler()
+	}
+	logger.Infof("重新加载配置文件: %s", filename)
+	return nil
+}
@@ -0,0 +1,24 @@
+package config
+
+const (
+	// 默认连续失败多少次触发告警
+	defaultAlertMaxFailures = 3
+)
+
+// AlertParameter 定时任务告警参数
+//
+//	任务连续失败MaxFailures次或者执行超时时触发, Command和Webhook可以同时配置
+type AlertParameter struct {
+	MaxFailures int    `name:"连续失败次数" yaml:"max_failures" default:"3"` // 连续失败多少次触发告警
+	Command     string `name:"告警命令" yaml:"command" default:""`         // shell命令, 告警信息通过环境变量传递
+	Webhook     string `name:"告警webhook" yaml:"webhook" default:""`    // webhook地址, 以json格式POST告警信息
+}
+
+// AlertConfig 获取定时任务告警配置
+func AlertConfig() AlertParameter {
+	alert := GetGlobalConfig().Runtime.Alert
+	if alert.MaxFailures <= 0 {
+		alert.MaxFailures = defaultAlertMaxFailures
+	}
+	return alert
+}
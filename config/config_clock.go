@@ -0,0 +1,16 @@
+package config
+
+// ClockParameter 时钟参数
+//
+//	默认是系统时钟, 模拟交易时段时可以切换成固定时钟或者加速时钟, 命令行的--clock参数优先.
+//	非系统时钟只能用于回放、回测或者paper交易通道, 其它命令会拒绝启动
+type ClockParameter struct {
+	Mode   string  `name:"时钟模式" yaml:"mode" default:"real"` // real|fixed|accelerated
+	Origin string  `name:"开始时间" yaml:"origin" default:""`   // YYYY-MM-DD HH:MM:SS, 固定时钟必须配置
+	Speed  float64 `name:"倍速" yaml:"speed" default:"1"`     // 加速时钟的倍速
+}
+
+// ClockConfig 获取时钟配置
+func ClockConfig() ClockParameter {
+	return GetGlobalConfig().Runtime.Clock
+}
@@ -5,11 +5,12 @@ type JobParameter struct {
 	//Name    string `yaml:"name" default:""`       // 任务名称
 	Trigger string `yaml:"trigger"  default:""`   // 触发条件
 	Enable  bool   `yaml:"enable" default:"true"` // 任务是否有效
+	Timeout string `yaml:"timeout" default:""`    // 超时告警阈值, 比如30s, 为空时@every任务取执行间隔
 }
 
 // CrontabConfig 获取定时任务配置
 func CrontabConfig() map[string]JobParameter {
-	return GlobalConfig.Runtime.Crontab
+	return GetGlobalConfig().Runtime.Crontab
 }
 
 // GetJobParameter 获取计划执行任务
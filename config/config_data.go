@@ -1,6 +1,10 @@
 package config
 
-import "gitee.com/quant1x/data/exchange"
+import (
+	"strings"
+
+	"gitee.com/quant1x/data/exchange"
+)
 
 const (
 	DefaultMinimumConcurrencyForSnapshots = 2 // 快照默认最小并发数
@@ -12,12 +16,13 @@ type DataParameter struct {
 	Trans       HistoricalTradingDataParameter `name:"历史成交数据" yaml:"trans"`          // 历史成交参数
 	Feature     FeatureParameter               `name:"特征" yaml:"feature"`            // 特征参数
 	Snapshot    SnapshotParameter              `name:"快照" yaml:"snapshot"`           // 快照参数
+	Source      SourceParameter                `name:"数据源" yaml:"source"`            // 行情数据源参数
 	Cache       map[string]map[string]any      `name:"缓存" yaml:"cache" default:"{}"` // 缓存的其它未尽参数
 }
 
 // GetDataConfig 取得数据配置
 func GetDataConfig() DataParameter {
-	dataParameter := GlobalConfig.Data
+	dataParameter := GetGlobalConfig().Data
 	backTestingParameter := dataParameter.BackTesting
 	backTestingParameter.TargetIndex = exchange.CorrectSecurityCode(backTestingParameter.TargetIndex)
 	return dataParameter
@@ -29,6 +34,12 @@ type BackTestingParameter struct {
 	NextPremiumRate float64 `name:"隔日溢价率" yaml:"next_premium_rate" default:"0.03"` // 隔日溢价率百分比
 }
 
+// SourceParameter 行情数据源参数
+type SourceParameter struct {
+	Name   string `name:"数据源" yaml:"name" default:"tdx"` // 数据源, tdx-通达信协议, replay-本地回放, record-通达信协议并录制
+	Replay string `name:"回放目录" yaml:"replay" default:""` // 回放数据的目录, 默认是缓存根目录下的replay
+}
+
 // HistoricalTradingDataParameter 历史成交数据参数
 type HistoricalTradingDataParameter struct {
 	BeginDate string `name:"默认开始日期" yaml:"begin_date" default:"2023-10-01"`
@@ -40,6 +51,22 @@ type FeatureParameter struct {
 	Tendency       int         `name:"趋势类型" yaml:"tendency" default:"0"`               // 策略是趋势主导还是股价主导, 默认是0, 0-股价主导,1-趋势主导,2-股价或趋势
 	Wave           FeatureWave `name:"波浪" yaml:"wave"`                                 // 波浪
 	CrossStarRatio float64     `name:"十字星实体占比" yaml:"cross_star_ratio" default:"0.50"` // 判断十字星, K线实体(OPEN-CLOSE)在K线长度(HIGH-LOW)中的占比
+	Storage        string      `name:"缓存格式" yaml:"storage" default:"csv"`              // 特征缓存的存储格式, csv或columnar
+}
+
+// 特征缓存的存储格式
+const (
+	FeatureStorageCsv      = "csv"      // 文本格式, 每行一个证券代码
+	FeatureStorageColumnar = "columnar" // 列式二进制格式
+)
+
+// FeatureStorage 特征缓存的存储格式, 无效值按csv处理
+func FeatureStorage() string {
+	storage := strings.ToLower(strings.TrimSpace(GetGlobalConfig().Data.Feature.Storage))
+	if storage == FeatureStorageColumnar {
+		return FeatureStorageColumnar
+	}
+	return FeatureStorageCsv
 }
 
 // FeatureF10 F10特征数据参数
@@ -62,5 +89,6 @@ type FeatureWaveFields struct {
 
 // SnapshotParameter 快照参数
 type SnapshotParameter struct {
-	Concurrency int `name:"并发数" yaml:"concurrency" default:"0"` // 并发数, 默认是0, 使用服务器数量的半数
+	Concurrency int  `name:"并发数" yaml:"concurrency" default:"0"` // 并发数, 默认是0, 使用服务器数量的半数
+	Record      bool `name:"录制" yaml:"record" default:"false"`   // 录制每一批快照, 用于回放, 默认关闭
 }
@@ -0,0 +1,28 @@
+package config
+
+import "strings"
+
+const (
+	// 内置HTTP服务默认监听地址
+	defaultHttpAddr = "127.0.0.1:18080"
+)
+
+// HttpParameter 内置HTTP服务参数
+type HttpParameter struct {
+	Enable bool   `name:"开关" yaml:"enable" default:"false"`           // 是否启动内置HTTP服务
+	Addr   string `name:"监听地址" yaml:"addr" default:"127.0.0.1:18080"` // 监听地址
+}
+
+// HttpEnable 获取配置中内置HTTP服务开关
+func HttpEnable() bool {
+	return GetGlobalConfig().Runtime.Http.Enable
+}
+
+// HttpAddr 获取内置HTTP服务的监听地址
+func HttpAddr() string {
+	addr := strings.TrimSpace(GetGlobalConfig().Runtime.Http.Addr)
+	if len(addr) == 0 {
+		addr = defaultHttpAddr
+	}
+	return addr
+}
@@ -0,0 +1,24 @@
+package config
+
+// ModelParameter 声明式策略参数
+//
+//	不需要编写Go代码, 通过配置文件定义选股策略, 表达式的语法见strategies.Expr
+type ModelParameter struct {
+	Id        uint64             `name:"策略编码" yaml:"id"`                           // 策略ID, 不能和已注册的策略冲突
+	Name      string             `name:"策略名称" yaml:"name"`                         // 策略名称
+	Flag      string             `name:"订单标识" yaml:"flag" default:"tail"`          // 订单标识, head/tick/tail
+	SkipRules bool               `name:"跳过通用规则" yaml:"skip_rules" default:"false"` // 是否跳过rules注册的通用过滤规则
+	Filters   []string           `name:"过滤条件" yaml:"filters"`                      // 过滤条件, 布尔表达式, 全部满足才通过
+	SortKey   string             `name:"排序字段" yaml:"sort_key"`                     // 排序表达式, 为空时由engine决定
+	SortAsc   bool               `name:"升序" yaml:"sort_asc" default:"false"`       // 排序方向, 默认降序
+	Evaluate  string             `name:"评估条件" yaml:"evaluate"`                     // 评估表达式, 布尔表达式, 为空时等同于过滤条件
+	Target    string             `name:"目标价格" yaml:"target"`                       // 目标价格表达式, 为空时取现价
+	Formula   string             `name:"通达信公式" yaml:"formula"`                     // 通达信公式文件, 评估时以日K线计算信号
+	Signal    string             `name:"信号变量" yaml:"signal" default:"B"`           // 公式中的信号变量, 最后一根K线非0为买入信号
+	Params    map[string]float64 `name:"公式参数" yaml:"params"`                       // 公式参数
+}
+
+// ModelConfig 获取声明式策略列表
+func ModelConfig() []ModelParameter {
+	return GetGlobalConfig().Models
+}
@@ -15,7 +15,7 @@ type PprofParameter struct {
 
 // PprofEnable 获取配置中pprof开关
 func PprofEnable() bool {
-	return GlobalConfig.Runtime.Pprof.Enable
+	return GetGlobalConfig().Runtime.Pprof.Enable
 }
 
 // StartPprof 启动性能分析工具
@@ -24,7 +24,7 @@ func StartPprof() {
 		return
 	}
 	go func() {
-		addr := fmt.Sprintf("localhost:%d", GlobalConfig.Runtime.Pprof.Port)
+		addr := fmt.Sprintf("localhost:%d", GetGlobalConfig().Runtime.Pprof.Port)
 		err := http.ListenAndServe(addr, nil)
 		logger.Info("启动pprof性能分析工具", err)
 	}()
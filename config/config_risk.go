@@ -0,0 +1,13 @@
+package config
+
+// RiskParameter 交易前风控参数
+//
+//	比例类参数单位都是%, 0表示不启用
+type RiskParameter struct {
+	Enable              bool    `name:"启用风控" yaml:"enable" default:"true"`                  // 风控总开关, 默认启用
+	MaxDailyLoss        float64 `name:"当日最大亏损%" yaml:"max_daily_loss" default:"0"`          // 当日总资产较开盘的最大亏损比例, 触发后禁止买入
+	MaxSingleExposure   float64 `name:"单一标的最大占比%" yaml:"max_single_exposure" default:"0"`   // 单一标的持仓市值加委托金额占总资产的最大比例
+	MaxStrategyExposure float64 `name:"单一策略最大占比%" yaml:"max_strategy_exposure" default:"0"` // 单一策略当日买入金额占总资产的最大比例
+	MaxOrdersPerMinute  int     `name:"每分钟最大委托数" yaml:"max_orders_per_minute" default:"30"` // 每分钟最多委托笔数
+	DuplicateSeconds    int     `name:"重复委托间隔" yaml:"duplicate_seconds" default:"60"`       // 同一策略同一标的同方向的委托, 间隔秒数内视为重复委托
+}
@@ -3,6 +3,9 @@ package config
 // RuntimeParameter 运行时配置参数
 type RuntimeParameter struct {
 	Pprof   PprofParameter          `name:"性能分析" yaml:"pprof"`
+	Http    HttpParameter           `name:"HTTP服务" yaml:"http"`
+	Alert   AlertParameter          `name:"任务告警" yaml:"alert"`
 	Debug   bool                    `name:"业务调试开关" yaml:"debug" default:"false"`
+	Clock   ClockParameter          `name:"时钟" yaml:"clock"`
 	Crontab map[string]JobParameter `name:"定时任务" yaml:"crontab" default:"{}"`
 }
@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"gitee.com/quant1x/data/level1/securities"
@@ -21,3 +23,62 @@ func TestBlocks(t *testing.T) {
 	blk := securities.GetBlockInfo(sectorCode)
 	fmt.Println(len(blk.ConstituentStocks))
 }
+
+func TestReloadConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), configFilename)
+	data := "basedir: /tmp/quant1x\ntrader:\n  keep_cash: 20000.00\n  order_path: /tmp/qmt\n"
+	if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	previousFilename := GetConfigFilename()
+	previous := GetGlobalConfig()
+	defer func() {
+		globalMutex.Lock()
+		quant1XConfigFilename = previousFilename
+		globalMutex.Unlock()
+		SetGlobalConfig(previous)
+	}()
+	globalMutex.Lock()
+	quant1XConfigFilename = filename
+	globalMutex.Unlock()
+	SetGlobalConfig(Quant1XConfig{BaseDir: "/opt/quant1x"})
+	reloaded := false
+	OnReload(func() {
+		reloaded = true
+	})
+	if err := ReloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	v := GetGlobalConfig()
+	fmt.Println(v.BaseDir, v.Trader.KeepCash, v.Trader.OrderPath)
+	if v.Trader.KeepCash != 20000 {
+		t.Errorf("keep_cash = %.2f, want 20000", v.Trader.KeepCash)
+	}
+	if v.BaseDir != "/opt/quant1x" || len(v.Trader.OrderPath) > 0 {
+		t.Errorf("basedir and order_path must not change on reload, got %s, %s", v.BaseDir, v.Trader.OrderPath)
+	}
+	if !reloaded {
+		t.Error("reload handler was not called")
+	}
+}
+
+func TestTraderConfigCopy(t *testing.T) {
+	previous := GetGlobalConfig()
+	defer SetGlobalConfig(previous)
+	SetGlobalConfig(Quant1XConfig{Trader: TraderParameter{
+		Strategies: []StrategyParameter{{Id: 1, Auto: true, Total: 3, Weight: 2}},
+	}})
+	trader := TraderConfig()
+	if trader.Strategies[0].Weight != 1 {
+		t.Errorf("weight = %.2f, want 1", trader.Strategies[0].Weight)
+	}
+	strategy := GetStrategyParameterByCode(1)
+	if strategy == nil || strategy.Weight != 1 {
+		t.Fatalf("strategy = %+v", strategy)
+	}
+	strategy.Weight = 0.5
+	// 重置仓位和修改返回的策略都不能改动全局配置
+	if v := GetGlobalConfig().Trader.Strategies[0].Weight; v != 2 {
+		t.Errorf("global weight = %.2f, want 2", v)
+	}
+}
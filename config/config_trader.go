@@ -1,6 +1,10 @@
 package config
 
-import "gitee.com/quant1x/data/exchange"
+import (
+	"slices"
+
+	"gitee.com/quant1x/data/exchange"
+)
 
 // TraderRole 交易员角色
 type TraderRole int
@@ -37,8 +41,11 @@ type TraderParameter struct {
 	BuyAmountMin                float64             `name:"可买最小金额" yaml:"buy_amount_min" default:"1000.00"`                                     // 买入最小金额, 默认1000.00
 	Role                        TraderRole          `name:"角色" yaml:"role" default:"3"`                                                         // 交易员角色, 默认是需要人工干预, 系统不做自动交易处理
 	ProxyUrl                    string              `name:"代理URL" yaml:"proxy_url" default:"http://127.0.0.1:18168/qmt"`                        // 禁止使用公网地址
+	Broker                      string              `name:"交易通道" yaml:"broker" default:""`                                                      // 交易通道, 为空时按角色选择, 默认是qmt
 	Strategies                  []StrategyParameter `name:"策略集合" yaml:"strategies"`                                                             // 策略集合
 	CancelSession               TradingSession      `name:"撤单时段" yaml:"cancel" default:"09:15:00~09:19:59,09:25:00~11:29:59,13:00:00~14:59:59"` // 可撤单配置
+	CancelTimeout               int                 `name:"撤单超时" yaml:"cancel_timeout" default:"0"`                                             // 买入委托超时未成交自动撤单, 单位秒, 默认0不自动撤单
+	Risk                        RiskParameter       `name:"风控" yaml:"risk"`                                                                     // 交易前风控
 	UndertakeRatio              float64             `name:"承接比" yaml:"undertake_ratio" default:"0.8000"`                                        // 竞价承接强度
 }
 
@@ -103,8 +110,13 @@ func (t TraderParameter) DailyRiskFreeRate(date string) float64 {
 }
 
 // TraderConfig 获取交易配置
+//
+//	持有读锁复制配置, 策略列表深拷贝之后再重置仓位占比, 不修改全局配置
 func TraderConfig() TraderParameter {
+	globalMutex.RLock()
 	trader := GlobalConfig.Trader
+	trader.Strategies = slices.Clone(trader.Strategies)
+	globalMutex.RUnlock()
 	trader.ResetPositionRatio()
 	return trader
 }
@@ -112,9 +124,9 @@ func TraderConfig() TraderParameter {
 // GetStrategyParameterByCode 通过策略编码查找规则
 func GetStrategyParameterByCode(strategyCode uint64) *StrategyParameter {
 	strategies := TraderConfig().Strategies
-	for _, v := range strategies {
-		if v.Auto && v.Id == strategyCode {
-			return &v
+	for i := range strategies {
+		if strategies[i].Auto && strategies[i].Id == strategyCode {
+			return &strategies[i]
 		}
 	}
 	return nil
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"gitee.com/quant1x/gox/api"
@@ -29,6 +30,14 @@ type NumberRange struct {
 	max float64
 }
 
+// NewNumberRange 创建数值范围, min大于max时交换
+func NewNumberRange(min, max float64) NumberRange {
+	if min > max {
+		min, max = max, min
+	}
+	return NumberRange{min: min, max: max}
+}
+
 func (this NumberRange) String() string {
 	return fmt.Sprintf("{min: %f, max: %f}", this.min, this.max)
 }
@@ -99,6 +108,21 @@ func (this *NumberRange) Parse(text string) error {
 	return nil
 }
 
+// Text 输出成配置文件的格式, 默认的最小值和最大值省略, 比如"0.382~2.8", "80~"
+func (this NumberRange) Text() string {
+	var begin, end string
+	if this.min != num.MinFloat64 {
+		begin = strconv.FormatFloat(this.min, 'f', -1, 64)
+	}
+	if this.max != num.MaxFloat64 {
+		end = strconv.FormatFloat(this.max, 'f', -1, 64)
+	}
+	if len(end) == 0 {
+		return begin + "~"
+	}
+	return begin + "~" + end
+}
+
 func (this NumberRange) MarshalText() (text []byte, err error) {
 	str := this.String()
 	return api.String2Bytes(str), nil
@@ -3,7 +3,9 @@ package config
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/pkg/yaml"
 )
@@ -37,3 +39,25 @@ name: "buyiwang"
 	err := yaml.Unmarshal(bytes, &v)
 	fmt.Println(err, v)
 }
+
+func TestTradingSessionWithClock(t *testing.T) {
+	var ts TradingSession
+	_ = ts.Parse("09:30:00~11:29:59,13:00:00~14:59:59")
+	tests := []struct {
+		hour, minute  int
+		trading, stop bool
+	}{
+		{9, 0, false, false},
+		{10, 0, true, false},
+		{12, 0, false, false},
+		{14, 0, true, true},
+	}
+	for _, v := range tests {
+		previous := clock.Set(clock.Fixed(time.Date(2024, 1, 2, v.hour, v.minute, 0, 0, time.Local)))
+		trading, stop := ts.IsTrading(), ts.CanStopLoss()
+		clock.Set(previous)
+		if trading != v.trading || stop != v.stop {
+			t.Errorf("%02d:%02d IsTrading=%t CanStopLoss=%t, want %t %t", v.hour, v.minute, trading, stop, v.trading, v.stop)
+		}
+	}
+}
@@ -46,3 +46,31 @@ func TestNumberRange(t *testing.T) {
 	err = r.Parse(text)
 	fmt.Println(r, err)
 }
+
+func TestNumberRangeText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "0.382~2.800", want: "0.382~2.8"},
+		{text: "80~", want: "80~"},
+		{text: "~3.82", want: "~3.82"},
+		{text: "2~1", want: "1~2"},
+	}
+	for _, v := range tests {
+		var r NumberRange
+		_ = r.Parse(v.text)
+		if got := r.Text(); got != v.want {
+			t.Errorf("Text(%q) = %q, want %q", v.text, got, v.want)
+		}
+		var r2 NumberRange
+		_ = r2.Parse(r.Text())
+		if r2 != r {
+			t.Errorf("Parse(Text(%q)) = %s, want %s", v.text, r2, r)
+		}
+	}
+	r := NewNumberRange(3, -3)
+	if r.Min() != -3 || r.Max() != 3 {
+		t.Errorf("NewNumberRange(3, -3) = %s", r)
+	}
+}
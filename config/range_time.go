@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/gox/exception"
 	"gitee.com/quant1x/pkg/yaml"
 )
@@ -22,7 +23,7 @@ var (
 )
 
 func getTradingTimestamp() string {
-	now := time.Now()
+	now := clock.Now()
 	return now.Format(formatOfTimestamp)
 }
 
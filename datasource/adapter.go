@@ -1,7 +1,126 @@
 package datasource
 
-// Adapter 数据源适配器
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gitee.com/quant1x/data/level1/quotes"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/market"
+)
+
+const (
+	SourceTdx    = "tdx"    // 通达信协议
+	SourceReplay = "replay" // 本地回放录制的数据
+	SourceRecord = "record" // 通达信协议, 同时录制到回放目录
+)
+
+const (
+	// 回放数据的默认目录, 在缓存根目录下
+	defaultReplayPath = "replay"
+)
+
+// FinanceInfo 基本财务数据
+type FinanceInfo struct {
+	IPODate      uint32  `name:"上市日期" json:"ipo_date"`        // 上市日期, YYYYMMDD
+	UpdatedDate  uint32  `name:"更新日期" json:"updated_date"`    // 财务数据的更新日期, YYYYMMDD
+	LiuTongGuBen float64 `name:"流通股本" json:"liu_tong_gu_ben"` // 流通股本
+	ZongGuBen    float64 `name:"总股本" json:"zong_gu_ben"`      // 总股本
+}
+
+// Adapter 行情数据源适配器
 //
-//	TODO: 需要根据不同的数据源抽象方法
+//	分页的接口和通达信协议一致, start是从最近一条记录往前数的偏移量, 每页的记录按时间升序.
+//	没有数据时返回空, 不返回错误
 type Adapter interface {
+	// Name 数据源名称
+	Name() string
+	// KLines K线, kType是K线类型, 比如proto.KLINE_TYPE_RI_K, 指数和个股由数据源区分
+	KLines(securityCode string, kType, start, count uint16) ([]quotes.SecurityBar, error)
+	// Minutes 指定日期的分时数据
+	Minutes(securityCode, date string) ([]quotes.MinuteTime, error)
+	// Transactions 指定日期的分笔成交数据, 盘中取当日实时的成交数据
+	Transactions(securityCode, date string, start, count uint16) ([]quotes.TickTransaction, error)
+	// Snapshots 即时行情快照
+	Snapshots(securityCodes []string) ([]quotes.Snapshot, error)
+	// Xdxr 除权除息信息
+	Xdxr(securityCode string) ([]quotes.XdxrInfo, error)
+	// Finance 基本财务数据
+	Finance(securityCode string) (*FinanceInfo, error)
+	// CompanyInfo F10公司信息的正文, category是信息的分类, 比如"资金动向"
+	CompanyInfo(securityCode, category string) (string, error)
+}
+
+var (
+	__adapterMutex sync.RWMutex
+	__adapter      Adapter = nil
+)
+
+func init() {
+	market.RegisterSnapshotSource(func(securityCodes []string) ([]quotes.Snapshot, error) {
+		return GetAdapter().Snapshots(securityCodes)
+	})
+}
+
+// ReplayPath 回放数据的目录, 没有配置时是缓存根目录下的replay
+func ReplayPath() string {
+	path := strings.TrimSpace(config.GetDataConfig().Source.Replay)
+	if len(path) == 0 {
+		path = filepath.Join(cache.GetRootPath(), defaultReplayPath)
+	}
+	return path
+}
+
+// 按配置创建数据源
+func newAdapterFromConfig() Adapter {
+	source := config.GetDataConfig().Source
+	switch strings.ToLower(strings.TrimSpace(source.Name)) {
+	case SourceReplay:
+		return NewReplay(ReplayPath())
+	case SourceRecord:
+		return NewRecorder(NewTdx(), ReplayPath())
+	}
+	return NewTdx()
+}
+
+// GetAdapter 获取当前的数据源, 第一次调用时按配置创建
+func GetAdapter() Adapter {
+	__adapterMutex.RLock()
+	adapter := __adapter
+	__adapterMutex.RUnlock()
+	if adapter != nil {
+		return adapter
+	}
+	__adapterMutex.Lock()
+	defer __adapterMutex.Unlock()
+	if __adapter == nil {
+		__adapter = newAdapterFromConfig()
+	}
+	return __adapter
+}
+
+// StartRecording 录制当前数据源的数据到回放目录, 已经在录制时不重复包装
+func StartRecording() {
+	adapter := GetAdapter()
+	if _, ok := adapter.(*Recorder); ok {
+		return
+	}
+	if _, ok := adapter.(*Replay); ok {
+		// 回放的数据不需要再录制
+		return
+	}
+	SetAdapter(NewRecorder(adapter, ReplayPath()))
+}
+
+// SetAdapter 切换数据源, 返回之前的数据源
+//
+//	测试和回测可以切换到回放数据源, 完全离线执行; adapter为nil时恢复按配置创建
+func SetAdapter(adapter Adapter) Adapter {
+	__adapterMutex.Lock()
+	defer __adapterMutex.Unlock()
+	previous := __adapter
+	__adapter = adapter
+	return previous
 }
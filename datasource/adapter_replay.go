@@ -0,0 +1,304 @@
+package datasource
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/data/level1/quotes"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/gox/api"
+)
+
+// 回放数据的目录结构
+//
+//	${root}/kline/${kType}/${cacheId}.json
+//	${root}/minutes/${YYYYMMDD}/${cacheId}.json
+//	${root}/trans/${YYYYMMDD}/${cacheId}.json
+//	${root}/snapshot/${cacheId}.json
+//	${root}/xdxr/${cacheId}.json
+//	${root}/finance/${cacheId}.json
+//	${root}/f10/${category}/${cacheId}.json
+const (
+	replayKLine    = "kline"
+	replayMinutes  = "minutes"
+	replayTrans    = "trans"
+	replaySnapshot = "snapshot"
+	replayXdxr     = "xdxr"
+	replayFinance  = "finance"
+	replayCompany  = "f10"
+	replaySuffix   = ".json"
+)
+
+// Replay 本地回放的数据源, 只读取Recorder录制的数据, 不访问网络
+//
+//	相同的录制数据总是返回相同的结果, 测试和回测可以离线、确定地执行
+type Replay struct {
+	root string
+}
+
+// NewReplay 创建回放数据源, root是录制数据的根目录
+func NewReplay(root string) *Replay {
+	return &Replay{root: root}
+}
+
+func (r *Replay) Name() string {
+	return SourceReplay
+}
+
+// 按证券代码存储的文件名
+func (r *Replay) filename(category, securityCode string) string {
+	return filepath.Join(r.root, category, cache.CacheId(securityCode)+replaySuffix)
+}
+
+// 按日期和证券代码存储的文件名
+func (r *Replay) dailyFilename(category, securityCode, date string) string {
+	date = exchange.FixTradeDate(date, cache.FilenameDate)
+	return filepath.Join(r.root, category, date, cache.CacheId(securityCode)+replaySuffix)
+}
+
+func (r *Replay) klineFilename(securityCode string, kType uint16) string {
+	return r.filename(filepath.Join(replayKLine, strconv.Itoa(int(kType))), securityCode)
+}
+
+// 加载回放文件, 文件不存在视为没有数据
+func loadReplayFile(filename string, v any) error {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// 保存回放文件
+func saveReplayFile(filename string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err = api.CheckFilepath(filename, true); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// 按通达信协议的方式分页, start是从最后一条记录往前数的偏移量
+func page[T any](list []T, start, count uint16) []T {
+	end := len(list) - int(start)
+	if end <= 0 || count == 0 {
+		return nil
+	}
+	begin := max(0, end-int(count))
+	return slices.Clone(list[begin:end])
+}
+
+func (r *Replay) KLines(securityCode string, kType, start, count uint16) ([]quotes.SecurityBar, error) {
+	var list []quotes.SecurityBar
+	err := loadReplayFile(r.klineFilename(securityCode, kType), &list)
+	if err != nil {
+		return nil, err
+	}
+	return page(list, start, count), nil
+}
+
+func (r *Replay) Minutes(securityCode, date string) ([]quotes.MinuteTime, error) {
+	var list []quotes.MinuteTime
+	err := loadReplayFile(r.dailyFilename(replayMinutes, securityCode, date), &list)
+	return list, err
+}
+
+func (r *Replay) Transactions(securityCode, date string, start, count uint16) ([]quotes.TickTransaction, error) {
+	var list []quotes.TickTransaction
+	err := loadReplayFile(r.dailyFilename(replayTrans, securityCode, date), &list)
+	if err != nil {
+		return nil, err
+	}
+	return page(list, start, count), nil
+}
+
+func (r *Replay) Snapshots(securityCodes []string) ([]quotes.Snapshot, error) {
+	var list []quotes.Snapshot
+	for _, securityCode := range securityCodes {
+		var snapshot *quotes.Snapshot
+		err := loadReplayFile(r.filename(replaySnapshot, securityCode), &snapshot)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot != nil {
+			list = append(list, *snapshot)
+		}
+	}
+	return list, nil
+}
+
+func (r *Replay) Xdxr(securityCode string) ([]quotes.XdxrInfo, error) {
+	var list []quotes.XdxrInfo
+	err := loadReplayFile(r.filename(replayXdxr, securityCode), &list)
+	return list, err
+}
+
+func (r *Replay) Finance(securityCode string) (*FinanceInfo, error) {
+	var info *FinanceInfo
+	err := loadReplayFile(r.filename(replayFinance, securityCode), &info)
+	return info, err
+}
+
+func (r *Replay) CompanyInfo(securityCode, category string) (string, error) {
+	var content string
+	err := loadReplayFile(r.filename(filepath.Join(replayCompany, category), securityCode), &content)
+	return content, err
+}
+
+// Recorder 录制数据源, 透传另一个数据源的数据, 同时保存成回放的格式
+//
+//	配置数据源为record或者update命令带--record参数时启用
+type Recorder struct {
+	source Adapter
+	replay *Replay
+	mutex  sync.Mutex
+}
+
+// NewRecorder 创建录制数据源, 录制的数据保存在root目录, 可以用NewReplay(root)回放
+func NewRecorder(source Adapter, root string) *Recorder {
+	return &Recorder{source: source, replay: NewReplay(root)}
+}
+
+func (r *Recorder) Name() string {
+	return r.source.Name()
+}
+
+func (r *Recorder) KLines(securityCode string, kType, start, count uint16) ([]quotes.SecurityBar, error) {
+	list, err := r.source.KLines(securityCode, kType, start, count)
+	if err != nil || len(list) == 0 {
+		return list, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	// 和已经录制的K线合并, 相同时间的K线以最新的数据为准
+	filename := r.replay.klineFilename(securityCode, kType)
+	var bars []quotes.SecurityBar
+	if err = loadReplayFile(filename, &bars); err != nil {
+		return list, err
+	}
+	merged := slices.Clone(list)
+	for _, v := range bars {
+		if !slices.ContainsFunc(list, func(e quotes.SecurityBar) bool { return e.DateTime == v.DateTime }) {
+			merged = append(merged, v)
+		}
+	}
+	slices.SortStableFunc(merged, func(a, b quotes.SecurityBar) int {
+		return strings.Compare(a.DateTime, b.DateTime)
+	})
+	return list, saveReplayFile(filename, merged)
+}
+
+func (r *Recorder) Minutes(securityCode, date string) ([]quotes.MinuteTime, error) {
+	list, err := r.source.Minutes(securityCode, date)
+	if err != nil || len(list) == 0 {
+		return list, err
+	}
+	return list, saveReplayFile(r.replay.dailyFilename(replayMinutes, securityCode, date), list)
+}
+
+func (r *Recorder) Transactions(securityCode, date string, start, count uint16) ([]quotes.TickTransaction, error) {
+	list, err := r.source.Transactions(securityCode, date, start, count)
+	if err != nil || len(list) == 0 {
+		return list, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	filename := r.replay.dailyFilename(replayTrans, securityCode, date)
+	var recorded []quotes.TickTransaction
+	if err = loadReplayFile(filename, &recorded); err != nil {
+		return list, err
+	}
+	return list, saveReplayFile(filename, mergeTransactions(recorded, list))
+}
+
+// 合并分笔成交, recorded和list都是当日成交中连续的一段
+//
+//	分笔成交的时间只精确到分钟, 同一分钟有多笔, 不能按时间去重.
+//	两段有重叠时按重叠的位置拼接, 没有重叠时按时间先后拼接
+func mergeTransactions(recorded, list []quotes.TickTransaction) []quotes.TickTransaction {
+	if len(recorded) == 0 {
+		return slices.Clone(list)
+	}
+	// list的开头和recorded的某个位置重叠
+	if merged, ok := overlapTransactions(recorded, list); ok {
+		return merged
+	}
+	// recorded的开头和list的某个位置重叠
+	if merged, ok := overlapTransactions(list, recorded); ok {
+		return merged
+	}
+	if list[len(list)-1].Time <= recorded[0].Time {
+		return append(slices.Clone(list), recorded...)
+	}
+	merged := append(slices.Clone(recorded), list...)
+	slices.SortStableFunc(merged, func(a, b quotes.TickTransaction) int {
+		return strings.Compare(a.Time, b.Time)
+	})
+	return merged
+}
+
+// 查找tail的开头在head中重叠的位置, 找到时返回拼接的结果
+func overlapTransactions(head, tail []quotes.TickTransaction) ([]quotes.TickTransaction, bool) {
+	for i := range head {
+		n := min(len(head)-i, len(tail))
+		if !slices.Equal(head[i:i+n], tail[:n]) {
+			continue
+		}
+		merged := append(slices.Clone(head[:i]), tail...)
+		// tail比head剩余的部分短, head后面的成交保留
+		if i+len(tail) < len(head) {
+			merged = append(merged, head[i+len(tail):]...)
+		}
+		return merged, true
+	}
+	return nil, false
+}
+
+func (r *Recorder) Snapshots(securityCodes []string) ([]quotes.Snapshot, error) {
+	list, err := r.source.Snapshots(securityCodes)
+	if err != nil {
+		return list, err
+	}
+	for _, v := range list {
+		securityCode := exchange.GetMarketFlag(v.Market) + v.Code
+		if err = saveReplayFile(r.replay.filename(replaySnapshot, securityCode), v); err != nil {
+			return list, err
+		}
+	}
+	return list, nil
+}
+
+func (r *Recorder) Xdxr(securityCode string) ([]quotes.XdxrInfo, error) {
+	list, err := r.source.Xdxr(securityCode)
+	if err != nil || len(list) == 0 {
+		return list, err
+	}
+	return list, saveReplayFile(r.replay.filename(replayXdxr, securityCode), list)
+}
+
+func (r *Recorder) Finance(securityCode string) (*FinanceInfo, error) {
+	info, err := r.source.Finance(securityCode)
+	if err != nil || info == nil {
+		return info, err
+	}
+	return info, saveReplayFile(r.replay.filename(replayFinance, securityCode), info)
+}
+
+func (r *Recorder) CompanyInfo(securityCode, category string) (string, error) {
+	content, err := r.source.CompanyInfo(securityCode, category)
+	if err != nil || len(content) == 0 {
+		return content, err
+	}
+	return content, saveReplayFile(r.replay.filename(filepath.Join(replayCompany, category), securityCode), content)
+}
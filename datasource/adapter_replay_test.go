@@ -0,0 +1,164 @@
+package datasource
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"gitee.com/quant1x/data/level1/quotes"
+)
+
+// 内存中的数据源, 模拟通达信的分页方式
+type memoryAdapter struct {
+	bars  []quotes.SecurityBar
+	trans []quotes.TickTransaction
+}
+
+func (m *memoryAdapter) Name() string { return "memory" }
+
+func (m *memoryAdapter) KLines(securityCode string, kType, start, count uint16) ([]quotes.SecurityBar, error) {
+	return page(m.bars, start, count), nil
+}
+
+func (m *memoryAdapter) Minutes(securityCode, date string) ([]quotes.MinuteTime, error) {
+	return []quotes.MinuteTime{{Price: 10.0, Vol: 100}, {Price: 10.1, Vol: 200}}, nil
+}
+
+func (m *memoryAdapter) Transactions(securityCode, date string, start, count uint16) ([]quotes.TickTransaction, error) {
+	return page(m.trans, start, count), nil
+}
+
+func (m *memoryAdapter) Snapshots(securityCodes []string) ([]quotes.Snapshot, error) {
+	return nil, nil
+}
+
+func (m *memoryAdapter) Xdxr(securityCode string) ([]quotes.XdxrInfo, error) {
+	return nil, nil
+}
+
+func (m *memoryAdapter) Finance(securityCode string) (*FinanceInfo, error) {
+	return &FinanceInfo{IPODate: 20100105, ZongGuBen: 1e8}, nil
+}
+
+func (m *memoryAdapter) CompanyInfo(securityCode, category string) (string, error) {
+	return category, nil
+}
+
+func newMemoryAdapter() *memoryAdapter {
+	m := &memoryAdapter{}
+	for i := 0; i < 10; i++ {
+		m.bars = append(m.bars, quotes.SecurityBar{DateTime: fmt.Sprintf("2024-01-%02d 15:00", i+1), Close: float64(10 + i)})
+	}
+	for i := 0; i < 7; i++ {
+		m.trans = append(m.trans, quotes.TickTransaction{Time: fmt.Sprintf("09:3%d", i), Price: float64(10 + i)})
+	}
+	return m
+}
+
+func TestPage(t *testing.T) {
+	list := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		start, count uint16
+		want         []int
+	}{
+		{0, 2, []int{4, 5}},
+		{2, 2, []int{2, 3}},
+		{4, 2, []int{1}},
+		{5, 2, nil},
+		{0, 0, nil},
+		{0, 10, []int{1, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		if got := page(list, tt.start, tt.count); !slices.Equal(got, tt.want) {
+			t.Errorf("page(start=%d, count=%d) = %v, want %v", tt.start, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestRecorderAndReplay(t *testing.T) {
+	root := t.TempDir()
+	source := newMemoryAdapter()
+	recorder := NewRecorder(source, root)
+	code := "sh600600"
+	date := "2024-01-10"
+	// 分两页录制K线, 先取最近的一页, 再取更早的一页
+	first, _ := recorder.KLines(code, 9, 0, 6)
+	second, _ := recorder.KLines(code, 9, 6, 6)
+	if len(first) != 6 || len(second) != 4 {
+		t.Fatalf("recorder kline pages = %d/%d, want 6/4", len(first), len(second))
+	}
+	for start := uint16(0); start < 7; start += 3 {
+		_, _ = recorder.Transactions(code, date, start, 3)
+	}
+	// 再次取最近的一页, 不能覆盖已经录制的更早的成交
+	_, _ = recorder.Transactions(code, date, 0, 3)
+	_, _ = recorder.Minutes(code, date)
+	_, _ = recorder.Finance(code)
+	_, _ = recorder.CompanyInfo(code, "资金动向")
+
+	replay := NewReplay(root)
+	bars, err := replay.KLines(code, 9, 0, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(bars, source.bars) {
+		t.Errorf("replay klines = %v, want %v", bars, source.bars)
+	}
+	bars, _ = replay.KLines(code, 9, 2, 3)
+	if !slices.Equal(bars, source.bars[5:8]) {
+		t.Errorf("replay kline page = %v, want %v", bars, source.bars[5:8])
+	}
+	trans, _ := replay.Transactions(code, date, 0, 100)
+	if !slices.Equal(trans, source.trans) {
+		t.Errorf("replay transactions = %v, want %v", trans, source.trans)
+	}
+	minutes, _ := replay.Minutes(code, date)
+	if len(minutes) != 2 {
+		t.Errorf("replay minutes = %d, want 2", len(minutes))
+	}
+	info, _ := replay.Finance(code)
+	if info == nil || info.IPODate != 20100105 {
+		t.Errorf("replay finance = %+v", info)
+	}
+	if content, _ := replay.CompanyInfo(code, "资金动向"); content != "资金动向" {
+		t.Errorf("replay company info = %q", content)
+	}
+	// 没有录制的数据返回空
+	bars, err = replay.KLines("sz000001", 9, 0, 10)
+	if err != nil || len(bars) != 0 {
+		t.Errorf("replay missing klines = %v, %v", bars, err)
+	}
+}
+
+func TestSetAdapter(t *testing.T) {
+	replay := NewReplay(t.TempDir())
+	previous := SetAdapter(replay)
+	defer SetAdapter(previous)
+	if GetAdapter() != replay {
+		t.Errorf("GetAdapter() = %v, want replay", GetAdapter())
+	}
+}
+
+func TestMergeTransactions(t *testing.T) {
+	var all []quotes.TickTransaction
+	for i := 0; i < 8; i++ {
+		// 同一分钟有多笔成交
+		all = append(all, quotes.TickTransaction{Time: fmt.Sprintf("09:3%d", i/2), Price: float64(10 + i)})
+	}
+	tests := []struct {
+		name           string
+		recorded, list []quotes.TickTransaction
+		want           []quotes.TickTransaction
+	}{
+		{"empty", nil, all[5:], all[5:]},
+		{"earlier page", all[4:], all[1:5], all[1:]},
+		{"later page", all[:6], all[4:], all},
+		{"inside", all, all[2:4], all},
+		{"no overlap", all[4:], all[:4], all},
+	}
+	for _, tt := range tests {
+		if got := mergeTransactions(tt.recorded, tt.list); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
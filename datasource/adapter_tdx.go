@@ -0,0 +1,93 @@
+package datasource
+
+import (
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/data/level1"
+	"gitee.com/quant1x/data/level1/quotes"
+)
+
+// Tdx 通达信协议的数据源
+type Tdx struct{}
+
+// NewTdx 创建通达信协议的数据源
+func NewTdx() *Tdx {
+	return &Tdx{}
+}
+
+func (t *Tdx) Name() string {
+	return SourceTdx
+}
+
+func (t *Tdx) KLines(securityCode string, kType, start, count uint16) ([]quotes.SecurityBar, error) {
+	tdxApi := level1.GetApi()
+	var data *quotes.SecurityBarsReply
+	var err error
+	if exchange.AssertIndexBySecurityCode(securityCode) {
+		data, err = tdxApi.GetIndexBars(securityCode, kType, start, count)
+	} else {
+		data, err = tdxApi.GetKLine(securityCode, kType, start, count)
+	}
+	if err != nil || data == nil || data.Count == 0 {
+		return nil, err
+	}
+	return data.List, nil
+}
+
+func (t *Tdx) Minutes(securityCode, date string) ([]quotes.MinuteTime, error) {
+	tdxApi := level1.GetApi()
+	u32Date := exchange.ToUint32Date(date)
+	hs, err := tdxApi.GetHistoryMinuteTimeData(securityCode, u32Date)
+	if err != nil || hs == nil || hs.Count == 0 {
+		return nil, err
+	}
+	return hs.List, nil
+}
+
+func (t *Tdx) Transactions(securityCode, date string, start, count uint16) ([]quotes.TickTransaction, error) {
+	tdxApi := level1.GetApi()
+	var data *quotes.TransactionReply
+	var err error
+	if exchange.CurrentlyTrading(date) {
+		data, err = tdxApi.GetTransactionData(securityCode, start, count)
+	} else {
+		u32Date := exchange.ToUint32Date(date)
+		data, err = tdxApi.GetHistoryTransactionData(securityCode, u32Date, start, count)
+	}
+	if err != nil || data == nil || data.Count == 0 {
+		return nil, err
+	}
+	return data.List, nil
+}
+
+func (t *Tdx) Snapshots(securityCodes []string) ([]quotes.Snapshot, error) {
+	tdxApi := level1.GetApi()
+	return tdxApi.GetSnapshot(securityCodes)
+}
+
+func (t *Tdx) Xdxr(securityCode string) ([]quotes.XdxrInfo, error) {
+	tdxApi := level1.GetApi()
+	return tdxApi.GetXdxrInfo(securityCode)
+}
+
+func (t *Tdx) Finance(securityCode string) (*FinanceInfo, error) {
+	tdxApi := level1.GetApi()
+	info, err := tdxApi.GetFinanceInfo(securityCode)
+	if err != nil || info == nil {
+		return nil, err
+	}
+	return &FinanceInfo{
+		IPODate:      info.IPODate,
+		UpdatedDate:  info.UpdatedDate,
+		LiuTongGuBen: info.LiuTongGuBen,
+		ZongGuBen:    info.ZongGuBen,
+	}, nil
+}
+
+func (t *Tdx) CompanyInfo(securityCode, category string) (string, error) {
+	tdxApi := level1.GetApi()
+	reply, err := tdxApi.GetCompanyInfoContent(securityCode, category)
+	if err != nil || reply == nil {
+		return "", err
+	}
+	return reply.Content, nil
+}
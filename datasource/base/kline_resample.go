@@ -0,0 +1,387 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/data/level1/quotes"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/gox/api"
+	"gitee.com/quant1x/gox/logger"
+)
+
+// A股的交易时段, 当日的秒数
+const (
+	sessionCallAuctionBegin = (9*60 + 15) * 60  // 集合竞价开始
+	sessionMorningBegin     = (9*60 + 30) * 60  // 上午开盘
+	sessionMorningEnd       = (11*60 + 30) * 60 // 上午收盘
+	sessionAfternoonBegin   = 13 * 60 * 60      // 下午开盘
+	sessionAfternoonEnd     = 15 * 60 * 60      // 下午收盘
+)
+
+const (
+	SessionMorningMinutes = 120 // 上午的交易分钟数, 半日市在第120分钟收盘
+	SessionMinutes        = 240 // 全天的交易分钟数
+	minuteKLineSource     = "1min"
+)
+
+var (
+	// 服务器原生支持的分钟周期
+	nativeMinuteFrequencies = []int{1, 5, 15, 30, 60}
+)
+
+var (
+	ErrFrequency = errors.New("unsupported kline frequency") // 不支持的K线周期
+	// 周期的格式, 数字加单位, 数字省略时是1
+	frequencyPattern = regexp.MustCompile(`^(\d*)\s*(min|m|h|hour|d|day)s?$`)
+)
+
+// Frequency K线周期, 分钟数和天数只有一个大于0
+type Frequency struct {
+	Minutes int // 日内K线的分钟数
+	Days    int // 日线以上K线的交易日数
+}
+
+// ParseFrequency 解析K线周期, 支持N分钟(5min,5m)、N小时(2h)和N个交易日(3d)
+//
+//	分钟数达到全天的交易分钟数时必须是整天, 按天数处理
+func ParseFrequency(freq string) (Frequency, error) {
+	text := strings.ToLower(strings.TrimSpace(freq))
+	matches := frequencyPattern.FindStringSubmatch(text)
+	if len(matches) == 0 {
+		return Frequency{}, fmt.Errorf("%w: %s", ErrFrequency, freq)
+	}
+	n := 1
+	if len(matches[1]) > 0 {
+		v, err := strconv.Atoi(matches[1])
+		if err != nil || v <= 0 {
+			return Frequency{}, fmt.Errorf("%w: %s", ErrFrequency, freq)
+		}
+		n = v
+	}
+	var f Frequency
+	switch matches[2] {
+	case "min", "m":
+		f.Minutes = n
+	case "h", "hour":
+		f.Minutes = n * 60
+	default:
+		f.Days = n
+	}
+	if f.Minutes >= SessionMinutes {
+		if f.Minutes%SessionMinutes != 0 {
+			return Frequency{}, fmt.Errorf("%w: %s", ErrFrequency, freq)
+		}
+		f.Days, f.Minutes = f.Minutes/SessionMinutes, 0
+	}
+	return f, nil
+}
+
+// Key 周期的关键字, 也是K线缓存的路径名, 分钟是Nmin, 天是Nd
+func (f Frequency) Key() string {
+	if f.Days > 0 {
+		return fmt.Sprintf("%dd", f.Days)
+	}
+	return fmt.Sprintf("%dmin", f.Minutes)
+}
+
+// Intraday 是否日内周期
+func (f Frequency) Intraday() bool {
+	return f.Days == 0
+}
+
+// Native 是否服务器原生的分钟周期, 原生周期直接拉取, 历史比1分钟K线长得多, 不能用1分钟K线重采样覆盖
+func (f Frequency) Native() bool {
+	return f.Intraday() && slices.Contains(nativeMinuteFrequencies, f.Minutes)
+}
+
+// TradingMinute 时刻对应的交易分钟序号, seconds是当日的秒数
+//
+//	序号从1开始, 第n分钟是左开右闭的区间, 集合竞价归入第1分钟, 午间休市归入上午的最后1分钟,
+//	收盘之后归入最后1分钟, 集合竞价之前返回false
+func TradingMinute(seconds int) (int, bool) {
+	switch {
+	case seconds < sessionCallAuctionBegin:
+		return 0, false
+	case seconds <= sessionMorningBegin:
+		return 1, true
+	case seconds <= sessionMorningEnd:
+		return (seconds - sessionMorningBegin + 59) / 60, true
+	case seconds <= sessionAfternoonBegin:
+		return SessionMorningMinutes, true
+	case seconds <= sessionAfternoonEnd:
+		return SessionMorningMinutes + (seconds-sessionAfternoonBegin+59)/60, true
+	default:
+		return SessionMinutes, true
+	}
+}
+
+// TradingMinuteTime 交易分钟结束的时刻, 当日的秒数
+func TradingMinuteTime(minute int) int {
+	if minute <= SessionMorningMinutes {
+		return sessionMorningBegin + minute*60
+	}
+	return sessionAfternoonBegin + (minute-SessionMorningMinutes)*60
+}
+
+// SessionBarMinute 交易分钟所属K线的结束分钟, period是K线的分钟数, closeMinute是当日收盘的交易分钟
+//
+//	K线从开盘连续计数, 跨午休的K线在下午结束, 比如90分钟K线是11:00,14:00和15:00
+func SessionBarMinute(minute, period, closeMinute int) int {
+	n := (minute + period - 1) / period
+	return min(n*period, closeMinute)
+}
+
+// 时刻的文本, HH:MM:00
+func sessionTimeText(seconds int) string {
+	return fmt.Sprintf("%02d:%02d:00", seconds/3600, seconds%3600/60)
+}
+
+// 解析时间HH:MM[:SS], 返回当日的秒数
+func parseSessionTime(tm string) (int, bool) {
+	parts := strings.Split(strings.TrimSpace(tm), ":")
+	if len(parts) < 2 {
+		return 0, false
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, false
+	}
+	second := 0
+	if len(parts) > 2 {
+		// 秒可能带毫秒
+		second, _ = strconv.Atoi(strings.SplitN(parts[2], ".", 2)[0])
+	}
+	return hour*3600 + minute*60 + second, true
+}
+
+// K线的时刻, 取Datetime的时间部分
+func klineSeconds(v KLine) (int, bool) {
+	_, tm, ok := strings.Cut(strings.TrimSpace(v.Datetime), " ")
+	if !ok {
+		return 0, false
+	}
+	return parseSessionTime(tm)
+}
+
+// 合并K线, 开盘价取第一根, 收盘价和计数取最后一根
+func mergeKLine(dest *KLine, v KLine) {
+	dest.Close = v.Close
+	dest.High = max(dest.High, v.High)
+	dest.Low = min(dest.Low, v.Low)
+	dest.Volume += v.Volume
+	dest.Amount += v.Amount
+	dest.Up = v.Up
+	dest.Down = v.Down
+	dest.AdjustmentCount = v.AdjustmentCount
+}
+
+// 按分钟数重采样日内K线, 每个交易日独立对齐
+func resampleMinutes(klines []KLine, period int) []KLine {
+	var result []KLine
+	minutes := make([]int, len(klines))
+	for i := 0; i < len(klines); {
+		date := klines[i].Date
+		j := i
+		closeMinute := SessionMorningMinutes
+		for ; j < len(klines) && klines[j].Date == date; j++ {
+			minute := 0
+			if seconds, ok := klineSeconds(klines[j]); ok {
+				minute, _ = TradingMinute(seconds)
+			}
+			minutes[j] = minute
+			if minute > SessionMorningMinutes {
+				closeMinute = SessionMinutes
+			}
+		}
+		lastEnd := 0
+		for k := i; k < j; k++ {
+			if minutes[k] == 0 {
+				// 集合竞价之前或者时间无效的K线
+				continue
+			}
+			end := SessionBarMinute(minutes[k], period, closeMinute)
+			if end == lastEnd {
+				mergeKLine(&result[len(result)-1], klines[k])
+				continue
+			}
+			bar := klines[k]
+			bar.Datetime = date + " " + sessionTimeText(TradingMinuteTime(end))
+			result = append(result, bar)
+			lastEnd = end
+		}
+		i = j
+	}
+	return result
+}
+
+// 按交易日数重采样, 先合成日线, 再按交易日历分组, 每days个交易日合成一根K线
+//
+//	calendar是从固定起点开始的升序交易日历, 交易日在日历中的序号除以days就是分组,
+//	分组和数据源的第一个交易日无关, 数据源的起点变化时历史K线的边界不变
+func resampleDays(klines []KLine, days int, calendar []string) []KLine {
+	daily := resampleMinutes(klines, SessionMinutes)
+	var result []KLine
+	lastGroup := -1
+	for _, v := range daily {
+		index, _ := slices.BinarySearch(calendar, v.Date)
+		group := index / days
+		if group == lastGroup {
+			bar := &result[len(result)-1]
+			mergeKLine(bar, v)
+			bar.Date = v.Date
+			bar.Datetime = v.Datetime
+			continue
+		}
+		result = append(result, v)
+		lastGroup = group
+	}
+	return result
+}
+
+// 从A股第一个交易日到date的交易日历, N个交易日的K线以此为起点计数
+func resampleCalendar(date string) []string {
+	return exchange.TradingDateRange(exchange.MARKET_CH_FIRST_LISTTIME, date)
+}
+
+// ResampleKLines 用分钟K线重采样成freq周期的K线, klines按时间升序
+//
+//	日内周期按A股的交易时段对齐: 集合竞价归入开盘的第一根K线, K线不跨交易日, 半日市(当日没有下午的K线)在11:30收盘.
+//	K线的时间是结束时刻. 周期是N个交易日时从A股的第一个交易日开始按交易日历计数, 日期是最后一个交易日
+func ResampleKLines(klines []KLine, freq string) ([]KLine, error) {
+	f, err := ParseFrequency(freq)
+	if err != nil {
+		return nil, err
+	}
+	if f.Intraday() {
+		return resampleMinutes(klines, f.Minutes), nil
+	}
+	if len(klines) == 0 {
+		return nil, nil
+	}
+	return resampleDays(klines, f.Days, resampleCalendar(klines[len(klines)-1].Date)), nil
+}
+
+// TransactionsToKLines 用一个交易日的成交数据合成1分钟K线, 成交量的单位从手改成股
+//
+//	成交时间只精确到分钟, HH:MM的成交归入HH:MM+1的K线, 11:30和15:00的成交归入当时的K线
+func TransactionsToKLines(date string, list []quotes.TickTransaction) []KLine {
+	date = exchange.FixTradeDate(date)
+	var klines []KLine
+	lastMinute := 0
+	for _, v := range list {
+		seconds, ok := parseSessionTime(v.Time)
+		if !ok || v.Price <= 0 {
+			continue
+		}
+		minute, ok := TradingMinute(seconds + 1)
+		if !ok {
+			continue
+		}
+		volume := float64(v.Vol) * 100
+		if minute != lastMinute {
+			klines = append(klines, KLine{
+				Date:     date,
+				Open:     v.Price,
+				Close:    v.Price,
+				High:     v.Price,
+				Low:      v.Price,
+				Datetime: date + " " + sessionTimeText(TradingMinuteTime(minute)),
+			})
+			lastMinute = minute
+		}
+		kline := &klines[len(klines)-1]
+		kline.Close = v.Price
+		kline.High = max(kline.High, v.Price)
+		kline.Low = min(kline.Low, v.Price)
+		kline.Volume += volume
+		kline.Amount += volume * v.Price
+	}
+	return klines
+}
+
+// 合并已有的K线缓存和重采样的K线, 保留重采样数据第一个交易日之前的缓存, 之后的用重采样的结果替换
+func mergeResampledKLines(cached, resampled []KLine) []KLine {
+	if len(resampled) == 0 {
+		return cached
+	}
+	first := resampled[0].Date
+	n := 0
+	for n < len(cached) && cached[n].Date < first {
+		n++
+	}
+	klines := make([]KLine, 0, n+len(resampled))
+	klines = append(klines, cached[:n]...)
+	return append(klines, resampled...)
+}
+
+// 用缓存的历史成交数据合成1分钟K线, 不从服务器下载
+//
+//	成交数据是不复权的价格, 合成之后按除权除息记录前复权, 和日K线、1分钟K线的口径保持一致
+func minuteKLinesFromTransactions(securityCode string) []KLine {
+	var klines []KLine
+	dates := exchange.TradingDateRange(GetBeginDateOfHistoricalTradingData(), clock.Today())
+	for _, date := range dates {
+		filename := cache.TransFilename(securityCode, date)
+		if !api.FileExist(filename) {
+			continue
+		}
+		var list []quotes.TickTransaction
+		if err := api.CsvToSlices(filename, &list); err != nil {
+			logger.Errorf("cache %s failed, error: %+v", filename, err)
+			continue
+		}
+		klines = append(klines, TransactionsToKLines(date, list)...)
+	}
+	calculatePreAdjustedStockPrice(securityCode, klines, exchange.MARKET_CH_FIRST_LISTTIME)
+	return klines
+}
+
+// UpdateResampledKLines 用缓存的1分钟K线重采样, 同时保存多个周期的K线
+//
+//	没有1分钟K线时用缓存的历史成交数据合成, 合成的K线先前复权再和缓存合并, 只在没有1分钟K线时使用.
+//	1分钟K线的历史比较短, 重采样的结果和已有的缓存合并, 数据源之前的K线保留, 原生周期应该用UpdateAllKLine直接拉取
+func UpdateResampledKLines(securityCode string, freqs ...string) {
+	securityCode = exchange.CorrectSecurityCode(securityCode)
+	source := LoadKline(securityCode, minuteKLineSource)
+	fromTransactions := false
+	if len(source) == 0 {
+		source = minuteKLinesFromTransactions(securityCode)
+		fromTransactions = true
+	}
+	if len(source) == 0 {
+		return
+	}
+	for _, freq := range freqs {
+		f, err := ParseFrequency(freq)
+		if err != nil {
+			logger.Errorf("code=%s, %+v", securityCode, err)
+			continue
+		}
+		key := f.Key()
+		klines := source
+		if key == minuteKLineSource {
+			if !fromTransactions {
+				// 1分钟K线就是数据源, 不需要重新保存
+				continue
+			}
+		} else {
+			klines, _ = ResampleKLines(source, key)
+		}
+		klines = mergeResampledKLines(LoadKline(securityCode, key), klines)
+		filename := cache.KLineFilenameEx(securityCode, key)
+		if err = api.SlicesToCsv(filename, klines); err != nil {
+			logger.Errorf("cache %s failed, error: %+v", filename, err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gitee.com/quant1x/data/level1/quotes"
+)
+
+func TestParseFrequency(t *testing.T) {
+	tests := map[string]string{
+		"1min":  "1min",
+		"5m":    "5min",
+		"90MIN": "90min",
+		"2h":    "120min",
+		"4h":    "1d",
+		"d":     "1d",
+		"3day":  "3d",
+	}
+	for freq, want := range tests {
+		f, err := ParseFrequency(freq)
+		if err != nil || f.Key() != want {
+			t.Errorf("ParseFrequency(%s) = %s, %v, want %s", freq, f.Key(), err, want)
+		}
+	}
+	for _, freq := range []string{"", "0min", "300min", "1w", "abc"} {
+		if _, err := ParseFrequency(freq); !errors.Is(err, ErrFrequency) {
+			t.Errorf("ParseFrequency(%s) error = %v", freq, err)
+		}
+	}
+}
+
+func TestFrequencyNative(t *testing.T) {
+	tests := map[string]bool{
+		"1min":  true,
+		"5m":    true,
+		"15min": true,
+		"30min": true,
+		"1h":    true,
+		"10min": false,
+		"90min": false,
+		"2h":    false,
+		"1d":    false,
+	}
+	for freq, want := range tests {
+		f, err := ParseFrequency(freq)
+		if err != nil || f.Native() != want {
+			t.Errorf("ParseFrequency(%s).Native() = %t, %v, want %t", freq, f.Native(), err, want)
+		}
+	}
+}
+
+func Test_mergeResampledKLines(t *testing.T) {
+	cached := []KLine{
+		{Date: "2024-01-02", Close: 1},
+		{Date: "2024-01-03", Close: 2},
+		{Date: "2024-01-04", Close: 3},
+	}
+	resampled := []KLine{
+		{Date: "2024-01-04", Close: 30},
+		{Date: "2024-01-05", Close: 40},
+	}
+	got := mergeResampledKLines(cached, resampled)
+	if len(got) != 4 || got[1].Close != 2 || got[2].Close != 30 || got[3].Close != 40 {
+		t.Errorf("mergeResampledKLines() = %+v", got)
+	}
+	if got = mergeResampledKLines(cached, nil); len(got) != 3 {
+		t.Errorf("mergeResampledKLines(nil) = %+v", got)
+	}
+	if got = mergeResampledKLines(nil, resampled); len(got) != 2 {
+		t.Errorf("mergeResampledKLines(empty cache) = %+v", got)
+	}
+}
+
+func TestTradingMinute(t *testing.T) {
+	tests := []struct {
+		tm     string
+		minute int
+		ok     bool
+	}{
+		{"09:10:00", 0, false},
+		{"09:25:00", 1, true},
+		{"09:31:00", 1, true},
+		{"09:31:01", 2, true},
+		{"11:30:00", 120, true},
+		{"12:00:00", 120, true},
+		{"13:01:00", 121, true},
+		{"15:00:00", 240, true},
+		{"15:05:00", 240, true},
+	}
+	for _, tt := range tests {
+		seconds, _ := parseSessionTime(tt.tm)
+		minute, ok := TradingMinute(seconds)
+		if minute != tt.minute || ok != tt.ok {
+			t.Errorf("TradingMinute(%s) = %d, %v, want %d, %v", tt.tm, minute, ok, tt.minute, tt.ok)
+		}
+	}
+}
+
+// 一个交易日的1分钟K线, 每根K线的收盘价是交易分钟序号
+func minuteKLines(date string, last int) []KLine {
+	var klines []KLine
+	for m := 1; m <= last; m++ {
+		seconds := TradingMinuteTime(m)
+		klines = append(klines, KLine{
+			Date:     date,
+			Open:     float64(m),
+			Close:    float64(m),
+			High:     float64(m),
+			Low:      float64(m),
+			Volume:   100,
+			Datetime: date + " " + sessionTimeText(seconds)[:5],
+		})
+	}
+	return klines
+}
+
+func TestResampleKLines(t *testing.T) {
+	klines := minuteKLines("2024-01-02", SessionMinutes)
+	tests := []struct {
+		freq  string
+		times []string
+	}{
+		{"30min", []string{"10:00", "10:30", "11:00", "11:30", "13:30", "14:00", "14:30", "15:00"}},
+		{"60min", []string{"10:30", "11:30", "14:00", "15:00"}},
+		{"90min", []string{"11:00", "14:00", "15:00"}},
+		{"2h", []string{"11:30", "15:00"}},
+	}
+	for _, tt := range tests {
+		bars, err := ResampleKLines(klines, tt.freq)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bars) != len(tt.times) {
+			t.Fatalf("%s: bars = %d, want %d", tt.freq, len(bars), len(tt.times))
+		}
+		for i, v := range bars {
+			if want := "2024-01-02 " + tt.times[i] + ":00"; v.Datetime != want {
+				t.Errorf("%s: bars[%d].Datetime = %s, want %s", tt.freq, i, v.Datetime, want)
+			}
+		}
+	}
+	bars, _ := ResampleKLines(klines, "60min")
+	first := bars[0]
+	if first.Open != 1 || first.Close != 60 || first.High != 60 || first.Low != 1 || first.Volume != 6000 {
+		t.Fatalf("bars[0] = %+v", first)
+	}
+	if bars[2].Open != 121 || bars[2].Close != 180 {
+		t.Fatalf("bars[2] = %+v", bars[2])
+	}
+}
+
+func TestResampleKLinesHalfDay(t *testing.T) {
+	klines := minuteKLines("2024-01-02", SessionMorningMinutes)
+	bars, _ := ResampleKLines(klines, "90min")
+	if len(bars) != 2 || bars[1].Datetime != "2024-01-02 11:30:00" || bars[1].Volume != 3000 {
+		t.Fatalf("半日市的最后一根K线在11:30收盘, bars = %+v", bars)
+	}
+	bars, _ = ResampleKLines(klines, "1d")
+	if len(bars) != 1 || bars[0].Datetime != "2024-01-02 11:30:00" {
+		t.Fatalf("半日市的日线, bars = %+v", bars)
+	}
+}
+
+func TestResampleKLinesDays(t *testing.T) {
+	var calendar []string
+	var klines []KLine
+	for i := 2; i <= 6; i++ {
+		date := fmt.Sprintf("2024-01-%02d", i)
+		calendar = append(calendar, date)
+		klines = append(klines, minuteKLines(date, SessionMinutes)...)
+	}
+	bars := resampleDays(klines, 2, calendar)
+	if len(bars) != 3 {
+		t.Fatalf("bars = %d, want 3", len(bars))
+	}
+	if bars[0].Date != "2024-01-03" || bars[0].Volume != 2*SessionMinutes*100 || bars[2].Date != "2024-01-06" {
+		t.Fatalf("bars = %+v", bars)
+	}
+	// 数据源从01-03开始, 分组仍然按日历对齐, 01-03单独一根K线
+	bars = resampleDays(klines[SessionMinutes:], 2, calendar)
+	if len(bars) != 3 || bars[0].Date != "2024-01-03" || bars[0].Volume != SessionMinutes*100 || bars[1].Date != "2024-01-05" {
+		t.Fatalf("数据源的起点不影响分组, bars = %+v", bars)
+	}
+}
+
+func TestTransactionsToKLines(t *testing.T) {
+	list := []quotes.TickTransaction{
+		{Time: "09:25", Price: 10.00, Vol: 10},
+		{Time: "09:30", Price: 10.10, Vol: 5},
+		{Time: "09:31", Price: 10.20, Vol: 1},
+		{Time: "11:30", Price: 10.30, Vol: 1},
+		{Time: "13:00", Price: 10.40, Vol: 1},
+		{Time: "15:00", Price: 10.50, Vol: 2},
+	}
+	klines := TransactionsToKLines("20240102", list)
+	want := []string{"09:31:00", "09:32:00", "11:30:00", "13:01:00", "15:00:00"}
+	if len(klines) != len(want) {
+		t.Fatalf("klines = %+v", klines)
+	}
+	for i, v := range klines {
+		if v.Datetime != "2024-01-02 "+want[i] {
+			t.Errorf("klines[%d].Datetime = %s, want %s", i, v.Datetime, want[i])
+		}
+	}
+	if first := klines[0]; first.Open != 10.00 || first.Close != 10.10 || first.Volume != 1500 {
+		t.Fatalf("集合竞价归入第一根K线, klines[0] = %+v", first)
+	}
+}
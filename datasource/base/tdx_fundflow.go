@@ -5,7 +5,7 @@ import (
 	"strings"
 
 	"gitee.com/quant1x/data/exchange"
-	"gitee.com/quant1x/data/level1"
+	"gitee.com/quant1x/engine/datasource"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/num"
 	"gitee.com/quant1x/pandas"
@@ -128,9 +128,8 @@ func splitContent(content, unit string) (headers []string, lines [][]string) {
 //
 //	deprecated: 不推荐
 func FundFlow(securityCode string) pandas.DataFrame {
-	tdxApi := level1.GetApi()
 	securityCode = exchange.CorrectSecurityCode(securityCode)
-	reply, err := tdxApi.GetCompanyInfoContent(securityCode, kCompanyInfoFundFlow)
+	content, err := datasource.GetAdapter().CompanyInfo(securityCode, kCompanyInfoFundFlow)
 	if err != nil {
 		return pandas.DataFrame{Err: err}
 	}
@@ -144,7 +143,7 @@ func FundFlow(securityCode string) pandas.DataFrame {
 	//	fmt.Println(key, value)
 	//})
 
-	headers, lines := splitContent(reply.Content, kCategoryFundFlow)
+	headers, lines := splitContent(content, kCategoryFundFlow)
 	//for _, v := range headers {
 	//	fmt.Printf("%s|", v)
 	//}
@@ -5,10 +5,10 @@ import (
 	"time"
 
 	"gitee.com/quant1x/data/exchange"
-	"gitee.com/quant1x/data/level1"
 	"gitee.com/quant1x/data/level1/proto"
 	"gitee.com/quant1x/data/level1/quotes"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/datasource"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/logger"
 	"gitee.com/quant1x/num"
@@ -63,7 +63,6 @@ func UpdateAllBasicKLine(securityCode string) []KLine {
 	// 1. 确定本地有效数据最后1条数据作为拉取数据的开始日期
 	startDate := exchange.MARKET_CH_FIRST_LISTTIME
 	securityCode = exchange.CorrectSecurityCode(securityCode)
-	isIndex := exchange.AssertIndexBySecurityCode(securityCode)
 	cacheKLines := LoadBasicKline(securityCode)
 	kLength := len(cacheKLines)
 	var klineDaysOffset = DataDaysDiff
@@ -110,9 +109,9 @@ func UpdateAllBasicKLine(securityCode string) []KLine {
 	step := uint16(quotes.SECURITY_BARS_MAX)
 	total := uint16(len(ts))
 	start := uint16(0)
-	hs := make([]quotes.SecurityBarsReply, 0)
+	hs := make([][]quotes.SecurityBar, 0)
 	kType := uint16(proto.KLINE_TYPE_RI_K)
-	tdxApi := level1.GetApi()
+	source := datasource.GetAdapter()
 	// 3. 拉取数据
 	for {
 		count := step
@@ -121,16 +120,12 @@ func UpdateAllBasicKLine(securityCode string) []KLine {
 		} else {
 			count = total - start
 		}
-		var data *quotes.SecurityBarsReply
+		var data []quotes.SecurityBar
 		var err error
 		retryTimes := 0
 		for retryTimes < quotes.DefaultRetryTimes {
-			if isIndex {
-				data, err = tdxApi.GetIndexBars(securityCode, kType, start, count)
-			} else {
-				data, err = tdxApi.GetKLine(securityCode, kType, start, count)
-			}
-			if err == nil && data != nil {
+			data, err = source.KLines(securityCode, kType, start, count)
+			if err == nil {
 				break
 			}
 			retryTimes++
@@ -139,11 +134,11 @@ func UpdateAllBasicKLine(securityCode string) []KLine {
 			logger.Errorf("code=%s, error=%s", securityCode, err.Error())
 			return []KLine{}
 		}
-		if data == nil || data.Count == 0 || len(data.List) == 0 {
+		if len(data) == 0 {
 			break
 		}
-		hs = append(hs, *data)
-		if data.Count < count {
+		hs = append(hs, data)
+		if uint16(len(data)) < count {
 			// 已经是最早的记录
 			// 需要排序
 			break
@@ -158,7 +153,7 @@ func UpdateAllBasicKLine(securityCode string) []KLine {
 	startDate = exchange.FixTradeDate(startDate)
 	// 5. 调整成交量, 单位从手改成股, vol字段 * 100
 	for _, v := range hs {
-		for _, row := range v.List {
+		for _, row := range v {
 			date := exchange.FixTradeDate(row.DateTime)
 			if date < startDate || date > currentTradingDate {
 				continue
@@ -371,7 +366,6 @@ func UpdateAllKLine(securityCode string, freq ...string) []KLine {
 	// 1. 确定本地有效数据最后1条数据作为拉取数据的开始日期
 	startDate := exchange.MARKET_CH_FIRST_LISTTIME
 	securityCode = exchange.CorrectSecurityCode(securityCode)
-	isIndex := exchange.AssertIndexBySecurityCode(securityCode)
 	cacheKLines := LoadKline(securityCode, freq_)
 	kLength := len(cacheKLines)
 	var klineDaysOffset = DataDaysDiff * numberOfDay
@@ -428,9 +422,9 @@ func UpdateAllKLine(securityCode string, freq ...string) []KLine {
 	total_ := days_ * numberOfDay
 	total := uint16(total_)
 	start := uint16(0)
-	hs := make([]quotes.SecurityBarsReply, 0)
+	hs := make([][]quotes.SecurityBar, 0)
 
-	tdxApi := level1.GetApi()
+	source := datasource.GetAdapter()
 	// 3. 拉取数据
 	for {
 		count := step
@@ -439,16 +433,12 @@ func UpdateAllKLine(securityCode string, freq ...string) []KLine {
 		} else {
 			count = total - start
 		}
-		var data *quotes.SecurityBarsReply
+		var data []quotes.SecurityBar
 		var err error
 		retryTimes := 0
 		for retryTimes < quotes.DefaultRetryTimes {
-			if isIndex {
-				data, err = tdxApi.GetIndexBars(securityCode, kType, start, count)
-			} else {
-				data, err = tdxApi.GetKLine(securityCode, kType, start, count)
-			}
-			if err == nil && data != nil {
+			data, err = source.KLines(securityCode, kType, start, count)
+			if err == nil {
 				break
 			}
 			retryTimes++
@@ -457,11 +447,11 @@ func UpdateAllKLine(securityCode string, freq ...string) []KLine {
 			logger.Errorf("code=%s, error=%s", securityCode, err.Error())
 			return []KLine{}
 		}
-		if data == nil || data.Count == 0 || len(data.List) == 0 {
+		if len(data) == 0 {
 			break
 		}
-		hs = append(hs, *data)
-		if data.Count < count {
+		hs = append(hs, data)
+		if uint16(len(data)) < count {
 			// 已经是最早的记录
 			// 需要排序
 			break
@@ -476,7 +466,7 @@ func UpdateAllKLine(securityCode string, freq ...string) []KLine {
 	startDate = exchange.FixTradeDate(startDate)
 	// 5. 调整成交量, 单位从手改成股, vol字段 * 100
 	for _, v := range hs {
-		for _, row := range v.List {
+		for _, row := range v {
 			date := exchange.FixTradeDate(row.DateTime)
 			if date < startDate || date > currentTradingDate {
 				continue
@@ -1,24 +1,20 @@
 package base
 
 import (
-	"gitee.com/quant1x/data/exchange"
-	"gitee.com/quant1x/data/level1"
 	"gitee.com/quant1x/data/level1/quotes"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/datasource"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/runtime"
 )
 
 // GetMinutes 获取分时数据
 func GetMinutes(securityCode, date string) (list []quotes.MinuteTime) {
-	tdxApi := level1.GetApi()
-	u32Date := exchange.ToUint32Date(date)
-	hs, err := tdxApi.GetHistoryMinuteTimeData(securityCode, u32Date)
-	if err != nil || hs.Count == 0 {
+	hs, err := datasource.GetAdapter().Minutes(securityCode, date)
+	if err != nil || len(hs) == 0 {
 		return
 	}
-	list = append(list, hs.List...)
-	_ = hs
+	list = append(list, hs...)
 	return
 }
 
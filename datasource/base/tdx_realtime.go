@@ -8,9 +8,9 @@ import (
 	"time"
 
 	"gitee.com/quant1x/data/exchange"
-	"gitee.com/quant1x/data/level1"
 	"gitee.com/quant1x/data/level1/quotes"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/datasource"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/logger"
 	"gitee.com/quant1x/gox/runtime"
@@ -38,12 +38,12 @@ func BatchRealtimeBasicKLine(codes []string) error {
 		return nil
 	}
 
-	tdxApi := level1.GetApi()
+	source := datasource.GetAdapter()
 	var err error
 	var hq []quotes.Snapshot
 	retryTimes := 0
 	for retryTimes < quotes.DefaultRetryTimes {
-		hq, err = tdxApi.GetSnapshot(codes)
+		hq, err = source.Snapshots(codes)
 		if err == nil && hq != nil && len(hq) > 0 {
 			break
 		}
@@ -5,10 +5,10 @@ import (
 	"sync"
 
 	"gitee.com/quant1x/data/exchange"
-	"gitee.com/quant1x/data/level1"
 	"gitee.com/quant1x/data/level1/quotes"
 	"gitee.com/quant1x/engine/cache"
 	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/datasource"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/logger"
 	"gitee.com/quant1x/gox/runtime"
@@ -60,19 +60,18 @@ func GetBeginDateOfHistoricalTradingData() string {
 // Deprecated: 废弃的函数, 推荐 CheckoutTransactionData [wangfeng on 2024/1/31 17:26]
 func GetHistoricalTradingData(securityCode, tradeDate string) []quotes.TickTransaction {
 	securityCode = exchange.CorrectSecurityCode(securityCode)
-	tdxApi := level1.GetApi()
+	source := datasource.GetAdapter()
 	offset := uint16(quotes.TRANSACTION_MAX)
 	start := uint16(0)
 	history := make([]quotes.TickTransaction, 0)
-	hs := make([]quotes.TransactionReply, 0)
-	u32Date := exchange.ToUint32Date(tradeDate)
+	hs := make([][]quotes.TickTransaction, 0)
 	for {
-		var data *quotes.TransactionReply
+		var data []quotes.TickTransaction
 		var err error
 		retryTimes := 0
 		for retryTimes < quotes.DefaultRetryTimes {
-			data, err = tdxApi.GetHistoryTransactionData(securityCode, u32Date, start, offset)
-			if err == nil && data != nil {
+			data, err = source.Transactions(securityCode, tradeDate, start, offset)
+			if err == nil {
 				break
 			}
 			retryTimes++
@@ -81,11 +80,11 @@ func GetHistoricalTradingData(securityCode, tradeDate string) []quotes.TickTrans
 			logger.Errorf("code=%s, tradeDate=%s, error=%s", securityCode, tradeDate, err.Error())
 			return []quotes.TickTransaction{}
 		}
-		if data == nil || data.Count == 0 {
+		if len(data) == 0 {
 			break
 		}
-		hs = append(hs, *data)
-		if data.Count < offset {
+		hs = append(hs, data)
+		if uint16(len(data)) < offset {
 			break
 		}
 		start += offset
@@ -93,7 +92,7 @@ func GetHistoricalTradingData(securityCode, tradeDate string) []quotes.TickTrans
 	// 这里需要反转一下
 	hs = api.Reverse(hs)
 	for _, v := range hs {
-		history = append(history, v.List...)
+		history = append(history, v...)
 	}
 
 	return history
@@ -103,9 +102,8 @@ func GetHistoricalTradingData(securityCode, tradeDate string) []quotes.TickTrans
 func GetAllHistoricalTradingData(securityCode string) {
 	defer runtime.CatchPanic("trans: code=%s", securityCode)
 	securityCode = exchange.CorrectSecurityCode(securityCode)
-	tdxApi := level1.GetApi()
-	info, err := tdxApi.GetFinanceInfo(securityCode)
-	if err != nil {
+	info, err := datasource.GetAdapter().Finance(securityCode)
+	if err != nil || info == nil {
 		return
 	}
 	tStart := strconv.FormatInt(int64(info.IPODate), 10)
@@ -215,24 +213,19 @@ func CheckoutTransactionData(securityCode string, cacheDate string, ignorePrevio
 		}
 	}
 
-	tdxApi := level1.GetApi()
+	source := datasource.GetAdapter()
 	offset := uint16(quotes.TRANSACTION_MAX)
-	u32Date := exchange.ToUint32Date(tradeDate)
 	// 只求增量, 分笔成交数据是从后往前取数据, 缓存是从前到后顺序存取
 	start := uint16(0)
 	history := make([]quotes.TickTransaction, 0)
 	hs := make([]quotes.TransactionReply, 0)
 	for {
-		var data *quotes.TransactionReply
+		var data []quotes.TickTransaction
 		var err error
 		retryTimes := 0
 		for retryTimes < quotes.DefaultRetryTimes {
-			if exchange.CurrentlyTrading(tradeDate) {
-				data, err = tdxApi.GetTransactionData(securityCode, start, offset)
-			} else {
-				data, err = tdxApi.GetHistoryTransactionData(securityCode, u32Date, start, offset)
-			}
-			if err == nil && data != nil {
+			data, err = source.Transactions(securityCode, tradeDate, start, offset)
+			if err == nil {
 				break
 			}
 			retryTimes++
@@ -241,11 +234,11 @@ func CheckoutTransactionData(securityCode string, cacheDate string, ignorePrevio
 			logger.Errorf("code=%s, tradeDate=%s, error=%s", securityCode, tradeDate, err.Error())
 			return
 		}
-		if data == nil || data.Count == 0 {
+		if len(data) == 0 {
 			break
 		}
 		var tmp quotes.TransactionReply
-		tmpList := api.Reverse(data.List)
+		tmpList := api.Reverse(data)
 		for _, td := range tmpList {
 			// 追加包含startTime之后的记录
 			if td.Time >= startTime {
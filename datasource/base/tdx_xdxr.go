@@ -2,9 +2,9 @@ package base
 
 import (
 	"gitee.com/quant1x/data/exchange"
-	"gitee.com/quant1x/data/level1"
 	"gitee.com/quant1x/data/level1/quotes"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/datasource"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/logger"
 )
@@ -12,8 +12,7 @@ import (
 // UpdateXdxrInfo 除权除息数据
 func UpdateXdxrInfo(securityCode string) {
 	securityCode = exchange.CorrectSecurityCode(securityCode)
-	tdxApi := level1.GetApi()
-	xdxrInfos, err := tdxApi.GetXdxrInfo(securityCode)
+	xdxrInfos, err := datasource.GetAdapter().Xdxr(securityCode)
 	if err != nil {
 		logger.Errorf("获取除权除息数据失败", err)
 		return
@@ -175,6 +175,11 @@ func QuarterlyReportsBySecurityCode(securityCode, date string, diffQuarters int,
 	return
 }
 
+const (
+	// 向前查找季报的最大季度数, 年报最晚在次年4月底披露, 4月初只能看到上一年的三季报
+	reportMaxDiffQuarters = 5
+)
+
 var (
 	mutexReports sync.RWMutex
 	mapReports   = map[string][]QuarterlyReport{}
@@ -245,14 +250,16 @@ func cacheQuarterlyReportsBySecurityCode(securityCode, date string, diffQuarters
 }
 
 // GetCacheQuarterlyReportsBySecurityCode 获取上市公司财务季报 Quarterly Reports
+//
+//	只返回date当日已经披露的最近一期季报, 回测和修复历史数据时不会看到之后才披露的季报
 func GetCacheQuarterlyReportsBySecurityCode(securityCode, date string, diffQuarters ...int) *QuarterlyReport {
 	diff := 1
 	if len(diffQuarters) > 0 {
 		diff = diffQuarters[0]
 	}
-	for ; diff < 4; diff++ {
+	for ; diff < reportMaxDiffQuarters; diff++ {
 		report := cacheQuarterlyReportsBySecurityCode(securityCode, date, diff)
-		if report == nil {
+		if report == nil || !ReportIsPublic(*report, date) {
 			continue
 		}
 		return report
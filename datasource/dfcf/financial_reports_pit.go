@@ -0,0 +1,98 @@
+package dfcf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitee.com/quant1x/data/exchange"
+)
+
+// 季报的法定最晚披露日期, 季度末月份 -> 月份偏移和日期
+//
+//	一季报和三季报在季度结束后的一个月内, 半年报在两个月内, 年报在次年的4月30日前
+var reportDeadlines = map[time.Month]struct {
+	months int
+	day    int
+}{
+	time.March:     {1, 30},
+	time.June:      {2, 31},
+	time.September: {1, 31},
+	time.December:  {4, 30},
+}
+
+// 报告期的法定最晚披露日期, 报告期不是季度末返回空字符串
+func reportDeadline(reportDate string) string {
+	reportDate = strings.TrimSpace(reportDate)
+	if len(reportDate) < len(time.DateOnly) {
+		return ""
+	}
+	tm, err := time.Parse(time.DateOnly, reportDate[:len(time.DateOnly)])
+	if err != nil {
+		return ""
+	}
+	deadline, ok := reportDeadlines[tm.Month()]
+	if !ok {
+		return ""
+	}
+	month := time.Date(tm.Year(), tm.Month()+time.Month(deadline.months), 1, 0, 0, 0, 0, time.Local)
+	return time.Date(month.Year(), month.Month(), deadline.day, 0, 0, 0, 0, time.Local).Format(time.DateOnly)
+}
+
+// ReportDisclosureDate 季报的披露日期
+//
+//	优先取公告日期, 公告日期是最新的公告日期, 更正过的季报偏晚, 不会提前看到数据;
+//	没有公告日期时按法定的最晚披露日期估计
+func ReportDisclosureDate(report QuarterlyReport) string {
+	if len(strings.TrimSpace(report.NoticeDate)) > 0 {
+		return exchange.FixTradeDate(report.NoticeDate)
+	}
+	return reportDeadline(report.ReportDate)
+}
+
+// ReportIsPublic 季报在date当日是否已经披露
+func ReportIsPublic(report QuarterlyReport, date string) bool {
+	disclosure := ReportDisclosureDate(report)
+	return len(disclosure) > 0 && disclosure <= exchange.FixTradeDate(date)
+}
+
+// ShareHolderDisclosureDate 流通股东的披露日期
+//
+//	取定期报告的公告日期(UpdateDate), 没有公告日期时按报告期的法定最晚披露日期估计
+func ShareHolderDisclosureDate(list []CirculatingShareholder) string {
+	if len(list) == 0 {
+		return ""
+	}
+	holder := list[0]
+	if len(strings.TrimSpace(holder.UpdateDate)) > 0 {
+		return exchange.FixTradeDate(holder.UpdateDate)
+	}
+	return reportDeadline(holder.EndDate)
+}
+
+// ShareHolderIsPublic 流通股东在date当日是否已经披露
+func ShareHolderIsPublic(list []CirculatingShareholder, date string) bool {
+	disclosure := ShareHolderDisclosureDate(list)
+	return len(disclosure) > 0 && disclosure <= exchange.FixTradeDate(date)
+}
+
+// QuarterEndDate 报告期对应的季度末日期, 报告期格式YYYYQn
+func QuarterEndDate(qdate string) (string, error) {
+	qdate = strings.ToUpper(strings.TrimSpace(qdate))
+	year, quarter, ok := strings.Cut(qdate, "Q")
+	if !ok || len(year) != 4 {
+		return "", fmt.Errorf("invalid quarter: %s", qdate)
+	}
+	y, err := strconv.Atoi(year)
+	if err != nil {
+		return "", fmt.Errorf("invalid quarter: %s", qdate)
+	}
+	q, err := strconv.Atoi(quarter)
+	if err != nil || q < 1 || q > 4 {
+		return "", fmt.Errorf("invalid quarter: %s", qdate)
+	}
+	// 下一个季度的第一天减一天
+	tm := time.Date(y, time.Month(q*3+1), 1, 0, 0, 0, 0, time.Local).AddDate(0, 0, -1)
+	return tm.Format(time.DateOnly), nil
+}
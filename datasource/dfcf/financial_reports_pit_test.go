@@ -0,0 +1,77 @@
+package dfcf
+
+import "testing"
+
+func TestReportDisclosureDate(t *testing.T) {
+	tests := []struct {
+		report QuarterlyReport
+		want   string
+	}{
+		{QuarterlyReport{NoticeDate: "2024-04-20 00:00:00", ReportDate: "2024-03-31 00:00:00"}, "2024-04-20"},
+		{QuarterlyReport{ReportDate: "2024-03-31 00:00:00"}, "2024-04-30"},
+		{QuarterlyReport{ReportDate: "2024-06-30 00:00:00"}, "2024-08-31"},
+		{QuarterlyReport{ReportDate: "2024-09-30 00:00:00"}, "2024-10-31"},
+		{QuarterlyReport{ReportDate: "2023-12-31 00:00:00"}, "2024-04-30"},
+		{QuarterlyReport{ReportDate: "2024-05-31"}, ""},
+		{QuarterlyReport{}, ""},
+	}
+	for _, tt := range tests {
+		if got := ReportDisclosureDate(tt.report); got != tt.want {
+			t.Errorf("ReportDisclosureDate(%+v) = %q, want %q", tt.report, got, tt.want)
+		}
+	}
+}
+
+func TestReportIsPublic(t *testing.T) {
+	report := QuarterlyReport{NoticeDate: "2024-04-20 00:00:00", ReportDate: "2024-03-31 00:00:00"}
+	if ReportIsPublic(report, "2024-04-19") {
+		t.Error("披露日期之前不可见")
+	}
+	if !ReportIsPublic(report, "2024-04-20") {
+		t.Error("披露日期当日可见")
+	}
+	if ReportIsPublic(QuarterlyReport{}, "2024-04-20") {
+		t.Error("没有披露日期的季报不可见")
+	}
+}
+
+func TestShareHolderIsPublic(t *testing.T) {
+	list := []CirculatingShareholder{{EndDate: "2024-03-31", UpdateDate: "2024-04-25"}}
+	if got := ShareHolderDisclosureDate(list); got != "2024-04-25" {
+		t.Errorf("ShareHolderDisclosureDate = %q, want 2024-04-25", got)
+	}
+	if ShareHolderIsPublic(list, "2024-04-24") {
+		t.Error("公告日期之前不可见")
+	}
+	if !ShareHolderIsPublic(list, "2024-04-25") {
+		t.Error("公告日期当日可见")
+	}
+	// 没有公告日期按法定最晚披露日期
+	noDate := []CirculatingShareholder{{EndDate: "2024-06-30 00:00:00"}}
+	if ShareHolderIsPublic(noDate, "2024-08-30") || !ShareHolderIsPublic(noDate, "2024-08-31") {
+		t.Error("没有公告日期时应在法定最晚披露日期可见")
+	}
+	if ShareHolderIsPublic(nil, "2024-08-31") {
+		t.Error("没有股东数据不可见")
+	}
+}
+
+func TestQuarterEndDate(t *testing.T) {
+	tests := map[string]string{
+		"2024Q1": "2024-03-31",
+		"2024Q2": "2024-06-30",
+		"2024Q3": "2024-09-30",
+		"2024Q4": "2024-12-31",
+	}
+	for qdate, want := range tests {
+		got, err := QuarterEndDate(qdate)
+		if err != nil || got != want {
+			t.Errorf("QuarterEndDate(%s) = %s, %v, want %s", qdate, got, err, want)
+		}
+	}
+	for _, qdate := range []string{"", "2024", "2024Q5", "24Q1"} {
+		if _, err := QuarterEndDate(qdate); err == nil {
+			t.Errorf("QuarterEndDate(%s) want error", qdate)
+		}
+	}
+}
@@ -249,6 +249,7 @@ const (
 	urlLongHuBang = "https://datacenter-web.eastmoney.com/api/data/v1/get"
 	lhbBuy        = "BUY"  // 龙虎榜买入
 	lhbSell       = "SELL" // 龙虎榜卖出
+	lhbPageSize   = 500    // 龙虎榜每页的记录数
 )
 
 var (
@@ -292,7 +293,7 @@ func rawBillBoardList(date string, pageNumber int, direction string) ([]BillBoar
 		"client":      {"WEB"},
 		"sortColumns": {direction},
 		"sortTypes":   {"-1"},
-		"pageSize":    {fmt.Sprintf("%d", rzrqPageSize)},
+		"pageSize":    {fmt.Sprintf("%d", lhbPageSize)},
 		"pageNumber":  {fmt.Sprintf("%d", pageNumber)},
 		"filter":      {fmt.Sprintf(`(TRADE_DATE='%s')`, tradeDate)},
 	}
@@ -309,3 +310,28 @@ func rawBillBoardList(date string, pageNumber int, direction string) ([]BillBoar
 	}
 	return raw.Data, raw.Pages, nil
 }
+
+// GetBillBoardList 获取指定日期的龙虎榜, 包括买入和卖出两个方向的席位
+//
+//	同一个席位可能同时出现在买入和卖出两个方向, 也可能因为多个上榜原因重复出现.
+//	任何一页失败都返回错误, 不返回残缺的列表
+func GetBillBoardList(date string) ([]BillBoard, error) {
+	var list []BillBoard
+	for _, direction := range []string{lhbBuy, lhbSell} {
+		pages := 1
+		for i := 0; i < pages; i++ {
+			tmpList, tmpPages, err := rawBillBoardList(date, i+1, direction)
+			if err != nil {
+				return nil, fmt.Errorf("龙虎榜[%s-%s]第%d页: %w", date, direction, i+1, err)
+			}
+			list = append(list, tmpList...)
+			if len(tmpList) < lhbPageSize {
+				break
+			}
+			if pages == 1 {
+				pages = tmpPages
+			}
+		}
+	}
+	return list, nil
+}
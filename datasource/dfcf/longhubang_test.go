@@ -2,7 +2,10 @@ package dfcf
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+
+	"gitee.com/quant1x/data/exchange"
 )
 
 func Test_rawBillBoard(t *testing.T) {
@@ -10,3 +13,34 @@ func Test_rawBillBoard(t *testing.T) {
 	v, n, err := rawBillBoardList(date, 1, lhbBuy)
 	fmt.Println(v, n, err)
 }
+
+func TestGetBillBoardList(t *testing.T) {
+	date := "20240730"
+	list, err := GetBillBoardList(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) == 0 {
+		t.Fatalf("date = %s, 没有龙虎榜数据", date)
+	}
+	tradeDate := exchange.FixTradeDate(date)
+	buy, sell := 0, 0
+	for _, v := range list {
+		if !strings.HasPrefix(v.TRADE_DATE, tradeDate) {
+			t.Errorf("%s: TRADE_DATE = %s, want %s", v.SECUCODE, v.TRADE_DATE, tradeDate)
+		}
+		if len(v.SECUCODE) == 0 || len(v.TRADE_ID) == 0 {
+			t.Errorf("invalid row: %+v", v)
+		}
+		if v.BUY > 0 {
+			buy++
+		}
+		if v.SELL > 0 {
+			sell++
+		}
+	}
+	// 买入和卖出两个方向都要拉取
+	if buy == 0 || sell == 0 {
+		t.Errorf("buy rows = %d, sell rows = %d", buy, sell)
+	}
+}
@@ -107,13 +107,13 @@ func rawMarginTradingList(date string, pageNumber int) ([]SecurityMarginTrading,
 // GetMarginTradingList 获取两融列表
 //
 //	东方财富两融数据只有前一个交易日的数据
-func GetMarginTradingList(date string) []SecurityMarginTrading {
+func GetMarginTradingList(date string) ([]SecurityMarginTrading, error) {
 	var list []SecurityMarginTrading
 	pages := 1
 	for i := 0; i < pages; i++ {
 		tmpList, tmpPages, err := rawMarginTradingList(date, i+1)
 		if err != nil {
-			break
+			return list, fmt.Errorf("两融列表[%s]第%d页: %w", date, i+1, err)
 		}
 		list = append(list, tmpList...)
 		if len(tmpList) < rzrqPageSize {
@@ -123,5 +123,5 @@ func GetMarginTradingList(date string) []SecurityMarginTrading {
 			pages = tmpPages
 		}
 	}
-	return list
+	return list, nil
 }
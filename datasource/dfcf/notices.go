@@ -126,12 +126,17 @@ type NoticeDetail struct {
 //	:return: 沪深京 A 股公告
 //	Deprecated: 弃用
 func AllNotices(noticeType EMNoticeType, date string, pageNumber ...int) (notices []NoticeDetail, pages int, err error) {
+	return AllNoticesInRange(noticeType, date, exchange.Today(), pageNumber...)
+}
+
+// AllNoticesInRange 全市场指定类型在日期范围内的公告
+func AllNoticesInRange(noticeType EMNoticeType, beginDate, endDate string, pageNumber ...int) (notices []NoticeDetail, pages int, err error) {
 	pageNo := 1
 	if len(pageNumber) > 0 {
 		pageNo = pageNumber[0]
 	}
-	beginDate := exchange.FixTradeDate(date)
-	endDate := exchange.Today()
+	beginDate = exchange.FixTradeDate(beginDate)
+	endDate = exchange.FixTradeDate(endDate)
 	pageSize := EastmoneyNoticesPageSize
 	params := urlpkg.Values{
 		"sr":         {"-1"},
@@ -445,16 +450,21 @@ func StockWarning(securityCode string, pageNumber ...int) (warning RawWarning, e
 //
 //	event_type: 报表披露, 业绩快报, 业务预告
 //	specific_eventtype: 年报披露, 年报预披露, x季报披露, x季报预披露, 中报披露, 业绩快报, 业绩预告
-func getAnnualReportDate(year string, events []WarningDetail) (annualReportDate, quarterlyReportDate string) {
+//	预披露是交易所公布的披露计划, 可以晚于featureDate; 披露是已经发生的公告, 只取featureDate当日及以前的
+func getAnnualReportDate(year, featureDate string, events []WarningDetail) (annualReportDate, quarterlyReportDate string) {
 	for _, v := range events {
 		date := exchange.FixTradeDate(v.NoticeDate)
 		tmpYear := date[0:4]
 		if v.EventType != "报表披露" {
 			continue
 		}
+		scheduled := strings.HasSuffix(v.SpecificEventType, "预披露")
+		if !scheduled && date > featureDate {
+			continue
+		}
 		if len(annualReportDate) == 0 && (v.SpecificEventType == "年报披露" || v.SpecificEventType == "年报预披露") && tmpYear >= year {
 			annualReportDate = date
-		} else if len(quarterlyReportDate) == 0 && strings.HasSuffix(v.SpecificEventType, "季报披露") || strings.HasSuffix(v.SpecificEventType, "季报预披露") {
+		} else if len(quarterlyReportDate) == 0 && (strings.HasSuffix(v.SpecificEventType, "季报披露") || strings.HasSuffix(v.SpecificEventType, "季报预披露")) {
 			quarterlyReportDate = date
 		}
 		if len(annualReportDate) > 0 && len(quarterlyReportDate) > 0 {
@@ -479,7 +489,7 @@ func NoticeDateForReport(code string, date string) (annualReportDate, quarterlyR
 			break
 		}
 		for _, events := range warning.Data {
-			tmpYearReportDate, tmpQuarterlyReportDate := getAnnualReportDate(year, events)
+			tmpYearReportDate, tmpQuarterlyReportDate := getAnnualReportDate(year, date, events)
 			if len(annualReportDate) == 0 && len(tmpYearReportDate) > 0 {
 				annualReportDate = tmpYearReportDate
 			}
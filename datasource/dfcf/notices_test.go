@@ -28,3 +28,28 @@ func TestNoticeDateForAnnualReport(t *testing.T) {
 	qs := exchange.DateRange(date, q)
 	fmt.Println(len(qs))
 }
+
+func Test_getAnnualReportDate(t *testing.T) {
+	events := []WarningDetail{
+		{EventType: "报表披露", SpecificEventType: "一季报披露", NoticeDate: "2024-04-26 00:00:00"},
+		{EventType: "报表披露", SpecificEventType: "年报预披露", NoticeDate: "2024-04-20 00:00:00"},
+		{EventType: "报表披露", SpecificEventType: "三季报披露", NoticeDate: "2023-10-28 00:00:00"},
+	}
+	// 一季报在04-26才公告, 04-10只能看到去年的三季报
+	y, q := getAnnualReportDate("2024", "2024-04-10", events)
+	if y != "2024-04-20" || q != "2023-10-28" {
+		t.Errorf("2024-04-10: got %s, %s", y, q)
+	}
+	y, q = getAnnualReportDate("2024", "2024-04-26", events)
+	if y != "2024-04-20" || q != "2024-04-26" {
+		t.Errorf("2024-04-26: got %s, %s", y, q)
+	}
+	// 已经取到的季报日期不会被后面的预披露覆盖
+	events = []WarningDetail{
+		{EventType: "报表披露", SpecificEventType: "一季报披露", NoticeDate: "2024-04-26 00:00:00"},
+		{EventType: "报表披露", SpecificEventType: "三季报预披露", NoticeDate: "2024-03-01 00:00:00"},
+	}
+	if _, q = getAnnualReportDate("2024", "2024-04-26", events); q != "2024-04-26" {
+		t.Errorf("预披露覆盖: got %s", q)
+	}
+}
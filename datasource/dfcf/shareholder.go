@@ -34,7 +34,7 @@ type CirculatingShareholder struct {
 	SecurityCode     string  `dataframe:"security_code"`       // 证券代码
 	SecurityName     string  `dataframe:"security_name"`       // 证券名称
 	EndDate          string  `dataframe:"end_date"`            // 报告日期
-	UpdateDate       string  `dataframe:"update_date"`         // 更新日期
+	UpdateDate       string  `dataframe:"update_date"`         // 公告日期
 	HolderType       string  `dataframe:"holder_type"`         // 股东类型
 	HolderName       string  `dataframe:"holder_name"`         // 股东名称
 	IsHoldOrg        string  `dataframe:"is_holdorg"`          // 股东是否机构
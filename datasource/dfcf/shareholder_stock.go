@@ -189,19 +189,37 @@ func cacheShareHolder(securityCode, date string, diffQuarters ...int) (list []Ci
 	return
 }
 
-// GetCacheShareHolder 获取流动股东数据
+// 流通股东最多往前查找的报告期数
+const shareHolderMaxDiffQuarters = 4
+
+// 从第diff个报告期开始查找date当日已经披露的流动股东数据, 返回数据和报告期的偏移
+func publicShareHolder(securityCode, date string, diff int) ([]CirculatingShareholder, int) {
+	for ; diff < shareHolderMaxDiffQuarters; diff++ {
+		tmpList := cacheShareHolder(securityCode, date, diff)
+		if len(tmpList) == 0 || !ShareHolderIsPublic(tmpList, date) {
+			continue
+		}
+		return tmpList, diff
+	}
+	return nil, diff
+}
+
+// GetCacheShareHolder 获取date当日已经披露的最新一期流动股东数据, 未披露的报告期不可见
 func GetCacheShareHolder(securityCode, date string, diffQuarters ...int) (list []CirculatingShareholder) {
 	diff := 1
 	if len(diffQuarters) > 0 {
 		diff = diffQuarters[0]
 	}
-	for ; diff < 4; diff++ {
-		tmpList := cacheShareHolder(securityCode, date, diff)
-		if len(tmpList) == 0 {
-			continue
-		}
-		list = tmpList
-		break
-	}
+	list, _ = publicShareHolder(securityCode, date, diff)
 	return
 }
+
+// GetCacheShareHolderWithPrevious 获取date当日已经披露的最新一期和它之前一期的流动股东数据
+func GetCacheShareHolderWithPrevious(securityCode, date string) (list, previous []CirculatingShareholder) {
+	list, diff := publicShareHolder(securityCode, date, 1)
+	if len(list) == 0 {
+		return nil, nil
+	}
+	previous, _ = publicShareHolder(securityCode, date, diff+1)
+	return list, previous
+}
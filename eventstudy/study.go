@@ -0,0 +1,262 @@
+package eventstudy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/datasource/dfcf"
+	"gitee.com/quant1x/engine/factors"
+)
+
+const (
+	defaultBefore = 5  // 默认事件日之前的交易日数
+	defaultAfter  = 10 // 默认事件日之后的交易日数
+
+	exchangeCloseTime = "15:00:00" // 收盘时间, 之后发布的公告从下一个交易日开始反应
+)
+
+var (
+	ErrEvent     = errors.New("不支持的事件类型")
+	ErrBenchmark = errors.New("参考指数没有宽表数据")
+	ErrNoEvents  = errors.New("没有符合条件的公告事件")
+)
+
+// Options 事件研究选项
+type Options struct {
+	Begin      string            // 公告的开始日期
+	End        string            // 公告的结束日期
+	Events     []string          // 事件类型, 为空取全部支持的事件
+	NoticeType dfcf.EMNoticeType // 公告类型, dfcf.NoticeAll不过滤
+	Before     int               // 事件日之前的交易日数, 小于0取默认值
+	After      int               // 事件日之后的交易日数, 小于0取默认值
+	Benchmark  string            // 参考指数, 为空取回测参数的参考指数
+	// 加载证券的宽表, 为空时从缓存加载
+	Load func(securityCode string) []factors.SecurityFeature
+}
+
+// Point 事件窗口中的一天
+type Point struct {
+	Offset int     // 相对事件日的交易日偏移, 0是事件日
+	AAR    float64 // 平均超额收益率%
+	CAAR   float64 // 累计平均超额收益率%
+	TStat  float64 // 平均超额收益率的t值
+}
+
+// Result 一类事件的研究结果
+type Result struct {
+	Event   string  // 事件类型
+	Events  int     // 参与统计的事件数
+	Skipped int     // 数据不足被跳过的事件数
+	Points  []Point // 事件窗口内每一天的平均超额收益
+	CAR     float64 // 整个窗口的平均累计超额收益率%
+	TStat   float64 // 累计超额收益率的t值
+	WinRate float64 // 累计超额收益率为正的事件占比%
+}
+
+// Study 统计公告事件前后的平均超额收益
+//
+//	超额收益是个股收益率减去参考指数的收益率, 事件日是公告日期当天或之后的第一根K线,
+//	收盘(15:00)之后发布的公告当天已经无法交易, 事件日顺延到下一根K线. 窗口按个股的K线计数, 停牌的交易日不计入
+func Study(options Options) ([]Result, error) {
+	events := options.Events
+	if len(events) == 0 {
+		events = factors.NoticeEvents()
+	}
+	for _, event := range events {
+		if !slices.Contains(factors.NoticeEvents(), event) {
+			return nil, fmt.Errorf("%w: %s", ErrEvent, event)
+		}
+	}
+	notices := factors.QueryNoticeEvents(options.Begin, options.End, options.NoticeType, events...)
+	if len(notices) == 0 {
+		return nil, ErrNoEvents
+	}
+	options.Events = events
+	return study(notices, options)
+}
+
+func study(notices []factors.NoticeEvent, options Options) ([]Result, error) {
+	before, after := options.Before, options.After
+	if before < 0 {
+		before = defaultBefore
+	}
+	if after < 0 {
+		after = defaultAfter
+	}
+	load := options.Load
+	if load == nil {
+		load = factors.LoadWideTable
+	}
+	benchmark := options.Benchmark
+	if len(benchmark) == 0 {
+		benchmark = config.GetDataConfig().BackTesting.TargetIndex
+	}
+	benchmark = exchange.CorrectSecurityCode(benchmark)
+	benchmarkLines := load(benchmark)
+	if len(benchmarkLines) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrBenchmark, benchmark)
+	}
+	benchmarkReturns := make(map[string]float64, len(benchmarkLines))
+	for i := range benchmarkLines {
+		benchmarkReturns[benchmarkLines[i].Date] = dailyReturn(benchmarkLines, i)
+	}
+
+	// 同一个证券同一天的同类事件只计一次
+	type eventKey struct {
+		event      string
+		code       string
+		date       string
+		afterClose bool
+	}
+	grouped := map[string][]eventKey{}
+	seen := map[eventKey]bool{}
+	for _, v := range notices {
+		date, afterClose := noticeEventDay(v)
+		key := eventKey{event: v.Event, code: exchange.CorrectSecurityCode(v.Code), date: date, afterClose: afterClose}
+		if len(key.event) == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		grouped[key.event] = append(grouped[key.event], key)
+	}
+
+	cacheLines := map[string][]factors.SecurityFeature{}
+	var results []Result
+	for _, event := range options.Events {
+		keys := grouped[event]
+		result := Result{Event: event}
+		var samples [][]float64
+		for _, key := range keys {
+			lines, ok := cacheLines[key.code]
+			if !ok {
+				lines = load(key.code)
+				cacheLines[key.code] = lines
+			}
+			ar := abnormalReturns(lines, benchmarkReturns, key.date, key.afterClose, before, after)
+			if ar == nil {
+				result.Skipped++
+				continue
+			}
+			samples = append(samples, ar)
+		}
+		result.Events = len(samples)
+		summarize(&result, samples, before)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// 公告的事件日期, afterClose表示收盘之后发布, 事件日从这个日期之后的第一根K线开始
+//
+//	显示时间早于公告日期时(前一天晚上发布的次日公告)按公告日期计算, 显示时间无效时也按公告日期
+func noticeEventDay(v factors.NoticeEvent) (date string, afterClose bool) {
+	date = exchange.FixTradeDate(v.Date)
+	displayTime := strings.TrimSpace(v.DisplayTime)
+	if len(displayTime) < len(time.DateTime) {
+		return date, false
+	}
+	tm, err := time.ParseInLocation(time.DateTime, displayTime[:len(time.DateTime)], time.Local)
+	if err != nil {
+		return date, false
+	}
+	displayDate := tm.Format(time.DateOnly)
+	if displayDate < date {
+		return date, false
+	}
+	return displayDate, tm.Format(time.TimeOnly) > exchangeCloseTime
+}
+
+// 第i根K线的收益率%, 昨收无效时用上一根K线的收盘价
+func dailyReturn(lines []factors.SecurityFeature, i int) float64 {
+	lastClose := lines[i].LastClose
+	if lastClose <= 0 && i > 0 {
+		lastClose = lines[i-1].Close
+	}
+	if lastClose <= 0 || lines[i].Close <= 0 {
+		return 0
+	}
+	return (lines[i].Close/lastClose - 1) * 100
+}
+
+// 一个事件窗口内每天的超额收益率%, 数据不足返回nil
+//
+//	事件日是date当天或之后的第一根K线, afterClose为true时是date之后的第一根K线
+func abnormalReturns(lines []factors.SecurityFeature, benchmarkReturns map[string]float64, date string, afterClose bool, before, after int) []float64 {
+	day0 := sort.Search(len(lines), func(i int) bool {
+		if afterClose {
+			return lines[i].Date > date
+		}
+		return lines[i].Date >= date
+	})
+	if day0-before < 0 || day0+after >= len(lines) {
+		return nil
+	}
+	ar := make([]float64, 0, before+after+1)
+	for i := day0 - before; i <= day0+after; i++ {
+		rm, ok := benchmarkReturns[lines[i].Date]
+		if !ok {
+			return nil
+		}
+		ar = append(ar, dailyReturn(lines, i)-rm)
+	}
+	return ar
+}
+
+// 汇总一类事件的超额收益
+func summarize(result *Result, samples [][]float64, before int) {
+	if len(samples) == 0 {
+		return
+	}
+	window := len(samples[0])
+	caar := 0.00
+	column := make([]float64, len(samples))
+	for t := 0; t < window; t++ {
+		for i, ar := range samples {
+			column[i] = ar[t]
+		}
+		aar, tStat := meanAndTStat(column)
+		caar += aar
+		result.Points = append(result.Points, Point{Offset: t - before, AAR: aar, CAAR: caar, TStat: tStat})
+	}
+	wins := 0
+	for i, ar := range samples {
+		car := 0.00
+		for _, v := range ar {
+			car += v
+		}
+		column[i] = car
+		if car > 0 {
+			wins++
+		}
+	}
+	result.CAR, result.TStat = meanAndTStat(column)
+	result.WinRate = float64(wins) / float64(len(samples)) * 100
+}
+
+// 均值和均值的t值, 样本数不足2个时t值为NaN
+func meanAndTStat(values []float64) (mean, tStat float64) {
+	n := float64(len(values))
+	for _, v := range values {
+		mean += v
+	}
+	mean /= n
+	if len(values) < 2 {
+		return mean, math.NaN()
+	}
+	variance := 0.00
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	std := math.Sqrt(variance / (n - 1))
+	if std == 0 {
+		return mean, math.NaN()
+	}
+	return mean, mean / (std / math.Sqrt(n))
+}
@@ -0,0 +1,132 @@
+package eventstudy
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"gitee.com/quant1x/engine/factors"
+)
+
+// 生成n根K线, 每天的收益率%由returns给出, 不足的天数收益率为0
+func testLines(n int, returns map[int]float64) []factors.SecurityFeature {
+	lines := make([]factors.SecurityFeature, n)
+	lastClose := 10.00
+	for i := range lines {
+		closePrice := lastClose * (1 + returns[i]/100)
+		lines[i] = factors.SecurityFeature{
+			Date:      fmt.Sprintf("2024-07-%02d", i+1),
+			Close:     closePrice,
+			LastClose: lastClose,
+		}
+		lastClose = closePrice
+	}
+	return lines
+}
+
+func Test_study(t *testing.T) {
+	data := map[string][]factors.SecurityFeature{
+		"sh000001": testLines(20, map[int]float64{10: 1}),
+		"sh600000": testLines(20, map[int]float64{10: 3, 11: 1}),
+		"sh600001": testLines(20, map[int]float64{10: 5, 11: -1}),
+		"sz000001": testLines(5, nil),
+	}
+	notices := []factors.NoticeEvent{
+		{Date: "2024-07-11", Code: "600000", Event: factors.EventBuyback},
+		{Date: "2024-07-11", Code: "600000", Event: factors.EventBuyback},
+		{Date: "2024-07-11", Code: "600001", Event: factors.EventBuyback},
+		{Date: "2024-07-11", Code: "000001", Event: factors.EventBuyback},
+		{Date: "2024-07-11", Code: "600000", Event: ""},
+	}
+	results, err := study(notices, Options{
+		Events:    []string{factors.EventBuyback, factors.EventReduce},
+		Before:    2,
+		After:     3,
+		Benchmark: "sh000001",
+		Load: func(securityCode string) []factors.SecurityFeature {
+			return data[securityCode]
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+	buyback := results[0]
+	if buyback.Events != 2 || buyback.Skipped != 1 || len(buyback.Points) != 6 {
+		t.Fatalf("buyback = %+v", buyback)
+	}
+	near := func(a, b float64) bool {
+		return math.Abs(a-b) < 1e-9
+	}
+	// 事件日的超额收益: (3-1 + 5-1)/2 = 3
+	day0 := buyback.Points[2]
+	if day0.Offset != 0 || !near(day0.AAR, 3) {
+		t.Errorf("day0 = %+v, want offset 0, AAR 3", day0)
+	}
+	// 事件日后一天: (1 + -1)/2 = 0, 累计3
+	if v := buyback.Points[3]; !near(v.AAR, 0) || !near(v.CAAR, 3) {
+		t.Errorf("day1 = %+v", v)
+	}
+	if !near(buyback.CAR, 3) || !near(buyback.WinRate, 100) {
+		t.Errorf("CAR = %v, WinRate = %v", buyback.CAR, buyback.WinRate)
+	}
+	if reduce := results[1]; reduce.Events != 0 || len(reduce.Points) != 0 {
+		t.Errorf("reduce = %+v", reduce)
+	}
+	_, err = study(notices, Options{Events: []string{factors.EventBuyback}, Benchmark: "sh000300", Load: func(string) []factors.SecurityFeature { return nil }})
+	if err == nil {
+		t.Error("missing benchmark should fail")
+	}
+}
+
+func Test_noticeEventDay(t *testing.T) {
+	tests := []struct {
+		date        string
+		displayTime string
+		want        string
+		afterClose  bool
+	}{
+		{"2024-07-10", "", "2024-07-10", false},
+		{"2024-07-10", "2024-07-10 09:12:30:000", "2024-07-10", false},
+		{"2024-07-10", "2024-07-10 15:00:00:000", "2024-07-10", false},
+		{"2024-07-10", "2024-07-10 18:32:01:000", "2024-07-10", true},
+		// 前一天晚上发布次日日期的公告
+		{"2024-07-11", "2024-07-10 20:05:00:000", "2024-07-11", false},
+		{"2024-07-10", "invalid", "2024-07-10", false},
+	}
+	for _, tt := range tests {
+		date, afterClose := noticeEventDay(factors.NoticeEvent{Date: tt.date, DisplayTime: tt.displayTime})
+		if date != tt.want || afterClose != tt.afterClose {
+			t.Errorf("noticeEventDay(%s, %q) = %s, %t, want %s, %t", tt.date, tt.displayTime, date, afterClose, tt.want, tt.afterClose)
+		}
+	}
+}
+
+func Test_abnormalReturns(t *testing.T) {
+	benchmark := testLines(20, nil)
+	benchmarkReturns := map[string]float64{}
+	for i := range benchmark {
+		benchmarkReturns[benchmark[i].Date] = 0
+	}
+	lines := testLines(20, map[int]float64{10: 3, 11: 1})
+	// 盘中发布, 事件日是2024-07-11
+	if ar := abnormalReturns(lines, benchmarkReturns, "2024-07-11", false, 0, 1); len(ar) != 2 || math.Abs(ar[0]-3) > 1e-9 {
+		t.Errorf("abnormalReturns(intraday) = %v", ar)
+	}
+	// 盘后发布, 事件日顺延到2024-07-12
+	if ar := abnormalReturns(lines, benchmarkReturns, "2024-07-11", true, 0, 1); len(ar) != 2 || math.Abs(ar[0]-1) > 1e-9 {
+		t.Errorf("abnormalReturns(after close) = %v", ar)
+	}
+}
+
+func Test_meanAndTStat(t *testing.T) {
+	mean, tStat := meanAndTStat([]float64{1, 2, 3})
+	if mean != 2 || math.Abs(tStat-2*math.Sqrt(3)) > 1e-9 {
+		t.Errorf("meanAndTStat = %v, %v", mean, tStat)
+	}
+	if _, tStat := meanAndTStat([]float64{1}); !math.IsNaN(tStat) {
+		t.Errorf("single sample tStat = %v, want NaN", tStat)
+	}
+}
@@ -1,7 +1,9 @@
 package factors
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"strings"
 	"sync"
 
@@ -58,6 +60,34 @@ func RegisterFeatureRotationAdapter(key string, adapter FeatureRotationAdapter)
 	__mapFeatureRotationAdapters[key] = adapter
 }
 
+// FeatureRotationAdapters 全部特征缓存适配器, 缓存关键字 -> 适配器
+func FeatureRotationAdapters() map[string]FeatureRotationAdapter {
+	__mutexFeatureRotationAdapters.Lock()
+	defer __mutexFeatureRotationAdapters.Unlock()
+	return maps.Clone(__mapFeatureRotationAdapters)
+}
+
+// IncreaseFeatures 用快照增量计算一个证券的全部特征, 返回缓存关键字 -> 特征
+//
+//	平台、扩展交易等依赖均线的特征和历史特征共用同一个快照的增量结果
+func IncreaseFeatures(adapters map[string]FeatureRotationAdapter, securityCode string, snapshot QuoteSnapshot) map[string]Feature {
+	features := make(map[string]Feature, len(adapters))
+	for key, adapter := range adapters {
+		if key == cacheL5KeyHistory {
+			if _, live := increaseHistory(securityCode, snapshot); live != nil {
+				features[key] = live
+			}
+			continue
+		}
+		feature := adapter.Element(securityCode)
+		if feature == nil {
+			continue
+		}
+		features[key] = feature.Increase(snapshot)
+	}
+	return features
+}
+
 // SwitchDate 统一切换数据的缓存日期
 func SwitchDate(date string) {
 	__mutexFeatureRotationAdapters.Lock()
@@ -67,6 +97,34 @@ func SwitchDate(date string) {
 	}
 }
 
+// FeatureStorageConverter 特征缓存存储格式转换
+type FeatureStorageConverter interface {
+	// ConvertStorage 把指定日期的csv缓存转换成列式缓存, 没有缓存文件时converted为false
+	ConvertStorage(date string) (converted bool, err error)
+}
+
+// ConvertFeatureStorage 把指定日期全部特征的csv缓存转换成列式缓存, 返回转换的文件数
+func ConvertFeatureStorage(date string) (count int, err error) {
+	__mutexFeatureRotationAdapters.Lock()
+	defer __mutexFeatureRotationAdapters.Unlock()
+	var errs []error
+	for key, v := range __mapFeatureRotationAdapters {
+		converter, ok := v.(FeatureStorageConverter)
+		if !ok {
+			continue
+		}
+		converted, e := converter.ConvertStorage(date)
+		if e != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, e))
+			continue
+		}
+		if converted {
+			count++
+		}
+	}
+	return count, errors.Join(errs...)
+}
+
 func Get(key string) FeatureRotationAdapter {
 	__mutexFeatureRotationAdapters.Lock()
 	defer __mutexFeatureRotationAdapters.Unlock()
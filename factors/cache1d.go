@@ -8,6 +8,7 @@ import (
 
 	"gitee.com/quant1x/data/exchange"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/config"
 	"gitee.com/quant1x/engine/market"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/concurrent"
@@ -86,6 +87,11 @@ func (this *Cache1D[T]) Usage() string {
 	return this.tShadow.Usage()
 }
 
+// DependOn 特征依赖的上游数据
+func (this *Cache1D[T]) DependOn() []cache.Kind {
+	return cache.DependOn(this.tShadow)
+}
+
 // Length 获取长度
 func (this *Cache1D[T]) Length() int {
 	return len(this.allCodes)
@@ -104,7 +110,7 @@ func (this *Cache1D[T]) loadCache(date string) {
 	this.mapCache.Clear()
 
 	var list []T
-	err := api.CsvToSlices(this.filename, &list)
+	err := loadCache1DFile(this.filename, &list)
 	if err != nil || len(list) == 0 {
 		logger.Errorf("%s 没有有效数据, error=%+v", this.filename, err)
 		return
@@ -267,7 +273,7 @@ func (this *Cache1D[T]) Apply(merge func(code string, local *T) (updated bool),
 		list = append(list, v)
 	}
 	if len(list) > 0 {
-		err := api.SlicesToCsv(this.filename, list, force...)
+		err := saveCache1DFile(this.filename, list, force...)
 		if err != nil {
 			logger.Errorf("刷新%s异常:%+v", this.filename, err)
 		}
@@ -295,10 +301,69 @@ func (this *Cache1D[T]) Merge(p *treemap.Map) {
 		list = append(list, v)
 	}
 	if len(list) > 0 {
-		err := api.SlicesToCsv(this.filename, list)
+		err := saveCache1DFile(this.filename, list)
 		if err != nil {
 			logger.Errorf("%s异常:%+v", this.filename, err)
 		}
 	}
 	_ = cacheDate
 }
+
+// 加载缓存文件, csv和列式文件都存在时读取最后修改的一个, 修改时间相同时读取配置的格式
+//
+//	切换存储格式后, 另一种格式遗留的旧文件不会覆盖新写入的数据.
+//	columns只对列式文件有效, 为空时读取全部列
+func loadCache1DFile[T any](filename string, list *[]T, columns ...string) error {
+	columnar := columnarFilename(filename)
+	csvStat, csvErr := os.Stat(filename)
+	colStat, colErr := os.Stat(columnar)
+	var useColumnar bool
+	switch {
+	case colErr != nil:
+		// 只有csv文件
+	case csvErr != nil:
+		useColumnar = true
+	case colStat.ModTime().Equal(csvStat.ModTime()):
+		useColumnar = config.FeatureStorage() == config.FeatureStorageColumnar
+	default:
+		useColumnar = colStat.ModTime().After(csvStat.ModTime())
+	}
+	if useColumnar {
+		return ColumnarToSlices(columnar, list, columns...)
+	}
+	return api.CsvToSlices(filename, list)
+}
+
+// 按配置的存储格式保存缓存文件
+func saveCache1DFile[T any](filename string, list []T, force ...bool) error {
+	if config.FeatureStorage() == config.FeatureStorageColumnar {
+		return SlicesToColumnar(columnarFilename(filename), list, force...)
+	}
+	return api.SlicesToCsv(filename, list, force...)
+}
+
+// ConvertStorage 把指定日期的csv缓存转换成列式缓存
+//
+//	csv文件保留, 列式文件更新, 加载时按修改时间读取列式文件.
+//	列式文件比csv新时不转换, 避免旧的csv覆盖列式存储中写入的数据
+func (this *Cache1D[T]) ConvertStorage(date string) (converted bool, err error) {
+	filename := getCache1DFilepath(this.cacheKey, exchange.FixTradeDate(date))
+	csvStat, err := os.Stat(filename)
+	if err != nil {
+		return false, nil
+	}
+	colStat, err := os.Stat(columnarFilename(filename))
+	if err == nil && colStat.ModTime().After(csvStat.ModTime()) {
+		return false, nil
+	}
+	var list []T
+	err = api.CsvToSlices(filename, &list)
+	if err != nil || len(list) == 0 {
+		return false, err
+	}
+	err = SlicesToColumnar(columnarFilename(filename), list, true)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
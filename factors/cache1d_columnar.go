@@ -0,0 +1,368 @@
+package factors
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"gitee.com/quant1x/engine/factors/pb"
+	"gitee.com/quant1x/gox/api"
+	"google.golang.org/protobuf/proto"
+)
+
+// 列式缓存文件格式, 文件头和每一列都是protobuf消息, 定义见cache1d.proto
+//
+//	magic   [4]byte  "Q1XC"
+//	length  uint32   文件头的字节数, 小端
+//	header  pb.ColumnarHeader
+//	data    每一列是一个pb.Column, offset相对数据区的起始位置
+//
+// 列名取dataframe标签, 读取时按列名匹配字段, 文件中多余的列忽略, 缺少的列保持零值.
+// 读取时只需要文件头就可以定位每一列, 可以只加载指定的列
+const (
+	columnarMagic     = "Q1XC"
+	columnarVersion   = 1
+	columnarExtension = ".col"
+	// magic和文件头长度的字节数
+	columnarPrefixSize = 8
+)
+
+var (
+	ErrColumnarFormat  = errors.New("列式缓存格式错误")
+	ErrColumnarVersion = errors.New("列式缓存版本不支持")
+)
+
+// columnType 列的存储类型
+type columnType = pb.ColumnType
+
+const (
+	columnBool    = pb.ColumnType_COLUMN_BOOL
+	columnInt64   = pb.ColumnType_COLUMN_INT64
+	columnUint64  = pb.ColumnType_COLUMN_UINT64
+	columnFloat64 = pb.ColumnType_COLUMN_FLOAT64
+	columnString  = pb.ColumnType_COLUMN_STRING
+)
+
+// columnField 结构体字段和列的对应关系
+type columnField struct {
+	name  string
+	index []int
+	typ   columnType
+}
+
+// 缓存解析过的结构体字段, reflect.Type -> []columnField
+var columnarFieldsCache sync.Map
+
+// 结构体中可以列式存储的字段
+func columnarFields(t reflect.Type) []columnField {
+	if v, ok := columnarFieldsCache.Load(t); ok {
+		return v.([]columnField)
+	}
+	fields := parseColumnarFields(t, nil)
+	columnarFieldsCache.Store(t, fields)
+	return fields
+}
+
+// 解析结构体字段, 匿名结构体展开, dataframe标签为"-"的字段跳过
+func parseColumnarFields(t reflect.Type, parent []int) []columnField {
+	var fields []columnField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("dataframe"), ",")
+		if name == "-" {
+			continue
+		}
+		index := append(append([]int{}, parent...), i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, parseColumnarFields(sf.Type, index)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if len(name) == 0 {
+			name = sf.Name
+		}
+		var typ columnType
+		switch sf.Type.Kind() {
+		case reflect.Bool:
+			typ = columnBool
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			typ = columnInt64
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			typ = columnUint64
+		case reflect.Float32, reflect.Float64:
+			typ = columnFloat64
+		case reflect.String:
+			typ = columnString
+		default:
+			continue
+		}
+		fields = append(fields, columnField{name: name, index: index, typ: typ})
+	}
+	return fields
+}
+
+// 切片元素的结构体类型, 元素可以是结构体或结构体指针
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
+// 切片第i个元素的结构体
+func rowValue(rv reflect.Value, i int) reflect.Value {
+	v := rv.Index(i)
+	if v.Kind() == reflect.Pointer {
+		return v.Elem()
+	}
+	return v
+}
+
+// 列式缓存的文件名
+func columnarFilename(filename string) string {
+	return filename + columnarExtension
+}
+
+// encodeColumn 编码一列数据
+func encodeColumn(rv reflect.Value, field columnField) ([]byte, error) {
+	rows := rv.Len()
+	var column pb.Column
+	switch field.typ {
+	case columnBool:
+		column.Bools = make([]bool, rows)
+		for i := 0; i < rows; i++ {
+			column.Bools[i] = rowValue(rv, i).FieldByIndex(field.index).Bool()
+		}
+	case columnInt64:
+		column.Ints = make([]int64, rows)
+		for i := 0; i < rows; i++ {
+			column.Ints[i] = rowValue(rv, i).FieldByIndex(field.index).Int()
+		}
+	case columnUint64:
+		column.Uints = make([]uint64, rows)
+		for i := 0; i < rows; i++ {
+			column.Uints[i] = rowValue(rv, i).FieldByIndex(field.index).Uint()
+		}
+	case columnFloat64:
+		column.Floats = make([]float64, rows)
+		for i := 0; i < rows; i++ {
+			column.Floats[i] = rowValue(rv, i).FieldByIndex(field.index).Float()
+		}
+	case columnString:
+		column.Strings = make([]string, rows)
+		for i := 0; i < rows; i++ {
+			column.Strings[i] = rowValue(rv, i).FieldByIndex(field.index).String()
+		}
+	}
+	return proto.Marshal(&column)
+}
+
+// decodeColumn 解码一列数据到切片
+func decodeColumn(rv reflect.Value, field columnField, typ columnType, data []byte) error {
+	rows := rv.Len()
+	if typ != field.typ {
+		return fmt.Errorf("%w: 列%s的类型是%s, 字段类型是%s", ErrColumnarFormat, field.name, typ, field.typ)
+	}
+	var column pb.Column
+	if err := proto.Unmarshal(data, &column); err != nil {
+		return fmt.Errorf("%w: 列%s, %v", ErrColumnarFormat, field.name, err)
+	}
+	var n int
+	switch typ {
+	case columnBool:
+		n = len(column.Bools)
+	case columnInt64:
+		n = len(column.Ints)
+	case columnUint64:
+		n = len(column.Uints)
+	case columnFloat64:
+		n = len(column.Floats)
+	case columnString:
+		n = len(column.Strings)
+	default:
+		return fmt.Errorf("%w: 列%s的类型%s无效", ErrColumnarFormat, field.name, typ)
+	}
+	if n != rows {
+		return fmt.Errorf("%w: 列%s有%d行, 应为%d行", ErrColumnarFormat, field.name, n, rows)
+	}
+	for i := 0; i < rows; i++ {
+		fv := rowValue(rv, i).FieldByIndex(field.index)
+		switch typ {
+		case columnBool:
+			fv.SetBool(column.Bools[i])
+		case columnInt64:
+			fv.SetInt(column.Ints[i])
+		case columnUint64:
+			fv.SetUint(column.Uints[i])
+		case columnFloat64:
+			fv.SetFloat(column.Floats[i])
+		case columnString:
+			fv.SetString(column.Strings[i])
+		}
+	}
+	return nil
+}
+
+// SlicesToColumnar 结构体切片写入列式缓存文件
+//
+//	force为true时目录不存在则创建, 和api.SlicesToCsv一致
+func SlicesToColumnar[T any](filename string, list []T, force ...bool) error {
+	rv := reflect.ValueOf(list)
+	elemType := rv.Type().Elem()
+	if elemType.Kind() == reflect.Pointer {
+		// nil指针按零值写入
+		for i := range list {
+			if rv.Index(i).IsNil() {
+				rv = reflect.ValueOf(slices.Clone(list))
+				for j := i; j < rv.Len(); j++ {
+					if rv.Index(j).IsNil() {
+						rv.Index(j).Set(reflect.New(elemType.Elem()))
+					}
+				}
+				break
+			}
+		}
+	}
+	if len(list) > math.MaxUint32 {
+		return fmt.Errorf("%w: 数据量超出范围", ErrColumnarFormat)
+	}
+	fields := columnarFields(structType(elemType))
+	header := pb.ColumnarHeader{
+		Version: columnarVersion,
+		Rows:    uint32(len(list)),
+		Columns: make([]*pb.ColumnIndex, len(fields)),
+	}
+	blocks := make([][]byte, len(fields))
+	offset := uint64(0)
+	for i, field := range fields {
+		block, err := encodeColumn(rv, field)
+		if err != nil {
+			return err
+		}
+		blocks[i] = block
+		header.Columns[i] = &pb.ColumnIndex{Name: field.name, Type: field.typ, Offset: offset, Size: uint64(len(block))}
+		offset += uint64(len(block))
+	}
+	headerData, err := proto.Marshal(&header)
+	if err != nil {
+		return err
+	}
+	if len(force) > 0 && force[0] {
+		if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return err
+		}
+	}
+	prefix := make([]byte, columnarPrefixSize)
+	copy(prefix, columnarMagic)
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(len(headerData)))
+	// 先写临时文件再改名, 避免读到写了一半的文件
+	tmpFilename := filename + ".tmp"
+	file, err := os.Create(tmpFilename)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(prefix, headerData...))
+	for i := 0; err == nil && i < len(blocks); i++ {
+		_, err = file.Write(blocks[i])
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpFilename)
+		return err
+	}
+	return os.Rename(tmpFilename, filename)
+}
+
+// 读取列式缓存的文件头, 返回文件头和数据区的起始位置
+func readColumnarHeader(file *os.File, size int64) (*pb.ColumnarHeader, int64, error) {
+	prefix := make([]byte, columnarPrefixSize)
+	if _, err := file.ReadAt(prefix, 0); err != nil || string(prefix[:4]) != columnarMagic {
+		return nil, 0, ErrColumnarFormat
+	}
+	length := int64(binary.LittleEndian.Uint32(prefix[4:]))
+	if columnarPrefixSize+length > size {
+		return nil, 0, fmt.Errorf("%w: 文件头不完整", ErrColumnarFormat)
+	}
+	data := make([]byte, length)
+	if _, err := file.ReadAt(data, columnarPrefixSize); err != nil {
+		return nil, 0, err
+	}
+	var header pb.ColumnarHeader
+	if err := proto.Unmarshal(data, &header); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrColumnarFormat, err)
+	}
+	if header.Version != columnarVersion {
+		return nil, 0, fmt.Errorf("%w: version=%d", ErrColumnarVersion, header.Version)
+	}
+	return &header, columnarPrefixSize + length, nil
+}
+
+// ColumnarToSlices 读取列式缓存文件到结构体切片
+//
+//	columns为空时读取全部列, 否则只读取指定的列, 其余字段保持零值
+func ColumnarToSlices[T any](filename string, pointer *[]T, columns ...string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer api.CloseQuietly(file)
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, start, err := readColumnarHeader(file, stat.Size())
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	body := uint64(stat.Size() - start)
+	index := make(map[string]*pb.ColumnIndex, len(header.Columns))
+	for _, column := range header.Columns {
+		index[column.Name] = column
+	}
+	rows := int(header.Rows)
+	// 每列每行至少1个字节, 防止损坏的文件导致超大的内存分配
+	if len(index) > 0 && uint64(rows) > body {
+		return fmt.Errorf("%w: %s, 行数%d超出文件范围", ErrColumnarFormat, filename, rows)
+	}
+	list := make([]T, rows)
+	rv := reflect.ValueOf(list)
+	elemType := rv.Type().Elem()
+	if elemType.Kind() == reflect.Pointer {
+		for i := 0; i < rows; i++ {
+			rv.Index(i).Set(reflect.New(elemType.Elem()))
+		}
+	}
+	for _, field := range columnarFields(structType(elemType)) {
+		if len(columns) > 0 && !slices.Contains(columns, field.name) {
+			continue
+		}
+		column, ok := index[field.name]
+		if !ok {
+			continue
+		}
+		if column.Offset+column.Size > body {
+			return fmt.Errorf("%w: %s, 列%s超出文件范围", ErrColumnarFormat, filename, field.name)
+		}
+		data := make([]byte, column.Size)
+		if _, err = file.ReadAt(data, start+int64(column.Offset)); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+		err = decodeColumn(rv, field, column.Type, data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+	*pointer = list
+	return nil
+}
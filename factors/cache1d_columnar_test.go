@@ -0,0 +1,144 @@
+package factors
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"gitee.com/quant1x/gox/api"
+)
+
+type columnarSummary struct {
+	Count int
+}
+
+type columnarRow struct {
+	columnarSummary `dataframe:"-"`
+	Date            string  `name:"日期" dataframe:"date"`
+	Code            string  `name:"代码" dataframe:"code"`
+	Price           float64 `name:"价格" dataframe:"price"`
+	Days            int     `name:"天数" dataframe:"days"`
+	Volume          int64   `name:"成交量" dataframe:"volume"`
+	UpdateTime      uint64  `name:"更新时间" dataframe:"update_time"`
+	Limit           bool    `name:"涨停" dataframe:"limit"`
+	ignore          string
+}
+
+type columnarRowV2 struct {
+	Code  string  `dataframe:"code"`
+	Price float64 `dataframe:"price"`
+	Extra float64 `dataframe:"extra"`
+}
+
+func TestColumnarRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "2024", "history.2024-06-25"+columnarExtension)
+	list := []*columnarRow{
+		{columnarSummary: columnarSummary{Count: 1}, Date: "2024-06-25", Code: "sh600000", Price: 7.12, Days: 3, Volume: -5, UpdateTime: 1 << 40, Limit: true, ignore: "x"},
+		nil,
+		{Date: "2024-06-25", Code: "sz000001", Price: 9.87, Days: 0},
+	}
+	if err := SlicesToColumnar(filename, list, true); err != nil {
+		t.Fatal(err)
+	}
+	var got []*columnarRow
+	if err := ColumnarToSlices(filename, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []*columnarRow{
+		{Date: "2024-06-25", Code: "sh600000", Price: 7.12, Days: 3, Volume: -5, UpdateTime: 1 << 40, Limit: true},
+		{},
+		{Date: "2024-06-25", Code: "sz000001", Price: 9.87},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// 按列名匹配, 多余的列忽略, 缺少的列为零值
+	var v2 []columnarRowV2
+	if err := ColumnarToSlices(filename, &v2); err != nil {
+		t.Fatal(err)
+	}
+	if len(v2) != 3 || v2[2].Code != "sz000001" || v2[2].Price != 9.87 || v2[2].Extra != 0 {
+		t.Errorf("v2 = %+v", v2)
+	}
+}
+
+func TestColumnarCorrupted(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "bad"+columnarExtension)
+	if err := os.WriteFile(filename, []byte("not a columnar file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var list []columnarRow
+	if err := ColumnarToSlices(filename, &list); err == nil {
+		t.Error("expected error for corrupted file")
+	}
+	if err := SlicesToColumnar(filename, []columnarRow{{Code: "sh600000", Price: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filename)
+	if err := os.WriteFile(filename, data[:len(data)-4], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ColumnarToSlices(filename, &list); err == nil {
+		t.Error("expected error for truncated file")
+	}
+}
+
+func TestColumnarSelectColumns(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "select"+columnarExtension)
+	list := []columnarRow{
+		{Date: "2024-06-25", Code: "sh600000", Price: 7.12, Days: 3, Limit: true},
+		{Date: "2024-06-25", Code: "sz000001", Price: 9.87, Days: 5},
+	}
+	if err := SlicesToColumnar(filename, list); err != nil {
+		t.Fatal(err)
+	}
+	var got []columnarRow
+	if err := ColumnarToSlices(filename, &got, "code", "price"); err != nil {
+		t.Fatal(err)
+	}
+	want := []columnarRow{
+		{Code: "sh600000", Price: 7.12},
+		{Code: "sz000001", Price: 9.87},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestCache1DFileNewer(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "history.2024-06-25")
+	csvList := []columnarRowV2{{Code: "sh600000", Price: 1}}
+	colList := []columnarRowV2{{Code: "sh600000", Price: 2}}
+	if err := api.SlicesToCsv(filename, csvList); err != nil {
+		t.Fatal(err)
+	}
+	if err := SlicesToColumnar(columnarFilename(filename), colList); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	// 列式文件较新
+	if err := os.Chtimes(filename, old, old); err != nil {
+		t.Fatal(err)
+	}
+	var got []columnarRowV2
+	if err := loadCache1DFile(filename, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Price != 2 {
+		t.Errorf("columnar newer: got %+v", got)
+	}
+	// csv较新, 例如切换回csv存储后写入
+	if err := os.Chtimes(columnarFilename(filename), old.Add(-time.Hour), old.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadCache1DFile(filename, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Price != 1 {
+		t.Errorf("csv newer: got %+v", got)
+	}
+}
@@ -22,6 +22,8 @@ const (
 	BasePerformanceForecast = cache.PluginMaskBaseData | (baseKind + 8)  // 基础数据-业绩预告
 	BaseChipDistribution    = cache.PluginMaskBaseData | (baseKind + 9)  // 基础数据-筹码分布
 	BaseKLineMinute         = cache.PluginMaskBaseData | (baseKind + 10) // 基础数据-基础分钟级别K线
+	BaseBillBoard           = cache.PluginMaskBaseData | (baseKind + 11) // 基础数据-龙虎榜
+	BaseNotices             = cache.PluginMaskBaseData | (baseKind + 12) // 基础数据-公告事件
 )
 
 // DataSet 数据层, 数据集接口 smart
@@ -52,7 +54,9 @@ var (
 		BaseWideKLine:           cache.Summary(BaseWideKLine, "wide", "宽表", cache.DefaultDataProvider),
 		BasePerformanceForecast: cache.Summary(BasePerformanceForecast, "forecast", "业绩预告", cache.DefaultDataProvider),
 		BaseChipDistribution:    cache.Summary(BaseChipDistribution, "chips", "筹码分布", cache.DefaultDataProvider),
-		BaseKLineMinute:         cache.Summary(BaseKLineMinute, "min", "分钟级K线", cache.DefaultDataProvider, "支持1min,5min,15min,30min,60min"),
+		BaseKLineMinute:         cache.Summary(BaseKLineMinute, "min", "分钟级K线", cache.DefaultDataProvider, "支持任意分钟、小时和交易日的周期, 比如5min,90min,2h,3d, 由1分钟K线重采样"),
+		BaseBillBoard:           cache.Summary(BaseBillBoard, "billboard", "龙虎榜", cache.DefaultDataProvider),
+		BaseNotices:             cache.Summary(BaseNotices, "notices", "公告事件", cache.DefaultDataProvider),
 	}
 )
 
@@ -0,0 +1,62 @@
+package factors
+
+import (
+	"context"
+
+	"gitee.com/quant1x/data/level1/quotes"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/gox/logger"
+)
+
+// DataBillBoard 龙虎榜
+//
+//	龙虎榜是全市场的数据, 每个交易日一个文件, 在Init中按日期拉取, 更新和修复都是重新拉取指定日期
+type DataBillBoard struct {
+	Manifest
+}
+
+func init() {
+	summary := __mapDataSets[BaseBillBoard]
+	_ = cache.Register(&DataBillBoard{Manifest: Manifest{DataSummary: summary}})
+}
+
+func (this *DataBillBoard) Clone(date string, code string) DataSet {
+	summary := __mapDataSets[BaseBillBoard]
+	var dest = DataBillBoard{
+		Manifest: Manifest{
+			DataSummary: summary,
+			Date:        date,
+			Code:        code,
+		},
+	}
+	return &dest
+}
+
+func (this *DataBillBoard) Init(ctx context.Context, date string) error {
+	_ = ctx
+	modName := "龙虎榜"
+	logger.Info(modName + ", 任务开始启动...")
+	list, err := syncBillBoardList(date)
+	logger.Infof("%s, date=%s, %d条, 任务开始结束...", modName, date, len(list))
+	return err
+}
+
+func (this *DataBillBoard) Update(date string) error {
+	_ = date
+	return nil
+}
+
+func (this *DataBillBoard) Repair(date string) error {
+	_ = date
+	return nil
+}
+
+func (this *DataBillBoard) Increase(snapshot quotes.Snapshot) error {
+	_ = snapshot
+	return nil
+}
+
+func (this *DataBillBoard) Print(code string, date ...string) {
+	_ = code
+	_ = date
+}
@@ -46,6 +46,11 @@ func (d *DataChip) Init(ctx context.Context, date string) error {
 	return nil
 }
 
+// DependOn 依赖的上游数据, 筹码分布由历史成交数据计算
+func (d *DataChip) DependOn() []cache.Kind {
+	return []cache.Kind{BaseTransaction}
+}
+
 func (d *DataChip) Update(featureDate string) error {
 	cacheFilename := cache.ChipsFilename(d.GetSecurityCode())
 	filepath, err := homedir.Expand(cacheFilename)
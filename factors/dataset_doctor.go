@@ -0,0 +1,537 @@
+package factors
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/data/level1/quotes"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/datasource/base"
+	"gitee.com/quant1x/engine/datasource/dfcf"
+	"gitee.com/quant1x/engine/factors/pb"
+	"gitee.com/quant1x/gox/api"
+	"google.golang.org/protobuf/proto"
+)
+
+// 数据问题的类型
+const (
+	IssueMissingFile = "missing_file" // 缓存文件不存在
+	IssueMissingDay  = "missing_day"  // 缺少交易日的数据
+	IssueOHLC        = "ohlc"         // 开高低收不一致
+	IssueZeroVolume  = "zero_volume"  // 有K线但是成交量为0
+	IssueUnadjusted  = "unadjusted"   // 除权除息日的跳空没有复权
+	IssuePriceJump   = "price_jump"   // 超过涨跌停的跳空, 没有对应的除权除息记录
+	IssueSchema      = "schema"       // CSV表头和数据结构不一致
+	IssueLookAhead   = "look_ahead"   // 特征引用了特征日期之后才披露的数据
+)
+
+const (
+	// 涨跌幅超过涨跌停限制的容差, 避免四舍五入的误报
+	doctorLimitTolerance = 0.01
+	// 上市后不设涨跌停的交易日数
+	doctorNoLimitDays = 5
+)
+
+// DataIssue 基础数据的一个问题
+type DataIssue struct {
+	Dataset    string `name:"数据集" dataframe:"dataset"`    // 数据集关键字
+	Code       string `name:"证券代码" dataframe:"code"`      // 证券代码
+	Date       string `name:"日期" dataframe:"date"`        // 交易日期
+	Type       string `name:"问题" dataframe:"type"`        // 问题类型
+	Detail     string `name:"描述" dataframe:"detail"`      // 问题描述
+	Repairable bool   `name:"可修复" dataframe:"repairable"` // 是否可以通过repair修复
+}
+
+// 检查一个证券在日期范围内的数据
+//
+//	dates是升序的交易日, klines是这个证券的日K线
+type dataSetChecker func(securityCode string, dates []string, klines []base.KLine) []DataIssue
+
+// 检查一个交易日的全市场数据, 返回的问题需要带上证券代码
+type dataSetDateChecker func(date string) []DataIssue
+
+var (
+	// 支持检查的数据集, 其它数据集按季度或全市场存储, 不按证券检查
+	__mapDataSetCheckers = map[cache.Kind]dataSetChecker{
+		BaseXdxr:             doctorXdxr,
+		BaseKLine:            doctorKLine,
+		BaseTransaction:      doctorTransaction,
+		BaseMinutes:          doctorMinutes,
+		BaseWideKLine:        doctorWideKLine,
+		BaseChipDistribution: doctorChips,
+	}
+	// 按交易日检查全市场的数据集
+	__mapDataSetDateCheckers = map[cache.Kind]dataSetDateChecker{
+		BaseQuarterlyReports: doctorLookAhead,
+	}
+)
+
+// DataSetKinds 全部数据集的类型, 按类型排序
+func DataSetKinds() []cache.Kind {
+	kinds := make([]cache.Kind, 0, len(__mapDataSets))
+	for kind := range __mapDataSets {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+// CheckableDataSet 数据集是否支持按证券检查
+func CheckableDataSet(kind cache.Kind) bool {
+	_, ok := __mapDataSetCheckers[kind]
+	return ok
+}
+
+// CheckableDataSetByDate 数据集是否支持按交易日检查
+func CheckableDataSetByDate(kind cache.Kind) bool {
+	_, ok := __mapDataSetDateCheckers[kind]
+	return ok
+}
+
+// DiagnoseDataSetsByDate 检查一个交易日全市场的数据, 不支持按交易日检查的数据集忽略
+func DiagnoseDataSetsByDate(date string, kinds ...cache.Kind) []DataIssue {
+	date = exchange.FixTradeDate(date)
+	var issues []DataIssue
+	for _, kind := range kinds {
+		checker, ok := __mapDataSetDateCheckers[kind]
+		if !ok {
+			continue
+		}
+		list := checker(date)
+		key := __mapDataSets[kind].Key()
+		for i := range list {
+			list[i].Dataset = key
+			list[i].Date = date
+		}
+		issues = append(issues, list...)
+	}
+	return issues
+}
+
+// DiagnoseDataSets 检查一个证券在日期范围内的基础数据
+//
+//	dates是升序的交易日, 不支持检查的数据集忽略
+func DiagnoseDataSets(securityCode string, dates []string, kinds ...cache.Kind) []DataIssue {
+	if len(dates) == 0 {
+		return nil
+	}
+	securityCode = exchange.CorrectSecurityCode(securityCode)
+	klines := base.LoadBasicKline(securityCode)
+	var issues []DataIssue
+	for _, kind := range kinds {
+		checker, ok := __mapDataSetCheckers[kind]
+		if !ok {
+			continue
+		}
+		list := checker(securityCode, dates, klines)
+		key := __mapDataSets[kind].Key()
+		for i := range list {
+			list[i].Dataset = key
+			list[i].Code = securityCode
+		}
+		issues = append(issues, list...)
+	}
+	return issues
+}
+
+// 结构体的CSV表头, 取dataframe标签
+func csvHeaders(v any) []string {
+	typ := reflect.TypeOf(v)
+	var headers []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(field.Tag.Get("dataframe"), ",")
+		if len(tag) == 0 || tag == "-" {
+			continue
+		}
+		headers = append(headers, tag)
+	}
+	return headers
+}
+
+// 读取CSV文件的表头
+func readCsvHeaders(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer api.CloseQuietly(f)
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	return reader.Read()
+}
+
+// 检查CSV表头和数据结构是否一致
+func checkSchema(filename string, v any) *DataIssue {
+	headers, err := readCsvHeaders(filename)
+	if err != nil {
+		return nil
+	}
+	expected := csvHeaders(v)
+	if len(expected) == 0 {
+		return nil
+	}
+	var missing, extra []string
+	for _, h := range expected {
+		if !slices.Contains(headers, h) {
+			missing = append(missing, h)
+		}
+	}
+	for _, h := range headers {
+		if !slices.Contains(expected, h) {
+			extra = append(extra, h)
+		}
+	}
+	if len(missing) == 0 && len(extra) == 0 {
+		return nil
+	}
+	return &DataIssue{
+		Type:       IssueSchema,
+		Detail:     fmt.Sprintf("%s, 缺少列[%s], 多出列[%s]", filename, strings.Join(missing, ","), strings.Join(extra, ",")),
+		Repairable: true,
+	}
+}
+
+// 有成交的交易日, 停牌的日期没有K线或成交量为0
+func tradedDates(dates []string, klines []base.KLine) []string {
+	traded := make(map[string]bool, len(klines))
+	for _, v := range klines {
+		if v.Volume > 0 {
+			traded[v.Date] = true
+		}
+	}
+	var list []string
+	for _, date := range dates {
+		if traded[date] {
+			list = append(list, date)
+		}
+	}
+	return list
+}
+
+// 缺少的交易日, 只检查上市之后的交易日
+func missingDates(dates []string, have map[string]bool, listingDate string) []string {
+	var list []string
+	for _, date := range dates {
+		if date < listingDate {
+			continue
+		}
+		if !have[date] {
+			list = append(list, date)
+		}
+	}
+	return list
+}
+
+// 检查除权除息
+func doctorXdxr(securityCode string, dates []string, klines []base.KLine) []DataIssue {
+	if !exchange.AssertStockBySecurityCode(securityCode) {
+		// 指数和板块没有除权除息
+		return nil
+	}
+	filename := cache.XdxrFilename(securityCode)
+	if !api.FileExist(filename) {
+		return []DataIssue{{Date: dates[len(dates)-1], Type: IssueMissingFile, Detail: filename, Repairable: true}}
+	}
+	if issue := checkSchema(filename, quotes.XdxrInfo{}); issue != nil {
+		issue.Date = dates[len(dates)-1]
+		return []DataIssue{*issue}
+	}
+	_ = klines
+	return nil
+}
+
+// 检查日K线
+func doctorKLine(securityCode string, dates []string, klines []base.KLine) []DataIssue {
+	lastDate := dates[len(dates)-1]
+	filename := cache.KLineFilename(securityCode)
+	if !api.FileExist(filename) {
+		return []DataIssue{{Date: lastDate, Type: IssueMissingFile, Detail: filename, Repairable: true}}
+	}
+	if issue := checkSchema(filename, base.KLine{}); issue != nil {
+		issue.Date = lastDate
+		return []DataIssue{*issue}
+	}
+	if len(klines) == 0 {
+		return []DataIssue{{Date: lastDate, Type: IssueMissingDay, Detail: "没有K线", Repairable: true}}
+	}
+	var issues []DataIssue
+	have := make(map[string]bool, len(klines))
+	for _, v := range klines {
+		have[v.Date] = true
+	}
+	for _, date := range missingDates(dates, have, klines[0].Date) {
+		issues = append(issues, DataIssue{Date: date, Type: IssueMissingDay, Detail: "没有K线", Repairable: true})
+	}
+	var xdxrDates map[string]bool
+	if exchange.AssertStockBySecurityCode(securityCode) {
+		xdxrDates = map[string]bool{}
+		for _, v := range base.GetCacheXdxrList(securityCode) {
+			if v.Category == 1 {
+				xdxrDates[exchange.FixTradeDate(v.Date)] = true
+			}
+		}
+	}
+	issues = append(issues, checkKLineValues(klines, dates[0], lastDate, exchange.MarketLimit(securityCode), xdxrDates)...)
+	return issues
+}
+
+// 检查K线的数值, 开高低收、成交量和跳空
+//
+//	xdxrDates为nil时不检查跳空, 比如指数和板块
+func checkKLineValues(klines []base.KLine, beginDate, endDate string, limitRate float64, xdxrDates map[string]bool) []DataIssue {
+	var issues []DataIssue
+	for i, v := range klines {
+		if v.Date < beginDate || v.Date > endDate {
+			continue
+		}
+		if v.Open <= 0 || v.Close <= 0 || v.High <= 0 || v.Low <= 0 ||
+			v.High < max(v.Open, v.Close) || v.Low > min(v.Open, v.Close) {
+			issues = append(issues, DataIssue{
+				Date:   v.Date,
+				Type:   IssueOHLC,
+				Detail: fmt.Sprintf("open=%.2f, high=%.2f, low=%.2f, close=%.2f", v.Open, v.High, v.Low, v.Close),
+			})
+			continue
+		}
+		if v.Volume <= 0 || v.Amount <= 0 {
+			issues = append(issues, DataIssue{
+				Date:   v.Date,
+				Type:   IssueZeroVolume,
+				Detail: fmt.Sprintf("volume=%.0f, amount=%.2f", v.Volume, v.Amount),
+			})
+		}
+		if xdxrDates == nil || i < doctorNoLimitDays || limitRate <= 0 {
+			continue
+		}
+		lastClose := klines[i-1].Close
+		if lastClose <= 0 {
+			continue
+		}
+		change := max(math.Abs(v.Open/lastClose-1), math.Abs(v.Close/lastClose-1))
+		if change <= limitRate+doctorLimitTolerance {
+			continue
+		}
+		if xdxrDates[v.Date] {
+			issues = append(issues, DataIssue{
+				Date:       v.Date,
+				Type:       IssueUnadjusted,
+				Detail:     fmt.Sprintf("除权除息日未复权, last_close=%.2f, open=%.2f, close=%.2f", lastClose, v.Open, v.Close),
+				Repairable: true,
+			})
+		} else {
+			issues = append(issues, DataIssue{
+				Date:   v.Date,
+				Type:   IssuePriceJump,
+				Detail: fmt.Sprintf("跳空%.2f%%超过涨跌停, 没有除权除息记录, last_close=%.2f, open=%.2f, close=%.2f", change*100, lastClose, v.Open, v.Close),
+			})
+		}
+	}
+	return issues
+}
+
+// 按交易日存储的CSV文件, 检查有成交的交易日是否都有文件
+func checkDailyFiles(dates []string, filename func(date string) string, v any) []DataIssue {
+	var issues []DataIssue
+	schemaChecked := false
+	for i := len(dates) - 1; i >= 0; i-- {
+		date := dates[i]
+		name := filename(date)
+		if !api.FileExist(name) {
+			issues = append(issues, DataIssue{Date: date, Type: IssueMissingFile, Detail: name, Repairable: true})
+			continue
+		}
+		// 只检查最近一个文件的表头
+		if !schemaChecked {
+			schemaChecked = true
+			if issue := checkSchema(name, v); issue != nil {
+				issue.Date = date
+				issues = append(issues, *issue)
+			}
+		}
+	}
+	slices.Reverse(issues)
+	return issues
+}
+
+// 检查历史成交数据, 只检查配置的起始日期之后有成交的交易日
+func doctorTransaction(securityCode string, dates []string, klines []base.KLine) []DataIssue {
+	beginDate := exchange.FixTradeDate(base.GetBeginDateOfHistoricalTradingData())
+	var list []string
+	for _, date := range tradedDates(dates, klines) {
+		if date >= beginDate {
+			list = append(list, date)
+		}
+	}
+	return checkDailyFiles(list, func(date string) string {
+		return cache.TransFilename(securityCode, date)
+	}, quotes.TickTransaction{})
+}
+
+// 检查分时数据
+func doctorMinutes(securityCode string, dates []string, klines []base.KLine) []DataIssue {
+	return checkDailyFiles(tradedDates(dates, klines), func(date string) string {
+		return cache.MinuteFilename(securityCode, date)
+	}, quotes.MinuteTime{})
+}
+
+// 检查宽表, 交易日应该和日K线一致
+func doctorWideKLine(securityCode string, dates []string, klines []base.KLine) []DataIssue {
+	lastDate := dates[len(dates)-1]
+	filename := cache.WideFilename(securityCode)
+	if !api.FileExist(filename) {
+		return []DataIssue{{Date: lastDate, Type: IssueMissingFile, Detail: filename, Repairable: true}}
+	}
+	if issue := checkSchema(filename, SecurityFeature{}); issue != nil {
+		issue.Date = lastDate
+		return []DataIssue{*issue}
+	}
+	var list []SecurityFeature
+	_ = api.CsvToSlices(filename, &list)
+	have := make(map[string]bool, len(list))
+	for _, v := range list {
+		have[v.Date] = true
+	}
+	var issues []DataIssue
+	for _, date := range tradedDates(dates, klines) {
+		if !have[date] {
+			issues = append(issues, DataIssue{Date: date, Type: IssueMissingDay, Detail: "宽表缺少K线的交易日", Repairable: true})
+		}
+	}
+	return issues
+}
+
+// 检查筹码分布, 和历史成交数据的范围一致
+func doctorChips(securityCode string, dates []string, klines []base.KLine) []DataIssue {
+	beginDate := exchange.FixTradeDate(base.GetBeginDateOfHistoricalTradingData())
+	var list []string
+	for _, date := range tradedDates(dates, klines) {
+		if date >= beginDate {
+			list = append(list, date)
+		}
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	filename := cache.ChipsFilename(securityCode)
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return []DataIssue{{Date: list[len(list)-1], Type: IssueMissingFile, Detail: filename, Repairable: true}}
+	}
+	cd := pb.ChipDistribution{}
+	if err = proto.Unmarshal(data, &cd); err != nil {
+		return []DataIssue{{Date: list[len(list)-1], Type: IssueSchema, Detail: fmt.Sprintf("%s, %v", filename, err), Repairable: true}}
+	}
+	var issues []DataIssue
+	for _, date := range list {
+		if _, ok := cd.Data[date]; !ok {
+			issues = append(issues, DataIssue{Date: date, Type: IssueMissingDay, Detail: "没有筹码分布", Repairable: true})
+		}
+	}
+	return issues
+}
+
+// 季报缓存中报告期为qdate的季报, 证券代码 -> 季报
+//
+//	季报数据集按拉取时所在的季度保存, 报告期的季报可能在报告期所在季度或者下一个季度的文件中
+func loadReportsByQuarter(qdate string) (map[string]dfcf.QuarterlyReport, error) {
+	qEnd, err := dfcf.QuarterEndDate(qdate)
+	if err != nil {
+		return nil, err
+	}
+	_, nextEnd := api.GetQuarterDayByDate(exchange.NextTradeDate(qEnd))
+	reports := map[string]dfcf.QuarterlyReport{}
+	for _, date := range []string{nextEnd, qEnd} {
+		var list []dfcf.QuarterlyReport
+		filename := cache.ReportsFilename(date)
+		if !api.FileExist(filename) {
+			continue
+		}
+		_ = api.CsvToSlices(filename, &list)
+		for _, v := range list {
+			if v.QDATE == qdate {
+				reports[exchange.CorrectSecurityCode(v.SecurityCode)] = v
+			}
+		}
+	}
+	return reports, nil
+}
+
+// 检查F10引用的季报在特征日期是否已经披露, 没有泄露返回nil
+func checkLookAhead(f10 *F10, report dfcf.QuarterlyReport) *DataIssue {
+	if dfcf.ReportIsPublic(report, f10.Date) {
+		return nil
+	}
+	return &DataIssue{
+		Code:       f10.Code,
+		Type:       IssueLookAhead,
+		Detail:     fmt.Sprintf("F10引用了报告期%s的季报, 披露日期%s晚于特征日期%s", f10.QDate, dfcf.ReportDisclosureDate(report), f10.Date),
+		Repairable: true,
+	}
+}
+
+// 检查F10记录的季报和流通股东的公告日期, 公告日期晚于特征日期即为前视, 没有泄露返回nil
+func checkNoticeDates(f10 *F10) *DataIssue {
+	var leaks []string
+	if len(f10.QNoticeDate) > 0 && f10.QNoticeDate > f10.Date {
+		leaks = append(leaks, fmt.Sprintf("报告期%s的季报公告日期%s", f10.QDate, f10.QNoticeDate))
+	}
+	if len(f10.HolderNoticeDate) > 0 && f10.HolderNoticeDate > f10.Date {
+		leaks = append(leaks, fmt.Sprintf("流通股东公告日期%s", f10.HolderNoticeDate))
+	}
+	if len(leaks) == 0 {
+		return nil
+	}
+	return &DataIssue{
+		Code:       f10.Code,
+		Type:       IssueLookAhead,
+		Detail:     fmt.Sprintf("F10引用的%s晚于特征日期%s", strings.Join(leaks, ", "), f10.Date),
+		Repairable: true,
+	}
+}
+
+// 检查季报和流通股东的前视偏差, F10引用的数据必须在特征日期当日或之前已经披露
+//
+//	优先核对F10中记录的公告日期, 旧的缓存没有公告日期时再按报告期核对季报的披露日期
+func doctorLookAhead(date string) []DataIssue {
+	list := FilterL5F10(func(v *F10) bool {
+		return len(v.QDate) > 0 || len(v.HolderNoticeDate) > 0
+	}, date)
+	mapReports := map[string]map[string]dfcf.QuarterlyReport{}
+	var issues []DataIssue
+	for _, v := range list {
+		f10 := *v
+		if len(f10.Date) == 0 {
+			f10.Date = date
+		}
+		if issue := checkNoticeDates(&f10); issue != nil {
+			issues = append(issues, *issue)
+			continue
+		}
+		if len(v.QDate) == 0 {
+			continue
+		}
+		reports, ok := mapReports[v.QDate]
+		if !ok {
+			reports, _ = loadReportsByQuarter(v.QDate)
+			mapReports[v.QDate] = reports
+		}
+		report, ok := reports[exchange.CorrectSecurityCode(v.Code)]
+		if !ok {
+			continue
+		}
+		if issue := checkLookAhead(&f10, report); issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+	return issues
+}
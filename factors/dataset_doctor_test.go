@@ -0,0 +1,113 @@
+package factors
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"gitee.com/quant1x/engine/datasource/base"
+	"gitee.com/quant1x/engine/datasource/dfcf"
+)
+
+func TestCheckKLineValues(t *testing.T) {
+	klines := []base.KLine{
+		{Date: "2024-01-02", Open: 10, Close: 10, High: 10.5, Low: 9.8, Volume: 100, Amount: 1000},
+		{Date: "2024-01-03", Open: 10, Close: 10.2, High: 10.3, Low: 9.9, Volume: 100, Amount: 1000},
+		{Date: "2024-01-04", Open: 10, Close: 10.2, High: 10.1, Low: 9.9, Volume: 100, Amount: 1000},
+		{Date: "2024-01-05", Open: 10, Close: 10.1, High: 10.3, Low: 9.9, Volume: 0, Amount: 0},
+		{Date: "2024-01-08", Open: 10, Close: 10.1, High: 10.3, Low: 9.9, Volume: 100, Amount: 1000},
+		{Date: "2024-01-09", Open: 10, Close: 10.1, High: 10.3, Low: 9.9, Volume: 100, Amount: 1000},
+		{Date: "2024-01-10", Open: 7, Close: 7.1, High: 7.2, Low: 6.9, Volume: 100, Amount: 1000},
+		{Date: "2024-01-11", Open: 5, Close: 5.1, High: 5.2, Low: 4.9, Volume: 100, Amount: 1000},
+		{Date: "2024-01-12", Open: 5.5, Close: 5.6, High: 5.6, Low: 5.4, Volume: 100, Amount: 1000},
+	}
+	xdxrDates := map[string]bool{"2024-01-10": true}
+	issues := checkKLineValues(klines, "2024-01-03", "2024-01-12", 0.10, xdxrDates)
+	var got []string
+	for _, v := range issues {
+		got = append(got, v.Date+":"+v.Type)
+	}
+	want := []string{
+		"2024-01-04:" + IssueOHLC,
+		"2024-01-05:" + IssueZeroVolume,
+		"2024-01-10:" + IssueUnadjusted,
+		"2024-01-11:" + IssuePriceJump,
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("issues = %v, want %v", got, want)
+	}
+	if !issues[2].Repairable || issues[3].Repairable {
+		t.Fatal("只有除权除息日未复权可以修复")
+	}
+	// 指数不检查跳空
+	issues = checkKLineValues(klines, "2024-01-10", "2024-01-12", 0.10, nil)
+	if len(issues) != 0 {
+		t.Fatalf("issues = %v", issues)
+	}
+}
+
+func TestMissingDates(t *testing.T) {
+	dates := []string{"2024-01-02", "2024-01-03", "2024-01-04", "2024-01-05"}
+	have := map[string]bool{"2024-01-03": true, "2024-01-05": true}
+	got := missingDates(dates, have, "2024-01-03")
+	if !slices.Equal(got, []string{"2024-01-04"}) {
+		t.Fatalf("missing = %v", got)
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	type row struct {
+		Date  string  `dataframe:"date"`
+		Close float64 `dataframe:"close,float64"`
+		Skip  int
+	}
+	if headers := csvHeaders(row{}); !slices.Equal(headers, []string{"date", "close"}) {
+		t.Fatalf("headers = %v", headers)
+	}
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "a.csv")
+	_ = os.WriteFile(filename, []byte("date,close\n2024-01-02,1.0\n"), 0644)
+	if issue := checkSchema(filename, row{}); issue != nil {
+		t.Fatalf("issue = %+v", issue)
+	}
+	_ = os.WriteFile(filename, []byte("date,open\n2024-01-02,1.0\n"), 0644)
+	issue := checkSchema(filename, row{})
+	if issue == nil || issue.Type != IssueSchema {
+		t.Fatalf("issue = %+v", issue)
+	}
+}
+
+func TestCheckLookAhead(t *testing.T) {
+	report := dfcf.QuarterlyReport{QDATE: "2024Q1", NoticeDate: "2024-04-26 00:00:00", ReportDate: "2024-03-31 00:00:00"}
+	f10 := &F10{Date: "2024-04-25", Code: "sh600600", QDate: "2024Q1"}
+	issue := checkLookAhead(f10, report)
+	if issue == nil || issue.Type != IssueLookAhead || issue.Code != "sh600600" || !issue.Repairable {
+		t.Fatalf("披露之前引用季报应该报告前视偏差, issue = %+v", issue)
+	}
+	f10.Date = "2024-04-26"
+	if issue = checkLookAhead(f10, report); issue != nil {
+		t.Fatalf("披露当日引用季报不是前视偏差, issue = %+v", issue)
+	}
+}
+
+func TestCheckNoticeDates(t *testing.T) {
+	f10 := &F10{Date: "2024-04-25", Code: "sh600600", QDate: "2024Q1", QNoticeDate: "2024-04-20", HolderNoticeDate: "2024-04-26"}
+	issue := checkNoticeDates(f10)
+	if issue == nil || issue.Type != IssueLookAhead || !strings.Contains(issue.Detail, "流通股东") || strings.Contains(issue.Detail, "季报") {
+		t.Fatalf("流通股东公告之前引用应该报告前视偏差, issue = %+v", issue)
+	}
+	f10.QNoticeDate = "2024-04-26"
+	if issue = checkNoticeDates(f10); issue == nil || !strings.Contains(issue.Detail, "季报") {
+		t.Fatalf("季报公告之前引用应该报告前视偏差, issue = %+v", issue)
+	}
+	f10.Date = "2024-04-26"
+	if issue = checkNoticeDates(f10); issue != nil {
+		t.Fatalf("公告当日引用不是前视偏差, issue = %+v", issue)
+	}
+	// 旧的缓存没有公告日期
+	if issue = checkNoticeDates(&F10{Date: "2024-04-25", QDate: "2024Q1"}); issue != nil {
+		t.Fatalf("没有公告日期不检查, issue = %+v", issue)
+	}
+}
@@ -48,6 +48,11 @@ func (k *DataKLine) Init(ctx context.Context, date string) error {
 	return nil
 }
 
+// DependOn 依赖的上游数据, 日K线按除权除息信息计算复权
+func (k *DataKLine) DependOn() []cache.Kind {
+	return []cache.Kind{BaseXdxr}
+}
+
 func (k *DataKLine) Checkout(securityCode, date string) {
 	//TODO implement me
 	_ = securityCode
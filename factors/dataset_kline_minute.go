@@ -2,22 +2,27 @@ package factors
 
 import (
 	"context"
-	"log"
+	"slices"
 
 	"gitee.com/quant1x/data/level1/quotes"
 	"gitee.com/quant1x/engine/cache"
 	"gitee.com/quant1x/engine/config"
 	"gitee.com/quant1x/engine/datasource/base"
-	"gitee.com/quant1x/num"
+	"gitee.com/quant1x/gox/logger"
 )
 
 const (
 	cacheKeyKLine = "kline"
 )
 
+const (
+	// 重采样的数据源, 从服务器拉取的分钟K线
+	minuteKLineSource = "1min"
+)
+
 var (
 	paramsKLine        = config.GetDataConfig().Cache[cacheKeyKLine]
-	minuteKLine        = "1min"
+	minuteKLines       []string // 启用的K线周期, 非原生的周期由1分钟K线重采样
 	minuteKLineEnabled = false
 )
 
@@ -28,21 +33,22 @@ type KLineMinute struct {
 }
 
 func init() {
-	// 初始化data.cache.kline参数
-	n := len(paramsKLine)
-	if n > 1 {
-		log.Fatal("分钟级K线配置只能是1个")
-	}
+	// 初始化data.cache.kline参数, 可以同时启用多个周期
 	for k, v := range paramsKLine {
 		enabled, ok := v.(bool)
-		if ok {
-			_, err := num.ParseFreq(k)
-			if err == nil {
-				minuteKLineEnabled = enabled
-				minuteKLine = k
-			}
+		if !ok || !enabled {
+			continue
+		}
+		f, err := base.ParseFrequency(k)
+		if err != nil {
+			logger.Errorf("data.cache.kline: %+v", err)
+			continue
 		}
+		minuteKLines = append(minuteKLines, f.Key())
 	}
+	slices.Sort(minuteKLines)
+	minuteKLines = slices.Compact(minuteKLines)
+	minuteKLineEnabled = len(minuteKLines) > 0
 	if minuteKLineEnabled {
 		summary := __mapDataSets[BaseKLineMinute]
 		_ = cache.Register(&KLineMinute{DataSummary: summary})
@@ -74,6 +80,11 @@ func (k *KLineMinute) Init(ctx context.Context, date string) error {
 	return nil
 }
 
+// DependOn 依赖的上游数据, 分钟K线按除权除息信息计算复权
+func (k *KLineMinute) DependOn() []cache.Kind {
+	return []cache.Kind{BaseXdxr}
+}
+
 func (k *KLineMinute) Checkout(securityCode, date string) {
 	//TODO implement me
 	_ = securityCode
@@ -102,14 +113,35 @@ func (k *KLineMinute) Print(code string, date ...string) {
 	panic("implement me")
 }
 
+// 更新启用的全部周期
+//
+//	服务器原生的周期(5/15/30/60分钟)直接拉取, 其它周期拉取1分钟K线后重采样, 和已有的缓存合并
+func (k *KLineMinute) sync() {
+	securityCode := k.GetSecurityCode()
+	var resampled []string
+	for _, freq := range minuteKLines {
+		f, err := base.ParseFrequency(freq)
+		if err == nil && f.Native() && freq != minuteKLineSource {
+			base.UpdateAllKLine(securityCode, freq)
+			continue
+		}
+		resampled = append(resampled, freq)
+	}
+	if len(resampled) == 0 {
+		return
+	}
+	base.UpdateAllKLine(securityCode, minuteKLineSource)
+	base.UpdateResampledKLines(securityCode, resampled...)
+}
+
 func (k *KLineMinute) Update(date string) error {
-	base.UpdateAllKLine(k.GetSecurityCode(), minuteKLine)
+	k.sync()
 	_ = date
 	return nil
 }
 
 func (k *KLineMinute) Repair(date string) error {
-	base.UpdateAllKLine(k.GetSecurityCode(), minuteKLine)
+	k.sync()
 	_ = date
 	return nil
 }
@@ -0,0 +1,249 @@
+package factors
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/data/level1/quotes"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/engine/datasource/dfcf"
+	"gitee.com/quant1x/gox/api"
+	"gitee.com/quant1x/gox/logger"
+)
+
+// 按公告标题识别的事件类型
+const (
+	EventBuyback  = "buyback"  // 回购
+	EventIncrease = "increase" // 增持
+	EventReduce   = "reduce"   // 减持
+	EventPreview  = "preview"  // 业绩预告
+	EventRisk     = "risk"     // 监管风险
+)
+
+var (
+	// 事件的识别规则, 按顺序匹配标题的关键词, 第一个匹配的规则生效
+	noticeEventRules = []struct {
+		event    string
+		keywords []string
+	}{
+		{EventPreview, []string{"业绩预告", "业绩预增", "业绩预减", "业绩预盈", "业绩预亏"}},
+		{EventBuyback, []string{"回购"}},
+		{EventReduce, []string{"减持"}},
+		{EventIncrease, []string{"增持"}},
+		{EventRisk, []string{"立案", "处罚", "退市风险"}},
+	}
+)
+
+// NoticeEvents 支持识别的全部事件类型
+func NoticeEvents() []string {
+	events := make([]string, 0, len(noticeEventRules))
+	for _, rule := range noticeEventRules {
+		events = append(events, rule.event)
+	}
+	return events
+}
+
+// 按公告标题识别事件类型, 没有匹配的规则返回空字符串
+func classifyNoticeEvent(title string) string {
+	for _, rule := range noticeEventRules {
+		for _, keyword := range rule.keywords {
+			if strings.Contains(title, keyword) {
+				return rule.event
+			}
+		}
+	}
+	return ""
+}
+
+// NoticeEvent 公告事件
+type NoticeEvent struct {
+	Date        string `name:"公告日期" dataframe:"date"`         // 公告日期
+	Code        string `name:"证券代码" dataframe:"code"`         // 证券代码
+	Name        string `name:"证券名称" dataframe:"name"`         // 证券名称
+	NoticeType  int    `name:"公告类型" dataframe:"notice_type"`  // 公告类型, dfcf.EMNoticeType
+	Event       string `name:"事件" dataframe:"event"`          // 按标题识别的事件类型, 为空表示没有识别
+	Title       string `name:"公告标题" dataframe:"title"`        // 公告标题
+	DisplayTime string `name:"显示时间" dataframe:"display_time"` // 显示时间
+}
+
+// DataNotice 公告事件
+//
+//	全市场每个交易日一个文件, 保存上一个交易日之后到当日的全部公告, 在Init中按日期拉取
+type DataNotice struct {
+	Manifest
+}
+
+func init() {
+	summary := __mapDataSets[BaseNotices]
+	_ = cache.Register(&DataNotice{Manifest: Manifest{DataSummary: summary}})
+}
+
+func (this *DataNotice) Clone(date string, code string) DataSet {
+	summary := __mapDataSets[BaseNotices]
+	var dest = DataNotice{
+		Manifest: Manifest{
+			DataSummary: summary,
+			Date:        date,
+			Code:        code,
+		},
+	}
+	return &dest
+}
+
+func (this *DataNotice) Init(ctx context.Context, date string) error {
+	_ = ctx
+	modName := "公告事件"
+	logger.Info(modName + ", 任务开始启动...")
+	list, err := syncNoticeEvents(date)
+	logger.Infof("%s, date=%s, %d条, 任务开始结束...", modName, date, len(list))
+	return err
+}
+
+func (this *DataNotice) Update(date string) error {
+	_ = date
+	return nil
+}
+
+func (this *DataNotice) Repair(date string) error {
+	_ = date
+	return nil
+}
+
+func (this *DataNotice) Increase(snapshot quotes.Snapshot) error {
+	_ = snapshot
+	return nil
+}
+
+func (this *DataNotice) Print(code string, date ...string) {
+	_ = code
+	_ = date
+}
+
+// 交易日date的公告范围, 从上一个交易日的次日到date, 覆盖节假日的公告
+func noticeDateRange(date string) (beginDate, endDate string) {
+	endDate = exchange.FixTradeDate(date)
+	beginDate = endDate
+	dates := exchange.LastNDate(endDate, 1)
+	if len(dates) == 0 {
+		return
+	}
+	tm, err := api.ParseTime(dates[0])
+	if err != nil {
+		return
+	}
+	beginDate = tm.AddDate(0, 0, 1).Format(time.DateOnly)
+	return
+}
+
+// 拉取并缓存交易日date的全部公告, 拉取失败或者没有数据时不覆盖已有的缓存
+func syncNoticeEvents(date string) ([]NoticeEvent, error) {
+	beginDate, endDate := noticeDateRange(date)
+	var list []NoticeEvent
+	seen := map[NoticeEvent]bool{}
+	for noticeType := dfcf.NoticeUnused1; noticeType <= dfcf.NoticeHolderChange; noticeType++ {
+		pages := 1
+		for pageNo := 1; pageNo < pages+1; pageNo++ {
+			notices, tmpPages, err := dfcf.AllNoticesInRange(noticeType, beginDate, endDate, pageNo)
+			if err != nil {
+				return nil, fmt.Errorf("公告[%s, type=%d]第%d页: %w", date, noticeType, pageNo, err)
+			}
+			if tmpPages < 1 {
+				break
+			}
+			pages = tmpPages
+			for _, v := range notices {
+				event := NoticeEvent{
+					Date:        exchange.FixTradeDate(v.NoticeDate),
+					Code:        v.Code,
+					Name:        v.Name,
+					NoticeType:  noticeType,
+					Event:       classifyNoticeEvent(v.Title),
+					Title:       v.Title,
+					DisplayTime: v.DisplayTime,
+				}
+				if !seen[event] {
+					seen[event] = true
+					list = append(list, event)
+				}
+			}
+			if len(notices) < dfcf.EastmoneyNoticesPageSize {
+				break
+			}
+		}
+	}
+	if len(list) == 0 {
+		logger.Errorf("date = %s, 没有公告数据", date)
+		return nil, nil
+	}
+	filename := cache.NoticeFilename(date)
+	if err := api.SlicesToCsv(filename, list); err != nil {
+		return list, fmt.Errorf("cache %s failed: %w", filename, err)
+	}
+	return list, nil
+}
+
+// 加载交易日date的公告, 缓存文件不存在时拉取, 当日及以后的公告可能不完整, 不拉取
+func loadNoticeEvents(date string) []NoticeEvent {
+	filename := cache.NoticeFilename(date)
+	if !api.FileExist(filename) {
+		if date >= clock.Today() {
+			return nil
+		}
+		list, err := syncNoticeEvents(date)
+		if err != nil {
+			logger.Errorf("date = %s, 拉取公告失败: %+v", date, err)
+		}
+		return list
+	}
+	var list []NoticeEvent
+	err := api.CsvToSlices(filename, &list)
+	if err != nil {
+		logger.Errorf("cache %s failed, error: %+v", filename, err)
+	}
+	return list
+}
+
+// 按公告类型和事件过滤
+func filterNoticeEvents(list []NoticeEvent, beginDate, endDate string, noticeType dfcf.EMNoticeType, events ...string) []NoticeEvent {
+	var result []NoticeEvent
+	for _, v := range list {
+		if v.Date < beginDate || v.Date > endDate {
+			continue
+		}
+		if noticeType != dfcf.NoticeAll && v.NoticeType != noticeType {
+			continue
+		}
+		if len(events) > 0 && !slices.Contains(events, v.Event) {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+// QueryNoticeEvents 查询日期范围内的公告事件, 按公告日期升序
+//
+//	noticeType为dfcf.NoticeAll时不过滤公告类型, events为空时不过滤事件类型
+func QueryNoticeEvents(beginDate, endDate string, noticeType dfcf.EMNoticeType, events ...string) []NoticeEvent {
+	beginDate = exchange.FixTradeDate(beginDate)
+	endDate = exchange.FixTradeDate(endDate)
+	dates := exchange.TradingDateRange(beginDate, endDate)
+	if len(dates) == 0 || dates[len(dates)-1] < endDate {
+		// 结束日期不是交易日, 节假日的公告保存在下一个交易日
+		dates = append(dates, exchange.NextTradeDate(endDate))
+	}
+	var result []NoticeEvent
+	for _, date := range dates {
+		list := loadNoticeEvents(date)
+		result = append(result, filterNoticeEvents(list, beginDate, endDate, noticeType, events...)...)
+	}
+	slices.SortStableFunc(result, func(a, b NoticeEvent) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+	return result
+}
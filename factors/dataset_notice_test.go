@@ -0,0 +1,45 @@
+package factors
+
+import (
+	"testing"
+
+	"gitee.com/quant1x/engine/datasource/dfcf"
+)
+
+func Test_classifyNoticeEvent(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"关于以集中竞价交易方式回购公司股份的进展公告", EventBuyback},
+		{"关于持股5%以上股东减持股份计划的预披露公告", EventReduce},
+		{"关于控股股东增持公司股份计划的公告", EventIncrease},
+		{"2024年半年度业绩预告", EventPreview},
+		{"关于收到中国证券监督管理委员会立案告知书的公告", EventRisk},
+		{"第八届董事会第三次会议决议公告", ""},
+	}
+	for _, tt := range tests {
+		if got := classifyNoticeEvent(tt.title); got != tt.want {
+			t.Errorf("classifyNoticeEvent(%s) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func Test_filterNoticeEvents(t *testing.T) {
+	list := []NoticeEvent{
+		{Date: "2024-07-12", Code: "sh600000", NoticeType: dfcf.NoticeHolderChange, Event: EventReduce},
+		{Date: "2024-07-13", Code: "sh600001", NoticeType: dfcf.NoticeWarning, Event: EventBuyback},
+		{Date: "2024-07-15", Code: "sz000001", NoticeType: dfcf.NoticeHolderChange, Event: EventIncrease},
+		{Date: "2024-07-16", Code: "sz000002", NoticeType: dfcf.NoticeUnused1, Event: ""},
+	}
+	if got := filterNoticeEvents(list, "2024-07-13", "2024-07-16", dfcf.NoticeAll); len(got) != 3 {
+		t.Errorf("filter by date = %d, want 3", len(got))
+	}
+	if got := filterNoticeEvents(list, "2024-07-01", "2024-07-31", dfcf.NoticeHolderChange); len(got) != 2 {
+		t.Errorf("filter by notice type = %d, want 2", len(got))
+	}
+	got := filterNoticeEvents(list, "2024-07-01", "2024-07-31", dfcf.NoticeAll, EventBuyback, EventIncrease)
+	if len(got) != 2 || got[0].Code != "sh600001" || got[1].Code != "sz000001" {
+		t.Errorf("filter by event = %+v", got)
+	}
+}
@@ -42,6 +42,11 @@ func (this *DataWideKLine) Init(ctx context.Context, date string) error {
 	return nil
 }
 
+// DependOn 依赖的上游数据, 宽表由日K线和历史成交数据合成
+func (this *DataWideKLine) DependOn() []cache.Kind {
+	return []cache.Kind{BaseKLine, BaseTransaction}
+}
+
 func (this *DataWideKLine) Update(date string) error {
 	pullWideByDate(this.GetSecurityCode(), date)
 	return nil
@@ -63,6 +63,12 @@ func loadWideTable(securityCode string) []SecurityFeature {
 	return lines
 }
 
+// LoadWideTable 加载证券全部的宽表数据, 按日期升序
+func LoadWideTable(securityCode string) []SecurityFeature {
+	securityCode = exchange.CorrectSecurityCode(securityCode)
+	return loadWideTable(securityCode)
+}
+
 // 矫正日期的偏移
 func checkWideTableOffset(lines []SecurityFeature, date string) (offset int) {
 	rows := len(lines)
@@ -50,6 +50,7 @@ const (
 	FeatureKLineShap                 = baseFeature + 6 // 特征数据-K线形态等
 	FeatureInvestmentSentimentMaster = baseFeature + 7 // 狩猎者-情绪周期
 	FeatureSecuritiesMarginTrading   = baseFeature + 8 // 融资融券
+	FeatureBillBoard                 = baseFeature + 9 // 龙虎榜
 )
 
 var (
@@ -60,6 +61,7 @@ var (
 		FeatureBreaksThroughBox:          cache.Summary(FeatureBreaksThroughBox, cacheL5KeyBox, "有效突破平台", cache.DefaultDataProvider),
 		FeatureInvestmentSentimentMaster: cache.Summary(FeatureInvestmentSentimentMaster, cacheL5KeyInvestmentSentimentMaster, "情绪大师", cache.DefaultDataProvider),
 		FeatureSecuritiesMarginTrading:   cache.Summary(FeatureSecuritiesMarginTrading, cacheL5KeySecuritiesMarginTrading, "融资融券", cache.DefaultDataProvider),
+		FeatureBillBoard:                 cache.Summary(FeatureBillBoard, cacheL5KeyBillBoard, "龙虎榜", cache.DefaultDataProvider),
 	}
 )
 
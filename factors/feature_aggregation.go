@@ -21,6 +21,8 @@ var (
 	__l5InvestmentSentimentMaster *Cache1D[*InvestmentSentimentMaster] = nil
 	// 融资融券
 	__l5SecuritiesMarginTrading *Cache1D[*SecuritiesMarginTrading] = nil
+	// 龙虎榜
+	__l5BillBoard *Cache1D[*BillBoard] = nil
 )
 
 func init() {
@@ -64,6 +66,12 @@ func lazyInitFeatures() {
 	if err != nil {
 		logger.Fatalf("%+v", err)
 	}
+	// 龙虎榜
+	__l5BillBoard = NewCache1D[*BillBoard](cacheL5KeyBillBoard, NewBillBoard)
+	err = cache.Register(__l5BillBoard)
+	if err != nil {
+		logger.Fatalf("%+v", err)
+	}
 }
 
 func GetL5History(securityCode string, date ...string) *History {
@@ -84,6 +92,13 @@ func GetL5F10(securityCode string, date ...string) *F10 {
 	return *data
 }
 
+// FilterL5F10 过滤指定日期的基本面数据
+func FilterL5F10(f func(v *F10) bool, date ...string) []*F10 {
+	__l5Once.Do(lazyInitFeatures)
+	__l5F10.Checkout(date...)
+	return __l5F10.Filter(f)
+}
+
 // GetL5Misc 获取扩展信息
 func GetL5Misc(securityCode string, date ...string) (exchange *Misc) {
 	__l5Once.Do(lazyInitFeatures)
@@ -153,3 +168,20 @@ func RefreshL5SecuritiesMarginTrading() {
 	__l5Once.Do(lazyInitFeatures)
 	__l5SecuritiesMarginTrading.Apply(nil, true)
 }
+
+// GetL5BillBoard 获取龙虎榜数据
+func GetL5BillBoard(securityCode string, date ...string) (lhb *BillBoard) {
+	__l5Once.Do(lazyInitFeatures)
+	v := __l5BillBoard.Get(securityCode, date...)
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// FilterL5BillBoard 过滤龙虎榜数据
+func FilterL5BillBoard(f func(v *BillBoard) bool, date ...string) []*BillBoard {
+	__l5Once.Do(lazyInitFeatures)
+	__l5BillBoard.Checkout(date...)
+	return __l5BillBoard.Filter(f)
+}
@@ -0,0 +1,120 @@
+package factors
+
+import (
+	"context"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/cache"
+)
+
+const (
+	cacheL5KeyBillBoard = "lhb"
+)
+
+// BillBoard 龙虎榜
+type BillBoard struct {
+	cache.DataSummary `dataframe:"-"`
+	Date              string  `name:"日期" dataframe:"日期"`                 // 数据日期
+	Code              string  `name:"证券代码" dataframe:"证券代码"`             // 证券代码
+	Times             int     `name:"上榜原因数" dataframe:"times"`           // 当日上榜原因数, 0表示当日未上榜
+	Seats             int     `name:"席位数" dataframe:"seats"`             // 当日上榜的席位数
+	Buy               float64 `name:"席位买入额(元)" dataframe:"buy"`          // 全部席位的买入额
+	Sell              float64 `name:"席位卖出额(元)" dataframe:"sell"`         // 全部席位的卖出额
+	Net               float64 `name:"席位净买额(元)" dataframe:"net"`          // 全部席位的净买额
+	InstitutionSeats  int     `name:"机构席位数" dataframe:"inst_seats"`      // 机构专用席位数
+	InstitutionBuy    float64 `name:"机构买入额(元)" dataframe:"inst_buy"`     // 机构专用席位的买入额
+	InstitutionSell   float64 `name:"机构卖出额(元)" dataframe:"inst_sell"`    // 机构专用席位的卖出额
+	InstitutionNet    float64 `name:"机构净买额(元)" dataframe:"inst_net"`     // 机构专用席位的净买额
+	RetailSeats       int     `name:"营业部席位数" dataframe:"retail_seats"`   // 营业部席位数
+	RetailBuy         float64 `name:"营业部买入额(元)" dataframe:"retail_buy"`  // 营业部席位的买入额
+	RetailSell        float64 `name:"营业部卖出额(元)" dataframe:"retail_sell"` // 营业部席位的卖出额
+	RetailNet         float64 `name:"营业部净买额(元)" dataframe:"retail_net"`  // 营业部席位的净买额
+	Appear3D          int     `name:"3日上榜天数" dataframe:"appear3d"`       // 最近3个交易日上榜的天数
+	Appear5D          int     `name:"5日上榜天数" dataframe:"appear5d"`       // 最近5个交易日上榜的天数
+	Appear10D         int     `name:"10日上榜天数" dataframe:"appear10d"`     // 最近10个交易日上榜的天数
+	UpdateTime        string  `name:"更新时间" dataframe:"update_time"`      // 更新时间
+	State             uint64  `name:"样本状态" dataframe:"样本状态"`             // 样本状态
+}
+
+// NewBillBoard 新建龙虎榜
+func NewBillBoard(date, code string) *BillBoard {
+	summary := __mapFeatures[FeatureBillBoard]
+	v := BillBoard{
+		DataSummary: summary,
+		Date:        date,
+		Code:        code,
+	}
+	return &v
+}
+
+func (this *BillBoard) Factory(date string, code string) Feature {
+	v := NewBillBoard(date, code)
+	return v
+}
+
+func (this *BillBoard) GetDate() string {
+	return this.Date
+}
+
+func (this *BillBoard) GetSecurityCode() string {
+	return this.Code
+}
+
+func (this *BillBoard) Init(ctx context.Context, date string) error {
+	BillBoardTargetInit(date)
+	_ = ctx
+	return nil
+}
+
+// DependOn 依赖的上游数据, 来自龙虎榜数据集
+func (this *BillBoard) DependOn() []cache.Kind {
+	return []cache.Kind{BaseBillBoard}
+}
+
+func (this *BillBoard) Update(code, cacheDate, featureDate string, whole bool) {
+	securityCode := exchange.CorrectSecurityCode(code)
+	this.Date = exchange.FixTradeDate(cacheDate)
+	this.Code = securityCode
+	lhb, appears := getBillBoardTarget(this.GetSecurityCode(), 3, 5, 10)
+	this.Appear3D, this.Appear5D, this.Appear10D = appears[0], appears[1], appears[2]
+	if this.Appear10D == 0 {
+		return
+	}
+	this.Times = lhb.Times
+	this.Seats = lhb.Seats
+	this.Buy = lhb.Buy
+	this.Sell = lhb.Sell
+	this.Net = lhb.Buy - lhb.Sell
+	this.InstitutionSeats = lhb.InstitutionSeats
+	this.InstitutionBuy = lhb.InstitutionBuy
+	this.InstitutionSell = lhb.InstitutionSell
+	this.InstitutionNet = lhb.InstitutionBuy - lhb.InstitutionSell
+	this.RetailSeats = lhb.RetailSeats
+	this.RetailBuy = lhb.RetailBuy
+	this.RetailSell = lhb.RetailSell
+	this.RetailNet = lhb.RetailBuy - lhb.RetailSell
+
+	this.UpdateTime = GetTimestamp()
+	this.State |= this.Kind()
+}
+
+func (this *BillBoard) Repair(securityCode, cacheDate, featureDate string, whole bool) {
+	this.Update(securityCode, cacheDate, featureDate, whole)
+}
+
+func (this *BillBoard) FromHistory(history History) Feature {
+	_ = history
+	return this
+}
+
+func (this *BillBoard) Increase(snapshot QuoteSnapshot) Feature {
+	_ = snapshot
+	return this
+}
+
+func (this *BillBoard) ValidateSample() error {
+	if this.State > 0 {
+		return nil
+	}
+	return ErrInvalidFeatureSample
+}
@@ -0,0 +1,91 @@
+package factors
+
+import (
+	"testing"
+
+	"gitee.com/quant1x/engine/datasource/dfcf"
+)
+
+func Test_summarizeBillBoard(t *testing.T) {
+	oneDay := "日涨幅偏离值达到7%的前5只证券"
+	threeDays := "连续三个交易日内，涨幅偏离值累计达到20%的证券"
+	retail := "某证券营业部"
+	tests := []struct {
+		name string
+		rows []dfcf.BillBoard
+		want billBoardSummary
+	}{
+		{
+			name: "同一席位同时在买入和卖出前5, 完全相同的两行只算一次",
+			rows: []dfcf.BillBoard{
+				{OPERATEDEPT_CODE: "2", OPERATEDEPT_NAME: retail, TRADE_ID: "a", EXPLANATION: oneDay, BUY: 200, SELL: 50},
+				{OPERATEDEPT_CODE: "2", OPERATEDEPT_NAME: retail, TRADE_ID: "a", EXPLANATION: oneDay, BUY: 200, SELL: 50},
+			},
+			want: billBoardSummary{Times: 1, Seats: 1, Buy: 200, Sell: 50, RetailSeats: 1, RetailBuy: 200, RetailSell: 50},
+		},
+		{
+			name: "多个机构席位的营业部代码相同, 每一行都是独立的席位",
+			rows: []dfcf.BillBoard{
+				{OPERATEDEPT_CODE: "9", OPERATEDEPT_NAME: billBoardInstitution, TRADE_ID: "a", EXPLANATION: oneDay, BUY: 300},
+				{OPERATEDEPT_CODE: "9", OPERATEDEPT_NAME: billBoardInstitution, TRADE_ID: "a", EXPLANATION: oneDay, BUY: 150},
+				{OPERATEDEPT_CODE: "9", OPERATEDEPT_NAME: billBoardInstitution, TRADE_ID: "a", EXPLANATION: oneDay, SELL: 80},
+			},
+			want: billBoardSummary{Times: 1, Seats: 3, Buy: 450, Sell: 80, InstitutionSeats: 3, InstitutionBuy: 450, InstitutionSell: 80},
+		},
+		{
+			name: "当日和多日累计两个上榜原因, 金额只取当日",
+			rows: []dfcf.BillBoard{
+				{OPERATEDEPT_CODE: "9", OPERATEDEPT_NAME: billBoardInstitution, TRADE_ID: "b", EXPLANATION: threeDays, BUY: 900},
+				{OPERATEDEPT_CODE: "3", OPERATEDEPT_NAME: "另一证券营业部", TRADE_ID: "b", EXPLANATION: threeDays, SELL: 500},
+				{OPERATEDEPT_CODE: "9", OPERATEDEPT_NAME: billBoardInstitution, TRADE_ID: "a", EXPLANATION: oneDay, BUY: 300},
+				{OPERATEDEPT_CODE: "9", OPERATEDEPT_NAME: billBoardInstitution, TRADE_ID: "a", EXPLANATION: oneDay, BUY: 150},
+				{OPERATEDEPT_CODE: "2", OPERATEDEPT_NAME: retail, TRADE_ID: "a", EXPLANATION: oneDay, BUY: 200, SELL: 50},
+				{OPERATEDEPT_CODE: "2", OPERATEDEPT_NAME: retail, TRADE_ID: "a", EXPLANATION: oneDay, BUY: 200, SELL: 50},
+			},
+			want: billBoardSummary{
+				Times: 2, Seats: 3, Buy: 650, Sell: 50,
+				InstitutionSeats: 2, InstitutionBuy: 450,
+				RetailSeats: 1, RetailBuy: 200, RetailSell: 50,
+			},
+		},
+		{
+			name: "只有多日累计的上榜原因时取累计的金额",
+			rows: []dfcf.BillBoard{
+				{OPERATEDEPT_CODE: "9", OPERATEDEPT_NAME: billBoardInstitution, TRADE_ID: "b", EXPLANATION: threeDays, BUY: 900},
+				{OPERATEDEPT_CODE: "3", OPERATEDEPT_NAME: "另一证券营业部", TRADE_ID: "b", EXPLANATION: threeDays, SELL: 500},
+			},
+			want: billBoardSummary{Times: 1, Seats: 2, Buy: 900, Sell: 500, InstitutionSeats: 1, InstitutionBuy: 900, RetailSeats: 1, RetailSell: 500},
+		},
+		{
+			name: "没有上榜",
+			want: billBoardSummary{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeBillBoard(tt.rows); got != tt.want {
+				t.Errorf("summarizeBillBoard() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_billBoardAppearances(t *testing.T) {
+	window := []string{"2024-07-10", "2024-07-11", "2024-07-12", "2024-07-15", "2024-07-16"}
+	dates := []string{"2024-07-10", "2024-07-12", "2024-07-16"}
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{1, 1},
+		{3, 2},
+		{5, 3},
+		{10, 3},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got := billBoardAppearances(window, dates, tt.n); got != tt.want {
+			t.Errorf("billBoardAppearances(%d) = %d, want %d", tt.n, got, tt.want)
+		}
+	}
+}
@@ -84,6 +84,11 @@ func (this *Box) Init(ctx context.Context, date string) error {
 	return nil
 }
 
+// DependOn 依赖的上游数据, 平台由日K线计算
+func (this *Box) DependOn() []cache.Kind {
+	return []cache.Kind{BaseKLine}
+}
+
 func (this *Box) FromHistory(history History) Feature {
 	_ = history
 	return this
@@ -150,9 +155,34 @@ func (this *Box) Repair(code, cacheDate, featureDate string, complete bool) {
 	this.Update(code, cacheDate, featureDate, complete)
 }
 
+// Increase 用快照增量计算当日盘中的平台状态
+//
+//	盘中假定平台不变, 倍量和半量的周期加1, 重新判断突破(B)、跌破MA3(S)和多空趋势
 func (this *Box) Increase(snapshot QuoteSnapshot) Feature {
-	_ = snapshot
-	return this
+	history, live := increaseHistory(this.Code, snapshot)
+	if history == nil || live == nil || history.CLOSE <= 0 || snapshot.Price <= 0 {
+		return this
+	}
+	v := *this
+	price := snapshot.Price
+	if v.DoubletPeriod >= 0 {
+		v.DoubletPeriod++
+	}
+	if v.HalfPeriod >= 0 {
+		v.HalfPeriod++
+	}
+	// B:CROSS(CLOSE,倍量H)
+	v.Buy = history.CLOSE < this.DoubleHigh && price > this.DoubleHigh
+	// S:CROSS(MA3,CLOSE)
+	v.Sell = history.MA3 < history.CLOSE && live.MA3 > price
+	// D:=MA5>REF(MA5,1) AND CLOSE>=MA5, 多空:IFF(FD>0,FD,-1*FK)
+	if live.MA5 > history.MA5 && price >= live.MA5 {
+		v.TendencyPeriod = max(this.TendencyPeriod, 0) + 1
+	} else {
+		v.TendencyPeriod = min(this.TendencyPeriod, 0) - 1
+	}
+	v.UpdateTime = GetTimestamp()
+	return &v
 }
 
 // ValidateSample 验证样本数据
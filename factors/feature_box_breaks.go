@@ -65,6 +65,11 @@ func NewKLineBox(code, date string) *KLineBox {
 	//	digits = int(securityInfo.DecimalPoint)
 	//}
 	df := pandas.LoadStructs(klines)
+	return computeKLineBox(securityCode, tradeDate, df)
+}
+
+// 以日K线计算有效突破平台
+func computeKLineBox(securityCode, tradeDate string, df pandas.DataFrame) *KLineBox {
 	var (
 		OPEN  = df.ColAsNDArray("open")
 		CLOSE = df.ColAsNDArray("close")
@@ -41,6 +41,8 @@ type F10 struct {
 	ReductionRatio       float64 `name:"当期减持比例" dataframe:"ReductionRatio"`        // 当期减持比例
 	QuarterlyYearQuarter string  `name:"季报期" dataframe:"quarterly_year_quarter"`   // 当前市场处于哪个季报期, 用于比较个股的季报数据是否存在拖延的情况
 	QDate                string  `name:"新报告期" dataframe:"qdate"`                   // 最新报告期
+	QNoticeDate          string  `name:"季报公告日期" dataframe:"qnotice_date"`          // 最新报告期的公告日期
+	HolderNoticeDate     string  `name:"股东公告日期" dataframe:"holder_notice_date"`    // 前十大流通股东的公告日期
 	AnnualReportDate     string  `name:"年报披露日期" dataframe:"annual_report_date"`    // 年报披露日期
 	QuarterlyReportDate  string  `name:"季报披露日期" dataframe:"quarterly_report_date"` // 最新季报披露日期
 	TotalOperateIncome   float64 `name:"营业总收入" dataframe:"TotalOperateIncome"`     // 当期营业总收入
@@ -98,6 +100,11 @@ func (this *F10) Init(ctx context.Context, date string) error {
 	return nil
 }
 
+// DependOn 依赖的上游数据, 季报数据来自季报数据集
+func (this *F10) DependOn() []cache.Kind {
+	return []cache.Kind{BaseQuarterlyReports}
+}
+
 func (this *F10) FromHistory(history History) Feature {
 	_ = history
 	return this
@@ -129,7 +136,7 @@ func (this *F10) Update(code, cacheDate, featureDate string, complete bool) {
 	this.SafetyScore = safetyScore
 
 	// 6. 年报季报披露日期
-	annualReportDate, quarterlyReportDate := dfcf.NoticeDateForReport(securityCode, cacheDate)
+	annualReportDate, quarterlyReportDate := dfcf.NoticeDateForReport(securityCode, featureDate)
 	this.AnnualReportDate = annualReportDate
 	this.QuarterlyReportDate = quarterlyReportDate
 
@@ -165,7 +172,7 @@ func (this *F10) Repair(code, cacheDate, featureDate string, complete bool) {
 	}
 
 	// 6. 年报季报披露日期
-	annualReportDate, quarterlyReportDate := dfcf.NoticeDateForReport(securityCode, cacheDate)
+	annualReportDate, quarterlyReportDate := dfcf.NoticeDateForReport(securityCode, featureDate)
 	this.AnnualReportDate = annualReportDate
 	this.QuarterlyReportDate = quarterlyReportDate
 
@@ -175,9 +182,10 @@ func (this *F10) Repair(code, cacheDate, featureDate string, complete bool) {
 	_ = complete
 }
 
+// Increase 基本面数据盘中不变
 func (this *F10) Increase(snapshot QuoteSnapshot) Feature {
-	//TODO implement me
-	panic("implement me")
+	_ = snapshot
+	return this
 }
 
 func (this *F10) ValidateSample() error {
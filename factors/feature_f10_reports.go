@@ -12,7 +12,9 @@ var (
 	__mapQuarterlyReports = map[string]dfcf.QuarterlyReport{}
 )
 
+// 加载date所在季度的季报缓存, 清除上一次加载的数据, 避免不同日期的季报混用
 func loadQuarterlyReports(date string) {
+	clear(__mapQuarterlyReports)
 	var allReports []dfcf.QuarterlyReport
 	_, qEnd := api.GetQuarterDayByDate(date)
 	filename := cache.ReportsFilename(qEnd)
@@ -35,6 +37,7 @@ func getQuarterlyYearQuarter(date string) string {
 // 季报概要
 type quarterlyReportSummary struct {
 	QDate              string
+	QNoticeDate        string // 季报的公告日期
 	BPS                float64
 	BasicEPS           float64
 	TotalOperateIncome float64
@@ -47,15 +50,17 @@ func (q *quarterlyReportSummary) Assign(v dfcf.QuarterlyReport) {
 	q.TotalOperateIncome = v.TotalOperateIncome
 	q.DeductBasicEPS = v.DeductBasicEPS
 	q.QDate = v.QDATE
+	q.QNoticeDate = dfcf.ReportDisclosureDate(v)
 }
 
+// 获取date当日已经披露的最新季报概要, 未披露的季报不可见
 func getQuarterlyReportSummary(securityCode, date string) quarterlyReportSummary {
 	var summary quarterlyReportSummary
 	if exchange.AssertIndexBySecurityCode(securityCode) {
 		return summary
 	}
 	v, ok := __mapQuarterlyReports[securityCode]
-	if ok {
+	if ok && dfcf.ReportIsPublic(v, date) {
 		summary.Assign(v)
 		return summary
 	}
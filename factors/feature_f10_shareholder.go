@@ -9,13 +9,14 @@ import (
 )
 
 type top10ShareHolder struct {
-	Code           string
-	FreeCapital    float64
-	Top10Capital   float64
-	Top10Change    float64
-	ChangeCapital  float64
-	IncreaseRatio  float64
-	ReductionRatio float64
+	Code             string
+	FreeCapital      float64
+	Top10Capital     float64
+	Top10Change      float64
+	ChangeCapital    float64
+	IncreaseRatio    float64
+	ReductionRatio   float64
+	HolderNoticeDate string // 前十大流通股东的公告日期
 }
 
 func checkoutShareHolder(securityCode, featureDate string) *top10ShareHolder {
@@ -25,23 +26,24 @@ func checkoutShareHolder(securityCode, featureDate string) *top10ShareHolder {
 	})
 	xdxrInfo := checkoutCapital(xdxrs, featureDate)
 	if xdxrInfo != nil && exchange.AssertStockBySecurityCode(securityCode) {
-		list := dfcf.GetCacheShareHolder(securityCode, featureDate)
+		// 只取featureDate当日已经披露的最新一期和它的上一期
+		list, frontList := dfcf.GetCacheShareHolderWithPrevious(securityCode, featureDate)
 		capital := xdxrInfo.HouLiuTong * 10000
 		totalCapital := xdxrInfo.HouZongGuBen * 10000
 		top10Capital, freeCapital, capitalChanged, increaseRatio, reductionRatio := ComputeFreeCapital(list, capital)
 		if freeCapital < 0 {
 			top10Capital, freeCapital, capitalChanged, increaseRatio, reductionRatio = ComputeFreeCapital(list, totalCapital)
 		}
-		frontList := dfcf.GetCacheShareHolder(securityCode, featureDate, 2)
 		frontTop10Capital, _, _, _, _ := ComputeFreeCapital(frontList, totalCapital)
 		shareHolder := top10ShareHolder{
-			Code:           securityCode,
-			FreeCapital:    freeCapital,
-			Top10Capital:   top10Capital,
-			Top10Change:    top10Capital - frontTop10Capital,
-			ChangeCapital:  capitalChanged,
-			IncreaseRatio:  increaseRatio,
-			ReductionRatio: reductionRatio,
+			Code:             securityCode,
+			FreeCapital:      freeCapital,
+			Top10Capital:     top10Capital,
+			Top10Change:      top10Capital - frontTop10Capital,
+			ChangeCapital:    capitalChanged,
+			IncreaseRatio:    increaseRatio,
+			ReductionRatio:   reductionRatio,
+			HolderNoticeDate: dfcf.ShareHolderDisclosureDate(list),
 		}
 		return &shareHolder
 	}
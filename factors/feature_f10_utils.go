@@ -7,6 +7,7 @@ import (
 	"gitee.com/quant1x/data/exchange"
 	"gitee.com/quant1x/data/level1"
 	"gitee.com/quant1x/data/level1/quotes"
+	"gitee.com/quant1x/engine/datasource"
 	"gitee.com/quant1x/engine/datasource/base"
 	"gitee.com/quant1x/gox/logger"
 	"gitee.com/quant1x/num"
@@ -17,11 +18,13 @@ func getFinanceInfo(securityCode, featureDate string) (capital, totalCapital flo
 	basicDate := uint32(num.AnyToInt64(exchange.MARKET_CN_FIRST_DATE))
 	for i := 0; i < quotes.DefaultRetryTimes; i++ {
 		securityCode := exchange.CorrectSecurityCode(securityCode)
-		tdxApi := level1.GetApi()
-		info, err := tdxApi.GetFinanceInfo(securityCode)
+		adapter := datasource.GetAdapter()
+		info, err := adapter.Finance(securityCode)
 		if err != nil {
 			logger.Error(err)
-			level1.ReOpen()
+			if adapter.Name() == datasource.SourceTdx {
+				level1.ReOpen()
+			}
 		}
 		if info != nil {
 			if info.LiuTongGuBen > 0 && info.ZongGuBen > 0 {
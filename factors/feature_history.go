@@ -2,12 +2,14 @@ package factors
 
 import (
 	"context"
+	"sync"
 
 	"gitee.com/quant1x/data/exchange"
 	"gitee.com/quant1x/engine/cache"
 	"gitee.com/quant1x/engine/datasource/base"
 	"gitee.com/quant1x/engine/utils"
 	"gitee.com/quant1x/gox/api"
+	"gitee.com/quant1x/num"
 	"gitee.com/quant1x/pandas"
 	. "gitee.com/quant1x/pandas/formula"
 )
@@ -16,6 +18,13 @@ const (
 	cacheL5KeyHistory = "history"
 )
 
+// MACD的周期参数
+const (
+	macdShort = 12
+	macdLong  = 26
+	macdMid   = 9
+)
+
 // History 历史整合数据
 //
 //	记录重要的截止上一个交易日的数据
@@ -37,6 +46,11 @@ type History struct {
 	MV19              float64 `name:"19日均量" dataframe:"mv19"`          // 19日均量
 	MA20              float64 `name:"20日均价" dataframe:"ma20"`          // 20日均价
 	MV20              float64 `name:"20日均量" dataframe:"mv20"`          // 20日均量
+	EMA12             float64 `name:"12日指数均价" dataframe:"ema12"`       // MACD: 短周期EMA
+	EMA26             float64 `name:"26日指数均价" dataframe:"ema26"`       // MACD: 长周期EMA
+	DIF               float64 `name:"DIF" dataframe:"dif"`             // MACD: DIF
+	DEA               float64 `name:"DEA" dataframe:"dea"`             // MACD: DEA
+	MACD              float64 `name:"MACD" dataframe:"macd"`           // MACD: (DIF-DEA)*2
 	OPEN              float64 `name:"开盘" dataframe:"open"`             // 昨日开盘
 	CLOSE             float64 `name:"收盘" dataframe:"close"`            // 昨日收盘
 	HIGH              float64 `name:"最高" dataframe:"high"`             // 昨日最高
@@ -84,6 +98,11 @@ func (this *History) Init(ctx context.Context, date string) error {
 	return nil
 }
 
+// DependOn 依赖的上游数据, 均线等历史数据由日K线计算
+func (this *History) DependOn() []cache.Kind {
+	return []cache.Kind{BaseKLine}
+}
+
 func (this *History) FromHistory(history History) Feature {
 	_ = api.Copy(this, &history)
 	return this
@@ -149,6 +168,16 @@ func (this *History) Repair(code, cacheDate, featureDate string, complete bool)
 	//	MV20       float64 // 20日均量
 	mv20 := MA(VOL, 20)
 	this.MV20 = utils.Float64IndexOf(mv20, -1)
+	// MACD, 保留EMA以便盘中增量计算
+	ema12 := EMA(CLOSE, macdShort)
+	ema26 := EMA(CLOSE, macdLong)
+	dif := ema12.Sub(ema26)
+	dea := EMA(dif, macdMid)
+	this.EMA12 = utils.Float64IndexOf(ema12, -1)
+	this.EMA26 = utils.Float64IndexOf(ema26, -1)
+	this.DIF = utils.Float64IndexOf(dif, -1)
+	this.DEA = utils.Float64IndexOf(dea, -1)
+	this.MACD = (this.DIF - this.DEA) * 2
 	//OPEN              float64        `name:"开盘" dataframe:"open"`           // 开盘价
 	this.OPEN = utils.Float64IndexOf(OPEN, -1)
 	//CLOSE             float64        `name:"收盘" dataframe:"close"`          // 收盘价
@@ -195,9 +224,107 @@ func (this *History) Repair(code, cacheDate, featureDate string, complete bool)
 	this.State |= this.Kind()
 }
 
+// Increase 用快照增量计算当日盘中的特征
+//
+//	返回新的对象, 不修改缓存中截止上一个交易日的数据, 所以每个快照都从上一个交易日的数据计算.
+//	均线用前N-1日的均价推算, 前8日和前18日均价没有缓存, MA9和MA19保持不变;
+//	均量和成交量相关的周期数盘中没有可比性, 也保持不变
 func (this *History) Increase(snapshot QuoteSnapshot) Feature {
-	//TODO implement me
-	panic("implement me")
+	if this.CLOSE <= 0 || snapshot.Price <= 0 {
+		return this
+	}
+	v := *this
+	price := snapshot.Price
+	v.MA2 = incrementalMA(this.CLOSE, 2, price)
+	v.MA3 = incrementalMA(this.MA2, 3, price)
+	v.MA4 = incrementalMA(this.MA3, 4, price)
+	v.MA5 = incrementalMA(this.MA4, 5, price)
+	v.MA10 = incrementalMA(this.MA9, 10, price)
+	v.MA20 = incrementalMA(this.MA19, 20, price)
+	// 旧的缓存文件没有EMA, 不计算MACD
+	if this.EMA12 > 0 && this.EMA26 > 0 {
+		v.EMA12 = EmaIncr(price, this.EMA12, AlphaOfEMA(macdShort))
+		v.EMA26 = EmaIncr(price, this.EMA26, AlphaOfEMA(macdLong))
+		v.DIF = v.EMA12 - v.EMA26
+		v.DEA = EmaIncr(v.DIF, this.DEA, AlphaOfEMA(macdMid))
+		v.MACD = (v.DIF - v.DEA) * 2
+	}
+	v.LastClose = this.CLOSE
+	v.OPEN = snapshot.Open
+	v.CLOSE = price
+	v.HIGH = snapshot.High
+	v.LOW = snapshot.Low
+	v.VOL = float64(snapshot.Vol)
+	v.AMOUNT = snapshot.Amount
+	if snapshot.Vol > 0 {
+		v.AveragePrice = snapshot.Amount / float64(snapshot.Vol)
+	}
+	// 多头排列
+	if v.MA5 > v.MA10 && v.MA10 > v.MA20 {
+		v.BullN = this.BullN + 1
+	} else {
+		v.BullN = 0
+	}
+	// 向上跳空缺口
+	if snapshot.Low > this.HIGH {
+		v.UpwardN = 0
+	} else if this.UpwardN >= 0 {
+		v.UpwardN = this.UpwardN + 1
+	}
+	// 最低价连续走低
+	if snapshot.Low < this.LOW {
+		v.NewLowN = this.NewLowN + 1
+	} else {
+		v.NewLowN = 0
+	}
+	v.OpenVolume = snapshot.OpenVolume
+	v.UpdateTime = GetTimestamp()
+	return &v
+}
+
+// 一个证券最近一个快照的历史特征增量结果
+type historyIncreaseResult struct {
+	date       string
+	serverTime string
+	history    *History
+	live       *History
+}
+
+var (
+	__mutexHistoryIncrease sync.Mutex
+	__mapHistoryIncrease   = map[string]historyIncreaseResult{}
+)
+
+// 用快照增量计算历史特征, history是上一个交易日的历史特征, live是包含现价的增量结果
+//
+//	平台、扩展交易等特征都依赖同一个快照的均线, 每个证券只保留最近一个快照的结果, 同一个快照只计算一次
+func increaseHistory(securityCode string, snapshot QuoteSnapshot) (history, live *History) {
+	history = GetL5History(securityCode)
+	if history == nil {
+		return nil, nil
+	}
+	__mutexHistoryIncrease.Lock()
+	defer __mutexHistoryIncrease.Unlock()
+	v, ok := __mapHistoryIncrease[securityCode]
+	if ok && v.history == history && v.date == snapshot.Date && v.serverTime == snapshot.ServerTime {
+		return history, v.live
+	}
+	live, _ = history.Increase(snapshot).(*History)
+	__mapHistoryIncrease[securityCode] = historyIncreaseResult{
+		date:       snapshot.Date,
+		serverTime: snapshot.ServerTime,
+		history:    history,
+		live:       live,
+	}
+	return history, live
+}
+
+// 增量计算移动平均线, previousHalfValue是前period-1日的均价
+//
+//	和realtime.IncrementalMovingAverage的算法一致, realtime依赖factors, 这里不能直接引用
+func incrementalMA(previousHalfValue float64, period int, price float64) float64 {
+	n := float64(period)
+	return num.Decimal((previousHalfValue*(n-1) + price) / n)
 }
 
 func (this *History) ValidateSample() error {
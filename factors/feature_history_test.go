@@ -3,6 +3,7 @@ package factors
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"testing"
 
 	"gitee.com/quant1x/data/exchange"
@@ -27,3 +28,31 @@ func TestHistory(t *testing.T) {
 	text := api.Bytes2String(data)
 	fmt.Println(text)
 }
+
+func TestHistoryIncrease(t *testing.T) {
+	history := NewHistory("2024-03-01", "sh600600")
+	history.CLOSE, history.HIGH, history.LOW = 10.00, 10.20, 9.80
+	history.MA2, history.MA3, history.MA4, history.MA5 = 9.90, 9.80, 9.70, 9.60
+	history.MA9, history.MA10, history.MA19, history.MA20 = 9.50, 9.45, 9.50, 8.95
+	history.EMA12, history.EMA26, history.DEA = 9.70, 9.40, 0.25
+	history.BullN, history.NewLowN, history.UpwardN = 3, 2, 5
+	snapshot := QuoteSnapshot{Date: "2024-03-01", Price: 10.50, Open: 10.25, High: 10.60, Low: 10.22, Vol: 1000, Amount: 1040000}
+	v, ok := history.Increase(snapshot).(*History)
+	if !ok {
+		t.Fatalf("Increase should return *History")
+	}
+	if v == history || history.MA5 != 9.60 || history.CLOSE != 10.00 {
+		t.Fatalf("Increase should not modify the cached history")
+	}
+	if v.MA5 != 9.86 || v.MA10 != 9.6 || v.MA20 != 9.55 || v.MA2 != 10.25 {
+		t.Errorf("ma2=%f, ma5=%f, ma10=%f, ma20=%f", v.MA2, v.MA5, v.MA10, v.MA20)
+	}
+	dif := 9.70 + (10.50-9.70)*2/13 - (9.40 + (10.50-9.40)*2/27)
+	dea := 0.25 + (dif-0.25)*2/10
+	if math.Abs(v.DIF-dif) > 1e-9 || math.Abs(v.DEA-dea) > 1e-9 || math.Abs(v.MACD-(dif-dea)*2) > 1e-9 {
+		t.Errorf("dif=%f, dea=%f, macd=%f, want dif=%f, dea=%f", v.DIF, v.DEA, v.MACD, dif, dea)
+	}
+	if v.CLOSE != 10.50 || v.LastClose != 10.00 || v.BullN != 4 || v.NewLowN != 0 || v.UpwardN != 0 {
+		t.Errorf("close=%f, last_close=%f, bull_n=%d, new_low_n=%d, upward_n=%d", v.CLOSE, v.LastClose, v.BullN, v.NewLowN, v.UpwardN)
+	}
+}
@@ -78,6 +78,11 @@ func (this *InvestmentSentimentMaster) Init(ctx context.Context, date string) er
 	return nil
 }
 
+// DependOn 依赖的上游数据, 情绪周期由日K线计算
+func (this *InvestmentSentimentMaster) DependOn() []cache.Kind {
+	return []cache.Kind{BaseKLine}
+}
+
 func (this *InvestmentSentimentMaster) Update(code, cacheDate, featureDate string, whole bool) {
 	securityCode := exchange.CorrectSecurityCode(code)
 	this.Date = exchange.FixTradeDate(cacheDate)
@@ -88,6 +93,13 @@ func (this *InvestmentSentimentMaster) Update(code, cacheDate, featureDate strin
 		return
 	}
 	df := pandas.LoadStructs(klines)
+	this.compute(securityCode, df)
+	this.UpdateTime = GetTimestamp()
+	this.State |= this.Kind()
+}
+
+// 以日K线计算情绪周期
+func (this *InvestmentSentimentMaster) compute(securityCode string, df pandas.DataFrame) {
 	var (
 		DATE  = df.Col("date")
 		OPEN  = df.ColAsNDArray("open")
@@ -190,8 +202,6 @@ func (this *InvestmentSentimentMaster) Update(code, cacheDate, featureDate strin
 	//	df = pandas.NewDataFrame(DATE, CLOSE, ZTJ, CZT, BN, FTZ, TN)
 	//	fmt.Println(df)
 	//}
-	this.UpdateTime = GetTimestamp()
-	this.State |= this.Kind()
 	_ = DATE
 	_ = OPEN
 }
@@ -105,10 +105,14 @@ func (this *Misc) Factory(date string, code string) Feature {
 }
 
 func (this *Misc) Init(ctx context.Context, date string) error {
-	MarginTradingTargetInit(this.GetDate())
 	_ = ctx
 	_ = date
-	return nil
+	return MarginTradingTargetInit(this.GetDate())
+}
+
+// DependOn 依赖的上游数据, K线扩展、历史成交的统计和F10的流通股本
+func (this *Misc) DependOn() []cache.Kind {
+	return []cache.Kind{BaseKLine, BaseTransaction, FeatureF10}
 }
 
 func (this *Misc) FromHistory(history History) Feature {
@@ -158,9 +162,33 @@ func (this *Misc) Repair(code, cacheDate, featureDate string, complete bool) {
 	_ = complete
 }
 
+// Increase 用快照增量计算当日盘中的K线相关数据
+//
+//	均线取History的增量结果, 其它和上一个交易日比较的字段保持不变
 func (this *Misc) Increase(snapshot QuoteSnapshot) Feature {
-	_ = snapshot
-	return this
+	if snapshot.Price <= 0 {
+		return this
+	}
+	v := *this
+	// 没有历史特征时均线保持不变
+	if _, live := increaseHistory(this.Code, snapshot); live != nil {
+		v.MA3 = live.MA3
+		v.MA5 = live.MA5
+		v.MA10 = live.MA10
+		v.MA20 = live.MA20
+	}
+	if snapshot.Low > 0 {
+		// 振幅, 和miscKLineExtend一致, 只比对最高价和最低价
+		v.AmplitudeRatio = (snapshot.High/snapshot.Low - 1.00) * 100.00
+	}
+	if snapshot.Vol > 0 {
+		v.AveragePrice = num.Decimal(snapshot.Amount / float64(snapshot.Vol))
+	}
+	v.Volume = int64(snapshot.Vol)
+	v.InnerVolume = int64(snapshot.SVol)
+	v.OuterVolume = int64(snapshot.BVol)
+	v.UpdateTime = GetTimestamp()
+	return &v
 }
 
 // ValidateSample 验证样本数据
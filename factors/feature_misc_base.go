@@ -118,17 +118,7 @@ func NewMiscKLine(code, date string) *MiscKLine {
 	//ma10 := MA(CLOSE, 10)
 	//QDMA20:=MA(CLOSE,20);
 	//ma20 := MA(CLOSE, 20)
-	//QDV1:=QDMA5*100/QDMA20-100;
-	//qdv1 := ma5.Div(ma20).Sub(1.00).Mul(100)
-	qdv1 := utils.SeriesChangeRate(ma20, ma5)
-	//QDV2:=QDMA10*100/QDMA20-100;
-	qdv2 := utils.SeriesChangeRate(ma20, ma10)
-	//QD1:=ABS(QDV1-REF(QDV1,1));
-	//qd1 := ABS(qdv1.Sub(REF(qdv1, 1)))
-	qd1 := qdv1.Sub(REF(qdv1, 1))
-	//QD2:=ABS(QDV2-REF(QDV2,1));
-	//qd2 := ABS(qdv2.Sub(REF(qdv2, 1)))
-	qd2 := qdv2.Sub(REF(qdv2, 1))
+	qd1, qd2 := computeIntensity(ma5, ma10, ma20)
 	//QDCD:=CLOSE*100/REF(CLOSE,1)-100;
 	//超短线:QDCD-REF(QDCD,1),NODRAW;
 	//短线强度:QD1-REF(QD1,1),NODRAW;
@@ -143,8 +133,41 @@ func NewMiscKLine(code, date string) *MiscKLine {
 	ek.MediumIntensityDiff = utils.Float64IndexOf(mediumIntensityDiff, -1)
 
 	// 7. 波动率
-	N := 3
-	M := 21
+	vix := computeVolatility(OPEN, CLOSE, HIGH, LOW, 3, 21)
+	ek.Vix = utils.Float64IndexOf(vix, -1)
+
+	// 8. 短线底部(Short-Term Bottom),股价最近一次上穿5日均线
+	closeCrossMa5 := CROSS(CLOSE, ma5)
+	crossPeriod := BARSLAST(closeCrossMa5)
+	crossPrice := REF(OPEN, crossPeriod)
+	ek.InitialPrice = num.Decimal(utils.Float64IndexOf(crossPrice, -1))
+
+	// 指数类情绪值 情绪一致
+	up := utils.Float64IndexOf(UP, -1)
+	down := utils.Float64IndexOf(DOWN, -1)
+	ek.Sentiment, ek.Consistent = market.SecuritySentiment(up, down)
+
+	return ek
+}
+
+// 多空强度, 保留了强度的方向, 没有取公式中的绝对值
+func computeIntensity(ma5, ma10, ma20 pandas.Series) (qd1, qd2 pandas.Series) {
+	//QDV1:=QDMA5*100/QDMA20-100;
+	//qdv1 := ma5.Div(ma20).Sub(1.00).Mul(100)
+	qdv1 := utils.SeriesChangeRate(ma20, ma5)
+	//QDV2:=QDMA10*100/QDMA20-100;
+	qdv2 := utils.SeriesChangeRate(ma20, ma10)
+	//QD1:=ABS(QDV1-REF(QDV1,1));
+	//qd1 := ABS(qdv1.Sub(REF(qdv1, 1)))
+	qd1 = qdv1.Sub(REF(qdv1, 1))
+	//QD2:=ABS(QDV2-REF(QDV2,1));
+	//qd2 := ABS(qdv2.Sub(REF(qdv2, 1)))
+	qd2 = qdv2.Sub(REF(qdv2, 1))
+	return qd1, qd2
+}
+
+// 波动率
+func computeVolatility(OPEN, CLOSE, HIGH, LOW pandas.Series, N, M int) pandas.Series {
 	//TYPICAL_PRICE:=(OPEN+CLOSE+HIGH+LOW)/4;
 	TYPICAL_PRICE := OPEN.Add(CLOSE).Add(HIGH).Add(LOW).Div(4.00)
 	//METHOD:=EMA(TYPICAL_PRICE,N);
@@ -164,21 +187,7 @@ func NewMiscKLine(code, date string) *MiscKLine {
 	//VIX:100*(MYSTDDEV-MINB)/(MAXB-MINB);
 	//10,DOTLINE,COLORGREEN;
 	//50,DOTLINE,COLORYELLOW;
-	vix := MYSTDDEV.Sub(MINB).Div(MAXB.Sub(MINB)).Mul(100)
-	ek.Vix = utils.Float64IndexOf(vix, -1)
-
-	// 8. 短线底部(Short-Term Bottom),股价最近一次上穿5日均线
-	closeCrossMa5 := CROSS(CLOSE, ma5)
-	crossPeriod := BARSLAST(closeCrossMa5)
-	crossPrice := REF(OPEN, crossPeriod)
-	ek.InitialPrice = num.Decimal(utils.Float64IndexOf(crossPrice, -1))
-
-	// 指数类情绪值 情绪一致
-	up := utils.Float64IndexOf(UP, -1)
-	down := utils.Float64IndexOf(DOWN, -1)
-	ek.Sentiment, ek.Consistent = market.SecuritySentiment(up, down)
-
-	return ek
+	return MYSTDDEV.Sub(MINB).Div(MAXB.Sub(MINB)).Mul(100)
 }
 
 func (k *MiscKLine) Kind() cache.Kind {
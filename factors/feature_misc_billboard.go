@@ -0,0 +1,193 @@
+package factors
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/datasource/dfcf"
+	"gitee.com/quant1x/gox/api"
+	"gitee.com/quant1x/gox/logger"
+)
+
+const (
+	billBoardInstitution = "机构专用" // 机构席位的营业部名称
+	billBoardMaxDays     = 10     // 统计上榜次数的最大交易日数
+)
+
+// 一个证券一天的龙虎榜汇总
+type billBoardSummary struct {
+	Times            int     // 上榜原因数
+	Seats            int     // 席位数
+	Buy              float64 // 席位买入额
+	Sell             float64 // 席位卖出额
+	InstitutionSeats int     // 机构席位数
+	InstitutionBuy   float64 // 机构买入额
+	InstitutionSell  float64 // 机构卖出额
+	RetailSeats      int     // 营业部席位数
+	RetailBuy        float64 // 营业部买入额
+	RetailSell       float64 // 营业部卖出额
+}
+
+var (
+	__mapBillBoardTargets   = map[string]billBoardSummary{}
+	__mapBillBoardDates     = map[string][]string{} // 证券代码在统计窗口内上榜的日期, 升序
+	__billBoardWindow       []string                // 统计窗口的交易日, 升序
+	__mutexBillBoardTargets sync.RWMutex
+)
+
+// 拉取并缓存指定日期的龙虎榜, 拉取失败或者没有数据时不覆盖已有的缓存
+func syncBillBoardList(date string) ([]dfcf.BillBoard, error) {
+	list, err := dfcf.GetBillBoardList(date)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		logger.Errorf("date = %s, 没有龙虎榜数据", date)
+		return nil, nil
+	}
+	filename := cache.BillBoardFilename(date)
+	if err := api.SlicesToCsv(filename, list); err != nil {
+		return list, fmt.Errorf("cache %s failed: %w", filename, err)
+	}
+	return list, nil
+}
+
+// 加载指定日期的龙虎榜, 缓存文件不存在或者为空时拉取
+func loadBillBoardList(date string) []dfcf.BillBoard {
+	filename := cache.BillBoardFilename(date)
+	var list []dfcf.BillBoard
+	if api.FileExist(filename) {
+		err := api.CsvToSlices(filename, &list)
+		if err != nil {
+			logger.Errorf("cache %s failed, error: %+v", filename, err)
+		}
+	}
+	if len(list) > 0 {
+		return list
+	}
+	list, err := syncBillBoardList(date)
+	if err != nil {
+		logger.Errorf("date = %s, 拉取龙虎榜失败: %+v", date, err)
+	}
+	return list
+}
+
+// 多日累计的上榜原因, 比如连续三个交易日内涨幅偏离值累计达到20%
+func billBoardMultiDay(explanation string) bool {
+	return strings.Contains(explanation, "连续")
+}
+
+// 汇总一个证券一天的龙虎榜
+//
+//	多个上榜原因公布的是同一批席位, 金额只取一个原因: 优先当日的上榜原因, 多日累计的金额包含了前几天的成交.
+//	同一个席位同时出现在买入和卖出前5时会有完全相同的两行, 只去掉这种重复行;
+//	机构专用的营业部代码相同, 每一行都是一个独立的机构席位, 不能按营业部合并
+func summarizeBillBoard(rows []dfcf.BillBoard) billBoardSummary {
+	type row struct {
+		trade string
+		seat  string
+		buy   float64
+		sell  float64
+	}
+	var summary billBoardSummary
+	var trades []string
+	primary := ""
+	for _, v := range rows {
+		if slices.Contains(trades, v.TRADE_ID) {
+			continue
+		}
+		trades = append(trades, v.TRADE_ID)
+		if len(primary) == 0 && !billBoardMultiDay(v.EXPLANATION) {
+			primary = v.TRADE_ID
+		}
+	}
+	summary.Times = len(trades)
+	if len(trades) == 0 {
+		return summary
+	}
+	if len(primary) == 0 {
+		primary = trades[0]
+	}
+	seen := map[row]bool{}
+	for _, v := range rows {
+		if v.TRADE_ID != primary {
+			continue
+		}
+		key := row{trade: v.TRADE_ID, seat: v.OPERATEDEPT_CODE + "|" + v.OPERATEDEPT_NAME, buy: v.BUY, sell: v.SELL}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		summary.Seats++
+		summary.Buy += v.BUY
+		summary.Sell += v.SELL
+		if v.OPERATEDEPT_NAME == billBoardInstitution {
+			summary.InstitutionSeats++
+			summary.InstitutionBuy += v.BUY
+			summary.InstitutionSell += v.SELL
+		} else {
+			summary.RetailSeats++
+			summary.RetailBuy += v.BUY
+			summary.RetailSell += v.SELL
+		}
+	}
+	return summary
+}
+
+// 最近n个交易日的上榜天数, window是升序的统计窗口, dates是升序的上榜日期
+func billBoardAppearances(window, dates []string, n int) int {
+	if n <= 0 || len(window) == 0 {
+		return 0
+	}
+	begin := window[max(len(window)-n, 0)]
+	count := 0
+	for _, date := range dates {
+		if date >= begin && date <= window[len(window)-1] {
+			count++
+		}
+	}
+	return count
+}
+
+// BillBoardTargetInit 缓存最近billBoardMaxDays个交易日的龙虎榜, date是最后一个交易日
+func BillBoardTargetInit(date string) {
+	__mutexBillBoardTargets.Lock()
+	defer __mutexBillBoardTargets.Unlock()
+	clear(__mapBillBoardTargets)
+	clear(__mapBillBoardDates)
+	date = exchange.FixTradeDate(date)
+	window := append(exchange.LastNDate(date, billBoardMaxDays-1), date)
+	__billBoardWindow = window
+	for _, tradeDate := range window {
+		list := loadBillBoardList(tradeDate)
+		mapRows := map[string][]dfcf.BillBoard{}
+		for _, v := range list {
+			securityCode := exchange.CorrectSecurityCode(v.SECUCODE)
+			mapRows[securityCode] = append(mapRows[securityCode], v)
+		}
+		for securityCode, rows := range mapRows {
+			__mapBillBoardDates[securityCode] = append(__mapBillBoardDates[securityCode], tradeDate)
+			if tradeDate == date {
+				__mapBillBoardTargets[securityCode] = summarizeBillBoard(rows)
+			}
+		}
+	}
+}
+
+// 获取龙虎榜数据, 返回当日的汇总和最近n个交易日的上榜天数
+func getBillBoardTarget(code string, days ...int) (billBoardSummary, []int) {
+	__mutexBillBoardTargets.RLock()
+	defer __mutexBillBoardTargets.RUnlock()
+	securityCode := exchange.CorrectSecurityCode(code)
+	summary := __mapBillBoardTargets[securityCode]
+	dates := __mapBillBoardDates[securityCode]
+	counts := make([]int, len(days))
+	for i, n := range days {
+		counts[i] = billBoardAppearances(__billBoardWindow, dates, n)
+	}
+	return summary, counts
+}
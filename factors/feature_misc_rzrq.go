@@ -1,12 +1,18 @@
 package factors
 
 import (
+	"errors"
+	"fmt"
 	"sync"
 
 	"gitee.com/quant1x/data/exchange"
 	"gitee.com/quant1x/engine/cache"
 	"gitee.com/quant1x/engine/datasource/dfcf"
-	"gitee.com/quant1x/gox/logger"
+)
+
+var (
+	// ErrMarginTradingNotFound 没有融资融券数据
+	ErrMarginTradingNotFound = errors.New("没有融资融券数据")
 )
 
 var (
@@ -15,20 +21,23 @@ var (
 )
 
 // MarginTradingTargetInit 一次性缓存两融数据, 交易日9点后更新上一个交易的两融数据
-func MarginTradingTargetInit(date string) {
+func MarginTradingTargetInit(date string) error {
 	__mutexMarginTradingTargets.Lock()
 	defer __mutexMarginTradingTargets.Unlock()
 	clear(__mapMarginTradingTargets)
 	_, featureDate := cache.CorrectDate(date)
-	list := dfcf.GetMarginTradingList(featureDate)
+	list, err := dfcf.GetMarginTradingList(featureDate)
+	if err != nil {
+		return err
+	}
 	if len(list) == 0 {
-		logger.Errorf("date = %s, 没有融资融券数据", date)
-		return
+		return fmt.Errorf("date = %s, %w", date, ErrMarginTradingNotFound)
 	}
 	for _, v := range list {
 		securityCode := exchange.CorrectSecurityCode(v.SecuCode)
 		__mapMarginTradingTargets[securityCode] = v
 	}
+	return nil
 }
 
 // GetMarginTradingTarget 获取两融数据
@@ -86,10 +86,9 @@ func (this *SecuritiesMarginTrading) GetSecurityCode() string {
 }
 
 func (this *SecuritiesMarginTrading) Init(ctx context.Context, date string) error {
-	MarginTradingTargetInit(this.GetDate())
 	_ = ctx
 	_ = date
-	return nil
+	return MarginTradingTargetInit(this.GetDate())
 }
 
 func (this *SecuritiesMarginTrading) Update(code, cacheDate, featureDate string, whole bool) {
@@ -1,17 +1,16 @@
 package factors
 
 import (
-	"time"
-
 	"gitee.com/quant1x/data/level1/securities"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
 )
 
 // GetTimestamp 时间戳
 //
-//	格式: YYYY-MM-DD hh:mm:ss.SSS
+//	格式: YYYY-MM-DD hh:mm:ss.SSS, 取引擎的时钟, 回放时是虚拟时间
 func GetTimestamp() string {
-	now := time.Now()
+	now := clock.Now()
 	return now.Format(cache.TimeStampMilli)
 }
 
@@ -0,0 +1,361 @@
+package factors
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"gitee.com/quant1x/engine/market"
+	"gitee.com/quant1x/gox/api"
+)
+
+const (
+	// 面板默认缓存的天数
+	defaultPanelCapacity = 20
+)
+
+var (
+	ErrPanelDate    = errors.New("日期不在面板范围内")
+	ErrPanelField   = errors.New("特征没有这个数值字段")
+	ErrPanelFeature = errors.New("不支持的特征")
+)
+
+// FeaturePanel 特征面板, 和特征的类型无关, 只访问数值字段
+type FeaturePanel interface {
+	// Key 特征的缓存关键字
+	Key() string
+	// Dates 面板的日期范围
+	Dates() []string
+	// Codes 面板的证券代码
+	Codes() []string
+	// Fields 数值字段列表
+	Fields() []string
+	// Field 截面数据, 证券代码 -> 字段值
+	Field(date, field string) (map[string]float64, error)
+	// Matrix 日期 × 证券代码的字段值, 缺失的数据为NaN
+	Matrix(field string) ([][]float64, error)
+}
+
+// panelDay 面板中的一天
+type panelDay[T Feature] struct {
+	date string
+	once sync.Once
+	data map[string]T
+	err  error
+}
+
+// Panel 多日特征面板, 日期 × 证券代码 × 字段
+//
+//	只读, 直接读取缓存文件, 不影响Cache1D当前的日期
+//	按需加载, 最多缓存capacity天的数据, 超出时淘汰最久未使用的日期
+//	可以并发使用
+type Panel[T Feature] struct {
+	key      string
+	dates    []string
+	codes    []string
+	codeSet  map[string]struct{}
+	capacity int
+	fields   []columnField
+	columns  []string                 // 加载的列, 为空时加载全部列
+	filename func(date string) string // 缓存文件名, 测试时可以替换
+
+	m    sync.Mutex
+	days map[string]*list.Element
+	lru  *list.List // 最近使用的在前
+}
+
+// NewPanel 创建特征面板
+//
+//	key是Cache1D的缓存关键字, codes为空时取全部证券代码, capacity小于等于0时取默认值
+//	columns为空时加载全部字段, 否则列式缓存只读取指定的数值字段
+func NewPanel[T Feature](key string, dates, codes []string, capacity int, columns ...string) *Panel[T] {
+	if len(codes) == 0 {
+		codes = market.GetCodeList()
+	}
+	if capacity <= 0 {
+		capacity = defaultPanelCapacity
+	}
+	p := &Panel[T]{
+		key:      key,
+		dates:    slices.Clone(dates),
+		codes:    slices.Clone(codes),
+		codeSet:  make(map[string]struct{}, len(codes)),
+		capacity: capacity,
+		days:     map[string]*list.Element{},
+		lru:      list.New(),
+	}
+	for _, v := range p.codes {
+		p.codeSet[v] = struct{}{}
+	}
+	p.filename = func(date string) string {
+		return getCache1DFilepath(p.key, date)
+	}
+	var t T
+	p.fields = columnarFields(structType(reflect.TypeOf(t)))
+	if len(columns) > 0 {
+		// 证券代码用于过滤, 总是加载, 各特征的列名不同, 按字段名查找
+		p.columns = []string{"code"}
+		if sf, ok := structType(reflect.TypeOf(t)).FieldByName("Code"); ok {
+			for _, v := range p.fields {
+				if slices.Equal(v.index, sf.Index) {
+					p.columns[0] = v.name
+				}
+			}
+		}
+		for _, name := range columns {
+			if field, err := p.field(name); err == nil {
+				p.columns = append(p.columns, field.name)
+			}
+		}
+		// 字段都不存在时仍然加载全部列, 由Field等方法返回错误
+		if len(p.columns) == 1 {
+			p.columns = nil
+		}
+	}
+	return p
+}
+
+// NewFeaturePanel 按缓存关键字创建特征面板, 支持history/misc/f10/box/ism/rzrq/lhb
+func NewFeaturePanel(key string, dates, codes []string, capacity int, columns ...string) (FeaturePanel, error) {
+	switch key {
+	case cacheL5KeyHistory:
+		return NewPanel[*History](key, dates, codes, capacity, columns...), nil
+	case cacheL5KeyMisc:
+		return NewPanel[*Misc](key, dates, codes, capacity, columns...), nil
+	case cacheL5KeyF10:
+		return NewPanel[*F10](key, dates, codes, capacity, columns...), nil
+	case cacheL5KeyBox:
+		return NewPanel[*Box](key, dates, codes, capacity, columns...), nil
+	case cacheL5KeyInvestmentSentimentMaster:
+		return NewPanel[*InvestmentSentimentMaster](key, dates, codes, capacity, columns...), nil
+	case cacheL5KeySecuritiesMarginTrading:
+		return NewPanel[*SecuritiesMarginTrading](key, dates, codes, capacity, columns...), nil
+	case cacheL5KeyBillBoard:
+		return NewPanel[*BillBoard](key, dates, codes, capacity, columns...), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPanelFeature, key)
+}
+
+func (p *Panel[T]) Key() string {
+	return p.key
+}
+
+func (p *Panel[T]) Dates() []string {
+	return slices.Clone(p.dates)
+}
+
+func (p *Panel[T]) Codes() []string {
+	return slices.Clone(p.codes)
+}
+
+// Fields 数值字段列表, 字段名取dataframe标签
+func (p *Panel[T]) Fields() []string {
+	var names []string
+	for _, v := range p.fields {
+		if v.typ != columnString && p.loaded(v) {
+			names = append(names, v.name)
+		}
+	}
+	return names
+}
+
+// 字段是否加载
+func (p *Panel[T]) loaded(field columnField) bool {
+	return len(p.columns) == 0 || slices.Contains(p.columns, field.name)
+}
+
+// 取得一天的数据, 没有加载时同步加载, 同一天只加载一次
+func (p *Panel[T]) day(date string) (map[string]T, error) {
+	if !slices.Contains(p.dates, date) {
+		return nil, fmt.Errorf("%w: %s", ErrPanelDate, date)
+	}
+	p.m.Lock()
+	var d *panelDay[T]
+	if e, ok := p.days[date]; ok {
+		p.lru.MoveToFront(e)
+		d = e.Value.(*panelDay[T])
+	} else {
+		d = &panelDay[T]{date: date}
+		p.days[date] = p.lru.PushFront(d)
+		for p.lru.Len() > p.capacity {
+			e := p.lru.Back()
+			p.lru.Remove(e)
+			delete(p.days, e.Value.(*panelDay[T]).date)
+		}
+	}
+	p.m.Unlock()
+	d.once.Do(func() {
+		d.data, d.err = p.load(date)
+	})
+	return d.data, d.err
+}
+
+// 加载缓存文件, 文件不存在视为当天没有数据
+func (p *Panel[T]) load(date string) (map[string]T, error) {
+	filename := p.filename(date)
+	data := map[string]T{}
+	if !api.FileExist(filename) && !api.FileExist(columnarFilename(filename)) {
+		return data, nil
+	}
+	var rows []T
+	if err := loadCache1DFile(filename, &rows, p.columns...); err != nil {
+		return nil, err
+	}
+	for _, v := range rows {
+		code := v.GetSecurityCode()
+		if _, ok := p.codeSet[code]; ok {
+			data[code] = v
+		}
+	}
+	return data, nil
+}
+
+// Get 获取指定日期和证券代码的特征, 返回的数据不能修改
+func (p *Panel[T]) Get(date, securityCode string) (T, bool) {
+	var t T
+	data, err := p.day(date)
+	if err != nil {
+		return t, false
+	}
+	t, ok := data[securityCode]
+	return t, ok
+}
+
+// CrossSection 指定日期的截面数据, 返回的数据不能修改
+func (p *Panel[T]) CrossSection(date string) (map[string]T, error) {
+	data, err := p.day(date)
+	if err != nil {
+		return nil, err
+	}
+	return maps.Clone(data), nil
+}
+
+// 按名称查找加载的数值字段
+func (p *Panel[T]) loadedField(name string) (columnField, error) {
+	field, err := p.field(name)
+	if err != nil {
+		return field, err
+	}
+	if !p.loaded(field) {
+		return columnField{}, fmt.Errorf("%w: %s.%s没有加载", ErrPanelField, p.key, name)
+	}
+	return field, nil
+}
+
+// 按名称查找数值字段, 支持dataframe标签和字段名, 不区分大小写
+func (p *Panel[T]) field(name string) (columnField, error) {
+	for _, v := range p.fields {
+		if v.typ == columnString {
+			continue
+		}
+		if strings.EqualFold(v.name, name) {
+			return v, nil
+		}
+	}
+	var t T
+	if sf, ok := structType(reflect.TypeOf(t)).FieldByName(name); ok {
+		for _, v := range p.fields {
+			if v.typ != columnString && slices.Equal(v.index, sf.Index) {
+				return v, nil
+			}
+		}
+	}
+	return columnField{}, fmt.Errorf("%w: %s.%s", ErrPanelField, p.key, name)
+}
+
+// 读取数值字段
+func fieldValue(v any, field columnField) float64 {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return math.NaN()
+		}
+		rv = rv.Elem()
+	}
+	fv := rv.FieldByIndex(field.index)
+	switch field.typ {
+	case columnBool:
+		if fv.Bool() {
+			return 1
+		}
+		return 0
+	case columnInt64:
+		return float64(fv.Int())
+	case columnUint64:
+		return float64(fv.Uint())
+	case columnFloat64:
+		return fv.Float()
+	}
+	return math.NaN()
+}
+
+// Field 截面数据, 证券代码 -> 字段值
+func (p *Panel[T]) Field(date, name string) (map[string]float64, error) {
+	field, err := p.loadedField(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := p.day(date)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]float64, len(data))
+	for code, v := range data {
+		values[code] = fieldValue(v, field)
+	}
+	return values, nil
+}
+
+// TimeSeries 单个证券代码的字段时间序列, 和Dates对齐, 缺失的数据为NaN
+func (p *Panel[T]) TimeSeries(securityCode, name string) ([]float64, error) {
+	field, err := p.loadedField(name)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, len(p.dates))
+	for i, date := range p.dates {
+		data, err := p.day(date)
+		if err != nil {
+			return nil, err
+		}
+		v, ok := data[securityCode]
+		if !ok {
+			values[i] = math.NaN()
+			continue
+		}
+		values[i] = fieldValue(v, field)
+	}
+	return values, nil
+}
+
+// Matrix 日期 × 证券代码的字段值, 和Dates/Codes对齐, 缺失的数据为NaN
+func (p *Panel[T]) Matrix(name string) ([][]float64, error) {
+	field, err := p.loadedField(name)
+	if err != nil {
+		return nil, err
+	}
+	matrix := make([][]float64, len(p.dates))
+	for i, date := range p.dates {
+		data, err := p.day(date)
+		if err != nil {
+			return nil, err
+		}
+		row := make([]float64, len(p.codes))
+		for j, code := range p.codes {
+			v, ok := data[code]
+			if !ok {
+				row[j] = math.NaN()
+				continue
+			}
+			row[j] = fieldValue(v, field)
+		}
+		matrix[i] = row
+	}
+	return matrix, nil
+}
@@ -0,0 +1,106 @@
+package factors
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func testHistoryPanel(t *testing.T, capacity int, columns ...string) *Panel[*History] {
+	dir := t.TempDir()
+	dates := []string{"2024-06-24", "2024-06-25", "2024-06-26"}
+	codes := []string{"sh600000", "sz000001"}
+	for i, date := range dates[:2] {
+		list := []*History{
+			{Date: date, Code: "sh600000", MA5: float64(10 + i), MA10: 1},
+			{Date: date, Code: "sz000001", MA5: float64(20 + i), MA10: 2},
+			{Date: date, Code: "sz000002", MA5: 30},
+		}
+		if err := SlicesToColumnar(filepath.Join(dir, date+columnarExtension), list); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := NewPanel[*History](cacheL5KeyHistory, dates, codes, capacity, columns...)
+	p.filename = func(date string) string {
+		return filepath.Join(dir, date)
+	}
+	return p
+}
+
+func TestPanel(t *testing.T) {
+	p := testHistoryPanel(t, 1)
+	v, ok := p.Get("2024-06-25", "sz000001")
+	if !ok || v.MA5 != 21 {
+		t.Fatalf("Get = %+v, %v", v, ok)
+	}
+	// 不在代码集合中
+	if _, ok = p.Get("2024-06-25", "sz000002"); ok {
+		t.Error("sz000002 should be filtered")
+	}
+	values, err := p.Field("2024-06-24", "ma5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || values["sh600000"] != 10 {
+		t.Errorf("Field = %v", values)
+	}
+	series, err := p.TimeSeries("sh600000", "MA5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if series[0] != 10 || series[1] != 11 || !math.IsNaN(series[2]) {
+		t.Errorf("TimeSeries = %v", series)
+	}
+	matrix, err := p.Matrix("ma5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matrix[1][1] != 21 || !math.IsNaN(matrix[2][0]) {
+		t.Errorf("Matrix = %v", matrix)
+	}
+	if p.lru.Len() != 1 {
+		t.Errorf("lru = %d, want 1", p.lru.Len())
+	}
+	if _, err = p.Field("2024-06-24", "code"); !errors.Is(err, ErrPanelField) {
+		t.Errorf("error = %v, want ErrPanelField", err)
+	}
+	if _, err = p.Field("2024-06-27", "ma5"); !errors.Is(err, ErrPanelDate) {
+		t.Errorf("error = %v, want ErrPanelDate", err)
+	}
+}
+
+func TestPanelConcurrent(t *testing.T) {
+	p := testHistoryPanel(t, 2)
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			date := p.dates[i%len(p.dates)]
+			if _, err := p.Field(date, "ma5"); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestPanelColumns(t *testing.T) {
+	p := testHistoryPanel(t, 2, "MA5")
+	values, err := p.Field("2024-06-25", "ma5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || values["sz000001"] != 21 {
+		t.Errorf("Field = %v", values)
+	}
+	// 没有加载的列
+	if v, _ := p.Get("2024-06-25", "sz000001"); v.MA10 != 0 {
+		t.Errorf("MA10 = %v, want 0", v.MA10)
+	}
+	if _, err = p.Field("2024-06-25", "ma10"); !errors.Is(err, ErrPanelField) {
+		t.Errorf("error = %v, want ErrPanelField", err)
+	}
+}
@@ -0,0 +1,372 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v6.30.0
+// source: cache1d.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 列的存储类型
+type ColumnType int32
+
+const (
+	ColumnType_COLUMN_UNKNOWN ColumnType = 0
+	ColumnType_COLUMN_BOOL    ColumnType = 1
+	ColumnType_COLUMN_INT64   ColumnType = 2
+	ColumnType_COLUMN_UINT64  ColumnType = 3
+	ColumnType_COLUMN_FLOAT64 ColumnType = 4
+	ColumnType_COLUMN_STRING  ColumnType = 5
+)
+
+// Enum value maps for ColumnType.
+var (
+	ColumnType_name = map[int32]string{
+		0: "COLUMN_UNKNOWN",
+		1: "COLUMN_BOOL",
+		2: "COLUMN_INT64",
+		3: "COLUMN_UINT64",
+		4: "COLUMN_FLOAT64",
+		5: "COLUMN_STRING",
+	}
+	ColumnType_value = map[string]int32{
+		"COLUMN_UNKNOWN": 0,
+		"COLUMN_BOOL":    1,
+		"COLUMN_INT64":   2,
+		"COLUMN_UINT64":  3,
+		"COLUMN_FLOAT64": 4,
+		"COLUMN_STRING":  5,
+	}
+)
+
+func (x ColumnType) Enum() *ColumnType {
+	p := new(ColumnType)
+	*p = x
+	return p
+}
+
+func (x ColumnType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ColumnType) Descriptor() protoreflect.EnumDescriptor {
+	return file_cache1d_proto_enumTypes[0].Descriptor()
+}
+
+func (ColumnType) Type() protoreflect.EnumType {
+	return &file_cache1d_proto_enumTypes[0]
+}
+
+func (x ColumnType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ColumnType.Descriptor instead.
+func (ColumnType) EnumDescriptor() ([]byte, []int) {
+	return file_cache1d_proto_rawDescGZIP(), []int{0}
+}
+
+// 列索引
+type ColumnIndex struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                     // 列名, 取dataframe标签
+	Type          ColumnType             `protobuf:"varint,2,opt,name=type,proto3,enum=pb.ColumnType" json:"type,omitempty"` // 存储类型
+	Offset        uint64                 `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`                // 相对数据区起始位置的偏移
+	Size          uint64                 `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`                    // 列数据的字节数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ColumnIndex) Reset() {
+	*x = ColumnIndex{}
+	mi := &file_cache1d_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ColumnIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ColumnIndex) ProtoMessage() {}
+
+func (x *ColumnIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_cache1d_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ColumnIndex.ProtoReflect.Descriptor instead.
+func (*ColumnIndex) Descriptor() ([]byte, []int) {
+	return file_cache1d_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ColumnIndex) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ColumnIndex) GetType() ColumnType {
+	if x != nil {
+		return x.Type
+	}
+	return ColumnType_COLUMN_UNKNOWN
+}
+
+func (x *ColumnIndex) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ColumnIndex) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// 列式缓存文件头
+type ColumnarHeader struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       uint32                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Rows          uint32                 `protobuf:"varint,2,opt,name=rows,proto3" json:"rows,omitempty"`
+	Columns       []*ColumnIndex         `protobuf:"bytes,3,rep,name=columns,proto3" json:"columns,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ColumnarHeader) Reset() {
+	*x = ColumnarHeader{}
+	mi := &file_cache1d_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ColumnarHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ColumnarHeader) ProtoMessage() {}
+
+func (x *ColumnarHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_cache1d_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ColumnarHeader.ProtoReflect.Descriptor instead.
+func (*ColumnarHeader) Descriptor() ([]byte, []int) {
+	return file_cache1d_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ColumnarHeader) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ColumnarHeader) GetRows() uint32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *ColumnarHeader) GetColumns() []*ColumnIndex {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+// 一列数据, 按存储类型只有一个字段有值
+type Column struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bools         []bool                 `protobuf:"varint,1,rep,packed,name=bools,proto3" json:"bools,omitempty"`
+	Ints          []int64                `protobuf:"zigzag64,2,rep,packed,name=ints,proto3" json:"ints,omitempty"`
+	Uints         []uint64               `protobuf:"varint,3,rep,packed,name=uints,proto3" json:"uints,omitempty"`
+	Floats        []float64              `protobuf:"fixed64,4,rep,packed,name=floats,proto3" json:"floats,omitempty"`
+	Strings       []string               `protobuf:"bytes,5,rep,name=strings,proto3" json:"strings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Column) Reset() {
+	*x = Column{}
+	mi := &file_cache1d_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Column) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Column) ProtoMessage() {}
+
+func (x *Column) ProtoReflect() protoreflect.Message {
+	mi := &file_cache1d_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Column.ProtoReflect.Descriptor instead.
+func (*Column) Descriptor() ([]byte, []int) {
+	return file_cache1d_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Column) GetBools() []bool {
+	if x != nil {
+		return x.Bools
+	}
+	return nil
+}
+
+func (x *Column) GetInts() []int64 {
+	if x != nil {
+		return x.Ints
+	}
+	return nil
+}
+
+func (x *Column) GetUints() []uint64 {
+	if x != nil {
+		return x.Uints
+	}
+	return nil
+}
+
+func (x *Column) GetFloats() []float64 {
+	if x != nil {
+		return x.Floats
+	}
+	return nil
+}
+
+func (x *Column) GetStrings() []string {
+	if x != nil {
+		return x.Strings
+	}
+	return nil
+}
+
+var File_cache1d_proto protoreflect.FileDescriptor
+
+const file_cache1d_proto_rawDesc = "" +
+	"\n" +
+	"\rcache1d.proto\x12\x02pb\"q\n" +
+	"\vColumnIndex\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\"\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x0e.pb.ColumnTypeR\x04type\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x04R\x06offset\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x04R\x04size\"i\n" +
+	"\x0eColumnarHeader\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\rR\aversion\x12\x12\n" +
+	"\x04rows\x18\x02 \x01(\rR\x04rows\x12)\n" +
+	"\acolumns\x18\x03 \x03(\v2\x0f.pb.ColumnIndexR\acolumns\"z\n" +
+	"\x06Column\x12\x14\n" +
+	"\x05bools\x18\x01 \x03(\bR\x05bools\x12\x12\n" +
+	"\x04ints\x18\x02 \x03(\x12R\x04ints\x12\x14\n" +
+	"\x05uints\x18\x03 \x03(\x04R\x05uints\x12\x16\n" +
+	"\x06floats\x18\x04 \x03(\x01R\x06floats\x12\x18\n" +
+	"\astrings\x18\x05 \x03(\tR\astrings*}\n" +
+	"\n" +
+	"ColumnType\x12\x12\n" +
+	"\x0eCOLUMN_UNKNOWN\x10\x00\x12\x0f\n" +
+	"\vCOLUMN_BOOL\x10\x01\x12\x10\n" +
+	"\fCOLUMN_INT64\x10\x02\x12\x11\n" +
+	"\rCOLUMN_UINT64\x10\x03\x12\x12\n" +
+	"\x0eCOLUMN_FLOAT64\x10\x04\x12\x11\n" +
+	"\rCOLUMN_STRING\x10\x05B\x06Z\x04./pbb\x06proto3"
+
+var (
+	file_cache1d_proto_rawDescOnce sync.Once
+	file_cache1d_proto_rawDescData []byte
+)
+
+func file_cache1d_proto_rawDescGZIP() []byte {
+	file_cache1d_proto_rawDescOnce.Do(func() {
+		file_cache1d_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_cache1d_proto_rawDesc), len(file_cache1d_proto_rawDesc)))
+	})
+	return file_cache1d_proto_rawDescData
+}
+
+var file_cache1d_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_cache1d_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_cache1d_proto_goTypes = []any{
+	(ColumnType)(0),        // 0: pb.ColumnType
+	(*ColumnIndex)(nil),    // 1: pb.ColumnIndex
+	(*ColumnarHeader)(nil), // 2: pb.ColumnarHeader
+	(*Column)(nil),         // 3: pb.Column
+}
+var file_cache1d_proto_depIdxs = []int32{
+	0, // 0: pb.ColumnIndex.type:type_name -> pb.ColumnType
+	1, // 1: pb.ColumnarHeader.columns:type_name -> pb.ColumnIndex
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_cache1d_proto_init() }
+func file_cache1d_proto_init() {
+	if File_cache1d_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cache1d_proto_rawDesc), len(file_cache1d_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_cache1d_proto_goTypes,
+		DependencyIndexes: file_cache1d_proto_depIdxs,
+		EnumInfos:         file_cache1d_proto_enumTypes,
+		MessageInfos:      file_cache1d_proto_msgTypes,
+	}.Build()
+	File_cache1d_proto = out.File
+	file_cache1d_proto_goTypes = nil
+	file_cache1d_proto_depIdxs = nil
+}
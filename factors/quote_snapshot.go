@@ -1,6 +1,10 @@
 package factors
 
-import "gitee.com/quant1x/data/level1/quotes"
+import (
+	"sync"
+
+	"gitee.com/quant1x/data/level1/quotes"
+)
 
 // QuoteSnapshot 即时行情快照(副本)
 type QuoteSnapshot struct {
@@ -176,3 +180,56 @@ func (q QuoteSnapshot) WeaknessToStrength() bool {
 
 	return frontToStrength || nextToStrength
 }
+
+var (
+	__mutexRealtimeFeatures sync.RWMutex
+	__realtimeFeatures      func(key, securityCode, date string) Feature
+)
+
+// RegisterRealtimeFeatures 注册盘中实时特征的查询方法, 没有实时特征时返回nil
+//
+//	实时特征在realtime包中计算, realtime依赖factors, 所以由realtime注册
+func RegisterRealtimeFeatures(get func(key, securityCode, date string) Feature) {
+	__mutexRealtimeFeatures.Lock()
+	defer __mutexRealtimeFeatures.Unlock()
+	__realtimeFeatures = get
+}
+
+// 快照当日的实时特征
+func realtimeFeature[T Feature](q QuoteSnapshot, key string) T {
+	var t T
+	__mutexRealtimeFeatures.RLock()
+	get := __realtimeFeatures
+	__mutexRealtimeFeatures.RUnlock()
+	if get == nil {
+		return t
+	}
+	if v, ok := get(key, q.SecurityCode, q.Date).(T); ok {
+		return v
+	}
+	return t
+}
+
+// RealtimeHistory 历史特征, 优先取快照当日的盘中实时数据, 均线和MACD包含现价, 没有时取缓存中的数据
+func (q QuoteSnapshot) RealtimeHistory() *History {
+	if v := realtimeFeature[*History](q, cacheL5KeyHistory); v != nil {
+		return v
+	}
+	return GetL5History(q.SecurityCode)
+}
+
+// RealtimeMisc 扩展交易特征, 优先取快照当日的盘中实时数据, 没有时取缓存中的数据
+func (q QuoteSnapshot) RealtimeMisc() *Misc {
+	if v := realtimeFeature[*Misc](q, cacheL5KeyMisc); v != nil {
+		return v
+	}
+	return GetL5Misc(q.SecurityCode)
+}
+
+// RealtimeBox 平台特征, 优先取快照当日的盘中实时数据, 没有时取缓存中的数据
+func (q QuoteSnapshot) RealtimeBox() *Box {
+	if v := realtimeFeature[*Box](q, cacheL5KeyBox); v != nil {
+		return v
+	}
+	return GetL5Box(q.SecurityCode)
+}
@@ -0,0 +1,238 @@
+package factors
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/tdx"
+	"gitee.com/quant1x/engine/utils"
+	"gitee.com/quant1x/num"
+	"gitee.com/quant1x/pandas"
+	. "gitee.com/quant1x/pandas/formula"
+)
+
+const (
+	// 对照用的K线, 和indicators共用
+	conformanceFixture = "../tdx/testdata/kline.csv"
+	// 对照用的证券代码, 主板10%涨跌幅
+	conformanceCode = "sh600000"
+	// 对照时跳过的K线数
+	conformanceWarmup = 120
+)
+
+// 通达信公式和特征计算的对照, 特征只输出最后一根K线, 逐根截取K线对照
+type conformanceCase struct {
+	file    string                                                // 公式文件
+	rewrite func(source string) string                            // 改写公式, 去掉Go实现之外的依赖
+	params  func(date string) map[string]float64                  // 公式参数
+	compute func(date string, df pandas.DataFrame) map[string]any // 特征计算, 公式变量 -> 最后一根K线的值
+}
+
+var conformanceCases = []conformanceCase{
+	{
+		// Go沿用V1.0.9的倍量条件CLOSE>OPEN, 公式文件是V1.1.3的CLOSE>=OPEN
+		file: "feature_box_breaks.tdx",
+		rewrite: func(source string) string {
+			return strings.Replace(source, "CLOSE>=OPEN", "CLOSE>OPEN", 1)
+		},
+		compute: func(date string, df pandas.DataFrame) map[string]any {
+			v := computeKLineBox(conformanceCode, date, df)
+			return map[string]any{
+				"倍量周期": v.DoubletPeriod, "倍量H": v.DoubleHigh, "倍量L": v.DoubleLow,
+				"B": v.Buy, "S": v.Sell, "多空": v.TendencyPeriod,
+			}
+		},
+	},
+	{
+		file: "feature_box_dkqs.tdx",
+		compute: func(date string, df pandas.DataFrame) map[string]any {
+			OPEN, CLOSE, HIGH, LOW, _ := conformanceColumns(df)
+			v := computeDuoKongQuShi(OPEN, CLOSE, HIGH, LOW)
+			return map[string]any{"MADK": v.Col, "K0": v.K0, "K": v.K, "D": v.D, "B": v.B, "S": v.S}
+		},
+	},
+	{
+		file: "feature_box_madx.tdx",
+		compute: func(date string, df pandas.DataFrame) map[string]any {
+			OPEN, CLOSE, HIGH, LOW, _ := conformanceColumns(df)
+			v := computeJuXianDongXiang(OPEN, CLOSE, HIGH, LOW)
+			return map[string]any{"DM0": v.Dm0, "DM1": v.Dm1, "DM2": v.Dm2, "DIVERGING": v.Diverging, "B": v.B, "BN": v.BN}
+		},
+	},
+	{
+		// 最后一根K线的量比按开盘以来的分钟数修正
+		file: "feature_box_qsfz.tdx",
+		params: func(date string) map[string]float64 {
+			return map[string]float64{"FROMOPEN": float64(exchange.Minutes(date))}
+		},
+		compute: func(date string, df pandas.DataFrame) map[string]any {
+			OPEN, CLOSE, HIGH, LOW, VOL := conformanceColumns(df)
+			v := computeQuShiFanZhuan(date, OPEN, CLOSE, HIGH, LOW, VOL)
+			return map[string]any{"CP": v.CP, "CV": v.CV, "VP": v.VP, "VP3": v.VP3, "VP5": v.VP5, "B": v.QSFZ}
+		},
+	},
+	{
+		// 名称和板块由Go按证券代码判断, 公式改为直接传入涨跌幅
+		file: "feature_ism.tdx",
+		rewrite: func(source string) string {
+			var lines []string
+			for _, line := range strings.Split(source, "\n") {
+				if strings.HasPrefix(line, "CST:=") || strings.HasPrefix(line, "ZDF:=") {
+					continue
+				}
+				lines = append(lines, line)
+			}
+			return strings.Join(lines, "\n")
+		},
+		params: func(date string) map[string]float64 {
+			return map[string]float64{"ZDF": exchange.MarketLimit(conformanceCode)}
+		},
+		compute: func(date string, df pandas.DataFrame) map[string]any {
+			var v InvestmentSentimentMaster
+			v.compute(conformanceCode, df)
+			return map[string]any{
+				"BN": v.BN, "TN": v.TN, "涨": v.ZHANG, "平": v.PING, "跌": v.DIE,
+				"OH": v.OH, "OHN": v.OHN, "OL": v.OL, "OLN": v.OLN,
+				"OHV": v.OHV, "OHBL": v.OHBL, "OLV": v.OLV, "OLBL": v.OLBL,
+			}
+		},
+	},
+	{
+		file: "feature_misc_bdl.tdx",
+		params: func(date string) map[string]float64 {
+			return map[string]float64{"N": 3, "M": 21}
+		},
+		compute: func(date string, df pandas.DataFrame) map[string]any {
+			OPEN, CLOSE, HIGH, LOW, _ := conformanceColumns(df)
+			vix := computeVolatility(OPEN, CLOSE, HIGH, LOW, 3, 21)
+			return map[string]any{"VIX": utils.Float64IndexOf(vix, -1)}
+		},
+	},
+	{
+		// Go保留了强度的方向, 公式去掉ABS之后对照
+		file: "feature_misc_qd.tdx",
+		rewrite: func(source string) string {
+			source = strings.Replace(source, "QD1:=ABS(QDV1-REF(QDV1,1));", "QD1:=QDV1-REF(QDV1,1);", 1)
+			return strings.Replace(source, "QD2:=ABS(QDV2-REF(QDV2,1));", "QD2:=QDV2-REF(QDV2,1);", 1)
+		},
+		compute: func(date string, df pandas.DataFrame) map[string]any {
+			_, CLOSE, _, _, _ := conformanceColumns(df)
+			qd1, qd2 := computeIntensity(MA(CLOSE, 5), MA(CLOSE, 10), MA(CLOSE, 20))
+			return map[string]any{
+				"QD1": utils.Float64IndexOf(qd1, -1),
+				"QD2": utils.Float64IndexOf(qd2, -1),
+			}
+		},
+	},
+}
+
+// 加载对照用的K线
+func loadConformanceKLines(t *testing.T) pandas.DataFrame {
+	if _, err := os.Stat(conformanceFixture); err != nil {
+		t.Fatalf("对照数据不存在: %+v", err)
+	}
+	df := pandas.ReadCSV(conformanceFixture)
+	if df.Nrow() <= conformanceWarmup {
+		t.Fatalf("%s: K线数%d, 不足%d", conformanceFixture, df.Nrow(), conformanceWarmup)
+	}
+	return df
+}
+
+// 截取前n根K线
+func conformanceWindow(df pandas.DataFrame, n int) pandas.DataFrame {
+	list := []pandas.Series{pandas.NewSeriesWithType(pandas.SERIES_TYPE_STRING, "date", df.Col("date").Strings()[:n])}
+	for _, name := range []string{"open", "close", "high", "low", "volume", "amount"} {
+		list = append(list, pandas.NewSeriesWithType(pandas.SERIES_TYPE_DTYPE, name, df.Col(name).Float64s()[:n]))
+	}
+	return pandas.NewDataFrame(list...)
+}
+
+func conformanceColumns(df pandas.DataFrame) (OPEN, CLOSE, HIGH, LOW, VOL pandas.Series) {
+	return df.ColAsNDArray("open"), df.ColAsNDArray("close"), df.ColAsNDArray("high"), df.ColAsNDArray("low"), df.ColAsNDArray("volume")
+}
+
+// 特征值转成公式的数值, 逻辑值用1和0表示
+func conformanceValue(v any) float64 {
+	switch x := v.(type) {
+	case bool:
+		if x {
+			return 1
+		}
+		return 0
+	case int:
+		return float64(x)
+	}
+	return num.AnyToFloat64(v)
+}
+
+// 每个公式文件都必须有对照
+func TestTdxConformanceCoverage(t *testing.T) {
+	files, err := filepath.Glob("*.tdx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	covered := make(map[string]bool, len(conformanceCases))
+	for _, v := range conformanceCases {
+		covered[v.file] = true
+	}
+	for _, file := range files {
+		if !covered[file] {
+			t.Errorf("%s: 没有对照", file)
+		}
+		delete(covered, file)
+	}
+	for file := range covered {
+		t.Errorf("%s: 公式文件不存在", file)
+	}
+}
+
+func TestTdxConformance(t *testing.T) {
+	df := loadConformanceKLines(t)
+	dates := df.Col("date").Strings()
+	for _, v := range conformanceCases {
+		data, err := os.ReadFile(v.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		source := string(data)
+		if v.rewrite != nil {
+			source = v.rewrite(source)
+		}
+		formula, err := tdx.Parse(source)
+		if err != nil {
+			t.Fatalf("%s: %+v", v.file, err)
+		}
+		for n := conformanceWarmup + 1; n <= df.Nrow(); n++ {
+			date := dates[n-1]
+			window := conformanceWindow(df, n)
+			expected := v.compute(date, window)
+			var params map[string]float64
+			if v.params != nil {
+				params = v.params(date)
+			}
+			var outputs []string
+			for name := range expected {
+				outputs = append(outputs, name)
+			}
+			result, err := formula.EvalDataFrame(window, params, outputs...)
+			if err != nil {
+				t.Fatalf("%s: %+v", v.file, err)
+			}
+			for name, value := range expected {
+				got := result.Col(name).Float64s()[n-1]
+				want := conformanceValue(value)
+				// 公式的无效值在Go里会被转换成0, 不对照
+				if math.IsNaN(got) || math.IsNaN(want) {
+					continue
+				}
+				if math.Abs(want-got) > 1e-6 {
+					t.Errorf("%s: %s[%s] = %v, want %v", v.file, name, date, got, want)
+				}
+			}
+		}
+	}
+}
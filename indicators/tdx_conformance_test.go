@@ -0,0 +1,183 @@
+package indicators
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitee.com/quant1x/engine/tdx"
+	"gitee.com/quant1x/pandas"
+)
+
+// 通达信公式和Go实现的对照
+//
+//	compute为空表示公式没有Go实现, 只校验outputs可以完整求值
+type conformanceCase struct {
+	file    string                                     // 公式文件
+	params  map[string]float64                         // 公式参数
+	compute func(df pandas.DataFrame) pandas.DataFrame // Go实现
+	columns map[string]string                          // Go输出列 -> 公式变量
+	outputs []string                                   // 没有Go实现时校验的公式变量
+}
+
+const (
+	// 对照用的K线, 固定的模拟数据, 包含涨停、平盘和倍量
+	conformanceFixture = "../tdx/testdata/kline.csv"
+	// 对照时跳过的K线数
+	conformanceWarmup = 120
+)
+
+var conformanceCases = []conformanceCase{
+	{
+		file:    "cdtd.tdx",
+		compute: CDTD,
+		columns: map[string]string{"B": "买入", "S": "卖出"},
+	},
+	{
+		file:    "ma4x.tdx",
+		compute: func(df pandas.DataFrame) pandas.DataFrame { return MA4X(df, 6) },
+		columns: map[string]string{"B": "B", "S": "S"},
+	},
+	{
+		// Go实现的B包含了公式中注释掉的C3, 只对照止损线
+		file:    "89k.tdx",
+		params:  map[string]float64{"N1": 89},
+		compute: func(df pandas.DataFrame) pandas.DataFrame { return F89K(df, 89) },
+		columns: map[string]string{"ZS": "ZS"},
+	},
+	{
+		file:    "platform.tdx",
+		compute: Platform,
+		columns: map[string]string{
+			"BLN": "倍量周期", "BLH": "倍量H", "BLL": "倍量L", "SL": "SL",
+			"SLN": "缩量周期", "SLH": "缩量H", "SLL": "缩量L",
+		},
+	},
+	{
+		// Go实现的B1用的是<=, 和公式的B不一致, 不对照
+		file:    "t02.tdx",
+		compute: Platform,
+		columns: map[string]string{
+			"BLN": "倍量周期", "BLH": "倍量H", "BLL": "倍量L", "BLZC": "倍量支撑", "SL": "SL",
+			"SLN": "缩量周期", "SLH": "缩量H", "SLL": "缩量L", "B2": "B1", "B3": "B2",
+		},
+	},
+	{
+		// DYNAINFO是实时行情, "BASIC.X_HIGH#DAY"引用外部数据, 只校验机构买卖
+		file:    "cps.tdx",
+		outputs: []string{"机构买1", "机构卖1", "净买入1"},
+	},
+	{
+		file:    "ljjs.tdx",
+		outputs: []string{"K", "D", "S", "B"},
+	},
+	{
+		// 止损和压力是DRAWLINE绘图语句
+		file: "ma1x.tdx",
+		outputs: []string{
+			"DN", "QR", "MA5", "MA13", "MA21", "MA34", "MA65", "MA89", "MA144",
+			"YL_N", "回踩", "突破", "HG10", "HG11", "HG20",
+		},
+	},
+	{
+		file:    "ma5x.tdx",
+		params:  map[string]float64{"P1": 5, "L1": 5},
+		outputs: []string{"AVL", "AVL1", "周期", "平高", "平低", "振幅", "SL", "缩量周期", "缩量H", "缩量L", "B1", "B2"},
+	},
+	{
+		file:    "obv.tdx",
+		params:  map[string]float64{"M": 30},
+		outputs: []string{"OBV", "MAOBV"},
+	},
+	{
+		file: "pzzy.tdx",
+		outputs: []string{
+			"重心", "压2", "压1", "支1", "支2", "上涨概率", "止损", "止盈", "MID", "UPPER", "LOWER", "新",
+		},
+	},
+}
+
+// 加载对照用的K线
+func loadConformanceKLines(t *testing.T) pandas.DataFrame {
+	if _, err := os.Stat(conformanceFixture); err != nil {
+		t.Fatalf("对照数据不存在: %+v", err)
+	}
+	df := pandas.ReadCSV(conformanceFixture)
+	if df.Nrow() <= conformanceWarmup {
+		t.Fatalf("%s: K线数%d, 不足%d", conformanceFixture, df.Nrow(), conformanceWarmup)
+	}
+	return df
+}
+
+// 每个公式文件都必须有对照
+func TestTdxConformanceCoverage(t *testing.T) {
+	files, err := filepath.Glob("*.tdx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	covered := make(map[string]bool, len(conformanceCases))
+	for _, v := range conformanceCases {
+		covered[v.file] = true
+	}
+	for _, file := range files {
+		if !covered[file] {
+			t.Errorf("%s: 没有对照", file)
+		}
+		delete(covered, file)
+	}
+	for file := range covered {
+		t.Errorf("%s: 公式文件不存在", file)
+	}
+}
+
+func TestTdxConformance(t *testing.T) {
+	df := loadConformanceKLines(t)
+	for _, v := range conformanceCases {
+		formula, err := tdx.ParseFile(v.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.compute == nil {
+			result, err := formula.EvalDataFrame(df, v.params, v.outputs...)
+			if err != nil {
+				t.Fatalf("%s: %+v", v.file, err)
+			}
+			for _, name := range v.outputs {
+				got := result.Col(name).Float64s()
+				valid := 0
+				for _, f := range got[conformanceWarmup:] {
+					if !math.IsNaN(f) && !math.IsInf(f, 0) {
+						valid++
+					}
+				}
+				if valid == 0 {
+					t.Errorf("%s: %s没有有效值", v.file, name)
+				}
+			}
+			continue
+		}
+		expected := v.compute(df)
+		var outputs []string
+		for _, name := range v.columns {
+			outputs = append(outputs, name)
+		}
+		result, err := formula.EvalDataFrame(df, v.params, outputs...)
+		if err != nil {
+			t.Fatalf("%s: %+v", v.file, err)
+		}
+		for column, name := range v.columns {
+			want := expected.Col(column).Float64s()
+			got := result.Col(name).Float64s()
+			// 前面的K线是指标的预热期, 两边对无效值和递归均线初值的处理不同
+			for i := conformanceWarmup; i < len(want); i++ {
+				if math.IsNaN(want[i]) || math.IsNaN(got[i]) {
+					continue
+				}
+				if math.Abs(want[i]-got[i]) > 1e-6 {
+					t.Errorf("%s: %s[%d] = %v, want %v", v.file, name, i, got[i], want[i])
+				}
+			}
+		}
+	}
+}
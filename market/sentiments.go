@@ -1,8 +1,9 @@
 package market
 
 import (
+	"sync"
+
 	"gitee.com/quant1x/data/exchange"
-	"gitee.com/quant1x/data/level1"
 	"gitee.com/quant1x/data/level1/quotes"
 	"gitee.com/quant1x/gox/logger"
 	"gitee.com/quant1x/num"
@@ -20,6 +21,20 @@ const (
 	SentimentLow  SentimentType = -1   // 情绪低迷
 )
 
+var (
+	__mutexSnapshotSource sync.RWMutex
+	__snapshotSource      func(securityCodes []string) ([]quotes.Snapshot, error)
+)
+
+// RegisterSnapshotSource 注册即时行情的数据源
+//
+//	数据源在datasource包中按配置创建, datasource依赖market, 所以由datasource注册
+func RegisterSnapshotSource(source func(securityCodes []string) ([]quotes.Snapshot, error)) {
+	__mutexSnapshotSource.Lock()
+	defer __mutexSnapshotSource.Unlock()
+	__snapshotSource = source
+}
+
 // IndexSentiment 情绪指数, 50%为平稳, 低于50%为情绪差, 高于50%为情绪好
 func IndexSentiment(codes ...string) (sentiment float64, consistent int) {
 	// 默认上证指数
@@ -34,9 +49,14 @@ func IndexSentiment(codes ...string) (sentiment float64, consistent int) {
 	if len(securityCode) != 8 {
 		return
 	}
-	tdxApi := level1.GetApi()
-	hq, err := tdxApi.GetSnapshot([]string{securityCode})
-	if err != nil && len(hq) != len(codes) {
+	__mutexSnapshotSource.RLock()
+	source := __snapshotSource
+	__mutexSnapshotSource.RUnlock()
+	if source == nil {
+		return
+	}
+	hq, err := source([]string{securityCode})
+	if err != nil || len(hq) == 0 {
 		logger.Errorf("获取即时行情数据失败", err)
 		return
 	}
@@ -2,8 +2,8 @@ package models
 
 import (
 	"gitee.com/quant1x/data/exchange"
-	"gitee.com/quant1x/data/level1"
 	"gitee.com/quant1x/data/level1/quotes"
+	"gitee.com/quant1x/engine/datasource"
 	"gitee.com/quant1x/engine/factors"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/logger"
@@ -43,13 +43,13 @@ func QuoteSnapshotFromProtocol(v quotes.Snapshot) factors.QuoteSnapshot {
 
 // BatchSnapShot 批量获取即时行情数据快照
 func BatchSnapShot(codes []string) []factors.QuoteSnapshot {
-	tdxApi := level1.GetApi()
+	adapter := datasource.GetAdapter()
 	list := []factors.QuoteSnapshot{}
 	var err error
 	var hq []quotes.Snapshot
 	retryTimes := 0
 	for retryTimes < quotes.DefaultRetryTimes {
-		hq, err = tdxApi.GetSnapshot(codes)
+		hq, err = adapter.Snapshots(codes)
 		if err == nil && hq != nil {
 			break
 		}
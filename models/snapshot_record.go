@@ -0,0 +1,95 @@
+package models
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"gitee.com/quant1x/data/level1/quotes"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/gox/api"
+)
+
+// SnapshotBatch 一次轮询得到的一批快照
+type SnapshotBatch struct {
+	Timestamp time.Time         `json:"timestamp"` // 轮询的时间
+	Snapshots []quotes.Snapshot `json:"snapshots"` // 快照列表
+}
+
+var (
+	__mutexRecord sync.Mutex
+)
+
+// RecordSnapshots 追加一批快照到交易日的录制文件
+func RecordSnapshots(date string, timestamp time.Time, snapshots []quotes.Snapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	filename := cache.SnapshotRecordFilename(date)
+	return appendSnapshotBatch(filename, SnapshotBatch{Timestamp: timestamp, Snapshots: snapshots})
+}
+
+// LoadSnapshotBatches 加载交易日录制的全部快照批次, 按录制的顺序
+func LoadSnapshotBatches(date string) ([]SnapshotBatch, error) {
+	filename := cache.SnapshotRecordFilename(date)
+	return loadSnapshotBatches(filename)
+}
+
+// 追加写入, 每一批是一个独立的gzip成员
+//
+//	已经写入的数据不需要改写, 进程异常退出最多丢失最后一批
+func appendSnapshotBatch(filename string, batch SnapshotBatch) error {
+	__mutexRecord.Lock()
+	defer __mutexRecord.Unlock()
+	if err := api.CheckFilepath(filename, true); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer api.CloseQuietly(file)
+	writer := bufio.NewWriter(file)
+	zw := gzip.NewWriter(writer)
+	if err = json.NewEncoder(zw).Encode(batch); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// 读取录制文件, 末尾不完整的批次忽略
+func loadSnapshotBatches(filename string) ([]SnapshotBatch, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer api.CloseQuietly(file)
+	zr, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return nil, err
+	}
+	defer api.CloseQuietly(zr)
+	var list []SnapshotBatch
+	decoder := json.NewDecoder(zr)
+	for {
+		var batch SnapshotBatch
+		err = decoder.Decode(&batch)
+		if err == io.EOF {
+			break
+		} else if errors.Is(err, io.ErrUnexpectedEOF) && len(list) > 0 {
+			break
+		} else if err != nil {
+			return list, err
+		}
+		list = append(list, batch)
+	}
+	return list, nil
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitee.com/quant1x/data/level1/quotes"
+)
+
+func TestSnapshotBatchRecordAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "2024", "20240102.jsonl.gz")
+	origin := time.Date(2024, 1, 2, 9, 25, 3, 0, time.Local)
+	for i := 0; i < 3; i++ {
+		batch := SnapshotBatch{
+			Timestamp: origin.Add(time.Duration(i) * time.Second),
+			Snapshots: []quotes.Snapshot{{Code: "600600", Price: 10 + float64(i)}},
+		}
+		if err := appendSnapshotBatch(filename, batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list, err := loadSnapshotBatches(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("batches = %d, want 3", len(list))
+	}
+	for i, v := range list {
+		if !v.Timestamp.Equal(origin.Add(time.Duration(i)*time.Second)) || len(v.Snapshots) != 1 || v.Snapshots[0].Price != 10+float64(i) {
+			t.Errorf("batch[%d] = %+v", i, v)
+		}
+	}
+	// 模拟写入中断, 末尾不完整的批次忽略
+	data, _ := os.ReadFile(filename)
+	_ = appendSnapshotBatch(filename, list[0])
+	full, _ := os.ReadFile(filename)
+	_ = os.WriteFile(filename, full[:len(data)+(len(full)-len(data))/2], 0644)
+	list, err = loadSnapshotBatches(filename)
+	if err != nil || len(list) != 3 {
+		t.Errorf("truncated batches = %d, %v, want 3", len(list), err)
+	}
+}
@@ -1,13 +1,17 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"sync"
 
 	"gitee.com/quant1x/data/exchange"
 	"gitee.com/quant1x/data/level1"
 	"gitee.com/quant1x/data/level1/quotes"
 	"gitee.com/quant1x/data/level1/securities"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/datasource"
 	"gitee.com/quant1x/engine/factors"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/logger"
@@ -31,6 +35,15 @@ func GetTickFromMemory(securityCode string) *quotes.Snapshot {
 	return nil
 }
 
+// UpdateTicksInMemory 更新快照缓存, 实时同步和回放都通过这个函数写入
+func UpdateTicksInMemory(snapshots []quotes.Snapshot) {
+	__mutexTicks.Lock()
+	defer __mutexTicks.Unlock()
+	for _, v := range snapshots {
+		__cacheTicks[v.SecurityCode] = v
+	}
+}
+
 // GetStrategySnapshot 从缓存中获取快照
 func GetStrategySnapshot(securityCode string) *factors.QuoteSnapshot {
 	v := GetTickFromMemory(securityCode)
@@ -62,7 +75,9 @@ func GetStrategySnapshot(securityCode string) *factors.QuoteSnapshot {
 }
 
 // SyncAllSnapshots 实时更新快照
-func SyncAllSnapshots(barIndex *int) {
+//
+//	重试之后仍然失败的批次不影响其它批次, 汇总成一个错误返回
+func SyncAllSnapshots(barIndex *int) error {
 	modName := "同步快照数据"
 	allCodes := securities.AllCodeList()
 	count := len(allCodes)
@@ -70,18 +85,19 @@ func SyncAllSnapshots(barIndex *int) {
 	if barIndex != nil {
 		bar = progressbar.NewBar(*barIndex, "执行["+modName+"]", count)
 	}
-	currentDate := exchange.GetCurrentlyDay()
-	tdxApi := level1.GetApi()
+	currentDate := clock.CurrentlyDay()
+	adapter := datasource.GetAdapter()
 	// 读取配置的并发数
 	parallelCount := config.GetDataConfig().Snapshot.Concurrency
 	if parallelCount < 1 {
-		parallelCount = tdxApi.NumOfServers()
-		parallelCount /= 2
-		if parallelCount < config.DefaultMinimumConcurrencyForSnapshots {
-			parallelCount = config.DefaultMinimumConcurrencyForSnapshots
+		parallelCount = config.DefaultMinimumConcurrencyForSnapshots
+		if adapter.Name() == datasource.SourceTdx {
+			// 通达信协议按服务器数量的半数并发
+			parallelCount = max(level1.GetApi().NumOfServers()/2, parallelCount)
 		}
 	}
 	var snapshots []quotes.Snapshot
+	var errs []error
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
 	codeCh := make(chan []string, parallelCount)
@@ -90,12 +106,15 @@ func SyncAllSnapshots(barIndex *int) {
 	for i := 0; i < parallelCount; i++ {
 		go func() {
 			for subCodes := range codeCh {
+				var lastErr error
 				for i := 0; i < quotes.DefaultRetryTimes; i++ {
-					list, err := tdxApi.GetSnapshot(subCodes)
+					list, err := adapter.Snapshots(subCodes)
 					if err != nil {
 						logger.Errorf("ZS: 网络异常: %+v, 重试: %d", err, i+1)
+						lastErr = err
 						continue
 					}
+					lastErr = nil
 					mutex.Lock()
 					for _, v := range list {
 						// 修订日期
@@ -106,6 +125,11 @@ func SyncAllSnapshots(barIndex *int) {
 
 					break
 				}
+				if lastErr != nil {
+					mutex.Lock()
+					errs = append(errs, fmt.Errorf("快照[%s...]: %w", subCodes[0], lastErr))
+					mutex.Unlock()
+				}
 			}
 			wg.Done()
 		}()
@@ -140,13 +164,16 @@ func SyncAllSnapshots(barIndex *int) {
 		bar.Wait()
 	}
 
-	__mutexTicks.Lock()
-	for _, v := range snapshots {
-		__cacheTicks[v.SecurityCode] = v
+	UpdateTicksInMemory(snapshots)
+	// 录制快照, 用于回放
+	if config.GetDataConfig().Snapshot.Record {
+		if err := RecordSnapshots(currentDate, clock.Now(), snapshots); err != nil {
+			logger.Errorf("录制快照失败: %+v", err)
+		}
 	}
-	__mutexTicks.Unlock()
 
 	if barIndex != nil {
 		*barIndex++
 	}
+	return errors.Join(errs...)
 }
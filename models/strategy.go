@@ -85,6 +85,8 @@ type Strategy interface {
 	// OrderFlag 订单标志
 	OrderFlag() string
 	// Filter 过滤
+	//
+	//	盘中的实时特征通过快照的RealtimeHistory、RealtimeMisc和RealtimeBox获取
 	Filter(ruleParameter config.RuleParameter, snapshot factors.QuoteSnapshot) error
 	// Sort 排序
 	Sort([]factors.QuoteSnapshot) SortedStatus
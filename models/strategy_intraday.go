@@ -0,0 +1,234 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"gitee.com/quant1x/engine/datasource/base"
+	"gitee.com/quant1x/engine/factors"
+)
+
+// IntradayAction 日内策略在一根K线收盘时的动作
+type IntradayAction int
+
+const (
+	IntradayHold IntradayAction = iota // 不操作
+	IntradayBuy                        // 买入
+	IntradaySell                       // 卖出
+)
+
+func (a IntradayAction) String() string {
+	switch a {
+	case IntradayBuy:
+		return "buy"
+	case IntradaySell:
+		return "sell"
+	default:
+		return "hold"
+	}
+}
+
+// IntradaySignal 日内策略的交易信号
+type IntradaySignal struct {
+	Action IntradayAction // 动作
+	Price  float64        // 委托价格, 0表示用K线的收盘价
+	Remark string         // 备注
+}
+
+// IntradayStrategy 日内分钟级策略, 策略可以选择实现
+//
+//	每根Frequency周期的K线收盘时调用OnBar, bars是当日已经收盘的K线, 按时间升序, 最后一根是刚收盘的K线.
+//	盘中由快照合成K线, 回测用缓存的分钟K线, 两种模式调用的是同一个OnBar.
+//	盘中跟踪只输出信号, 不会下单, 日内策略的委托需要策略自己通过trader提交
+type IntradayStrategy interface {
+	Strategy
+	// Frequency K线周期, 日内的分钟周期, 比如5min,90min,2h
+	Frequency() string
+	// OnBar K线收盘时评估
+	OnBar(securityCode string, bars []base.KLine) IntradaySignal
+}
+
+var (
+	ErrNotIntraday        = errors.New("the strategy does not support intraday bars") // 不是日内策略
+	ErrIntradayFrequency  = errors.New("unsupported intraday frequency")              // 不支持的K线周期
+	intradayBarExpiration = 5                                                         // K线结束后等待迟到快照的秒数
+)
+
+// CheckoutIntradayStrategy 捡出日内策略
+func CheckoutIntradayStrategy(strategyNumber uint64) (IntradayStrategy, error) {
+	model, err := CheckoutStrategy(strategyNumber)
+	if err != nil {
+		return nil, err
+	}
+	intraday, ok := model.(IntradayStrategy)
+	if !ok {
+		return nil, ErrNotIntraday
+	}
+	return intraday, nil
+}
+
+// IntradayFrequency 规范K线周期, 返回周期的关键字和分钟数, 和分钟K线缓存的路径一致
+//
+//	支持日内的任意分钟周期, 不是交易所原生的周期需要在data.cache.kline中启用, 由1分钟K线重采样
+func IntradayFrequency(freq string) (string, int, error) {
+	f, err := base.ParseFrequency(freq)
+	if err != nil || !f.Intraday() {
+		return "", 0, fmt.Errorf("%w: %s", ErrIntradayFrequency, freq)
+	}
+	return f.Key(), f.Minutes, nil
+}
+
+// IntradayBarEnd 时刻所属K线的结束时刻, seconds和返回值都是当日的秒数, period是K线周期的分钟数
+//
+//	集合竞价归入开盘的第一根K线, 午间休市和收盘之后的快照归入上一个时段的最后一根K线, 集合竞价之前返回false
+func IntradayBarEnd(seconds, period int) (int, bool) {
+	minute, ok := base.TradingMinute(seconds)
+	if !ok {
+		return 0, false
+	}
+	end := base.SessionBarMinute(minute, period, base.SessionMinutes)
+	return base.TradingMinuteTime(end), true
+}
+
+// 一个证券当日的K线合成状态
+type intradayBarState struct {
+	date    string       // 交易日期
+	bar     base.KLine   // 正在合成的K线
+	end     int          // 正在合成的K线的结束时刻, 0表示没有
+	lastEnd int          // 最后一根收盘K线的结束时刻
+	vol     int          // 上一个快照的总量, 单位手
+	amount  float64      // 上一个快照的总金额
+	bars    []base.KLine // 当日已经收盘的K线
+}
+
+// 收盘正在合成的K线
+func (s *intradayBarState) close() {
+	if s.end == 0 {
+		return
+	}
+	s.bars = append(s.bars, s.bar)
+	s.lastEnd = s.end
+	s.end = 0
+}
+
+// IntradayBarBuilder 用快照合成日内K线
+//
+//	快照的总量和总金额是累计值, 相邻快照的差值计入K线. K线在下一根K线的快照到达时收盘,
+//	没有新快照时由Expire按时间收盘, 午间和收盘的最后一根K线依赖Expire
+type IntradayBarBuilder struct {
+	mutex  sync.Mutex
+	freq   string
+	period int
+	states map[string]*intradayBarState
+}
+
+// NewIntradayBarBuilder 创建日内K线合成器
+func NewIntradayBarBuilder(freq string) (*IntradayBarBuilder, error) {
+	freq, period, err := IntradayFrequency(freq)
+	if err != nil {
+		return nil, err
+	}
+	builder := IntradayBarBuilder{
+		freq:   freq,
+		period: period,
+		states: map[string]*intradayBarState{},
+	}
+	return &builder, nil
+}
+
+// Frequency K线周期
+func (b *IntradayBarBuilder) Frequency() string {
+	return b.freq
+}
+
+// Update 用快照更新K线, seconds是快照的时刻, 当日的秒数, 返回是否有K线收盘
+func (b *IntradayBarBuilder) Update(snapshot factors.QuoteSnapshot, seconds int) bool {
+	end, ok := IntradayBarEnd(seconds, b.period)
+	if !ok || snapshot.Price <= 0 {
+		return false
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	securityCode := snapshot.SecurityCode
+	state, found := b.states[securityCode]
+	if !found || state.date != snapshot.Date {
+		state = &intradayBarState{date: snapshot.Date}
+		b.states[securityCode] = state
+	}
+	closed := false
+	if state.end > 0 && end > state.end {
+		state.close()
+		closed = true
+	}
+	volume := max(snapshot.Vol-state.vol, 0)
+	amount := max(snapshot.Amount-state.amount, 0)
+	state.vol, state.amount = snapshot.Vol, snapshot.Amount
+	if end <= state.lastEnd {
+		// 已经收盘的K线的迟到快照, 只更新累计值
+		return closed
+	}
+	if state.end == 0 {
+		state.end = end
+		state.bar = base.KLine{
+			Date:     snapshot.Date,
+			Open:     snapshot.Price,
+			Close:    snapshot.Price,
+			High:     snapshot.Price,
+			Low:      snapshot.Price,
+			Datetime: fmt.Sprintf("%s %02d:%02d:00", snapshot.Date, end/3600, end%3600/60),
+		}
+	}
+	bar := &state.bar
+	bar.Close = snapshot.Price
+	bar.High = max(bar.High, snapshot.Price)
+	bar.Low = min(bar.Low, snapshot.Price)
+	// 成交量的单位从手改成股, 和K线缓存一致
+	bar.Volume += float64(volume * 100)
+	bar.Amount += amount
+	return closed
+}
+
+// Expire 收盘结束时刻已经超过seconds的K线, 返回有K线收盘的证券代码
+func (b *IntradayBarBuilder) Expire(seconds int) []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	var codes []string
+	for securityCode, state := range b.states {
+		if state.end > 0 && seconds >= state.end+intradayBarExpiration {
+			state.close()
+			codes = append(codes, securityCode)
+		}
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// Flush 收盘全部正在合成的K线, 返回有K线收盘的证券代码
+//
+//	收盘之后不会再有新的快照, 最后一根K线不等待过期, 盘中跟踪退出前调用
+func (b *IntradayBarBuilder) Flush() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	var codes []string
+	for securityCode, state := range b.states {
+		if state.end > 0 {
+			state.close()
+			codes = append(codes, securityCode)
+		}
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// Bars 证券当日已经收盘的K线
+func (b *IntradayBarBuilder) Bars(securityCode string) []base.KLine {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	state, ok := b.states[securityCode]
+	if !ok {
+		return nil
+	}
+	return slices.Clone(state.bars)
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"gitee.com/quant1x/engine/factors"
+)
+
+func hms(h, m, s int) int {
+	return h*3600 + m*60 + s
+}
+
+func TestIntradayBarEnd(t *testing.T) {
+	tests := []struct {
+		seconds int
+		period  int
+		want    int
+		ok      bool
+	}{
+		{hms(9, 10, 0), 5, 0, false},
+		{hms(9, 25, 3), 5, hms(9, 35, 0), true},
+		{hms(9, 30, 0), 5, hms(9, 35, 0), true},
+		{hms(9, 35, 0), 5, hms(9, 35, 0), true},
+		{hms(9, 35, 1), 5, hms(9, 40, 0), true},
+		{hms(11, 30, 2), 5, hms(11, 30, 0), true},
+		{hms(12, 10, 0), 5, hms(11, 30, 0), true},
+		{hms(13, 0, 1), 5, hms(13, 5, 0), true},
+		{hms(15, 0, 3), 5, hms(15, 0, 0), true},
+		{hms(10, 31, 0), 60, hms(11, 30, 0), true},
+		{hms(13, 30, 0), 60, hms(14, 0, 0), true},
+		{hms(14, 59, 0), 1, hms(14, 59, 0), true},
+	}
+	for _, tt := range tests {
+		got, ok := IntradayBarEnd(tt.seconds, tt.period)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("IntradayBarEnd(%d, %d) = %d, %v, want %d, %v", tt.seconds, tt.period, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestIntradayFrequency(t *testing.T) {
+	freq, minutes, err := IntradayFrequency("5m")
+	if err != nil || freq != "5min" || minutes != 5 {
+		t.Fatalf("IntradayFrequency(5m) = %s, %d, %v", freq, minutes, err)
+	}
+	freq, minutes, err = IntradayFrequency("2h")
+	if err != nil || freq != "120min" || minutes != 120 {
+		t.Fatalf("IntradayFrequency(2h) = %s, %d, %v", freq, minutes, err)
+	}
+	for _, v := range []string{"1d", "7x", "0min"} {
+		if _, _, err = IntradayFrequency(v); !errors.Is(err, ErrIntradayFrequency) {
+			t.Fatalf("IntradayFrequency(%s) error = %v", v, err)
+		}
+	}
+}
+
+func TestIntradayBarBuilder(t *testing.T) {
+	builder, err := NewIntradayBarBuilder("5min")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := func(price float64, vol int, amount float64) factors.QuoteSnapshot {
+		return factors.QuoteSnapshot{Date: "2024-01-02", SecurityCode: "sh600600", Price: price, Vol: vol, Amount: amount}
+	}
+	if builder.Update(snapshot(10.0, 100, 1000), hms(9, 25, 3)) {
+		t.Fatal("第一个快照不应该收盘K线")
+	}
+	builder.Update(snapshot(10.5, 150, 1500), hms(9, 33, 0))
+	builder.Update(snapshot(9.8, 200, 2000), hms(9, 35, 0))
+	if !builder.Update(snapshot(10.1, 260, 2600), hms(9, 35, 3)) {
+		t.Fatal("下一根K线的快照应该收盘上一根K线")
+	}
+	bars := builder.Bars("sh600600")
+	if len(bars) != 1 {
+		t.Fatalf("bars = %d, want 1", len(bars))
+	}
+	bar := bars[0]
+	if bar.Open != 10.0 || bar.High != 10.5 || bar.Low != 9.8 || bar.Close != 9.8 || bar.Volume != 20000 || bar.Amount != 2000 {
+		t.Fatalf("bar = %+v", bar)
+	}
+	if bar.Datetime != "2024-01-02 09:35:00" {
+		t.Fatalf("bar.Datetime = %s", bar.Datetime)
+	}
+	if codes := builder.Expire(hms(9, 40, 1)); len(codes) != 0 {
+		t.Fatalf("未到过期时间, codes = %v", codes)
+	}
+	if codes := builder.Expire(hms(9, 40, 5)); len(codes) != 1 {
+		t.Fatalf("过期收盘, codes = %v", codes)
+	}
+	bars = builder.Bars("sh600600")
+	if len(bars) != 2 || bars[1].Volume != 6000 {
+		t.Fatalf("bars = %+v", bars)
+	}
+	// 已经收盘的K线的迟到快照不生成新K线
+	builder.Update(snapshot(10.2, 270, 2700), hms(9, 40, 0))
+	if codes := builder.Expire(hms(10, 0, 0)); len(codes) != 0 {
+		t.Fatalf("迟到快照不应该生成K线, codes = %v", codes)
+	}
+	// 收盘的最后一根K线不等待过期
+	builder.Update(snapshot(10.3, 300, 3000), hms(14, 59, 58))
+	builder.Update(snapshot(10.4, 320, 3200), hms(15, 0, 1))
+	if codes := builder.Flush(); len(codes) != 1 {
+		t.Fatalf("收盘, codes = %v", codes)
+	}
+	bars = builder.Bars("sh600600")
+	if last := bars[len(bars)-1]; len(bars) != 3 || last.Datetime != "2024-01-02 15:00:00" || last.Close != 10.4 {
+		t.Fatalf("bars = %+v", bars)
+	}
+	if codes := builder.Flush(); len(codes) != 0 {
+		t.Fatalf("重复收盘, codes = %v", codes)
+	}
+}
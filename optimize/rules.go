@@ -0,0 +1,149 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gitee.com/quant1x/engine/config"
+)
+
+const (
+	rangeMin = "min" // 范围参数的最小值后缀
+	rangeMax = "max" // 范围参数的最大值后缀
+)
+
+var (
+	ErrParameter = errors.New("不支持的规则参数")
+)
+
+var (
+	typeNumberRange = reflect.TypeOf(config.NumberRange{})
+)
+
+// 规则参数中可以优化的字段
+type ruleField struct {
+	name    string // yaml字段名
+	index   int
+	kind    reflect.Kind
+	isRange bool
+}
+
+// 按yaml字段名查找规则参数, 只支持数值范围、浮点和整型字段
+func lookupRuleField(name string) (ruleField, error) {
+	typ := reflect.TypeOf(config.RuleParameter{})
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if tag != name {
+			continue
+		}
+		switch {
+		case sf.Type == typeNumberRange:
+			return ruleField{name: tag, index: i, isRange: true}, nil
+		case sf.Type.Kind() == reflect.Float64 || sf.Type.Kind() == reflect.Int:
+			return ruleField{name: tag, index: i, kind: sf.Type.Kind()}, nil
+		}
+		break
+	}
+	return ruleField{}, fmt.Errorf("%w: %s", ErrParameter, name)
+}
+
+// 拆分参数名, 比如volume_ratio.max拆分成volume_ratio和max
+func splitName(name string) (field ruleField, bound string, err error) {
+	fieldName, bound, _ := strings.Cut(name, ".")
+	field, err = lookupRuleField(fieldName)
+	if err != nil {
+		return
+	}
+	if field.isRange && bound != rangeMin && bound != rangeMax {
+		return field, bound, fmt.Errorf("%w: %s, 范围参数需要.min或.max后缀", ErrParameter, name)
+	}
+	if !field.isRange && len(bound) > 0 {
+		return field, bound, fmt.Errorf("%w: %s, 只有范围参数可以带后缀", ErrParameter, name)
+	}
+	return field, bound, nil
+}
+
+// ValidateSpace 检查搜索空间的参数名是否都是可以优化的规则参数
+func ValidateSpace(space Space) error {
+	for _, d := range space {
+		if _, _, err := splitName(d.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyParams 把参数设置到规则参数的副本上, 没有优化的字段保持原值
+func ApplyParams(rules config.RuleParameter, params Params) (config.RuleParameter, error) {
+	v := reflect.ValueOf(&rules).Elem()
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		value := params[name]
+		field, bound, err := splitName(name)
+		if err != nil {
+			return rules, err
+		}
+		fv := v.Field(field.index)
+		switch {
+		case field.isRange:
+			r := fv.Interface().(config.NumberRange)
+			lower, upper := r.Min(), r.Max()
+			if bound == rangeMin {
+				lower = value
+			} else {
+				upper = value
+			}
+			fv.Set(reflect.ValueOf(config.NewNumberRange(lower, upper)))
+		case field.kind == reflect.Int:
+			fv.SetInt(int64(math.Round(value)))
+		default:
+			fv.SetFloat(value)
+		}
+	}
+	return rules, nil
+}
+
+// RulesYaml 输出优化过的规则参数, 可以直接粘贴到策略配置的rules下面
+//
+//	范围参数只调整了一端时, 另一端保持原配置的值
+func RulesYaml(rules config.RuleParameter, space Space) string {
+	v := reflect.ValueOf(rules)
+	var fields []ruleField
+	for _, d := range space {
+		field, _, err := splitName(d.Name)
+		if err != nil || slices.ContainsFunc(fields, func(e ruleField) bool { return e.index == field.index }) {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	slices.SortFunc(fields, func(a, b ruleField) int {
+		return a.index - b.index
+	})
+	var builder strings.Builder
+	builder.WriteString("rules:\n")
+	for _, field := range fields {
+		fv := v.Field(field.index)
+		var text string
+		switch {
+		case field.isRange:
+			r := fv.Interface().(config.NumberRange)
+			text = strconv.Quote(r.Text())
+		case field.kind == reflect.Int:
+			text = strconv.FormatInt(fv.Int(), 10)
+		default:
+			text = strconv.FormatFloat(fv.Float(), 'f', -1, 64)
+		}
+		builder.WriteString(fmt.Sprintf("  %s: %s\n", field.name, text))
+	}
+	return builder.String()
+}
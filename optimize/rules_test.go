@@ -0,0 +1,43 @@
+package optimize
+
+import (
+	"strings"
+	"testing"
+
+	"gitee.com/quant1x/engine/config"
+)
+
+func TestApplyParams(t *testing.T) {
+	var rules config.RuleParameter
+	_ = rules.VolumeRatio.Parse("0.382~2.800")
+	params := Params{
+		"volume_ratio.max":           2.5,
+		"open_change_rate.min":       -3,
+		"maximum_increase_within_5d": 15,
+		"sectors_top_n":              4.6,
+	}
+	tuned, err := ApplyParams(rules, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tuned.VolumeRatio.Min() != 0.382 || tuned.VolumeRatio.Max() != 2.5 {
+		t.Errorf("volume_ratio = %s", tuned.VolumeRatio)
+	}
+	if rules.VolumeRatio.Max() != 2.8 {
+		t.Errorf("ApplyParams should not modify the original rules")
+	}
+	if tuned.MaximumIncreaseWithin5days != 15 || tuned.SectorsTopN != 5 {
+		t.Errorf("float/int fields not applied: %+v", tuned)
+	}
+	space, _ := ParseSpace("volume_ratio.max=2:3:0.5,maximum_increase_within_5d=10:20:5")
+	text := RulesYaml(tuned, space)
+	want := "rules:\n  maximum_increase_within_5d: 15\n  volume_ratio: \"0.382~2.5\"\n"
+	if text != want {
+		t.Errorf("RulesYaml() = %q, want %q", text, want)
+	}
+	for _, name := range []string{"volume_ratio", "gap_down", "price.avg", "sectors_top_n.max", "unknown"} {
+		if _, err := ApplyParams(rules, Params{name: 1}); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("ApplyParams(%s) = %v", name, err)
+		}
+	}
+}
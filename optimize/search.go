@@ -0,0 +1,252 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	MethodGrid   = "grid"   // 网格搜索
+	MethodRandom = "random" // 随机搜索
+	MethodBayes  = "bayes"  // 贝叶斯优化, TPE
+)
+
+const (
+	defaultTrials    = 50   // 随机搜索和贝叶斯优化默认的试验次数
+	maxGridSize      = 5000 // 网格搜索的组合数上限
+	tpeGamma         = 0.25 // TPE: 得分最高的25%作为好的样本
+	tpeCandidates    = 24   // TPE: 每次从好的样本分布中抽取的候选数
+	tpeMinBandwidth  = 0.05 // TPE: 核函数的最小带宽, 取值已归一化到[0,1]
+	tpeMaxDuplicates = 10   // TPE: 抽到重复参数时最多重试的次数
+)
+
+var (
+	ErrMethod   = errors.New("不支持的搜索方法")
+	ErrGridStep = errors.New("网格搜索的每个维度都必须指定步长")
+	ErrGridSize = errors.New("网格搜索的组合数太多")
+)
+
+// Searcher 参数搜索, 得分越高越好
+type Searcher interface {
+	// Next 返回最多n组待评估的参数, 返回空表示搜索结束
+	Next(n int) []Params
+	// Observe 反馈一组参数的得分
+	Observe(params Params, score float64)
+}
+
+// NewSearcher 创建参数搜索
+//
+//	method: grid, random, bayes
+//	trials: 随机搜索和贝叶斯优化的试验次数, 小于等于0时取默认值, 网格搜索忽略这个参数
+//	seed: 随机数种子, 相同的种子得到相同的搜索序列
+func NewSearcher(method string, space Space, trials int, seed int64) (Searcher, error) {
+	if len(space) == 0 {
+		return nil, ErrSpaceEmpty
+	}
+	if trials <= 0 {
+		trials = defaultTrials
+	}
+	rnd := rand.New(rand.NewSource(seed))
+	switch method {
+	case MethodGrid:
+		return newGridSearcher(space)
+	case MethodRandom:
+		return &randomSearcher{space: space, rnd: rnd, remaining: trials}, nil
+	case MethodBayes:
+		return &bayesSearcher{
+			space:     space,
+			rnd:       rnd,
+			remaining: trials,
+			startup:   max(len(space)+1, trials/5),
+			seen:      map[string]struct{}{},
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrMethod, method)
+}
+
+// 在搜索空间内均匀随机取一组参数
+func randomParams(space Space, rnd *rand.Rand) Params {
+	params := make(Params, len(space))
+	for _, d := range space {
+		if values := d.Values(); len(values) > 0 {
+			params[d.Name] = values[rnd.Intn(len(values))]
+		} else {
+			params[d.Name] = d.Snap(d.Min + rnd.Float64()*(d.Max-d.Min))
+		}
+	}
+	return params
+}
+
+// 网格搜索, 按维度顺序遍历全部组合
+type gridSearcher struct {
+	space  Space
+	values [][]float64
+	size   int
+	index  int
+}
+
+func newGridSearcher(space Space) (*gridSearcher, error) {
+	g := &gridSearcher{space: space, size: 1}
+	for _, d := range space {
+		values := d.Values()
+		if len(values) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrGridStep, d.Name)
+		}
+		g.values = append(g.values, values)
+		g.size *= len(values)
+		if g.size > maxGridSize {
+			return nil, fmt.Errorf("%w: 超过%d", ErrGridSize, maxGridSize)
+		}
+	}
+	return g, nil
+}
+
+func (g *gridSearcher) Next(n int) []Params {
+	var list []Params
+	for ; len(list) < n && g.index < g.size; g.index++ {
+		params := make(Params, len(g.space))
+		k := g.index
+		// 最后一个维度变化最快
+		for i := len(g.space) - 1; i >= 0; i-- {
+			values := g.values[i]
+			params[g.space[i].Name] = values[k%len(values)]
+			k /= len(values)
+		}
+		list = append(list, params)
+	}
+	return list
+}
+
+func (g *gridSearcher) Observe(Params, float64) {}
+
+// 随机搜索
+type randomSearcher struct {
+	space     Space
+	rnd       *rand.Rand
+	remaining int
+}
+
+func (r *randomSearcher) Next(n int) []Params {
+	var list []Params
+	for ; len(list) < n && r.remaining > 0; r.remaining-- {
+		list = append(list, randomParams(r.space, r.rnd))
+	}
+	return list
+}
+
+func (r *randomSearcher) Observe(Params, float64) {}
+
+// 贝叶斯优化的一次观测, 参数已归一化到[0,1]
+type observation struct {
+	x     []float64
+	score float64
+}
+
+// 贝叶斯优化, TPE(Tree-structured Parzen Estimator)
+//
+//	前startup次随机取样, 之后按得分把观测分成好坏两组, 每个维度分别用高斯核估计
+//	好的分布l(x)和坏的分布g(x), 从l(x)中抽取候选, 取l(x)/g(x)最大的一个
+type bayesSearcher struct {
+	space     Space
+	rnd       *rand.Rand
+	remaining int
+	startup   int
+	history   []observation
+	seen      map[string]struct{}
+}
+
+func (b *bayesSearcher) Next(n int) []Params {
+	var list []Params
+	for ; len(list) < n && b.remaining > 0; b.remaining-- {
+		var params Params
+		for i := 0; i < tpeMaxDuplicates; i++ {
+			if len(b.history) < b.startup {
+				params = randomParams(b.space, b.rnd)
+			} else {
+				params = b.suggest()
+			}
+			if _, ok := b.seen[params.Key()]; !ok {
+				break
+			}
+		}
+		b.seen[params.Key()] = struct{}{}
+		list = append(list, params)
+	}
+	return list
+}
+
+func (b *bayesSearcher) Observe(params Params, score float64) {
+	if math.IsNaN(score) {
+		score = math.Inf(-1)
+	}
+	x := make([]float64, len(b.space))
+	for i, d := range b.space {
+		x[i] = normalize(d, params[d.Name])
+	}
+	b.history = append(b.history, observation{x: x, score: score})
+	b.seen[params.Key()] = struct{}{}
+}
+
+// 按TPE给出一组参数
+func (b *bayesSearcher) suggest() Params {
+	history := make([]observation, len(b.history))
+	copy(history, b.history)
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].score > history[j].score
+	})
+	count := int(math.Ceil(tpeGamma * float64(len(history))))
+	count = max(1, min(count, len(history)-1))
+	good, bad := history[:count], history[count:]
+	params := make(Params, len(b.space))
+	for i, d := range b.space {
+		goodX := make([]float64, len(good))
+		for k, v := range good {
+			goodX[k] = v.x[i]
+		}
+		badX := make([]float64, len(bad))
+		for k, v := range bad {
+			badX[k] = v.x[i]
+		}
+		goodWidth, badWidth := bandwidth(len(goodX)), bandwidth(len(badX))
+		best, bestRatio := 0.0, math.Inf(-1)
+		for k := 0; k < tpeCandidates; k++ {
+			center := goodX[b.rnd.Intn(len(goodX))]
+			x := math.Max(0, math.Min(1, center+b.rnd.NormFloat64()*goodWidth))
+			ratio := parzen(x, goodX, goodWidth) / parzen(x, badX, badWidth)
+			if ratio > bestRatio {
+				best, bestRatio = x, ratio
+			}
+		}
+		params[d.Name] = d.Snap(d.Min + best*(d.Max-d.Min))
+	}
+	return params
+}
+
+// 归一化到[0,1]
+func normalize(d Dimension, v float64) float64 {
+	if d.Max <= d.Min {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (v-d.Min)/(d.Max-d.Min)))
+}
+
+// 核函数的带宽, 样本越多越窄
+func bandwidth(n int) float64 {
+	if n < 1 {
+		return 1
+	}
+	return math.Max(tpeMinBandwidth, 0.3*math.Pow(float64(n), -0.2))
+}
+
+// Parzen窗密度估计, 混合[0,1]上的均匀分布作为先验, 避免密度为0
+func parzen(x float64, points []float64, width float64) float64 {
+	density := 1.0
+	for _, p := range points {
+		z := (x - p) / width
+		density += math.Exp(-0.5*z*z) / (width * math.Sqrt(2*math.Pi))
+	}
+	return density / float64(len(points)+1)
+}
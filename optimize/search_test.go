@@ -0,0 +1,93 @@
+package optimize
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGridSearcher(t *testing.T) {
+	space, _ := ParseSpace("a=0:2:1,b=0:1:1")
+	s, err := NewSearcher(MethodGrid, space, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for {
+		list := s.Next(4)
+		if len(list) == 0 {
+			break
+		}
+		for _, p := range list {
+			seen[p.Key()] = true
+		}
+	}
+	if len(seen) != 6 {
+		t.Errorf("grid visited %d points, want 6", len(seen))
+	}
+	space, _ = ParseSpace("a=0:1")
+	if _, err = NewSearcher(MethodGrid, space, 0, 1); err == nil {
+		t.Errorf("grid search without step should fail")
+	}
+	if _, err = NewSearcher("anneal", space, 0, 1); err == nil {
+		t.Errorf("unknown method should fail")
+	}
+}
+
+func TestRandomSearcher(t *testing.T) {
+	space, _ := ParseSpace("a=-3:0:0.5,b=1:2")
+	s1, _ := NewSearcher(MethodRandom, space, 20, 7)
+	s2, _ := NewSearcher(MethodRandom, space, 20, 7)
+	l1, l2 := s1.Next(100), s2.Next(100)
+	if len(l1) != 20 {
+		t.Fatalf("len = %d, want 20", len(l1))
+	}
+	for i := range l1 {
+		if l1[i].Key() != l2[i].Key() {
+			t.Fatalf("same seed should give the same sequence")
+		}
+		a, b := l1[i]["a"], l1[i]["b"]
+		if a < -3 || a > 0 || math.Mod(a*2, 1) != 0 || b < 1 || b > 2 {
+			t.Errorf("params out of space: %v", l1[i])
+		}
+	}
+	if len(s1.Next(1)) != 0 {
+		t.Errorf("random search should stop after trials")
+	}
+}
+
+// 单峰函数, 最优值在a=2, b=-1
+func objective(p Params) float64 {
+	return -(p["a"]-2)*(p["a"]-2) - (p["b"]+1)*(p["b"]+1)
+}
+
+func runSearcher(s Searcher, batch int) float64 {
+	best := math.Inf(-1)
+	for {
+		list := s.Next(batch)
+		if len(list) == 0 {
+			break
+		}
+		for _, p := range list {
+			score := objective(p)
+			s.Observe(p, score)
+			best = math.Max(best, score)
+		}
+	}
+	return best
+}
+
+func TestBayesSearcher(t *testing.T) {
+	space, _ := ParseSpace("a=-10:10,b=-10:10")
+	// 贝叶斯优化在多数种子上应该不差于随机搜索
+	better := 0
+	for seed := int64(1); seed <= 10; seed++ {
+		bayes, _ := NewSearcher(MethodBayes, space, 60, seed)
+		random, _ := NewSearcher(MethodRandom, space, 60, seed)
+		if runSearcher(bayes, 4) >= runSearcher(random, 4) {
+			better++
+		}
+	}
+	if better < 7 {
+		t.Errorf("bayes beat random on %d/10 seeds", better)
+	}
+}
@@ -0,0 +1,149 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrSpaceFormat = errors.New("搜索空间格式错误")
+	ErrSpaceEmpty  = errors.New("搜索空间为空")
+)
+
+// Dimension 搜索空间的一个维度
+//
+//	Step大于0时取值是离散的: Min, Min+Step, ..., 不超过Max
+type Dimension struct {
+	Name string  // 参数名, 规则参数的yaml字段名, 范围字段加.min或.max后缀, 比如volume_ratio.max
+	Min  float64 // 最小值
+	Max  float64 // 最大值
+	Step float64 // 步长, 0表示连续取值
+}
+
+// Values 离散的取值列表, 连续取值的维度返回nil
+func (d Dimension) Values() []float64 {
+	if d.Step <= 0 {
+		return nil
+	}
+	n := int(math.Floor((d.Max-d.Min)/d.Step+1e-9)) + 1
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = round(d.Min + float64(i)*d.Step)
+	}
+	return values
+}
+
+// Snap 把v限制在取值范围内, 离散的维度取最近的一个值
+func (d Dimension) Snap(v float64) float64 {
+	v = math.Max(d.Min, math.Min(d.Max, v))
+	if d.Step <= 0 {
+		return round(v)
+	}
+	i := math.Round((v - d.Min) / d.Step)
+	v = d.Min + i*d.Step
+	if v > d.Max+1e-9 {
+		v -= d.Step
+	}
+	return round(v)
+}
+
+// 消除浮点运算的误差, 保留10位小数
+func round(v float64) float64 {
+	return math.Round(v*1e10) / 1e10
+}
+
+// Space 搜索空间
+type Space []Dimension
+
+// ParseSpace 解析搜索空间
+//
+//	格式: 参数名=最小值:最大值[:步长], 多个维度用逗号分隔
+//	比如: volume_ratio.max=1.5:3.0:0.5,open_change_rate.min=-3:0:1
+func ParseSpace(text string) (Space, error) {
+	var space Space
+	for _, item := range strings.Split(text, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		name, value, found := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if !found || len(name) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrSpaceFormat, item)
+		}
+		arr := strings.Split(value, ":")
+		if len(arr) < 2 || len(arr) > 3 {
+			return nil, fmt.Errorf("%w: %s", ErrSpaceFormat, item)
+		}
+		numbers := make([]float64, len(arr))
+		for i, v := range arr {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+				return nil, fmt.Errorf("%w: %s", ErrSpaceFormat, item)
+			}
+			numbers[i] = f
+		}
+		d := Dimension{Name: name, Min: numbers[0], Max: numbers[1]}
+		if d.Min > d.Max {
+			d.Min, d.Max = d.Max, d.Min
+		}
+		if len(numbers) == 3 {
+			d.Step = numbers[2]
+			if d.Step <= 0 {
+				return nil, fmt.Errorf("%w: 步长必须大于0, %s", ErrSpaceFormat, item)
+			}
+		}
+		if slices.ContainsFunc(space, func(e Dimension) bool { return e.Name == d.Name }) {
+			return nil, fmt.Errorf("%w: 参数重复, %s", ErrSpaceFormat, d.Name)
+		}
+		space = append(space, d)
+	}
+	if len(space) == 0 {
+		return nil, ErrSpaceEmpty
+	}
+	return space, nil
+}
+
+// Size 离散空间的组合数, 存在连续取值的维度时返回0
+func (s Space) Size() int {
+	size := 1
+	for _, d := range s {
+		values := d.Values()
+		if len(values) == 0 {
+			return 0
+		}
+		size *= len(values)
+	}
+	return size
+}
+
+// Params 一组参数, 参数名 -> 数值
+type Params map[string]float64
+
+// Key 参数的唯一标识, 按参数名排序, 用于去重
+func (p Params) Key() string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var builder strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(name)
+		builder.WriteByte('=')
+		builder.WriteString(strconv.FormatFloat(p[name], 'f', -1, 64))
+	}
+	return builder.String()
+}
+
+// String 和Key相同
+func (p Params) String() string {
+	return p.Key()
+}
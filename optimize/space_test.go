@@ -0,0 +1,61 @@
+package optimize
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseSpace(t *testing.T) {
+	space, err := ParseSpace("volume_ratio.max=1.5:3.0:0.5, open_change_rate.min=0:-3:1,sentiment.min=30:50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(space) != 3 {
+		t.Fatalf("len(space) = %d, want 3", len(space))
+	}
+	if got := space[0].Values(); !slices.Equal(got, []float64{1.5, 2, 2.5, 3}) {
+		t.Errorf("values = %v", got)
+	}
+	if got := space[1].Values(); !slices.Equal(got, []float64{-3, -2, -1, 0}) {
+		t.Errorf("values = %v", got)
+	}
+	if space[2].Values() != nil || space.Size() != 0 {
+		t.Errorf("continuous dimension should have no values")
+	}
+	if got := space[:2].Size(); got != 16 {
+		t.Errorf("Size() = %d, want 16", got)
+	}
+	for _, text := range []string{"", "volume_ratio.max", "a=1", "a=1:2:0", "a=x:2", "a=1:2,a=2:3"} {
+		if _, err := ParseSpace(text); err == nil {
+			t.Errorf("ParseSpace(%q) should fail", text)
+		} else if !errors.Is(err, ErrSpaceFormat) && !errors.Is(err, ErrSpaceEmpty) {
+			t.Errorf("ParseSpace(%q) = %v", text, err)
+		}
+	}
+}
+
+func TestDimensionSnap(t *testing.T) {
+	d := Dimension{Name: "a", Min: 0.1, Max: 0.35, Step: 0.1}
+	tests := []struct {
+		v, want float64
+	}{
+		{v: -1, want: 0.1},
+		{v: 0.14, want: 0.1},
+		{v: 0.26, want: 0.3},
+		{v: 0.35, want: 0.3},
+		{v: 9, want: 0.3},
+	}
+	for _, v := range tests {
+		if got := d.Snap(v.v); got != v.want {
+			t.Errorf("Snap(%v) = %v, want %v", v.v, got, v.want)
+		}
+	}
+}
+
+func TestParamsKey(t *testing.T) {
+	p := Params{"b": 2, "a": 1.5}
+	if got := p.Key(); got != "a=1.5,b=2" {
+		t.Errorf("Key() = %q", got)
+	}
+}
@@ -0,0 +1,307 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/models"
+	"gitee.com/quant1x/engine/tracker"
+)
+
+const (
+	MetricSharpe  = "sharpe"  // 夏普比率
+	MetricReturn  = "return"  // 累计收益率
+	MetricAnnual  = "annual"  // 年化收益率
+	MetricCalmar  = "calmar"  // 年化收益率/最大回撤
+	MetricWinRate = "winrate" // 胜率
+)
+
+const (
+	defaultTrainDays = 60     // 默认的样本内交易日数
+	defaultTestDays  = 20     // 默认的样本外交易日数
+	defaultCapital   = 100000 // 默认的初始资金
+)
+
+var (
+	ErrMetric       = errors.New("不支持的评价指标")
+	ErrStrategy     = errors.New("策略没有配置")
+	ErrNotEnoughDay = errors.New("交易日数不足以切分样本内和样本外")
+)
+
+// Options 参数优化选项
+type Options struct {
+	StrategyId uint64   // 策略编号
+	Dates      []string // 全部交易日
+	Space      Space    // 搜索空间
+	Method     string   // 搜索方法: grid, random, bayes
+	Trials     int      // 试验次数
+	Train      int      // 样本内交易日数
+	Test       int      // 样本外交易日数, 也是窗口滚动的步长
+	Metric     string   // 评价指标
+	MinTrades  int      // 样本内最少的卖出笔数, 不足时得分视为最低
+	Capital    float64  // 初始资金
+	Workers    int      // 并发数
+	Seed       int64    // 随机数种子
+	Progress   func(fold, total int)
+}
+
+// Trial 一次试验
+type Trial struct {
+	Params  Params
+	Score   float64
+	Summary tracker.PortfolioSummary
+}
+
+// Fold 一个滚动窗口, 样本内搜索最优参数, 样本外验证
+type Fold struct {
+	TrainBegin  string
+	TrainEnd    string
+	TestBegin   string
+	TestEnd     string
+	Trials      int    // 样本内的试验次数
+	Best        Params // 样本内的最优参数
+	InSample    Trial  // 最优参数的样本内表现
+	OutOfSample Trial  // 最优参数的样本外表现
+}
+
+// Result 参数优化结果
+type Result struct {
+	Strategy string
+	Metric   string
+	Folds    []Fold
+	Final    Trial                // 最近train个交易日上的最优参数, 用于实盘
+	Rules    config.RuleParameter // 应用最优参数后的规则
+	Yaml     string               // 可以直接粘贴的规则参数
+}
+
+// Efficiency 样本外和样本内平均得分的比值, 越接近1说明过拟合越少
+func (r *Result) Efficiency() float64 {
+	var in, out float64
+	count := 0
+	for _, f := range r.Folds {
+		if !valid(f.InSample.Score) || !valid(f.OutOfSample.Score) {
+			continue
+		}
+		in += f.InSample.Score
+		out += f.OutOfSample.Score
+		count++
+	}
+	if count == 0 || in == 0 {
+		return math.NaN()
+	}
+	return out / in
+}
+
+func valid(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// Score 按评价指标计算组合回测的得分, 越高越好
+func Score(metric string, summary tracker.PortfolioSummary) (float64, error) {
+	switch metric {
+	case MetricSharpe:
+		return summary.Sharpe, nil
+	case MetricReturn:
+		return summary.TotalReturn, nil
+	case MetricAnnual:
+		return summary.AnnualReturn, nil
+	case MetricCalmar:
+		if summary.MaxDrawdown >= 0 {
+			// 没有回撤
+			return summary.AnnualReturn, nil
+		}
+		return summary.AnnualReturn / -summary.MaxDrawdown, nil
+	case MetricWinRate:
+		return summary.WinRate, nil
+	}
+	return math.NaN(), fmt.Errorf("%w: %s", ErrMetric, metric)
+}
+
+// 滚动切分样本内和样本外
+func splitFolds(dates []string, train, test int) [][2][]string {
+	var folds [][2][]string
+	for start := 0; start+train+test <= len(dates); start += test {
+		folds = append(folds, [2][]string{
+			dates[start : start+train],
+			dates[start+train : start+train+test],
+		})
+	}
+	return folds
+}
+
+// 优化器, 持有一次优化全部的共享状态
+type optimizer struct {
+	opts      Options
+	model     models.Strategy
+	tradeRule config.StrategyParameter
+	klines    *tracker.WideKLines
+}
+
+// 用一批参数在指定日期上执行组合回测
+func (o *optimizer) evaluate(list []Params, dates []string) ([]Trial, error) {
+	portfolios := make([]*tracker.Portfolio, len(list))
+	for i, params := range list {
+		rules, err := ApplyParams(o.tradeRule.Rules, params)
+		if err != nil {
+			return nil, err
+		}
+		tradeRule := o.tradeRule
+		tradeRule.Rules = rules
+		portfolios[i] = tracker.NewPortfolio(o.model, tradeRule, o.opts.Capital).WithKLines(o.klines)
+	}
+	results := tracker.RunPortfolios(portfolios, dates, o.opts.Workers)
+	trials := make([]Trial, len(list))
+	for i, result := range results {
+		score, err := Score(o.opts.Metric, result.Summary)
+		if err != nil {
+			return nil, err
+		}
+		if result.Summary.Trades < o.opts.MinTrades || !valid(score) {
+			score = math.Inf(-1)
+		}
+		trials[i] = Trial{Params: list[i], Score: score, Summary: result.Summary}
+	}
+	return trials, nil
+}
+
+// 在样本内搜索最优参数
+func (o *optimizer) search(dates []string, seed int64) (best Trial, count int, err error) {
+	searcher, err := NewSearcher(o.opts.Method, o.opts.Space, o.opts.Trials, seed)
+	if err != nil {
+		return best, 0, err
+	}
+	// 贝叶斯优化每批只取并发数个参数, 以便尽早利用观测结果
+	batch := math.MaxInt
+	if o.opts.Method == MethodBayes {
+		batch = o.opts.Workers
+	}
+	best.Score = math.Inf(-1)
+	scores := map[string]Trial{}
+	for {
+		list := searcher.Next(batch)
+		if len(list) == 0 {
+			break
+		}
+		// 相同的参数只回测一次
+		var pending []Params
+		for _, params := range list {
+			if _, ok := scores[params.Key()]; !ok {
+				pending = append(pending, params)
+			}
+		}
+		if len(pending) > 0 {
+			trials, err := o.evaluate(pending, dates)
+			if err != nil {
+				return best, count, err
+			}
+			for _, trial := range trials {
+				scores[trial.Params.Key()] = trial
+			}
+		}
+		for _, params := range list {
+			trial := scores[params.Key()]
+			searcher.Observe(params, trial.Score)
+			count++
+			if best.Params == nil || trial.Score > best.Score {
+				best = trial
+			}
+		}
+	}
+	return best, count, nil
+}
+
+// WalkForward 滚动窗口参数优化
+//
+//	每个窗口在样本内搜索最优参数, 然后在紧接着的样本外验证, 窗口按样本外的长度滚动.
+//	最后在最近的train个交易日上再搜索一次, 作为推荐的参数.
+func WalkForward(opts Options) (*Result, error) {
+	if opts.Train <= 0 {
+		opts.Train = defaultTrainDays
+	}
+	if opts.Test <= 0 {
+		opts.Test = defaultTestDays
+	}
+	if len(opts.Metric) == 0 {
+		opts.Metric = MetricSharpe
+	}
+	if opts.Capital <= 0 {
+		opts.Capital = defaultCapital
+	}
+	opts.Workers = max(1, opts.Workers)
+	if _, err := Score(opts.Metric, tracker.PortfolioSummary{}); err != nil {
+		return nil, err
+	}
+	if err := ValidateSpace(opts.Space); err != nil {
+		return nil, err
+	}
+	folds := splitFolds(opts.Dates, opts.Train, opts.Test)
+	if len(folds) == 0 {
+		return nil, fmt.Errorf("%w: 共%d天, 样本内%d天, 样本外%d天", ErrNotEnoughDay, len(opts.Dates), opts.Train, opts.Test)
+	}
+	model, err := models.CheckoutStrategy(opts.StrategyId)
+	if err != nil {
+		return nil, err
+	}
+	// 参数优化不要求策略已启用
+	var tradeRule *config.StrategyParameter
+	for _, v := range config.TraderConfig().Strategies {
+		if v.Id == opts.StrategyId {
+			tradeRule = &v
+			break
+		}
+	}
+	if tradeRule == nil {
+		return nil, fmt.Errorf("%w: %d", ErrStrategy, opts.StrategyId)
+	}
+	o := &optimizer{
+		opts:      opts,
+		model:     model,
+		tradeRule: *tradeRule,
+		klines:    tracker.NewWideKLines(),
+	}
+	result := &Result{
+		Strategy: fmt.Sprintf("%d:%s", model.Code(), model.Name()),
+		Metric:   opts.Metric,
+	}
+	total := len(folds) + 1
+	for i, v := range folds {
+		train, test := v[0], v[1]
+		best, count, err := o.search(train, opts.Seed+int64(i))
+		if err != nil {
+			return nil, err
+		}
+		trials, err := o.evaluate([]Params{best.Params}, test)
+		if err != nil {
+			return nil, err
+		}
+		result.Folds = append(result.Folds, Fold{
+			TrainBegin:  train[0],
+			TrainEnd:    train[len(train)-1],
+			TestBegin:   test[0],
+			TestEnd:     test[len(test)-1],
+			Trials:      count,
+			Best:        best.Params,
+			InSample:    best,
+			OutOfSample: trials[0],
+		})
+		if opts.Progress != nil {
+			opts.Progress(i+1, total)
+		}
+	}
+	latest := opts.Dates[len(opts.Dates)-opts.Train:]
+	result.Final, _, err = o.search(latest, opts.Seed+int64(len(folds)))
+	if err != nil {
+		return nil, err
+	}
+	if opts.Progress != nil {
+		opts.Progress(total, total)
+	}
+	result.Rules, err = ApplyParams(o.tradeRule.Rules, result.Final.Params)
+	if err != nil {
+		return nil, err
+	}
+	result.Yaml = RulesYaml(result.Rules, opts.Space)
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package realtime
+
+import (
+	"sync"
+
+	"gitee.com/quant1x/engine/factors"
+	"gitee.com/quant1x/engine/models"
+)
+
+// Features 个股盘中的实时特征
+//
+//	每个特征都是用最新的快照从上一个交易日的缓存增量计算的
+type Features struct {
+	Date         string                     // 快照的交易日期
+	SecurityCode string                     // 证券代码
+	UpdateTime   string                     // 快照的本地时间戳
+	data         map[string]factors.Feature // 缓存关键字 -> 特征
+}
+
+// Get 按缓存关键字获取特征, 比如history, misc, box
+func (f *Features) Get(key string) factors.Feature {
+	if f == nil {
+		return nil
+	}
+	return f.data[key]
+}
+
+var (
+	__mutexFeatures sync.RWMutex
+	__mapFeatures   = map[string]*Features{}
+)
+
+func init() {
+	// 策略通过快照读取实时特征
+	factors.RegisterRealtimeFeatures(func(key, securityCode, date string) factors.Feature {
+		features := GetFeatures(securityCode, date)
+		if features == nil {
+			return nil
+		}
+		return features.Get(key)
+	})
+}
+
+// 用快照增量计算一个证券的全部特征
+func increaseFeatures(adapters map[string]factors.FeatureRotationAdapter, securityCode string, snapshot factors.QuoteSnapshot) *Features {
+	return &Features{
+		Date:         snapshot.Date,
+		SecurityCode: securityCode,
+		UpdateTime:   factors.GetTimestamp(),
+		data:         factors.IncreaseFeatures(adapters, securityCode, snapshot),
+	}
+}
+
+// UpdateFeatures 用内存中最新的快照增量计算证券的实时特征, 返回更新的证券数
+//
+//	每次都整体替换, 没有快照的证券(停牌等)没有实时特征
+func UpdateFeatures(codes []string) int {
+	adapters := factors.FeatureRotationAdapters()
+	mapFeatures := make(map[string]*Features, len(codes))
+	for _, securityCode := range codes {
+		snapshot := models.GetStrategySnapshot(securityCode)
+		if snapshot == nil {
+			continue
+		}
+		mapFeatures[securityCode] = increaseFeatures(adapters, securityCode, *snapshot)
+	}
+	__mutexFeatures.Lock()
+	__mapFeatures = mapFeatures
+	__mutexFeatures.Unlock()
+	return len(mapFeatures)
+}
+
+// GetFeatures 获取证券指定交易日的实时特征, 没有这个交易日的盘中数据时返回nil
+func GetFeatures(securityCode, date string) *Features {
+	__mutexFeatures.RLock()
+	features, ok := __mapFeatures[securityCode]
+	__mutexFeatures.RUnlock()
+	if !ok || features.Date != date {
+		return nil
+	}
+	return features
+}
@@ -0,0 +1,75 @@
+package realtime
+
+import (
+	"math"
+	"testing"
+
+	"gitee.com/quant1x/engine/factors"
+)
+
+// 特征的增量计算和realtime的增量函数结果一致
+func TestHistoryIncreaseConsistency(t *testing.T) {
+	history := factors.NewHistory("2024-03-01", "sz002528")
+	history.CLOSE, history.HIGH, history.LOW = 5.12, 5.20, 5.01
+	history.MA2, history.MA3, history.MA4 = 5.08, 5.05, 5.02
+	history.MA9, history.MA19 = 4.93, 4.81
+	history.EMA12, history.EMA26, history.DEA = 5.01, 4.90, 0.07
+	snapshot := factors.QuoteSnapshot{Date: "2024-03-01", Price: 5.31, Open: 5.15, High: 5.33, Low: 5.12}
+	live, ok := history.Increase(snapshot).(*factors.History)
+	if !ok {
+		t.Fatalf("Increase should return *factors.History")
+	}
+	if want := IncrementalMovingAverage(history.MA4, 5, snapshot.Price); live.MA5 != want {
+		t.Errorf("ma5 = %f, want %f", live.MA5, want)
+	}
+	if want := IncrementalMovingAverage(history.MA9, 10, snapshot.Price); live.MA10 != want {
+		t.Errorf("ma10 = %f, want %f", live.MA10, want)
+	}
+	if want := IncrementalMovingAverage(history.MA19, 20, snapshot.Price); live.MA20 != want {
+		t.Errorf("ma20 = %f, want %f", live.MA20, want)
+	}
+	dif, dea, macd := IncrementalMovingAverageConvergenceDivergence(snapshot.Price, history.EMA12, history.EMA26, history.DEA, 12, 26, 9)
+	if math.Abs(live.DIF-dif) > 1e-9 || math.Abs(live.DEA-dea) > 1e-9 || math.Abs(live.MACD-macd) > 1e-9 {
+		t.Errorf("macd = (%f, %f, %f), want (%f, %f, %f)", live.DIF, live.DEA, live.MACD, dif, dea, macd)
+	}
+	if want := IncrementalExponentialMovingAverage(snapshot.Price, history.EMA12, AlphaOfExponentialMovingAverage(12)); math.Abs(live.EMA12-want) > 1e-9 {
+		t.Errorf("ema12 = %f, want %f", live.EMA12, want)
+	}
+}
+
+func TestGetFeatures(t *testing.T) {
+	__mutexFeatures.Lock()
+	__mapFeatures = map[string]*Features{
+		"sz002528": {Date: "2024-03-01", SecurityCode: "sz002528", data: map[string]factors.Feature{
+			"history": &factors.History{Code: "sz002528", MA5: 5.2},
+		}},
+	}
+	__mutexFeatures.Unlock()
+	if v, _ := GetFeatures("sz002528", "2024-03-01").Get("history").(*factors.History); v == nil || v.MA5 != 5.2 {
+		t.Errorf("live history = %+v", v)
+	}
+	if GetFeatures("sz002528", "2024-02-29") != nil {
+		t.Errorf("features of another date should be nil")
+	}
+	if GetFeatures("sz002528", "2024-03-01").Get("box") != nil {
+		t.Errorf("box should be nil")
+	}
+}
+
+func TestSnapshotRealtimeFeatures(t *testing.T) {
+	__mutexFeatures.Lock()
+	__mapFeatures = map[string]*Features{
+		"sz002528": {Date: "2024-03-01", SecurityCode: "sz002528", data: map[string]factors.Feature{
+			"history": &factors.History{Code: "sz002528", MA5: 5.2},
+			"box":     &factors.Box{Code: "sz002528", Buy: true},
+		}},
+	}
+	__mutexFeatures.Unlock()
+	snapshot := factors.QuoteSnapshot{Date: "2024-03-01", SecurityCode: "sz002528"}
+	if v := snapshot.RealtimeHistory(); v == nil || v.MA5 != 5.2 {
+		t.Errorf("live history = %+v", v)
+	}
+	if v := snapshot.RealtimeBox(); v == nil || !v.Buy {
+		t.Errorf("live box = %+v", v)
+	}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/storages"
+	"gitee.com/quant1x/engine/trader"
+	"gitee.com/quant1x/gox/logger"
+)
+
+// HttpResult 内置HTTP服务的统一返回结构
+type HttpResult struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// StartHttpServer 按配置启动内置HTTP服务
+func StartHttpServer() {
+	if !config.HttpEnable() {
+		return
+	}
+	addr := config.HttpAddr()
+	go func() {
+		logger.Infof("启动内置HTTP服务, 监听地址: %s", addr)
+		err := http.ListenAndServe(addr, NewHttpHandler())
+		if err != nil {
+			logger.Errorf("内置HTTP服务异常退出: %+v", err)
+		}
+	}()
+}
+
+// NewHttpHandler 内置HTTP服务的路由
+//
+//	GET  /api/jobs                定时任务列表及运行状态
+//	POST /api/jobs/{name}/trigger 立即执行一次定时任务
+//	GET  /api/stockpool           股票池
+//	GET  /api/positions           持仓
+//	GET  /api/results             当日策略结果
+//	GET  /api/metrics             特征的性能指标
+//	POST /api/config/reload       重新加载配置文件
+//	GET  /metrics                 Prometheus格式的定时任务指标
+func NewHttpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteJobMetrics(w)
+	})
+	mux.HandleFunc("GET /api/jobs", func(w http.ResponseWriter, r *http.Request) {
+		httpResponse(w, GetJobStatusList(), nil)
+	})
+	mux.HandleFunc("POST /api/jobs/{name}/trigger", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		err := TriggerJob(name)
+		httpResponse(w, name, err)
+	})
+	mux.HandleFunc("GET /api/stockpool", func(w http.ResponseWriter, r *http.Request) {
+		httpResponse(w, storages.GetStockPoolList(), nil)
+	})
+	mux.HandleFunc("GET /api/positions", func(w http.ResponseWriter, r *http.Request) {
+		list, err := trader.QueryHolding()
+		httpResponse(w, list, err)
+	})
+	mux.HandleFunc("GET /api/results", func(w http.ResponseWriter, r *http.Request) {
+		httpResponse(w, storages.GetTodayStrategyResults(), nil)
+	})
+	mux.HandleFunc("GET /api/metrics", func(w http.ResponseWriter, r *http.Request) {
+		httpResponse(w, storages.GetFactorMetrics(), nil)
+	})
+	mux.HandleFunc("POST /api/config/reload", func(w http.ResponseWriter, r *http.Request) {
+		err := config.ReloadConfig()
+		httpResponse(w, nil, err)
+	})
+	return mux
+}
+
+func httpResponse(w http.ResponseWriter, v any, err error) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	result := HttpResult{Status: http.StatusOK, Message: "success", Data: v}
+	if err != nil {
+		result.Status = http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrJobNotFound):
+			result.Status = http.StatusNotFound
+		case errors.Is(err, ErrJobRunning):
+			result.Status = http.StatusConflict
+		}
+		result.Message = err.Error()
+		w.WriteHeader(result.Status)
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHttpHandlerJobs(t *testing.T) {
+	handler := NewHttpHandler()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/jobs", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var result struct {
+		Status int         `json:"status"`
+		Data   []JobStatus `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Data) != len(GetJobStatusList()) {
+		t.Errorf("jobs = %d, want %d", len(result.Data), len(GetJobStatusList()))
+	}
+}
+
+func TestHttpHandlerTriggerNotFound(t *testing.T) {
+	handler := NewHttpHandler()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/jobs/not_exists/trigger", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
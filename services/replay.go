@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/engine/factors"
+	"gitee.com/quant1x/engine/market"
+	"gitee.com/quant1x/engine/models"
+	"gitee.com/quant1x/engine/realtime"
+	"gitee.com/quant1x/engine/storages"
+	"gitee.com/quant1x/engine/tracker"
+	"gitee.com/quant1x/engine/trader"
+	"gitee.com/quant1x/gox/logger"
+)
+
+var (
+	ErrReplayNoData = errors.New("no snapshots recorded") // 没有录制的快照
+	ErrReplaySpeed  = errors.New("invalid replay speed")  // 回放倍速格式错误
+)
+
+var (
+	// 回放时由虚拟时钟调度的定时任务
+	//
+	//	快照来自录制的数据, 不能调度更新快照、K线等访问网络的任务
+	replayJobs = []string{keyCronCookieCutterSell, keyCronReconcileOrders, keyCronRiskBaseline}
+)
+
+const (
+	// 录制的快照之间的最大间隔, 超过这个间隔视为休市(比如午休), 回放时不等待
+	replayMaxGap = time.Minute
+)
+
+// ReplayParameter 回放参数
+type ReplayParameter struct {
+	Date       string   // 交易日期
+	Begin      string   // 开始时间, HH:MM:SS, 为空从第一批快照开始
+	End        string   // 结束时间, HH:MM:SS, 为空到最后一批快照
+	Speed      float64  // 回放倍速, 0表示不等待
+	Capital    float64  // 回放模拟盘的初始资金
+	Strategies []uint64 // 盘中跟踪的策略编号
+}
+
+// ParseReplaySpeed 解析回放倍速, 支持10x、0.5x和10, 0表示不等待
+func ParseReplaySpeed(text string) (float64, error) {
+	text = strings.TrimSpace(strings.ToLower(text))
+	text = strings.TrimSuffix(text, "x")
+	if len(text) == 0 {
+		return 1, nil
+	}
+	speed, err := strconv.ParseFloat(text, 64)
+	if err != nil || speed < 0 {
+		return 0, ErrReplaySpeed
+	}
+	return speed, nil
+}
+
+// 回放的虚拟时钟
+//
+//	虚拟时间跟随录制的时间前进, 按倍速等待墙上时间, 超过replayMaxGap的间隔只等待replayMaxGap
+type replayClock struct {
+	*clock.Manual               // 当前的虚拟时间
+	speed         float64       // 回放倍速
+	start         time.Time     // 开始回放的墙上时间
+	elapsed       time.Duration // 扣除休市后经过的虚拟时间
+	sleep         func(d time.Duration)
+}
+
+func newReplayClock(origin time.Time, speed float64) *replayClock {
+	return &replayClock{
+		Manual: clock.NewManual(origin),
+		speed:  speed,
+		start:  time.Now(),
+		sleep:  time.Sleep,
+	}
+}
+
+// 虚拟时间前进到tm, 倍速大于0时等待对应的墙上时间
+func (c *replayClock) advanceTo(tm time.Time) {
+	gap := tm.Sub(c.Now())
+	if gap <= 0 {
+		return
+	}
+	c.Set(tm)
+	c.elapsed += min(gap, replayMaxGap)
+	if c.speed <= 0 {
+		return
+	}
+	wall := c.start.Add(time.Duration(float64(c.elapsed) / c.speed))
+	if d := time.Until(wall); d > 0 {
+		c.sleep(d)
+	}
+}
+
+// Replay 回放指定交易日录制的快照
+//
+//	按录制的顺序把快照写回内存缓存, 回放期间引擎的时钟切换成虚拟时钟, 驱动实时特征和盘中跟踪,
+//	一刀切卖出等交易任务按虚拟时钟通过RunDueJobs调度, 交易在沙箱的模拟盘中撮合. 返回回放的快照批次数
+func Replay(parameter ReplayParameter) (int, error) {
+	date := exchange.FixTradeDate(parameter.Date)
+	batches, err := models.LoadSnapshotBatches(date)
+	if err != nil {
+		return 0, err
+	}
+	batches = filterSnapshotBatches(batches, parameter.Begin, parameter.End)
+	if len(batches) == 0 {
+		return 0, ErrReplayNoData
+	}
+	replay := newReplayClock(batches[0].Timestamp, parameter.Speed)
+	previous := clock.Set(replay)
+	defer clock.Set(previous)
+	broker, restore := trader.UseReplaySandbox(date, parameter.Capital)
+	defer restore()
+	// 股票池也写到沙箱, 回放不能修改实盘的股票池
+	restorePool := storages.UseStockPoolSandbox(trader.ReplaySandboxPath(date))
+	defer restorePool()
+	factors.SwitchDate(date)
+	allCodes := market.GetCodeList()
+	logger.Infof("回放快照: %s, %d批, 倍速%.2f, begin", date, len(batches), parameter.Speed)
+	for _, batch := range batches {
+		from := replay.Now()
+		replay.advanceTo(batch.Timestamp)
+		timestamp := replay.Now().Format(time.TimeOnly)
+		models.UpdateTicksInMemory(batch.Snapshots)
+		realtime.UpdateFeatures(allCodes)
+		barIndex := 1
+		tracker.TrackSnapshots(&barIndex, parameter.Strategies, timestamp)
+		RunDueJobs(from, replayJobs...)
+		broker.Match()
+	}
+	// 回放结束, 收盘日内策略最后一根K线
+	tracker.FlushIntradayBars(parameter.Strategies, replay.Now().Format(time.TimeOnly))
+	logger.Infof("回放快照: %s, %d批, end", date, len(batches))
+	return len(batches), nil
+}
+
+// 按时间范围过滤快照批次
+func filterSnapshotBatches(batches []models.SnapshotBatch, begin, end string) []models.SnapshotBatch {
+	begin, end = strings.TrimSpace(begin), strings.TrimSpace(end)
+	if len(begin) == 0 && len(end) == 0 {
+		return batches
+	}
+	var list []models.SnapshotBatch
+	for _, v := range batches {
+		tm := v.Timestamp.Format(time.TimeOnly)
+		if len(begin) > 0 && tm < begin {
+			continue
+		}
+		if len(end) > 0 && tm > end {
+			continue
+		}
+		list = append(list, v)
+	}
+	return list
+}
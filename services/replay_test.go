@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"gitee.com/quant1x/engine/models"
+)
+
+func TestParseReplaySpeed(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    float64
+		wantErr bool
+	}{
+		{"10x", 10, false},
+		{"0.5X", 0.5, false},
+		{"2", 2, false},
+		{"", 1, false},
+		{"0", 0, false},
+		{"-1x", 0, true},
+		{"fast", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseReplaySpeed(tt.text)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseReplaySpeed(%q) = %v, %v, want %v, error=%t", tt.text, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestReplayClock(t *testing.T) {
+	origin := time.Date(2024, 1, 2, 9, 25, 0, 0, time.Local)
+	clock := newReplayClock(origin, 10)
+	var slept time.Duration
+	clock.start = time.Now().Add(-time.Hour) // 墙上时间已经超过, 不需要等待
+	clock.sleep = func(d time.Duration) { slept += d }
+	clock.advanceTo(origin.Add(3 * time.Second))
+	if !clock.Now().Equal(origin.Add(3*time.Second)) || slept != 0 {
+		t.Errorf("clock.Now() = %v, slept=%v", clock.Now(), slept)
+	}
+	// 午休的间隔只计replayMaxGap
+	clock.advanceTo(time.Date(2024, 1, 2, 13, 0, 0, 0, time.Local))
+	if want := 3*time.Second + replayMaxGap; clock.elapsed != want {
+		t.Errorf("clock.elapsed = %v, want %v", clock.elapsed, want)
+	}
+	// 时间不回退
+	clock.advanceTo(origin)
+	if clock.Now().Hour() != 13 {
+		t.Errorf("clock.Now() = %v, want 13:00:00", clock.Now())
+	}
+	clock = newReplayClock(origin, 1)
+	clock.sleep = func(d time.Duration) { slept += d }
+	clock.advanceTo(origin.Add(2 * time.Second))
+	if slept <= time.Second || slept > 2*time.Second {
+		t.Errorf("slept = %v, want about 2s", slept)
+	}
+}
+
+func Test_filterSnapshotBatches(t *testing.T) {
+	origin := time.Date(2024, 1, 2, 9, 25, 0, 0, time.Local)
+	var batches []models.SnapshotBatch
+	for i := 0; i < 5; i++ {
+		batches = append(batches, models.SnapshotBatch{Timestamp: origin.Add(time.Duration(i) * time.Minute)})
+	}
+	if got := filterSnapshotBatches(batches, "", ""); len(got) != 5 {
+		t.Errorf("filter all = %d, want 5", len(got))
+	}
+	if got := filterSnapshotBatches(batches, "09:26:00", "09:28:00"); len(got) != 3 {
+		t.Errorf("filter range = %d, want 3", len(got))
+	}
+	if got := filterSnapshotBatches(batches, "09:28:30", ""); len(got) != 1 {
+		t.Errorf("filter begin = %d, want 1", len(got))
+	}
+}
@@ -18,9 +18,9 @@ import (
 //	默认每10秒检测1次
 //	排名不分先后
 type Task struct {
-	name    string // 任务名称
-	spec    string // 触发条件
-	Service func() // 任务函数
+	name    string       // 任务名称
+	spec    string       // 触发条件
+	Service func() error // 任务函数, 返回错误由调度器记录
 }
 
 var (
@@ -35,7 +35,7 @@ var (
 )
 
 // Register 注册定时任务
-func Register(name, spec string, callback func()) error {
+func Register(name, spec string, callback func() error) error {
 	jobMutex.Lock()
 	defer jobMutex.Unlock()
 	_, ok := mapJobs[name]
@@ -74,7 +74,7 @@ func DaemonService() {
 	for _, v := range mapJobs {
 		message := fmt.Sprintf("Service: %s, Interval: %s, ", v.name, v.spec)
 		logger.Info(message)
-		_, err := crontab.AddJobWithSkipIfStillRunning(v.spec, v.Service)
+		_, err := crontab.AddJobWithSkipIfStillRunning(v.spec, v.run)
 		if err != nil {
 			logger.Infof(message+"failed, err: %s", err.Error())
 		} else {
@@ -82,6 +82,8 @@ func DaemonService() {
 		}
 	}
 	jobMutex.Unlock()
+	// 启动内置HTTP服务
+	StartHttpServer()
 	// 等待结束
 	coroutine.WaitForShutdown()
 	// 关闭任务调度
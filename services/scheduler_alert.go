@@ -0,0 +1,152 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/gox/logger"
+)
+
+const (
+	alertTimeout = 10 * time.Second // 告警命令和webhook的超时时间
+)
+
+// 告警类型
+const (
+	AlertFailures = "failures" // 连续失败
+	AlertOverrun  = "overrun"  // 执行超时
+)
+
+// JobAlert 任务告警信息
+type JobAlert struct {
+	Job      string `json:"job"`      // 任务名称
+	Kind     string `json:"kind"`     // 告警类型
+	Message  string `json:"message"`  // 告警说明
+	Failures int    `json:"failures"` // 连续失败次数
+	Time     string `json:"time"`     // 告警时间
+}
+
+var (
+	__mutexJobOverrun sync.Mutex
+	__mapJobOverrun   = map[string]bool{} // 任务最近一次执行是否超时
+)
+
+// 记录任务的超时状态, 只有从未超时变成超时时返回true, 持续超时不重复告警
+func jobOverrunChanged(name string, overrun bool) bool {
+	__mutexJobOverrun.Lock()
+	defer __mutexJobOverrun.Unlock()
+	previous := __mapJobOverrun[name]
+	__mapJobOverrun[name] = overrun
+	return overrun && !previous
+}
+
+// 任务的超时阈值, 优先取配置的timeout, 其次取@every的间隔
+func jobOverrunThreshold(task Task) time.Duration {
+	jobParam := config.GetJobParameter(task.name)
+	if jobParam != nil {
+		timeout := strings.TrimSpace(jobParam.Timeout)
+		if len(timeout) > 0 {
+			d, err := time.ParseDuration(timeout)
+			if err == nil {
+				return d
+			}
+			logger.Errorf("Service: %s, 无效的timeout配置: %s", task.name, timeout)
+		}
+	}
+	spec := strings.TrimSpace(task.spec)
+	if interval, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err == nil {
+			return d
+		}
+	}
+	return 0
+}
+
+// 检查任务是否需要告警
+func checkJobAlert(task Task, execution JobExecution, failures int) {
+	threshold := jobOverrunThreshold(task)
+	duration := time.Duration(execution.Duration * float64(time.Second))
+	overrun := jobOverrunChanged(task.name, threshold > 0 && duration > threshold)
+	alertParam := config.AlertConfig()
+	if len(alertParam.Command) == 0 && len(alertParam.Webhook) == 0 {
+		return
+	}
+	now := time.Now().Format(time.DateTime)
+	// 连续失败达到阈值时告警一次, 成功后重新计数
+	if failures == alertParam.MaxFailures {
+		alert := JobAlert{
+			Job:      task.name,
+			Kind:     AlertFailures,
+			Message:  fmt.Sprintf("任务连续失败%d次, 最近错误: %s", failures, execution.Error),
+			Failures: failures,
+			Time:     now,
+		}
+		go sendJobAlert(alertParam, alert)
+	}
+	// 执行超时时告警一次, 恢复正常后再次超时才重新告警
+	if overrun {
+		alert := JobAlert{
+			Job:      task.name,
+			Kind:     AlertOverrun,
+			Message:  fmt.Sprintf("任务执行耗时%s, 超过%s", duration, threshold),
+			Failures: failures,
+			Time:     now,
+		}
+		go sendJobAlert(alertParam, alert)
+	}
+}
+
+// 发送告警
+func sendJobAlert(alertParam config.AlertParameter, alert JobAlert) {
+	logger.Warnf("Service: %s, alert[%s]: %s", alert.Job, alert.Kind, alert.Message)
+	ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+	defer cancel()
+	if command := strings.TrimSpace(alertParam.Command); len(command) > 0 {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", command)
+		}
+		cmd.Env = append(os.Environ(),
+			"QUANT1X_ALERT_JOB="+alert.Job,
+			"QUANT1X_ALERT_KIND="+alert.Kind,
+			"QUANT1X_ALERT_MESSAGE="+alert.Message,
+			fmt.Sprintf("QUANT1X_ALERT_FAILURES=%d", alert.Failures),
+			"QUANT1X_ALERT_TIME="+alert.Time,
+		)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			logger.Errorf("Service: %s, 告警命令执行失败: %+v, %s", alert.Job, err, string(output))
+		}
+	}
+	if webhook := strings.TrimSpace(alertParam.Webhook); len(webhook) > 0 {
+		data, _ := json.Marshal(alert)
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(data))
+		if err != nil {
+			logger.Errorf("Service: %s, 告警webhook无效: %+v", alert.Job, err)
+			return
+		}
+		request.Header.Set("Content-Type", "application/json; charset=utf-8")
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			logger.Errorf("Service: %s, 告警webhook发送失败: %+v", alert.Job, err)
+			return
+		}
+		_ = response.Body.Close()
+		if response.StatusCode >= http.StatusBadRequest {
+			logger.Errorf("Service: %s, 告警webhook返回状态码: %d", alert.Job, response.StatusCode)
+		}
+	}
+}
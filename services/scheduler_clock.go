@@ -0,0 +1,51 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/gox/cron"
+	"gitee.com/quant1x/gox/logger"
+)
+
+// RunDueJobs 按当前时钟执行触发时间在(from, now]之间的定时任务, 返回执行的任务名称
+//
+//	守护进程的调度器用系统时间触发, 模拟和回放时用虚拟时钟推进调度, 每次推进时钟后调用,
+//	任务按名称顺序串行执行, 同样的时钟得到同样的执行顺序. names不为空时只执行指定的任务
+func RunDueJobs(from time.Time, names ...string) []string {
+	now := clock.Now()
+	jobMutex.Lock()
+	tasks := make([]Task, 0, len(mapJobs))
+	for _, v := range mapJobs {
+		if len(names) > 0 && !slices.Contains(names, v.name) {
+			continue
+		}
+		tasks = append(tasks, v)
+	}
+	jobMutex.Unlock()
+	slices.SortFunc(tasks, func(a, b Task) int {
+		return strings.Compare(a.name, b.name)
+	})
+	var executed []string
+	for _, task := range tasks {
+		if !jobIsDue(task.spec, from, now) {
+			continue
+		}
+		task.run()
+		executed = append(executed, task.name)
+	}
+	return executed
+}
+
+// 触发条件在(from, to]之间是否到期
+func jobIsDue(spec string, from, to time.Time) bool {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		logger.Errorf("Service: 触发条件[%s]错误: %+v", spec, err)
+		return false
+	}
+	next := schedule.Next(from)
+	return !next.IsZero() && !next.After(to)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/gox/logger"
+)
+
+const (
+	jobHistoryFilename = "jobs.history" // 任务执行历史文件名
+	jobHistoryMaxSize  = 16 << 20       // 单个历史文件最大16MB
+	jobHistoryBackups  = 3              // 保留的历史文件个数
+)
+
+// JobExecution 任务执行记录
+type JobExecution struct {
+	Name     string  `json:"name"`            // 任务名称
+	Start    string  `json:"start"`           // 开始时间
+	End      string  `json:"end"`             // 结束时间
+	Duration float64 `json:"duration"`        // 耗时, 单位秒
+	Outcome  string  `json:"outcome"`         // 结果
+	Error    string  `json:"error,omitempty"` // 错误信息
+	Stack    string  `json:"stack,omitempty"` // panic堆栈
+}
+
+var (
+	historyMutex sync.Mutex
+)
+
+// 任务执行历史文件, 每行一条json记录
+func getJobHistoryFilename() string {
+	return filepath.Join(cache.GetVariablePath(), jobHistoryFilename)
+}
+
+// 滚动历史文件, jobs.history -> jobs.history.1 -> ... -> jobs.history.N
+func rollJobHistory(filename string) {
+	stat, err := os.Stat(filename)
+	if err != nil || stat.Size() < jobHistoryMaxSize {
+		return
+	}
+	for i := jobHistoryBackups - 1; i > 0; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", filename, i), fmt.Sprintf("%s.%d", filename, i+1))
+	}
+	_ = os.Rename(filename, filename+".1")
+}
+
+// 追加一条任务执行记录
+func saveJobExecution(execution JobExecution) {
+	data, err := json.Marshal(execution)
+	if err != nil {
+		logger.Errorf("Service: %s, 序列化执行记录失败: %+v", execution.Name, err)
+		return
+	}
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+	filename := getJobHistoryFilename()
+	rollJobHistory(filename)
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		logger.Errorf("Service: %s, 打开执行历史文件失败: %+v", execution.Name, err)
+		return
+	}
+	defer file.Close()
+	data = append(data, '\n')
+	_, _ = file.Write(data)
+}
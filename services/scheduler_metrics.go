@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// 任务耗时直方图的桶, 单位秒
+var jobDurationBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300}
+
+// 单个任务的计数器和耗时直方图
+type jobMetrics struct {
+	runs    map[string]uint64 // 按结果计数
+	buckets []uint64          // 各个桶的计数, 非累计
+	sum     float64           // 耗时总和
+	count   uint64            // 执行总次数
+}
+
+var (
+	metricsMutex sync.Mutex
+	mapMetrics   = map[string]*jobMetrics{}
+)
+
+// 记录一次任务执行的指标
+func observeJobExecution(execution JobExecution) {
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+	m, ok := mapMetrics[execution.Name]
+	if !ok {
+		m = &jobMetrics{
+			runs:    map[string]uint64{},
+			buckets: make([]uint64, len(jobDurationBuckets)),
+		}
+		mapMetrics[execution.Name] = m
+	}
+	m.runs[execution.Outcome]++
+	m.count++
+	m.sum += execution.Duration
+	for i, le := range jobDurationBuckets {
+		if execution.Duration <= le {
+			m.buckets[i]++
+			break
+		}
+	}
+}
+
+// WriteJobMetrics 以Prometheus文本格式输出定时任务的指标
+//
+//	quant1x_job_runs_total{job,outcome}  执行次数
+//	quant1x_job_duration_seconds{job}    执行耗时直方图
+func WriteJobMetrics(w io.Writer) {
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+	names := make([]string, 0, len(mapMetrics))
+	for name := range mapMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	_, _ = fmt.Fprintln(w, "# HELP quant1x_job_runs_total Total number of job executions by outcome.")
+	_, _ = fmt.Fprintln(w, "# TYPE quant1x_job_runs_total counter")
+	for _, name := range names {
+		m := mapMetrics[name]
+		for _, outcome := range []string{JobSuccess, JobFailed, JobPanic} {
+			_, _ = fmt.Fprintf(w, "quant1x_job_runs_total{job=%q,outcome=%q} %d\n", name, outcome, m.runs[outcome])
+		}
+	}
+	_, _ = fmt.Fprintln(w, "# HELP quant1x_job_duration_seconds Job execution duration in seconds.")
+	_, _ = fmt.Fprintln(w, "# TYPE quant1x_job_duration_seconds histogram")
+	for _, name := range names {
+		m := mapMetrics[name]
+		var cumulative uint64
+		for i, le := range jobDurationBuckets {
+			cumulative += m.buckets[i]
+			_, _ = fmt.Fprintf(w, "quant1x_job_duration_seconds_bucket{job=%q,le=%q} %d\n", name, strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+		}
+		_, _ = fmt.Fprintf(w, "quant1x_job_duration_seconds_bucket{job=%q,le=\"+Inf\"} %d\n", name, m.count)
+		_, _ = fmt.Fprintf(w, "quant1x_job_duration_seconds_sum{job=%q} %g\n", name, m.sum)
+		_, _ = fmt.Fprintf(w, "quant1x_job_duration_seconds_count{job=%q} %d\n", name, m.count)
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteJobMetrics(t *testing.T) {
+	name := "test_metrics"
+	observeJobExecution(JobExecution{Name: name, Outcome: JobSuccess, Duration: 0.02})
+	observeJobExecution(JobExecution{Name: name, Outcome: JobFailed, Duration: 2})
+	var buf bytes.Buffer
+	WriteJobMetrics(&buf)
+	text := buf.String()
+	for _, want := range []string{
+		`quant1x_job_runs_total{job="test_metrics",outcome="success"} 1`,
+		`quant1x_job_runs_total{job="test_metrics",outcome="failed"} 1`,
+		`quant1x_job_duration_seconds_bucket{job="test_metrics",le="0.01"} 0`,
+		`quant1x_job_duration_seconds_bucket{job="test_metrics",le="0.05"} 1`,
+		`quant1x_job_duration_seconds_bucket{job="test_metrics",le="5"} 2`,
+		`quant1x_job_duration_seconds_bucket{job="test_metrics",le="+Inf"} 2`,
+		`quant1x_job_duration_seconds_count{job="test_metrics"} 2`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+}
+
+func TestJobOverrunThreshold(t *testing.T) {
+	if d := jobOverrunThreshold(Task{name: "test_every", spec: "@every 10s"}); d != 10*time.Second {
+		t.Errorf("threshold = %s, want 10s", d)
+	}
+	if d := jobOverrunThreshold(Task{name: "test_cron", spec: "0 9 * * *"}); d != 0 {
+		t.Errorf("threshold = %s, want 0", d)
+	}
+}
+
+func Test_jobOverrunChanged(t *testing.T) {
+	name := "test_overrun"
+	if !jobOverrunChanged(name, true) {
+		t.Error("the first overrun should alert")
+	}
+	if jobOverrunChanged(name, true) {
+		t.Error("a continued overrun should not alert again")
+	}
+	if jobOverrunChanged(name, false) {
+		t.Error("recovery should not alert")
+	}
+	if !jobOverrunChanged(name, true) {
+		t.Error("an overrun after recovery should alert")
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/gox/logger"
+)
+
+var (
+	ErrJobNotFound = errors.New("the job not found")        // 任务不存在
+	ErrJobRunning  = errors.New("the job is still running") // 任务正在运行
+)
+
+// 任务执行结果
+const (
+	JobSuccess = "success" // 成功
+	JobFailed  = "failed"  // 返回错误
+	JobPanic   = "panic"   // 发生panic
+)
+
+// JobStatus 任务运行状态
+type JobStatus struct {
+	Name      string `name:"任务" json:"name"`
+	Spec      string `name:"触发条件" json:"spec"`
+	Running   bool   `name:"运行中" json:"running"`
+	Count     int    `name:"运行次数" json:"count"`
+	Failures  int    `name:"连续失败次数" json:"failures"`
+	LastStart string `name:"最近开始时间" json:"last_start"`
+	LastEnd   string `name:"最近结束时间" json:"last_end"`
+	Duration  string `name:"最近耗时" json:"duration"`
+	Outcome   string `name:"最近结果" json:"outcome"`
+	Error     string `name:"最近错误" json:"error"`
+}
+
+var (
+	statusMutex sync.Mutex
+	mapStatus   = map[string]*JobStatus{}
+)
+
+// 标记任务开始, 任务正在运行则返回false
+func jobBegin(task Task) bool {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+	status, ok := mapStatus[task.name]
+	if !ok {
+		status = &JobStatus{Name: task.name, Spec: task.spec}
+		mapStatus[task.name] = status
+	}
+	if status.Running {
+		return false
+	}
+	status.Running = true
+	status.Count++
+	status.LastStart = time.Now().Format(cache.TimeStampMilli)
+	return true
+}
+
+// 标记任务结束, 返回连续失败的次数
+func jobEnd(execution JobExecution) int {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+	status := mapStatus[execution.Name]
+	if status == nil {
+		return 0
+	}
+	status.Running = false
+	status.LastEnd = execution.End
+	status.Duration = time.Duration(execution.Duration * float64(time.Second)).String()
+	status.Outcome = execution.Outcome
+	status.Error = execution.Error
+	if execution.Outcome == JobSuccess {
+		status.Failures = 0
+	} else {
+		status.Failures++
+	}
+	return status.Failures
+}
+
+// 执行任务并记录运行状态、执行历史和指标
+func (t Task) run() {
+	if !jobBegin(t) {
+		return
+	}
+	start := time.Now()
+	execution := JobExecution{
+		Name:  t.name,
+		Start: start.Format(cache.TimeStampMilli),
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			execution.Outcome = JobPanic
+			execution.Error = fmt.Sprintf("%v", r)
+			execution.Stack = string(debug.Stack())
+			logger.Errorf("Service: %s, panic: %v\n%s", t.name, r, execution.Stack)
+		}
+		end := time.Now()
+		execution.End = end.Format(cache.TimeStampMilli)
+		execution.Duration = end.Sub(start).Seconds()
+		failures := jobEnd(execution)
+		saveJobExecution(execution)
+		observeJobExecution(execution)
+		checkJobAlert(t, execution, failures)
+	}()
+	err := t.Service()
+	if err != nil {
+		execution.Outcome = JobFailed
+		execution.Error = err.Error()
+		logger.Errorf("Service: %s, failed: %+v", t.name, err)
+	} else {
+		execution.Outcome = JobSuccess
+	}
+}
+
+// TriggerJob 立即执行一次定时任务
+func TriggerJob(name string) error {
+	jobMutex.Lock()
+	task, ok := mapJobs[name]
+	jobMutex.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+	statusMutex.Lock()
+	status, ok := mapStatus[name]
+	running := ok && status.Running
+	statusMutex.Unlock()
+	if running {
+		return ErrJobRunning
+	}
+	go task.run()
+	return nil
+}
+
+// GetJobStatusList 获取定时任务运行状态列表, 按名称排序
+func GetJobStatusList() []JobStatus {
+	jobMutex.Lock()
+	tasks := make([]Task, 0, len(mapJobs))
+	for _, v := range mapJobs {
+		tasks = append(tasks, v)
+	}
+	jobMutex.Unlock()
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+	list := make([]JobStatus, 0, len(tasks))
+	for _, v := range tasks {
+		status, ok := mapStatus[v.name]
+		if ok {
+			list = append(list, *status)
+		} else {
+			list = append(list, JobStatus{Name: v.name, Spec: v.spec})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
@@ -1,7 +1,29 @@
 package services
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestJobRealtimeKLine(t *testing.T) {
 	jobRealtimeKLine()
 }
+
+func Test_jobIsDue(t *testing.T) {
+	from := time.Date(2024, 1, 2, 8, 59, 0, 0, time.Local)
+	tests := []struct {
+		spec string
+		to   time.Time
+		want bool
+	}{
+		{CronTriggerInit, from.Add(30 * time.Second), false},
+		{CronTriggerInit, from.Add(time.Minute), true},
+		{CronTickInterval, from.Add(time.Second), true},
+		{cronSyncOrdersInterval, from.Add(time.Hour), false},
+	}
+	for _, tt := range tests {
+		if got := jobIsDue(tt.spec, from, tt.to); got != tt.want {
+			t.Errorf("jobIsDue(%q, %v) = %t, want %t", tt.spec, tt.to, got, tt.want)
+		}
+	}
+}
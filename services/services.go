@@ -18,6 +18,8 @@ const (
 	cronSyncOrdersInterval = "2 15-23 * * *"
 	// cronMarginTrading 更新融资融券
 	cronMarginTrading = "5 9 * * *"
+	// 风控基准, 集合竞价之前和收盘之后各记录一次总资产
+	cronRiskBaseline = "10 9,15 * * *"
 )
 
 const (
@@ -28,15 +30,17 @@ const (
 
 // 定时任务关键字
 const (
-	keyCronReset            = "global_reset"    // 全局重置
-	keyCronRealTimeKLine    = "realtime_kline"  // 实时更新K线
-	keyCronUpdateSnapshot   = "update_snapshot" // 更新快照
-	keyCronUpdateMisc       = "update_misc"     // 更新misc
-	keyCronUpdateAll        = "update_all"      // 更新全部数据, 包括基础数据和特征数据
-	keyCronCookieCutterSell = "sell_117"        // 一刀切卖出, one-size-fits-all
-	keyCronSyncQmtOrder     = "sync_orders"     // 同步订单
-	keyCronResetNetwork     = "reset_network"   // 重置网络
-	keyCronMarginTrading    = "update_rzrq"     // 更新融资融券
+	keyCronReset            = "global_reset"     // 全局重置
+	keyCronRealTimeKLine    = "realtime_kline"   // 实时更新K线
+	keyCronUpdateSnapshot   = "update_snapshot"  // 更新快照
+	keyCronUpdateMisc       = "update_misc"      // 更新misc
+	keyCronUpdateAll        = "update_all"       // 更新全部数据, 包括基础数据和特征数据
+	keyCronCookieCutterSell = "sell_117"         // 一刀切卖出, one-size-fits-all
+	keyCronSyncQmtOrder     = "sync_orders"      // 同步订单
+	keyCronReconcileOrders  = "reconcile_orders" // 核对订单状态
+	keyCronResetNetwork     = "reset_network"    // 重置网络
+	keyCronMarginTrading    = "update_rzrq"      // 更新融资融券
+	keyCronRiskBaseline     = "risk_baseline"    // 记录风控的资产基准
 )
 
 func init() {
@@ -82,11 +86,21 @@ func init() {
 	if err != nil {
 		logger.Fatal(err)
 	}
+	// 核对订单状态
+	err = Register(keyCronReconcileOrders, CronDefaultInterval, jobReconcileOrders)
+	if err != nil {
+		logger.Fatal(err)
+	}
 	// 更新融资融券
 	err = Register(keyCronMarginTrading, cronMarginTrading, jobUpdateMarginTrading)
 	if err != nil {
 		logger.Fatal(err)
 	}
+	// 记录风控的资产基准
+	err = Register(keyCronRiskBaseline, cronRiskBaseline, jobRiskBaseline)
+	if err != nil {
+		logger.Fatal(err)
+	}
 }
 
 // IsTrading 状态是否交易中
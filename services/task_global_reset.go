@@ -5,15 +5,15 @@ import (
 	"gitee.com/quant1x/engine/cache"
 	"gitee.com/quant1x/engine/factors"
 	"gitee.com/quant1x/gox/logger"
-	"gitee.com/quant1x/gox/runtime"
 )
 
 // 任务 - 交易日数据缓存重置
-func jobGlobalReset() {
-	defer runtime.IgnorePanic("")
+func jobGlobalReset() error {
 	logger.Info("系统初始化...")
 	logger.Info("清理过期的更新状态文件...")
-	_ = cleanExpiredStateFiles()
+	if err := cleanExpiredStateFiles(); err != nil {
+		return err
+	}
 	logger.Info("清理过期的更新状态文件...OK")
 	level1.ReOpen()
 	logger.Info("重置系统缓存...")
@@ -21,4 +21,5 @@ func jobGlobalReset() {
 	logger.Info("重置系统缓存...OK")
 
 	logger.Info("系统初始化...OK")
+	return nil
 }
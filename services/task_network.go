@@ -6,8 +6,9 @@ import (
 )
 
 // 网络配置重置
-func jobResetNetwork() {
+func jobResetNetwork() error {
 	logger.Infof("刷新服务器列表...")
 	quotes.BestIP()
 	logger.Infof("刷新服务器列表...OK")
+	return nil
 }
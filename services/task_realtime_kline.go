@@ -1,7 +1,7 @@
 package services
 
 import (
-	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/datasource/base"
 	"gitee.com/quant1x/engine/market"
 	"gitee.com/quant1x/engine/models"
@@ -12,9 +12,9 @@ import (
 )
 
 // 任务 - 实时更新K线
-func jobRealtimeKLine() {
+func jobRealtimeKLine() error {
 	funcName := "jobRealtimeKLine"
-	updateInRealTime, status := exchange.CanUpdateInRealtime()
+	updateInRealTime, status := clock.CanUpdateInRealtime()
 	// 14:30:00~15:01:00之间更新数据
 	if updateInRealTime && IsTrading(status) {
 		realtimeUpdateOfKLine()
@@ -25,11 +25,11 @@ func jobRealtimeKLine() {
 			logger.Infof("%s, 非尾盘交易时段: %d", funcName, status)
 		}
 	}
+	return nil
 }
 
 // 更新K线
 func realtimeUpdateOfKLine() {
-	defer runtime.IgnorePanic("")
 	barIndex := barIndexRealtimeKLine
 	allCodes := market.GetCodeList()
 	wg := coroutine.NewRollingWaitGroup(5)
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"fmt"
+
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/engine/trader"
+	"gitee.com/quant1x/gox/runtime"
+)
+
+// 任务 - 核对订单状态
+func jobReconcileOrders() error {
+	// 非交易日直接退出
+	if !clock.DateIsTradingDay() {
+		return nil
+	}
+	updateInRealTime, status := clock.CanUpdateInRealtime()
+	if !(updateInRealTime && IsTrading(status)) && !runtime.Debug() {
+		return nil
+	}
+	err := trader.ReconcileOrders()
+	if err != nil {
+		return fmt.Errorf("核对订单状态失败: %w", err)
+	}
+	// 超时未成交的买入委托自动撤单
+	trader.CancelStaleOrders()
+	return nil
+}
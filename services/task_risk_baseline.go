@@ -0,0 +1,16 @@
+package services
+
+import (
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/trader"
+)
+
+// 任务 - 记录风控的资产基准
+func jobRiskBaseline() error {
+	risk := config.TraderConfig().Risk
+	// 只有当日最大亏损用到资产基准
+	if !risk.Enable || risk.MaxDailyLoss <= 0 {
+		return nil
+	}
+	return trader.SnapshotRiskBaseline()
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/trader"
+)
+
+// 只返回账户总资产的交易通道
+type riskTestBroker struct {
+	totalAsset float64
+}
+
+func (b *riskTestBroker) QueryAccount() (*trader.AccountDetail, error) {
+	return &trader.AccountDetail{TotalAsset: b.totalAsset, Cash: b.totalAsset}, nil
+}
+
+func (b *riskTestBroker) QueryHolding() ([]trader.PositionDetail, error) {
+	return nil, nil
+}
+
+func (b *riskTestBroker) QueryOrders() ([]trader.OrderDetail, error) {
+	return nil, nil
+}
+
+func (b *riskTestBroker) PlaceOrder(direction trader.Direction, strategyName, orderRemark, securityCode string, priceType trader.PriceType, price float64, volume int) (int, error) {
+	return 0, nil
+}
+
+func (b *riskTestBroker) CancelOrder(orderId int) error {
+	return nil
+}
+
+func Test_jobRiskBaseline(t *testing.T) {
+	previousConfig := config.GetGlobalConfig()
+	testConfig := previousConfig
+	testConfig.Trader.Risk = config.RiskParameter{Enable: true, MaxDailyLoss: 5}
+	config.SetGlobalConfig(testConfig)
+	date := "2024-01-03"
+	at := func(hour, minute int) {
+		clock.Set(clock.Fixed(time.Date(2024, 1, 3, hour, minute, 0, 0, time.Local)))
+	}
+	previousClock := clock.Set(nil)
+	broker := &riskTestBroker{totalAsset: 100000}
+	restore := trader.UseSandbox(t.TempDir(), broker)
+	defer func() {
+		restore()
+		clock.Set(previousClock)
+		config.SetGlobalConfig(previousConfig)
+	}()
+	// 集合竞价之前记录开盘总资产
+	at(9, 10)
+	if err := jobRiskBaseline(); err != nil {
+		t.Fatal(err)
+	}
+	if baseline := trader.RiskBaseline(date); baseline.OpeningAsset != 100000 || baseline.ClosingAsset != 0 {
+		t.Fatalf("baseline = %+v, want opening asset 100000", baseline)
+	}
+	// 盘中不记录, 亏损6%超过5%的上限, 禁止买入
+	at(10, 30)
+	broker.totalAsset = 94000
+	if err := jobRiskBaseline(); err != nil {
+		t.Fatal(err)
+	}
+	if baseline := trader.RiskBaseline(date); baseline.OpeningAsset != 100000 {
+		t.Fatalf("baseline = %+v, intraday snapshot should not change it", baseline)
+	}
+	order := trader.RiskOrder{Direction: trader.BUY, StrategyName: "test", SecurityCode: "sh600000", Price: 10.00, Volume: 100}
+	if rejection := trader.CheckRisk(order); rejection == nil || rejection.Rule != trader.RiskRuleDailyLoss {
+		t.Fatalf("rejection = %v, want %s", rejection, trader.RiskRuleDailyLoss)
+	}
+	// 亏损4%, 未超过上限
+	broker.totalAsset = 96000
+	if rejection := trader.CheckRisk(order); rejection != nil {
+		t.Fatalf("unexpected rejection: %v", rejection)
+	}
+	// 收盘之后记录收盘总资产
+	at(15, 10)
+	if err := jobRiskBaseline(); err != nil {
+		t.Fatal(err)
+	}
+	if baseline := trader.RiskBaseline(date); baseline.OpeningAsset != 100000 || baseline.ClosingAsset != 96000 {
+		t.Fatalf("baseline = %+v, want closing asset 96000", baseline)
+	}
+}
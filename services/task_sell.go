@@ -1,10 +1,12 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 
 	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/config"
 	"gitee.com/quant1x/engine/factors"
 	"gitee.com/quant1x/engine/market"
@@ -17,40 +19,45 @@ import (
 )
 
 // 任务 - 卖出117
-func jobOneSizeFitsAllSales() {
-	updateInRealTime, status := exchange.CanUpdateInRealtime()
+func jobOneSizeFitsAllSales() error {
+	updateInRealTime, status := clock.CanUpdateInRealtime()
 	if updateInRealTime && IsTrading(status) {
-		cookieCutterSell()
+		return cookieCutterSell()
 	} else if runtime.Debug() {
-		cookieCutterSell()
+		return cookieCutterSell()
 	}
+	return nil
 }
 
 // 一刀切卖出
-func cookieCutterSell() {
-	defer runtime.IgnorePanic("")
+func cookieCutterSell() error {
+	// 判断是否交易日
+	if !clock.DateIsTradingDay() {
+		return nil
+	}
+	return cookieCutterSellAt()
+}
+
+// 一刀切卖出, timestamp是判断交易时段的时间, 默认是当前时间
+func cookieCutterSellAt(timestamp ...string) error {
 	sellStrategyCode := models.ModelOneSizeFitsAllSells
 	// 1. 获取117号策略(卖出)
 	sellRule := config.GetStrategyParameterByCode(sellStrategyCode)
 	if sellRule == nil {
-		return
+		return nil
 	}
 	// 2. 判断是否可以指定自动卖出
 	if !sellRule.IsCookieCutterForSell() {
-		return
+		return nil
 	}
-	// 3. 判断是否交易日
-	if !exchange.DateIsTradingDay() {
-		return
-	}
-	// 3.1 判断是否交易时段
-	if !sellRule.Session.IsTrading() {
-		return
+	// 3. 判断是否交易时段
+	if !sellRule.Session.IsTrading(timestamp...) {
+		return nil
 	}
 	// 4. 查询持仓可卖的股票
 	positions, err := trader.QueryHolding()
 	if err != nil {
-		return
+		return err
 	}
 	// 5. 确定持股到期的个股列表
 	var holdings []string
@@ -66,6 +73,11 @@ func cookieCutterSell() {
 	// 6. 遍历持仓
 	direction := trader.SELL
 	strategyName := sellRule.QmtStrategyName()
+	if halt := trader.HaltedFor(direction, strategyName); halt != nil {
+		logger.Warnf("%s: 交易已暂停(%s), 放弃卖出", strategyName, halt)
+		return nil
+	}
+	var errs []error
 	for _, position := range positions {
 		orderRemark := sellRule.Flag
 		isNeedToSell := false
@@ -103,7 +115,7 @@ func cookieCutterSell() {
 		floatProfitLossRatio := num.NetChangeRate(avgPrice, lastPrice)
 		// 6.9 确定是否规则内最后一天持股
 		isFinal := slices.Contains(finalCodeList, securityCode)
-		todayLastSession := sellRule.Session.IsTodayLastSession()
+		todayLastSession := sellRule.Session.IsTodayLastSession(timestamp...)
 		logger.Infof("%s[%d]: %s, profit-loss-ratio=%.02f, last-day=%t, last-session=%t", sellRule.Name, sellRule.Id, securityCode, floatProfitLossRatio, isFinal, todayLastSession)
 		// 117. 最后一天持股, 且是最后一个交易时段, 则卖出
 		if isFinal && todayLastSession {
@@ -136,11 +148,11 @@ func cookieCutterSell() {
 				orderRemark += ">H>MA5>0"
 			} else {
 				//6.11 如果股价触及止盈比例, 则卖出
-				if sellRule.Session.CanTakeProfit() && floatProfitLossRatio > sellRule.TakeProfitRatio {
+				if sellRule.Session.CanTakeProfit(timestamp...) && floatProfitLossRatio > sellRule.TakeProfitRatio {
 					isNeedToSell = true
 					// 止盈
 					orderRemark += ">TPR"
-				} else if sellRule.Session.CanStopLoss() && floatProfitLossRatio < sellRule.StopLossRatio {
+				} else if sellRule.Session.CanStopLoss(timestamp...) && floatProfitLossRatio < sellRule.StopLossRatio {
 					isNeedToSell = true
 					// 止损
 					orderRemark += "<SLR"
@@ -175,8 +187,10 @@ func cookieCutterSell() {
 		// 卖出
 		order_id, err := trader.DirectOrder(direction, strategyName, orderRemark, securityCode, trader.LATEST_PRICE, orderPrice, orderVolume)
 		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", securityCode, err))
 			continue
 		}
 		_ = order_id
 	}
+	return errors.Join(errs...)
 }
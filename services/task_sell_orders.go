@@ -6,6 +6,7 @@ import (
 
 	"gitee.com/quant1x/data/exchange"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/config"
 	"gitee.com/quant1x/engine/models"
 	"gitee.com/quant1x/engine/storages"
@@ -16,14 +17,14 @@ import (
 
 // 获得T+HoldingPeriod的具体日期
 func getEarlierDate(period int) string {
-	dates := exchange.LastNDate(exchange.LastTradeDate(), period)
+	dates := exchange.LastNDate(clock.LastTradeDate(), period)
 	earlier_date := exchange.FixTradeDate(dates[0], cache.CACHE_DATE)
 	return earlier_date
 }
 
 // 不包含最后一个交易日的持股日期列表
 func getHoldingDates(period int) []string {
-	dates := exchange.LastNDate(exchange.LastTradeDate(), period)
+	dates := exchange.LastNDate(clock.LastTradeDate(), period)
 	for i := 0; i < len(dates); i++ {
 		dates[i] = exchange.FixTradeDate(dates[i], cache.CACHE_DATE)
 	}
@@ -1,19 +1,21 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+
 	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/trader"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/logger"
-	"gitee.com/quant1x/gox/runtime"
 )
 
 // 同步委托订单
-func jobSyncTraderOrders() {
-	defer runtime.IgnorePanic("")
+func jobSyncTraderOrders() error {
 	// 非交易日直接退出
-	if !exchange.DateIsTradingDay() {
-		return
+	if !clock.DateIsTradingDay() {
+		return nil
 	}
 	name := trader.GetOrderFilename()
 	// 检查文件最后修改时间, 如果文件存在, 且时间在收盘之后, 则跳过同步
@@ -21,15 +23,23 @@ func jobSyncTraderOrders() {
 	if err == nil && stat != nil {
 		modTime := stat.LastWriteTime.Format(exchange.CN_SERVERTIME_FORMAT)
 		if modTime >= exchange.CN_CallAuctionPmEnd {
-			return
+			return nil
 		}
 	}
 	logger.Info("同步交易订单...")
 	defer logger.Info("同步交易订单...OK")
+	// 收盘后最后核对一次订单状态, 核对失败也要同步订单, 错误合并返回
+	var errReconcile error
+	if err := trader.ReconcileOrders(); err != nil {
+		errReconcile = fmt.Errorf("核对订单状态失败: %w", err)
+	}
 	list, err := trader.QueryOrders()
-	if err != nil || len(list) == 0 {
+	if err != nil {
+		return errors.Join(errReconcile, err)
+	}
+	if len(list) == 0 {
 		logger.Info("同步交易订单...今日未操作")
-		return
+		return errReconcile
 	}
-	_ = api.SlicesToCsv(name, list)
+	return errors.Join(errReconcile, api.SlicesToCsv(name, list))
 }
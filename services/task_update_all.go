@@ -1,10 +1,11 @@
 package services
 
 import (
-	"time"
+	"errors"
 
 	"gitee.com/quant1x/data/exchange"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/factors"
 	"gitee.com/quant1x/engine/storages"
 )
@@ -17,11 +18,11 @@ var (
 )
 
 // 任务 - 更新全部数据
-func jobUpdateAll() {
-	now := time.Now()
+func jobUpdateAll() error {
+	now := clock.Now()
 	tm := now.Format(exchange.CN_SERVERTIME_FORMAT)
-	today := exchange.Today()
-	lastDate := exchange.LastTradeDate()
+	today := clock.Today()
+	lastDate := clock.LastTradeDate()
 	bUpdated := false
 	phase := ""
 	if today == lastDate {
@@ -42,30 +43,35 @@ func jobUpdateAll() {
 	}
 	if bUpdated && len(phase) > 0 {
 		factors.SwitchDate(cache.DefaultCanReadDate())
-		updateAll()
+		err := updateAll()
+		// 部分失败也记录本阶段已更新, 避免每分钟重跑全量更新, 错误交给调度器计数和告警
 		doneUpdate(today, phase)
+		return err
 	}
+	return nil
 }
 
-func updateAll() {
+func updateAll() error {
 	barIndex := 1
 	currentDate := cache.DefaultCanUpdateDate()
 	cacheDate, featureDate := cache.CorrectDate(currentDate)
-	updateAllBaseData(barIndex, featureDate)
-	updateAllFeatures(barIndex+1, cacheDate, featureDate)
+	errBase := updateAllBaseData(barIndex, featureDate)
+	errFeatures := updateAllFeatures(barIndex+1, cacheDate, featureDate)
+	return errors.Join(errBase, errFeatures)
 }
 
-func updateAllBaseData(barIndex int, featureDate string) {
+func updateAllBaseData(barIndex int, featureDate string) error {
 	// 1. 获取全部注册的数据集插件
 	mask := cache.PluginMaskBaseData
 	plugins := cache.Plugins(mask)
 	// 2. 执行操作
-	storages.DataSetUpdate(barIndex, featureDate, plugins, cache.OpUpdate)
+	return storages.DataSetUpdate(barIndex, featureDate, plugins, cache.OpUpdate)
 }
 
-func updateAllFeatures(barIndex int, cacheDate, featureDate string) {
+func updateAllFeatures(barIndex int, cacheDate, featureDate string) error {
 	// 1. 获取全部注册的数据集插件
 	mask := cache.PluginMaskFeature
 	plugins := cache.Plugins(mask)
-	storages.FeaturesUpdate(&barIndex, cacheDate, featureDate, plugins, cache.OpUpdate)
+	_, err := storages.FeaturesUpdate(&barIndex, cacheDate, featureDate, plugins, cache.OpUpdate)
+	return err
 }
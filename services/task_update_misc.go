@@ -1,11 +1,13 @@
 package services
 
 import (
-	"time"
+	"errors"
+	"fmt"
 
 	"gitee.com/quant1x/data/exchange"
 	"gitee.com/quant1x/data/level1/quotes"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/factors"
 	"gitee.com/quant1x/engine/market"
 	"gitee.com/quant1x/engine/models"
@@ -17,17 +19,18 @@ import (
 )
 
 // 更新快照
-func jobUpdateMiscAndSnapshot() {
-	now := time.Now()
-	updateInRealTime, _ := exchange.CanUpdateInRealtime()
+func jobUpdateMiscAndSnapshot() error {
+	now := clock.Now()
+	updateInRealTime, _ := clock.CanUpdateInRealtime()
 	// 集合竞价时段更新数据
 	if updateInRealTime && exchange.CheckCallAuctionTime(now) {
-		realtimeUpdateMiscAndSnapshot()
+		return realtimeUpdateMiscAndSnapshot()
 	} else {
 		if runtime.Debug() {
-			realtimeUpdateMiscAndSnapshot()
+			return realtimeUpdateMiscAndSnapshot()
 		}
 	}
+	return nil
 }
 
 var (
@@ -46,7 +49,7 @@ func resetSnapshotCache() {
 }
 
 // realtimeUpdateMiscAndSnapshot 更新快照缓存
-func realtimeUpdateMiscAndSnapshot() {
+func realtimeUpdateMiscAndSnapshot() error {
 	onceSnapshot.Do(resetSnapshotCache)
 	moduleName := "执行[misc]"
 	logger.Infof("%s: begin", moduleName)
@@ -65,7 +68,7 @@ func realtimeUpdateMiscAndSnapshot() {
 			continue
 		}
 		//kind := exchange.AssertCode(securityCode)
-		timestamp := time.Now()
+		timestamp := clock.Now()
 		// 1. 修订日期
 		v.Date = currentDate
 		securityCode := v.SecurityCode
@@ -159,7 +162,8 @@ func realtimeUpdateMiscAndSnapshot() {
 	bar.Wait()
 	// 刷新Misc快照本地cache
 	factors.RefreshL5Misc()
-	timestamp := time.Now()
+	timestamp := clock.Now()
+	var errs []error
 	if exchange.CheckCallAuctionOpenFinished(timestamp) || exchange.CheckCallAuctionCloseFinished(timestamp) {
 		// 早盘和尾盘集合竞价结束后刷新缓存文件
 		for _, listSnapshot := range mapSnapshot {
@@ -192,9 +196,12 @@ func realtimeUpdateMiscAndSnapshot() {
 				cacheList = listSnapshot
 			}
 			if len(cacheList) > 0 {
-				_ = api.SlicesToCsv(filename, cacheList)
+				if err := api.SlicesToCsv(filename, cacheList); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", filename, err))
+				}
 			}
 		}
 	}
 	logger.Infof("%s: end", moduleName)
+	return errors.Join(errs...)
 }
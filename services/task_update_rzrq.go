@@ -8,13 +8,16 @@ import (
 	"gitee.com/quant1x/gox/logger"
 )
 
-func jobUpdateMarginTrading() {
+func jobUpdateMarginTrading() error {
 	logger.Infof("同步融资融券...")
 	date := cache.DefaultCanReadDate()
-	factors.MarginTradingTargetInit(date)
+	if err := factors.MarginTradingTargetInit(date); err != nil {
+		return err
+	}
 	updateMarginTradingForMisc(date)
 	updateMarginTradingForRzrq(date)
 	logger.Infof("同步融资融券...OK")
+	return nil
 }
 
 func updateMarginTradingForMisc(cacheDate string) {
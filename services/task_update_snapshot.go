@@ -1,31 +1,39 @@
 package services
 
 import (
-	"time"
-
 	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/engine/market"
 	"gitee.com/quant1x/engine/models"
+	"gitee.com/quant1x/engine/realtime"
 	"gitee.com/quant1x/gox/logger"
 	"gitee.com/quant1x/gox/runtime"
 )
 
 // 任务 - 更新快照
-func jobUpdateSnapshot() {
-	tm := time.Now()
+func jobUpdateSnapshot() error {
+	tm := clock.Now()
 	updateInRealTime, status := exchange.CanUpdateInRealtime(tm)
 	// 交易时间更新数据
 	if updateInRealTime && (IsTrading(status) || exchange.CheckCallAuctionClose(tm)) {
-		realtimeUpdateSnapshot()
+		return realtimeUpdateSnapshot()
 	} else {
 		if runtime.Debug() {
-			realtimeUpdateSnapshot()
+			return realtimeUpdateSnapshot()
 		}
 	}
+	return nil
 }
 
 // 更新快照
-func realtimeUpdateSnapshot() {
+func realtimeUpdateSnapshot() error {
 	logger.Infof("同步snapshot...")
-	models.SyncAllSnapshots(nil)
+	if err := models.SyncAllSnapshots(nil); err != nil {
+		return err
+	}
 	logger.Infof("同步snapshot...OK")
+	// 增量计算盘中的实时特征
+	count := realtime.UpdateFeatures(market.GetCodeList())
+	logger.Infof("增量计算特征...OK, %d", count)
+	return nil
 }
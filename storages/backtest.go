@@ -78,6 +78,6 @@ func FeaturesBackTest(barIndex *int, cacheDate, featureDate string, plugins []ca
 	wgAdapter.Wait()
 	barAdapter.Wait()
 	logger.Infof("%s: all, end", moduleName)
-
+	saveFactorMetrics(metrics)
 	return metrics
 }
@@ -2,6 +2,8 @@ package storages
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"sync"
 
@@ -15,17 +17,18 @@ import (
 	"gitee.com/quant1x/gox/text/runewidth"
 )
 
-func syncDataSetByDate(data factors.DataSet, date string, op cache.OpKind) {
+func syncDataSetByDate(data factors.DataSet, date string, op cache.OpKind) (err error) {
 	defer runtime.CatchPanic("%s[%s]: date=%s", data.Name(), data.GetSecurityCode(), date)
 	if op == cache.OpUpdate {
-		data.Update(date)
+		err = data.Update(date)
 	} else if op == cache.OpRepair {
-		data.Repair(date)
+		err = data.Repair(date)
 	}
+	return err
 }
 
-// 更新单个数据集
-func updateOneDataSet(wg *sync.WaitGroup, parent, bar *progressbar.Bar, dataSet factors.DataSet, date string, op cache.OpKind, allCodes []string) {
+// 更新单个数据集, 有证券失败时把失败数量和第一个错误写入result
+func updateOneDataSet(wg *sync.WaitGroup, parent, bar *progressbar.Bar, dataSet factors.DataSet, date string, op cache.OpKind, allCodes []string, result *error) {
 	moduleName := "基础数据"
 	if op == cache.OpRepair {
 		moduleName = "修复" + moduleName
@@ -33,18 +36,30 @@ func updateOneDataSet(wg *sync.WaitGroup, parent, bar *progressbar.Bar, dataSet
 		moduleName = "更新" + moduleName
 	}
 	logger.Infof("%s: %s, begin", moduleName, dataSet.Name())
+	failed := 0
+	var first error
 	for _, code := range allCodes {
 		data := dataSet.Clone(date, code).(factors.DataSet)
-		syncDataSetByDate(data, date, op)
+		if err := syncDataSetByDate(data, date, op); err != nil {
+			failed++
+			if first == nil {
+				first = fmt.Errorf("%s[%s]: %w", dataSet.Name(), code, err)
+			}
+		}
 		bar.Add(1)
 	}
+	if failed > 0 {
+		*result = fmt.Errorf("%s: %d/%d失败, %w", moduleName, failed, len(allCodes), first)
+	}
 	parent.Add(1)
 	wg.Done()
 	logger.Infof("%s: %s, end", moduleName, dataSet.Name())
 }
 
 // DataSetUpdate 修复数据
-func DataSetUpdate(barIndex int, date string, plugins []cache.DataAdapter, op cache.OpKind) {
+//
+//	单个证券失败不中断更新, 全部执行完之后汇总返回各数据集的错误
+func DataSetUpdate(barIndex int, date string, plugins []cache.DataAdapter, op cache.OpKind) error {
 	moduleName := "基础数据"
 	if op == cache.OpRepair {
 		moduleName = "修复" + moduleName
@@ -65,38 +80,53 @@ func DataSetUpdate(barIndex int, date string, plugins []cache.DataAdapter, op ca
 		}
 	}
 	logger.Infof("%s: all, begin", moduleName)
-	// 2. 遍历全部数据插件
+	// 2. 按依赖关系分层遍历全部数据插件, 上游的数据集先执行
 	dataSetCount := len(dataSetList)
 	barCache := progressbar.NewBar(barIndex, "执行["+date+":"+moduleName+"]", dataSetCount)
 
 	allCodes := market.GetCodeList()
 	codeCount := len(allCodes)
-	var wg sync.WaitGroup
 
 	parent := coroutine.Context()
 	ctx := context.WithValue(parent, cache.KBarIndex, barIndex)
-	for sequence, dataSet := range dataSetList {
-		_ = dataSet.Init(ctx, date)
-		//format := fmt.Sprintf("%%%ds", maxWidth)
-		//title := fmt.Sprintf(format, dataSet.Name())
-
-		desc := dataSet.Name()
-		width := runewidth.StringWidth(desc)
-		title := strings.Repeat(" ", maxWidth-width) + desc
-		barNo := barIndex + 1
-		if cache.UseGoroutine {
-			barNo += sequence
+	var errs []error
+	for _, level := range cache.Levels(plugins) {
+		var wg sync.WaitGroup
+		var bars []*progressbar.Bar
+		results := make([]error, len(level))
+		for sequence, plugin := range level {
+			dataSet, ok := plugin.(factors.DataSet)
+			if !ok {
+				continue
+			}
+			if err := dataSet.Init(ctx, date); err != nil {
+				errs = append(errs, fmt.Errorf("%s: 初始化失败, %w", dataSet.Name(), err))
+			}
+			desc := dataSet.Name()
+			width := runewidth.StringWidth(desc)
+			title := strings.Repeat(" ", maxWidth-width) + desc
+			barNo := barIndex + 1
+			if cache.UseGoroutine {
+				// 同一层的数据集互不依赖, 可以并行
+				barNo += sequence
+			}
+			barCode := progressbar.NewBar(barNo, "执行["+title+"]", codeCount)
+			wg.Add(1)
+			if cache.UseGoroutine {
+				go updateOneDataSet(&wg, barCache, barCode, dataSet, date, op, allCodes, &results[sequence])
+				bars = append(bars, barCode)
+			} else {
+				updateOneDataSet(&wg, barCache, barCode, dataSet, date, op, allCodes, &results[sequence])
+				barCode.Wait()
+			}
 		}
-		barCode := progressbar.NewBar(barNo, "执行["+title+"]", codeCount)
-		wg.Add(1)
-		if cache.UseGoroutine {
-			go updateOneDataSet(&wg, barCache, barCode, dataSet, date, op, allCodes)
-		} else {
-			updateOneDataSet(&wg, barCache, barCode, dataSet, date, op, allCodes)
+		for _, bar := range bars {
+			bar.Wait()
 		}
-		barCode.Wait()
+		wg.Wait()
+		errs = append(errs, results...)
 	}
 	barCache.Wait()
-	wg.Wait()
 	logger.Infof("%s: all, end", moduleName)
+	return errors.Join(errs...)
 }
@@ -0,0 +1,187 @@
+package storages
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/factors"
+	"gitee.com/quant1x/gox/api"
+	"gitee.com/quant1x/gox/coroutine"
+	"gitee.com/quant1x/gox/logger"
+	"gitee.com/quant1x/gox/progressbar"
+	"gitee.com/quant1x/gox/runtime"
+)
+
+// RepairTask 修复队列中的一项, 修复指定日期和证券的一个数据集
+type RepairTask struct {
+	Date    string `name:"日期" dataframe:"date"`     // 交易日期
+	Dataset string `name:"数据集" dataframe:"dataset"` // 数据集关键字
+	Code    string `name:"证券代码" dataframe:"code"`   // 证券代码
+}
+
+// DoctorReport 基础数据质量检查报告
+type DoctorReport struct {
+	Begin   string              // 开始日期
+	End     string              // 结束日期
+	Codes   int                 // 检查的证券数
+	Checked []string            // 检查的数据集
+	Skipped []string            // 不支持检查的数据集
+	Issues  []factors.DataIssue // 发现的问题, 按数据集、证券代码和日期排序
+}
+
+// RepairQueue 可以修复的问题, 去重后按日期、数据集和证券代码排序
+func (r *DoctorReport) RepairQueue() []RepairTask {
+	var tasks []RepairTask
+	for _, v := range r.Issues {
+		if !v.Repairable {
+			continue
+		}
+		tasks = append(tasks, RepairTask{Date: v.Date, Dataset: v.Dataset, Code: v.Code})
+	}
+	slices.SortFunc(tasks, compareRepairTask)
+	return slices.Compact(tasks)
+}
+
+func compareRepairTask(a, b RepairTask) int {
+	return cmp.Or(cmp.Compare(a.Date, b.Date), cmp.Compare(a.Dataset, b.Dataset), cmp.Compare(a.Code, b.Code))
+}
+
+// Doctor 检查全部证券在日期范围内的基础数据
+//
+//	dates是升序的交易日, plugins为空时检查全部数据集
+func Doctor(barIndex int, codes, dates []string, plugins []cache.DataAdapter, workers int) DoctorReport {
+	report := DoctorReport{Codes: len(codes)}
+	if len(dates) == 0 {
+		return report
+	}
+	report.Begin, report.End = dates[0], dates[len(dates)-1]
+	var kinds, dateKinds []cache.Kind
+	for _, kind := range factors.DataSetKinds() {
+		if len(plugins) > 0 && !slices.ContainsFunc(plugins, func(p cache.DataAdapter) bool { return p.Kind() == kind }) {
+			continue
+		}
+		key := factors.GetDataDescript(kind).Key()
+		if factors.CheckableDataSet(kind) {
+			kinds = append(kinds, kind)
+			report.Checked = append(report.Checked, key)
+		} else if factors.CheckableDataSetByDate(kind) {
+			dateKinds = append(dateKinds, kind)
+			report.Checked = append(report.Checked, key)
+		} else {
+			report.Skipped = append(report.Skipped, key)
+		}
+	}
+	logger.Infof("数据检查: %s ~ %s, 数据集%v, begin", report.Begin, report.End, report.Checked)
+	bar := progressbar.NewBar(barIndex, "执行[数据检查]", len(codes))
+	var mutex sync.Mutex
+	wg := coroutine.NewRollingWaitGroup(max(1, workers))
+	for _, code := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer bar.Add(1)
+			defer runtime.CatchPanic("doctor: code=%s", code)
+			issues := factors.DiagnoseDataSets(code, dates, kinds...)
+			if len(issues) == 0 {
+				return
+			}
+			mutex.Lock()
+			report.Issues = append(report.Issues, issues...)
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+	bar.Wait()
+	// 全市场的数据集按交易日检查, 特征缓存按日期切换, 不能并发
+	if len(dateKinds) > 0 {
+		bar = progressbar.NewBar(barIndex, "执行[前视检查]", len(dates))
+		for _, date := range dates {
+			report.Issues = append(report.Issues, factors.DiagnoseDataSetsByDate(date, dateKinds...)...)
+			bar.Add(1)
+		}
+		bar.Wait()
+	}
+	slices.SortStableFunc(report.Issues, func(a, b factors.DataIssue) int {
+		return cmp.Or(cmp.Compare(a.Dataset, b.Dataset), cmp.Compare(a.Code, b.Code), cmp.Compare(a.Date, b.Date))
+	})
+	logger.Infof("数据检查: %s ~ %s, 问题%d个, end", report.Begin, report.End, len(report.Issues))
+	return report
+}
+
+// SaveRepairQueue 保存修复队列
+func SaveRepairQueue(filename string, tasks []RepairTask) error {
+	return api.SlicesToCsv(filename, tasks)
+}
+
+// LoadRepairQueue 加载修复队列
+func LoadRepairQueue(filename string) ([]RepairTask, error) {
+	var tasks []RepairTask
+	err := api.CsvToSlices(filename, &tasks)
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// RepairWithQueue 按修复队列修复指定日期和证券的数据集
+//
+//	每个交易日内按依赖关系分层修复, 下游的基础数据随之修复同一批证券.
+//	返回修复的日期和数据集插件, 下游的特征数据由调用方重新计算
+func RepairWithQueue(barIndex int, tasks []RepairTask) (dates []string, plugins []cache.DataAdapter) {
+	// 日期 -> 数据集关键字 -> 证券代码
+	mapTasks := map[string]map[string][]string{}
+	var keywords []string
+	for _, v := range tasks {
+		datasets, ok := mapTasks[v.Date]
+		if !ok {
+			datasets = map[string][]string{}
+			mapTasks[v.Date] = datasets
+			dates = append(dates, v.Date)
+		}
+		datasets[v.Dataset] = append(datasets[v.Dataset], v.Code)
+		if !slices.Contains(keywords, v.Dataset) {
+			keywords = append(keywords, v.Dataset)
+		}
+	}
+	slices.Sort(dates)
+	plugins = cache.PluginsWithName(cache.PluginMaskBaseData, keywords...)
+	plugins = append(plugins, cache.FilterPlugins(cache.Downstream(plugins), cache.PluginMaskBaseData)...)
+	levels := cache.Levels(plugins)
+	parent := coroutine.Context()
+	ctx := context.WithValue(parent, cache.KBarIndex, barIndex)
+	bar := progressbar.NewBar(barIndex, "执行[修复队列]", len(dates))
+	for _, date := range dates {
+		datasets := mapTasks[date]
+		// 数据类型 -> 修复过的证券代码
+		repaired := map[cache.Kind][]string{}
+		for _, level := range levels {
+			for _, plugin := range level {
+				dataSet, ok := plugin.(factors.DataSet)
+				if !ok {
+					continue
+				}
+				codes := slices.Clone(datasets[dataSet.Key()])
+				for _, kind := range cache.DependOn(dataSet) {
+					codes = append(codes, repaired[kind]...)
+				}
+				if len(codes) == 0 {
+					continue
+				}
+				slices.Sort(codes)
+				codes = slices.Compact(codes)
+				_ = dataSet.Init(ctx, date)
+				for _, code := range codes {
+					data := dataSet.Clone(date, code).(factors.DataSet)
+					syncDataSetByDate(data, date, cache.OpRepair)
+				}
+				repaired[dataSet.Kind()] = codes
+			}
+		}
+		bar.Add(1)
+	}
+	bar.Wait()
+	return dates, plugins
+}
@@ -0,0 +1,29 @@
+package storages
+
+import (
+	"slices"
+	"testing"
+
+	"gitee.com/quant1x/engine/factors"
+)
+
+func TestDoctorReportRepairQueue(t *testing.T) {
+	report := DoctorReport{
+		Issues: []factors.DataIssue{
+			{Dataset: "trans", Code: "sz000001", Date: "2024-01-03", Type: factors.IssueMissingFile, Repairable: true},
+			{Dataset: "day", Code: "sh600000", Date: "2024-01-03", Type: factors.IssueMissingDay, Repairable: true},
+			{Dataset: "day", Code: "sh600000", Date: "2024-01-03", Type: factors.IssueUnadjusted, Repairable: true},
+			{Dataset: "day", Code: "sh600000", Date: "2024-01-02", Type: factors.IssuePriceJump},
+			{Dataset: "day", Code: "sh600000", Date: "2024-01-02", Type: factors.IssueMissingDay, Repairable: true},
+		},
+	}
+	got := report.RepairQueue()
+	want := []RepairTask{
+		{Date: "2024-01-02", Dataset: "day", Code: "sh600000"},
+		{Date: "2024-01-03", Dataset: "day", Code: "sh600000"},
+		{Date: "2024-01-03", Dataset: "trans", Code: "sz000001"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("queue = %v, want %v", got, want)
+	}
+}
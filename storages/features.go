@@ -2,7 +2,10 @@ package storages
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -14,7 +17,6 @@ import (
 	"gitee.com/quant1x/gox/progressbar"
 	"gitee.com/quant1x/gox/runtime"
 	"gitee.com/quant1x/gox/tags"
-	"gitee.com/quant1x/gox/text/runewidth"
 	"gitee.com/quant1x/gox/util/treemap"
 	"gitee.com/quant1x/pkg/tablewriter"
 )
@@ -22,6 +24,34 @@ import (
 // MetricCallback 性能指标回调函数
 type MetricCallback func()
 
+var (
+	metricMutex sync.RWMutex
+	mapMetrics  = map[cache.Kind]cache.FactorMetrics{}
+)
+
+// 记录各个特征最近一次的性能指标
+func saveFactorMetrics(metrics []cache.FactorMetrics) {
+	metricMutex.Lock()
+	defer metricMutex.Unlock()
+	for _, v := range metrics {
+		mapMetrics[v.Kind] = v
+	}
+}
+
+// GetFactorMetrics 获取各个特征最近一次的性能指标, 按类型排序
+func GetFactorMetrics() []cache.FactorMetrics {
+	metricMutex.RLock()
+	defer metricMutex.RUnlock()
+	list := make([]cache.FactorMetrics, 0, len(mapMetrics))
+	for _, v := range mapMetrics {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Kind < list[j].Kind
+	})
+	return list
+}
+
 func updateStockFeature(wg *coroutine.RollingWaitGroup, bar *progressbar.Bar, feature factors.Feature, code string, cacheDate, featureDate string, op cache.OpKind, p *treemap.Map, sb *cache.ScoreBoard, now time.Time) {
 	defer runtime.CatchPanic("code[%s]: cacheDate=%s,featureDate=%s", code, cacheDate, featureDate)
 	defer sb.Add(1, time.Since(now), false, false)
@@ -35,8 +65,47 @@ func updateStockFeature(wg *coroutine.RollingWaitGroup, bar *progressbar.Bar, fe
 	wg.Done()
 }
 
+// 更新一个特征的全部证券, 返回性能指标
+func updateOneFeature(barIndex *int, barNo int, moduleName string, adapter factors.FeatureRotationAdapter, cacheDate, featureDate string, op cache.OpKind, allCodes []string) (cache.FactorMetrics, error) {
+	logger.Infof("%s: %s, begin", moduleName, adapter.Name())
+	var sb cache.ScoreBoard
+	barCode := progressbar.NewBar(barNo, "执行["+adapter.Name()+"]", len(allCodes))
+	mapFeature := treemap.NewWithStringComparator()
+	wg := coroutine.NewRollingWaitGroup(5)
+	dataSource := adapter.Factory(featureDate, "")
+	parent := coroutine.Context()
+	ctx := context.WithValue(parent, cache.KBarIndex, barIndex)
+	err := dataSource.Init(ctx, featureDate)
+	if err != nil {
+		err = fmt.Errorf("%s: 初始化失败, %w", adapter.Name(), err)
+	}
+	for _, code := range allCodes {
+		now := time.Now()
+		feature := adapter.Factory(featureDate, code).(factors.Feature)
+		if feature.Kind() != factors.FeatureHistory {
+			history := factors.GetL5History(code, cacheDate)
+			if history != nil {
+				feature = feature.FromHistory(*history)
+			}
+		}
+		sb.From(cache.GetDataAdapter(feature.Kind()))
+		wg.Add(1)
+		go updateStockFeature(wg, barCode, feature, code, cacheDate, featureDate, op, mapFeature, &sb, now)
+	}
+	wg.Wait()
+	barCode.Wait()
+	// 加载缓存
+	adapter.Checkout(cacheDate)
+	// 合并
+	adapter.Merge(mapFeature)
+	logger.Infof("%s: %s, end", moduleName, adapter.Name())
+	return sb.Metric(), err
+}
+
 // FeaturesUpdate 更新特征
-func FeaturesUpdate(barIndex *int, cacheDate, featureDate string, plugins []cache.DataAdapter, op cache.OpKind) MetricCallback {
+//
+//	按依赖关系分层执行, 上游的特征合并到缓存之后再计算下游, 同一层的特征并行
+func FeaturesUpdate(barIndex *int, cacheDate, featureDate string, plugins []cache.DataAdapter, op cache.OpKind) (MetricCallback, error) {
 	moduleName := "特征数据"
 	if op == cache.OpRepair {
 		moduleName = "修复" + moduleName
@@ -44,67 +113,44 @@ func FeaturesUpdate(barIndex *int, cacheDate, featureDate string, plugins []cach
 		moduleName = "更新" + moduleName
 	}
 	moduleName += cacheDate
-	var adapters []factors.FeatureRotationAdapter
-	maxWidth := 0
+	cacheCount := 0
 	for _, plugin := range plugins {
-		adapter, ok := plugin.(factors.FeatureRotationAdapter)
-		if ok {
-			adapters = append(adapters, adapter)
-			width := runewidth.StringWidth(adapter.Name())
-			if width > maxWidth {
-				maxWidth = width
-			}
+		if _, ok := plugin.(factors.FeatureRotationAdapter); ok {
+			cacheCount++
 		}
 	}
 	logger.Infof("%s: all, begin", moduleName)
 
-	var wgAdapter sync.WaitGroup
-	cacheCount := len(adapters)
 	barAdapter := progressbar.NewBar(*barIndex, "执行["+moduleName+"]", cacheCount)
 	allCodes := market.GetCodeList()
-	allCodes = allCodes[:]
-	codeCount := len(allCodes)
 	var metrics []cache.FactorMetrics
-	for _, adapter := range adapters {
-		logger.Infof("%s: %s, begin", moduleName, adapter.Name())
-
-		wgAdapter.Add(1)
-		var sb cache.ScoreBoard
-		barCode := progressbar.NewBar(*barIndex+1, "执行["+adapter.Name()+"]", codeCount)
-		mapFeature := treemap.NewWithStringComparator()
-		wg := coroutine.NewRollingWaitGroup(5)
-		dataSource := adapter.Factory(featureDate, "")
-		parent := coroutine.Context()
-		ctx := context.WithValue(parent, cache.KBarIndex, barIndex)
-		_ = dataSource.Init(ctx, featureDate)
-		for _, code := range allCodes {
-			now := time.Now()
-			feature := adapter.Factory(featureDate, code).(factors.Feature)
-			if feature.Kind() != factors.FeatureHistory {
-				history := factors.GetL5History(code, cacheDate)
-				if history != nil {
-					feature = feature.FromHistory(*history)
-				}
+	var errs []error
+	for _, level := range cache.Levels(plugins) {
+		var adapters []factors.FeatureRotationAdapter
+		for _, plugin := range level {
+			if adapter, ok := plugin.(factors.FeatureRotationAdapter); ok {
+				adapters = append(adapters, adapter)
 			}
-			sb.From(cache.GetDataAdapter(feature.Kind()))
-			wg.Add(1)
-			go updateStockFeature(wg, barCode, feature, code, cacheDate, featureDate, op, mapFeature, &sb, now)
 		}
-		wg.Wait()
-		barCode.Wait()
-		// 加载缓存
-		adapter.Checkout(cacheDate)
-		// 合并
-		adapter.Merge(mapFeature)
-		// 适配器进度条+1
-		barAdapter.Add(1)
-		wgAdapter.Done()
-		metrics = append(metrics, sb.Metric())
-		logger.Infof("%s: %s, end", moduleName, adapter.Name())
+		var wgAdapter sync.WaitGroup
+		results := make([]cache.FactorMetrics, len(adapters))
+		levelErrs := make([]error, len(adapters))
+		for i, adapter := range adapters {
+			wgAdapter.Add(1)
+			go func() {
+				defer wgAdapter.Done()
+				results[i], levelErrs[i] = updateOneFeature(barIndex, *barIndex+1+i, moduleName, adapter, cacheDate, featureDate, op, allCodes)
+				// 适配器进度条+1
+				barAdapter.Add(1)
+			}()
+		}
+		wgAdapter.Wait()
+		metrics = append(metrics, results...)
+		errs = append(errs, levelErrs...)
 	}
-	wgAdapter.Wait()
 	barAdapter.Wait()
 	logger.Infof("%s: all, end", moduleName)
+	saveFactorMetrics(metrics)
 	// 输出衡量性能的指标列表
 	mcb := func() {
 		metricCount := len(metrics)
@@ -122,5 +168,5 @@ func FeaturesUpdate(barIndex *int, cacheDate, featureDate string, plugins []cach
 			table.Render()
 		}
 	}
-	return mcb
+	return mcb, errors.Join(errs...)
 }
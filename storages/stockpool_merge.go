@@ -3,10 +3,10 @@ package storages
 import (
 	"path/filepath"
 	"sync"
-	"time"
 
 	"gitee.com/quant1x/data/exchange"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/models"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/logger"
@@ -18,14 +18,34 @@ const (
 
 var (
 	poolMutex sync.Mutex
+	poolPath  = "" // 股票池的目录, 为空时是QMT的缓存目录
 )
 
 // 股票池文件
 func getStockPoolFilename() string {
-	filename := filepath.Join(cache.GetQmtCachePath(), filenameStockPool)
+	path := poolPath
+	if len(path) == 0 {
+		path = cache.GetQmtCachePath()
+	}
+	filename := filepath.Join(path, filenameStockPool)
 	return filename
 }
 
+// UseStockPoolSandbox 股票池切换到沙箱目录, 返回恢复现场的函数
+//
+//	回放时策略输出的股票池写到沙箱, 不影响实盘的股票池
+func UseStockPoolSandbox(path string) func() {
+	poolMutex.Lock()
+	defer poolMutex.Unlock()
+	previous := poolPath
+	poolPath = path
+	return func() {
+		poolMutex.Lock()
+		defer poolMutex.Unlock()
+		poolPath = previous
+	}
+}
+
 // 从本地缓存加载股票池
 func getStockPoolFromCache() (list []StockPool) {
 	filename := getStockPoolFilename()
@@ -34,6 +54,13 @@ func getStockPoolFromCache() (list []StockPool) {
 	return
 }
 
+// GetStockPoolList 获取当前股票池
+func GetStockPoolList() []StockPool {
+	poolMutex.Lock()
+	defer poolMutex.Unlock()
+	return getStockPoolFromCache()
+}
+
 // 刷新本地股票池缓存
 func saveStockPoolToCache(list []StockPool) {
 	filename := getStockPoolFilename()
@@ -71,7 +98,7 @@ func stockPoolMerge(model models.Strategy, date string, orders []models.Statisti
 		cacheStatistics[sp.Key()] = &sp
 	}
 	count := len(localStockPool)
-	now := time.Now()
+	now := clock.Now()
 	updateTime := now.Format(cache.TimeStampMilli)
 	for i := 0; i < count; i++ {
 		local := &(localStockPool[i])
@@ -1,6 +1,9 @@
 package storages
 
 import (
+	"path/filepath"
+	"testing"
+
 	"gitee.com/quant1x/engine/config"
 	"gitee.com/quant1x/engine/factors"
 	"gitee.com/quant1x/engine/models"
@@ -35,3 +38,20 @@ func (m TestModel) Evaluate(securityCode string, result *concurrent.TreeMap[stri
 	//TODO implement me
 	panic("implement me")
 }
+
+func TestUseStockPoolSandbox(t *testing.T) {
+	dir := t.TempDir()
+	restore := UseStockPoolSandbox(dir)
+	saveStockPoolToCache([]StockPool{{Date: "2024-01-10", Code: "sh600600"}})
+	if got := getStockPoolFilename(); got != filepath.Join(dir, filenameStockPool) {
+		t.Errorf("sandbox filename = %s", got)
+	}
+	list := getStockPoolFromCache()
+	if len(list) != 1 || list[0].Code != "sh600600" {
+		t.Errorf("sandbox stock pool = %+v", list)
+	}
+	restore()
+	if filepath.Dir(getStockPoolFilename()) == dir {
+		t.Errorf("stock pool path not restored")
+	}
+}
@@ -2,6 +2,7 @@ package storages
 
 import (
 	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/config"
 	"gitee.com/quant1x/engine/models"
 	"gitee.com/quant1x/engine/trader"
@@ -32,7 +33,7 @@ func strategyOrderIsFinished(model models.Strategy) bool {
 // 检查买入订单, 条件满足则买入
 func checkOrderForBuy(list []StockPool, model models.Strategy, date string) bool {
 	// 1. 判断是否交易日
-	if !exchange.DateIsTradingDay() {
+	if !clock.DateIsTradingDay() {
 		// 非交易日
 		logger.Errorf("%s[%d]: 非交易日, 放弃", model.Name(), model.Code())
 		return false
@@ -52,6 +53,10 @@ func checkOrderForBuy(list []StockPool, model models.Strategy, date string) bool
 	// 4. 校对交易日期
 	tradeDate := exchange.FixTradeDate(date)
 	direction := trader.BUY
+	if halt := trader.HaltedFor(direction, models.QmtStrategyName(model)); halt != nil {
+		logger.Warnf("%s[%d]: 交易已暂停(%s), 放弃", model.Name(), model.Code(), halt)
+		return false
+	}
 	// 5. 统计指定交易日的策略已执行买入的标的数量
 	numberOfStrategy := CountStrategyOrders(tradeDate, model, direction)
 	if numberOfStrategy >= strategyParameter.Total {
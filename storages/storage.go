@@ -2,8 +2,12 @@ package storages
 
 import (
 	"path/filepath"
+	"sort"
+	"sync"
 
+	"gitee.com/quant1x/data/exchange"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/config"
 	"gitee.com/quant1x/engine/models"
 )
@@ -31,4 +35,47 @@ func OutputStatistics(model models.Strategy, date string, v []models.Statistics)
 	}
 	topN := tradeRule.Total
 	stockPoolMerge(model, date, v, topN)
+	saveStrategyResults(model, date, v)
+}
+
+// StrategyResult 策略结果
+type StrategyResult struct {
+	StrategyCode uint64              `json:"strategy_code"`
+	StrategyName string              `json:"strategy_name"`
+	Date         string              `json:"date"`
+	Statistics   []models.Statistics `json:"statistics"`
+}
+
+var (
+	resultMutex sync.RWMutex
+	mapResults  = map[uint64]StrategyResult{}
+)
+
+// 记录策略最近一次输出的结果
+func saveStrategyResults(model models.Strategy, date string, v []models.Statistics) {
+	resultMutex.Lock()
+	defer resultMutex.Unlock()
+	mapResults[model.Code()] = StrategyResult{
+		StrategyCode: model.Code(),
+		StrategyName: model.Name(),
+		Date:         exchange.FixTradeDate(date),
+		Statistics:   v,
+	}
+}
+
+// GetTodayStrategyResults 获取当日各策略输出的结果
+func GetTodayStrategyResults() []StrategyResult {
+	today := clock.CurrentlyDay()
+	resultMutex.RLock()
+	defer resultMutex.RUnlock()
+	list := []StrategyResult{}
+	for _, v := range mapResults {
+		if v.Date == today {
+			list = append(list, v)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].StrategyCode < list[j].StrategyCode
+	})
+	return list
 }
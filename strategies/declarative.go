@@ -0,0 +1,226 @@
+package strategies
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gitee.com/quant1x/data/level1/securities"
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/factors"
+	"gitee.com/quant1x/engine/models"
+	"gitee.com/quant1x/engine/tdx"
+	"gitee.com/quant1x/gox/concurrent"
+	"gitee.com/quant1x/gox/logger"
+)
+
+var (
+	ErrModelFilter = errors.New("未满足过滤条件")
+)
+
+// 表达式可以引用的对象类型
+var declarativeTypes = map[string]reflect.Type{
+	exprSnapshot: reflect.TypeOf(factors.QuoteSnapshot{}),
+	exprHistory:  reflect.TypeOf(factors.History{}),
+	exprMisc:     reflect.TypeOf(factors.Misc{}),
+	exprF10:      reflect.TypeOf(factors.F10{}),
+	exprBox:      reflect.TypeOf(factors.Box{}),
+}
+
+func init() {
+	for _, v := range config.ModelConfig() {
+		model, err := NewDeclarativeModel(v)
+		if err != nil {
+			logger.Errorf("声明式策略[%d]%s: %+v", v.Id, v.Name, err)
+			continue
+		}
+		err = models.Register(model)
+		if err != nil {
+			logger.Errorf("声明式策略[%d]%s: 注册失败, %+v", v.Id, v.Name, err)
+		}
+	}
+}
+
+// DeclarativeModel 声明式策略, 由配置文件中的表达式组成
+type DeclarativeModel struct {
+	parameter config.ModelParameter
+	filters   []*Expr
+	sortKey   *Expr
+	evaluate  []*Expr
+	target    *Expr
+	formula   *tdx.Formula
+}
+
+// NewDeclarativeModel 创建声明式策略, 编译全部表达式
+func NewDeclarativeModel(parameter config.ModelParameter) (*DeclarativeModel, error) {
+	if len(strings.TrimSpace(parameter.Name)) == 0 {
+		parameter.Name = fmt.Sprintf("%d号策略", parameter.Id)
+	}
+	switch parameter.Flag {
+	case models.OrderFlagHead, models.OrderFlagTick, models.OrderFlagTail:
+	case "":
+		parameter.Flag = models.OrderFlagTail
+	default:
+		return nil, fmt.Errorf("无效的订单标识: %s", parameter.Flag)
+	}
+	m := &DeclarativeModel{parameter: parameter}
+	for _, v := range parameter.Filters {
+		expr, err := CompileExpr(v, declarativeTypes)
+		if err != nil {
+			return nil, err
+		}
+		m.filters = append(m.filters, expr)
+	}
+	var err error
+	if len(strings.TrimSpace(parameter.SortKey)) > 0 {
+		m.sortKey, err = CompileExpr(parameter.SortKey, declarativeTypes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(strings.TrimSpace(parameter.Evaluate)) > 0 {
+		expr, err := CompileExpr(parameter.Evaluate, declarativeTypes)
+		if err != nil {
+			return nil, err
+		}
+		m.evaluate = []*Expr{expr}
+	} else {
+		m.evaluate = m.filters
+	}
+	if len(strings.TrimSpace(parameter.Target)) > 0 {
+		m.target, err = CompileExpr(parameter.Target, declarativeTypes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(strings.TrimSpace(parameter.Formula)) > 0 {
+		m.formula, err = tdx.ParseFile(parameter.Formula)
+		if err != nil {
+			return nil, err
+		}
+		if len(strings.TrimSpace(m.parameter.Signal)) == 0 {
+			m.parameter.Signal = "B"
+		}
+		if !m.formula.Defined(m.parameter.Signal) {
+			return nil, fmt.Errorf("%s: %w: %s", parameter.Formula, tdx.ErrUndefined, m.parameter.Signal)
+		}
+	}
+	return m, nil
+}
+
+func (m *DeclarativeModel) Code() models.ModelKind {
+	return m.parameter.Id
+}
+
+func (m *DeclarativeModel) Name() string {
+	return m.parameter.Name
+}
+
+func (m *DeclarativeModel) OrderFlag() string {
+	return m.parameter.Flag
+}
+
+// 构建表达式的求值环境, 盘中优先使用实时特征
+func (m *DeclarativeModel) env(snapshot *factors.QuoteSnapshot) exprEnv {
+	return exprEnv{
+		exprSnapshot: snapshot,
+		exprHistory:  snapshot.RealtimeHistory(),
+		exprMisc:     snapshot.RealtimeMisc(),
+		exprF10:      factors.GetL5F10(snapshot.SecurityCode),
+		exprBox:      snapshot.RealtimeBox(),
+	}
+}
+
+// 全部条件满足返回nil
+func matchAll(list []*Expr, env exprEnv) error {
+	for _, expr := range list {
+		ok, err := expr.EvalBool(env)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrModelFilter, expr)
+		}
+	}
+	return nil
+}
+
+func (m *DeclarativeModel) Filter(ruleParameter config.RuleParameter, snapshot factors.QuoteSnapshot) error {
+	if !m.parameter.SkipRules {
+		if err := GeneralFilter(ruleParameter, snapshot); err != nil {
+			return err
+		}
+	}
+	if len(m.filters) == 0 {
+		return nil
+	}
+	return matchAll(m.filters, m.env(&snapshot))
+}
+
+func (m *DeclarativeModel) Sort(snapshots []factors.QuoteSnapshot) models.SortedStatus {
+	if m.sortKey == nil {
+		return models.SortDefault
+	}
+	keys := make(map[string]float64, len(snapshots))
+	for i := range snapshots {
+		key, err := m.sortKey.EvalFloat(m.env(&snapshots[i]))
+		if err != nil {
+			// 无法计算排序值的排在最后
+			continue
+		}
+		keys[snapshots[i].SecurityCode] = key
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		a, okA := keys[snapshots[i].SecurityCode]
+		b, okB := keys[snapshots[j].SecurityCode]
+		if okA != okB {
+			return okA
+		}
+		if m.parameter.SortAsc {
+			return a < b
+		}
+		return a > b
+	})
+	return models.SortFinished
+}
+
+func (m *DeclarativeModel) Evaluate(securityCode string, result *concurrent.TreeMap[string, models.ResultInfo]) {
+	snapshot := models.GetStrategySnapshot(securityCode)
+	if snapshot == nil {
+		return
+	}
+	env := m.env(snapshot)
+	if err := matchAll(m.evaluate, env); err != nil {
+		return
+	}
+	if m.formula != nil {
+		ok, err := m.formula.Signal(factors.KLine(securityCode), m.parameter.Params, m.parameter.Signal)
+		if err != nil {
+			logger.Errorf("%s[%d]: %s, 计算公式信号失败: %+v", m.Name(), m.Code(), securityCode, err)
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+	price := snapshot.Price
+	target := price
+	if m.target != nil {
+		v, err := m.target.EvalFloat(env)
+		if err != nil {
+			logger.Errorf("%s[%d]: %s, 计算目标价格失败: %+v", m.Name(), m.Code(), securityCode, err)
+			return
+		}
+		target = v
+	}
+	result.Put(securityCode, models.ResultInfo{Code: securityCode,
+		Name:         securities.GetStockName(securityCode),
+		Date:         snapshot.Date,
+		Rate:         snapshot.ChangeRate,
+		Buy:          price,
+		Sell:         target,
+		StrategyCode: m.Code(),
+		StrategyName: m.Name()})
+}
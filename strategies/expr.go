@@ -0,0 +1,396 @@
+package strategies
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 表达式可以引用的对象
+//
+//	snapshot: factors.QuoteSnapshot
+//	history : factors.History
+//	misc    : factors.Misc
+//	f10     : factors.F10
+//	box     : factors.Box
+const (
+	exprSnapshot = "snapshot"
+	exprHistory  = "history"
+	exprMisc     = "misc"
+	exprF10      = "f10"
+	exprBox      = "box"
+)
+
+var (
+	ErrExprSyntax      = errors.New("表达式语法错误")
+	ErrExprUnsupported = errors.New("表达式不支持的语法")
+	ErrExprType        = errors.New("表达式类型错误")
+	ErrExprNilObject   = errors.New("表达式引用的数据不存在")
+)
+
+// exprEnv 表达式的求值环境, 对象名到结构体指针
+type exprEnv map[string]any
+
+// Expr 编译后的表达式
+//
+//	语法是Go表达式的子集: 数字、字符串、布尔字面量, 对象字段(如history.MA5),
+//	算术运算 + - * / %, 比较运算 == != < <= > >=, 逻辑运算 && || !, 括号,
+//	以及函数 abs/min/max
+type Expr struct {
+	source string
+	node   ast.Expr
+}
+
+// String 表达式源码
+func (e *Expr) String() string {
+	return e.source
+}
+
+// exprFunctions 内置函数
+var exprFunctions = map[string]func(args []float64) (float64, error){
+	"abs": func(args []float64) (float64, error) {
+		if len(args) != 1 {
+			return 0, fmt.Errorf("%w: abs需要1个参数", ErrExprSyntax)
+		}
+		return math.Abs(args[0]), nil
+	},
+	"min": func(args []float64) (float64, error) {
+		if len(args) == 0 {
+			return 0, fmt.Errorf("%w: min至少需要1个参数", ErrExprSyntax)
+		}
+		v := args[0]
+		for _, a := range args[1:] {
+			v = math.Min(v, a)
+		}
+		return v, nil
+	},
+	"max": func(args []float64) (float64, error) {
+		if len(args) == 0 {
+			return 0, fmt.Errorf("%w: max至少需要1个参数", ErrExprSyntax)
+		}
+		v := args[0]
+		for _, a := range args[1:] {
+			v = math.Max(v, a)
+		}
+		return v, nil
+	},
+}
+
+// CompileExpr 编译表达式, types是对象名到结构体类型的映射, 用于校验字段是否存在
+func CompileExpr(source string, types map[string]reflect.Type) (*Expr, error) {
+	source = strings.TrimSpace(source)
+	if len(source) == 0 {
+		return nil, fmt.Errorf("%w: 表达式为空", ErrExprSyntax)
+	}
+	node, err := parser.ParseExpr(source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s, %v", ErrExprSyntax, source, err)
+	}
+	if err = checkExprNode(node, types); err != nil {
+		return nil, err
+	}
+	return &Expr{source: source, node: node}, nil
+}
+
+// 校验表达式只使用了支持的语法, 引用的对象和字段存在
+func checkExprNode(node ast.Expr, types map[string]reflect.Type) error {
+	switch v := node.(type) {
+	case *ast.BasicLit:
+		if v.Kind != token.INT && v.Kind != token.FLOAT && v.Kind != token.STRING {
+			return fmt.Errorf("%w: %s", ErrExprUnsupported, v.Value)
+		}
+		return nil
+	case *ast.Ident:
+		if v.Name != "true" && v.Name != "false" {
+			return fmt.Errorf("%w: 未知的标识符 %s", ErrExprSyntax, v.Name)
+		}
+		return nil
+	case *ast.ParenExpr:
+		return checkExprNode(v.X, types)
+	case *ast.UnaryExpr:
+		return checkExprNode(v.X, types)
+	case *ast.BinaryExpr:
+		if err := checkExprNode(v.X, types); err != nil {
+			return err
+		}
+		return checkExprNode(v.Y, types)
+	case *ast.SelectorExpr:
+		return checkExprSelector(v, types)
+	case *ast.CallExpr:
+		ident, ok := v.Fun.(*ast.Ident)
+		if !ok {
+			return fmt.Errorf("%w: 函数调用", ErrExprUnsupported)
+		}
+		if _, found := exprFunctions[ident.Name]; !found {
+			return fmt.Errorf("%w: 未知的函数 %s", ErrExprSyntax, ident.Name)
+		}
+		for _, arg := range v.Args {
+			if err := checkExprNode(arg, types); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %T", ErrExprUnsupported, node)
+}
+
+// 校验对象和字段是否存在
+func checkExprSelector(v *ast.SelectorExpr, types map[string]reflect.Type) error {
+	ident, ok := v.X.(*ast.Ident)
+	if !ok {
+		return fmt.Errorf("%w: 只支持一级字段访问", ErrExprUnsupported)
+	}
+	t, found := types[ident.Name]
+	if !found {
+		return fmt.Errorf("%w: 未知的对象 %s", ErrExprSyntax, ident.Name)
+	}
+	if _, found = exprFieldIndex(t, v.Sel.Name); !found {
+		return fmt.Errorf("%w: %s没有字段 %s", ErrExprSyntax, ident.Name, v.Sel.Name)
+	}
+	return nil
+}
+
+// 查找字段, 先按字段名, 其次按dataframe标签
+func exprFieldIndex(t reflect.Type, name string) (int, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return 0, false
+	}
+	if f, ok := t.FieldByName(name); ok && len(f.Index) == 1 && f.IsExported() {
+		return f.Index[0], true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.IsExported() && f.Tag.Get("dataframe") == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Eval 表达式求值
+func (e *Expr) Eval(env exprEnv) (any, error) {
+	return exprEval(e.node, env)
+}
+
+// EvalBool 布尔表达式求值
+func (e *Expr) EvalBool(env exprEnv) (bool, error) {
+	v, err := e.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: %s 不是布尔表达式", ErrExprType, e.source)
+	}
+	return b, nil
+}
+
+// EvalFloat 数值表达式求值
+func (e *Expr) EvalFloat(env exprEnv) (float64, error) {
+	v, err := e.Eval(env)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s 不是数值表达式", ErrExprType, e.source)
+	}
+	return f, nil
+}
+
+func exprEval(node ast.Expr, env exprEnv) (any, error) {
+	switch v := node.(type) {
+	case *ast.ParenExpr:
+		return exprEval(v.X, env)
+	case *ast.BasicLit:
+		switch v.Kind {
+		case token.INT, token.FLOAT:
+			return strconv.ParseFloat(v.Value, 64)
+		case token.STRING:
+			return strconv.Unquote(v.Value)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrExprUnsupported, v.Value)
+	case *ast.Ident:
+		return v.Name == "true", nil
+	case *ast.SelectorExpr:
+		return exprSelect(v, env)
+	case *ast.UnaryExpr:
+		x, err := exprEval(v.X, env)
+		if err != nil {
+			return nil, err
+		}
+		switch v.Op {
+		case token.NOT:
+			b, ok := x.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%w: !需要布尔值", ErrExprType)
+			}
+			return !b, nil
+		case token.SUB, token.ADD:
+			f, ok := x.(float64)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s需要数值", ErrExprType, v.Op)
+			}
+			if v.Op == token.SUB {
+				f = -f
+			}
+			return f, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrExprUnsupported, v.Op)
+	case *ast.BinaryExpr:
+		return exprBinary(v, env)
+	case *ast.CallExpr:
+		ident := v.Fun.(*ast.Ident)
+		args := make([]float64, len(v.Args))
+		for i, arg := range v.Args {
+			x, err := exprEval(arg, env)
+			if err != nil {
+				return nil, err
+			}
+			f, ok := x.(float64)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s的参数需要数值", ErrExprType, ident.Name)
+			}
+			args[i] = f
+		}
+		return exprFunctions[ident.Name](args)
+	}
+	return nil, fmt.Errorf("%w: %T", ErrExprUnsupported, node)
+}
+
+// 读取对象字段, 数值统一转换成float64
+func exprSelect(v *ast.SelectorExpr, env exprEnv) (any, error) {
+	name := v.X.(*ast.Ident).Name
+	obj, ok := env[name]
+	if !ok || obj == nil {
+		return nil, fmt.Errorf("%w: %s", ErrExprNilObject, name)
+	}
+	value := reflect.ValueOf(obj)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, fmt.Errorf("%w: %s", ErrExprNilObject, name)
+		}
+		value = value.Elem()
+	}
+	index, found := exprFieldIndex(value.Type(), v.Sel.Name)
+	if !found {
+		return nil, fmt.Errorf("%w: %s没有字段 %s", ErrExprSyntax, name, v.Sel.Name)
+	}
+	field := value.Field(index)
+	switch field.Kind() {
+	case reflect.Bool:
+		return field.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), nil
+	case reflect.String:
+		return field.String(), nil
+	}
+	return nil, fmt.Errorf("%w: %s.%s", ErrExprType, name, v.Sel.Name)
+}
+
+func exprBinary(v *ast.BinaryExpr, env exprEnv) (any, error) {
+	x, err := exprEval(v.X, env)
+	if err != nil {
+		return nil, err
+	}
+	// 逻辑运算短路求值
+	if v.Op == token.LAND || v.Op == token.LOR {
+		a, ok := x.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s需要布尔值", ErrExprType, v.Op)
+		}
+		if (v.Op == token.LAND && !a) || (v.Op == token.LOR && a) {
+			return a, nil
+		}
+		y, err := exprEval(v.Y, env)
+		if err != nil {
+			return nil, err
+		}
+		b, ok := y.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s需要布尔值", ErrExprType, v.Op)
+		}
+		return b, nil
+	}
+	y, err := exprEval(v.Y, env)
+	if err != nil {
+		return nil, err
+	}
+	switch a := x.(type) {
+	case float64:
+		b, ok := y.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s两边类型不一致", ErrExprType, v.Op)
+		}
+		switch v.Op {
+		case token.ADD:
+			return a + b, nil
+		case token.SUB:
+			return a - b, nil
+		case token.MUL:
+			return a * b, nil
+		case token.QUO:
+			return a / b, nil
+		case token.REM:
+			return math.Mod(a, b), nil
+		case token.EQL:
+			return a == b, nil
+		case token.NEQ:
+			return a != b, nil
+		case token.LSS:
+			return a < b, nil
+		case token.LEQ:
+			return a <= b, nil
+		case token.GTR:
+			return a > b, nil
+		case token.GEQ:
+			return a >= b, nil
+		}
+	case string:
+		b, ok := y.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s两边类型不一致", ErrExprType, v.Op)
+		}
+		switch v.Op {
+		case token.ADD:
+			return a + b, nil
+		case token.EQL:
+			return a == b, nil
+		case token.NEQ:
+			return a != b, nil
+		case token.LSS:
+			return a < b, nil
+		case token.LEQ:
+			return a <= b, nil
+		case token.GTR:
+			return a > b, nil
+		case token.GEQ:
+			return a >= b, nil
+		}
+	case bool:
+		b, ok := y.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s两边类型不一致", ErrExprType, v.Op)
+		}
+		switch v.Op {
+		case token.EQL:
+			return a == b, nil
+		case token.NEQ:
+			return a != b, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrExprUnsupported, v.Op)
+}
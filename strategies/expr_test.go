@@ -0,0 +1,78 @@
+package strategies
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type exprTestQuote struct {
+	Code   string  `dataframe:"code"`
+	Price  float64 `dataframe:"price"`
+	Volume int     `dataframe:"vol"`
+	Buy    bool
+}
+
+var exprTestTypes = map[string]reflect.Type{
+	"q": reflect.TypeOf(exprTestQuote{}),
+}
+
+func TestExprEval(t *testing.T) {
+	env := exprEnv{"q": &exprTestQuote{Code: "sh600000", Price: 10.5, Volume: 300, Buy: true}}
+	tests := []struct {
+		source string
+		want   any
+	}{
+		{"q.Price > 10 && q.Volume >= 300", true},
+		{"q.price * 2 - 1", float64(20)},
+		{"q.vol % 7", float64(6)},
+		{"!q.Buy || q.Price < 0", false},
+		{`q.Code == "sh600000"`, true},
+		{"max(q.Price, 11, abs(-12)) + min(1, 2)", float64(13)},
+		{"-(q.Price - 0.5)", float64(-10)},
+	}
+	for _, v := range tests {
+		expr, err := CompileExpr(v.source, exprTestTypes)
+		if err != nil {
+			t.Fatalf("%s: %v", v.source, err)
+		}
+		got, err := expr.Eval(env)
+		if err != nil {
+			t.Fatalf("%s: %v", v.source, err)
+		}
+		if got != v.want {
+			t.Errorf("%s = %v, want %v", v.source, got, v.want)
+		}
+	}
+}
+
+func TestExprCompileError(t *testing.T) {
+	tests := []struct {
+		source string
+		err    error
+	}{
+		{"q.Price >", ErrExprSyntax},
+		{"q.NotExists > 1", ErrExprSyntax},
+		{"x.Price > 1", ErrExprSyntax},
+		{"unknown(q.Price)", ErrExprSyntax},
+		{"q.Price[0]", ErrExprUnsupported},
+	}
+	for _, v := range tests {
+		_, err := CompileExpr(v.source, exprTestTypes)
+		if !errors.Is(err, v.err) {
+			t.Errorf("%s: err = %v, want %v", v.source, err, v.err)
+		}
+	}
+}
+
+func TestExprNilObject(t *testing.T) {
+	expr, err := CompileExpr("q.Price > 1", exprTestTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var quote *exprTestQuote
+	_, err = expr.EvalBool(exprEnv{"q": quote})
+	if !errors.Is(err, ErrExprNilObject) {
+		t.Errorf("err = %v, want %v", err, ErrExprNilObject)
+	}
+}
@@ -63,14 +63,32 @@ func (m ModelNo1) Evaluate(securityCode string, result *concurrent.TreeMap[strin
 		return
 	}
 
+	// 两个金叉在同一天
+	c1, c2 := no1Cross(history, snapshot.Price)
+	if c1 && c2 {
+		price := snapshot.Price
+		date := snapshot.Date
+		result.Put(securityCode, models.ResultInfo{Code: securityCode,
+			Name:         securities.GetStockName(securityCode),
+			Date:         date,
+			Rate:         0.00,
+			Buy:          price,
+			Sell:         price * 1.05,
+			StrategyCode: m.Code(),
+			StrategyName: m.Name()})
+	}
+}
+
+// 5日线上穿10日线和10日线上穿20日线, 以昨日均线和最新价增量计算当日均线
+func no1Cross(history *factors.History, price float64) (c1, c2 bool) {
 	// 取出昨日的数据
 	r1MA5 := history.MA5
 	r1MA10 := history.MA10
 	r1MA20 := history.MA20
 
-	ma5 := realtime.IncrementalMovingAverage(history.MA4, 5, snapshot.Price)
-	ma10 := realtime.IncrementalMovingAverage(history.MA9, 10, snapshot.Price)
-	ma20 := realtime.IncrementalMovingAverage(history.MA19, 20, snapshot.Price)
+	ma5 := realtime.IncrementalMovingAverage(history.MA4, 5, price)
+	ma10 := realtime.IncrementalMovingAverage(history.MA9, 10, price)
+	ma20 := realtime.IncrementalMovingAverage(history.MA19, 20, price)
 
 	// 组织series
 	s5 := pandas.ToSeries(r1MA5, ma5)
@@ -78,21 +96,7 @@ func (m ModelNo1) Evaluate(securityCode string, result *concurrent.TreeMap[strin
 	s20 := pandas.ToSeries(r1MA20, ma20)
 
 	// 两个金叉
-	c1 := CROSS(s5, s10)
-	c2 := CROSS(s10, s20)
-	// 横向对比
-	d := c1.And(c2)
-	s := utils.BoolIndexOf(d, -1)
-	if s {
-		price := snapshot.Price
-		date := snapshot.Date
-		result.Put(securityCode, models.ResultInfo{Code: securityCode,
-			Name:         securities.GetStockName(securityCode),
-			Date:         date,
-			Rate:         0.00,
-			Buy:          price,
-			Sell:         price * 1.05,
-			StrategyCode: m.Code(),
-			StrategyName: m.Name()})
-	}
+	cross1 := CROSS(s5, s10)
+	cross2 := CROSS(s10, s20)
+	return utils.BoolIndexOf(cross1, -1), utils.BoolIndexOf(cross2, -1)
 }
@@ -0,0 +1,85 @@
+package strategies
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitee.com/quant1x/engine/factors"
+	"gitee.com/quant1x/engine/tdx"
+	"gitee.com/quant1x/engine/utils"
+	"gitee.com/quant1x/pandas"
+	. "gitee.com/quant1x/pandas/formula"
+)
+
+const (
+	// 对照用的K线, 和indicators共用
+	conformanceFixture = "../tdx/testdata/kline.csv"
+	// 对照时跳过的K线数
+	conformanceWarmup = 120
+)
+
+// 每个公式文件都必须有对照
+func TestTdxConformanceCoverage(t *testing.T) {
+	files, err := filepath.Glob("*.tdx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if file != "no1.tdx" {
+			t.Errorf("%s: 没有对照", file)
+		}
+	}
+}
+
+// 1号策略按昨日均线增量计算当日均线, 和公式逐日对照金叉
+func TestTdxConformanceNo1(t *testing.T) {
+	if _, err := os.Stat(conformanceFixture); err != nil {
+		t.Fatalf("对照数据不存在: %+v", err)
+	}
+	df := pandas.ReadCSV(conformanceFixture)
+	if df.Nrow() <= conformanceWarmup {
+		t.Fatalf("%s: K线数%d, 不足%d", conformanceFixture, df.Nrow(), conformanceWarmup)
+	}
+	formula, err := tdx.ParseFile("no1.tdx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := formula.EvalDataFrame(df, nil, "C1", "C2", "B")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		dates = df.Col("date").Strings()
+		CLOSE = df.ColAsNDArray("close")
+		ma4   = MA(CLOSE, 4)
+		ma5   = MA(CLOSE, 5)
+		ma9   = MA(CLOSE, 9)
+		ma10  = MA(CLOSE, 10)
+		ma19  = MA(CLOSE, 19)
+		ma20  = MA(CLOSE, 20)
+		price = CLOSE.Float64s()
+		C1    = result.Col("C1").Float64s()
+		C2    = result.Col("C2").Float64s()
+		B     = result.Col("B").Float64s()
+	)
+	for i := conformanceWarmup; i < df.Nrow(); i++ {
+		// 以前一日的K线作为历史数据
+		history := &factors.History{
+			MA4:  utils.Float64IndexOf(ma4, i-1),
+			MA5:  utils.Float64IndexOf(ma5, i-1),
+			MA9:  utils.Float64IndexOf(ma9, i-1),
+			MA10: utils.Float64IndexOf(ma10, i-1),
+			MA19: utils.Float64IndexOf(ma19, i-1),
+			MA20: utils.Float64IndexOf(ma20, i-1),
+		}
+		c1, c2 := no1Cross(history, price[i])
+		if c1 != (C1[i] == 1) || c2 != (C2[i] == 1) {
+			t.Errorf("%s: cross = %t/%t, want %v/%v", dates[i], c1, c2, C1[i], C2[i])
+		}
+		// 策略要求两个金叉在同一天, 比公式的3天内更严格
+		if c1 && c2 && B[i] != 1 {
+			t.Errorf("%s: 策略信号成立, 公式B = %v", dates[i], B[i])
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package tdx
+
+import (
+	"fmt"
+
+	"gitee.com/quant1x/pandas"
+)
+
+// K线数据中公式可以引用的列
+var klineColumns = []string{"open", "close", "high", "low", "volume", "amount"}
+
+// 从K线中提取公式需要的数据列
+func klineSeries(df pandas.DataFrame) map[string]Series {
+	columns := make(map[string]Series, len(klineColumns))
+	for _, name := range klineColumns {
+		s := df.Col(name)
+		if s == nil {
+			continue
+		}
+		columns[name] = s.Float64s()
+	}
+	return columns
+}
+
+// EvalDataFrame 以K线计算公式, K线一般来自factors.KLine
+//
+//	返回date列和输出变量列, 逻辑值用1和0表示
+func (f *Formula) EvalDataFrame(df pandas.DataFrame, params map[string]float64, outputs ...string) (pandas.DataFrame, error) {
+	result, err := f.Eval(klineSeries(df), params, outputs...)
+	if err != nil {
+		return pandas.DataFrame{}, err
+	}
+	list := make([]pandas.Series, 0, len(result.Names)+1)
+	if date := df.Col("date"); date != nil {
+		list = append(list, pandas.NewSeriesWithType(pandas.SERIES_TYPE_STRING, "date", date.Strings()))
+	}
+	for _, name := range result.Names {
+		list = append(list, pandas.NewSeriesWithType(pandas.SERIES_TYPE_DTYPE, name, result.Outputs[name]))
+	}
+	return pandas.NewDataFrame(list...), nil
+}
+
+// Signal 公式作为策略信号, 最后一根K线的信号变量非0即为信号成立
+func (f *Formula) Signal(df pandas.DataFrame, params map[string]float64, name string) (bool, error) {
+	if df.Nrow() == 0 {
+		return false, nil
+	}
+	if !f.Defined(name) {
+		return false, fmt.Errorf("%w: %s", ErrUndefined, name)
+	}
+	result, err := f.Eval(klineSeries(df), params, name)
+	if err != nil {
+		return false, err
+	}
+	return isTrue(result.Last(name)), nil
+}
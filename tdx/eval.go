@@ -0,0 +1,289 @@
+package tdx
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Series 序列, 逻辑值用1和0表示, 无效值是NaN
+type Series = []float64
+
+// 行情数据列的别名, 通达信名称 -> K线列名
+var columnAliases = map[string]string{
+	"OPEN":   "open",
+	"O":      "open",
+	"CLOSE":  "close",
+	"C":      "close",
+	"HIGH":   "high",
+	"H":      "high",
+	"LOW":    "low",
+	"L":      "low",
+	"VOL":    "volume",
+	"V":      "volume",
+	"VOLUME": "volume",
+	"AMOUNT": "amount",
+	"AMO":    "amount",
+}
+
+// 绘图函数, 语句只用于画图, 求值时忽略
+var drawFunctions = map[string]bool{
+	"DRAWICON":   true,
+	"DRAWTEXT":   true,
+	"DRAWLINE":   true,
+	"DRAWKLINE":  true,
+	"DRAWNUMBER": true,
+	"DRAWBAND":   true,
+	"STICKLINE":  true,
+	"PLOYLINE":   true,
+	"POLYLINE":   true,
+}
+
+// 每个交易日的交易分钟数
+const totalMinutes = 240
+
+// Result 公式计算结果
+type Result struct {
+	Length  int               // 数据长度
+	Names   []string          // 输出变量, 按定义顺序
+	Outputs map[string]Series // 输出变量的值
+}
+
+// Get 获取输出变量
+func (r *Result) Get(name string) (Series, bool) {
+	v, ok := r.Outputs[strings.ToUpper(name)]
+	return v, ok
+}
+
+// Last 输出变量最后一根K线的值
+func (r *Result) Last(name string) float64 {
+	v, ok := r.Get(name)
+	if !ok || len(v) == 0 {
+		return math.NaN()
+	}
+	return v[len(v)-1]
+}
+
+// 求值环境
+type evaluator struct {
+	formula  *Formula
+	length   int
+	columns  map[string]Series  // 行情数据, 列名小写
+	params   map[string]float64 // 公式参数, 大写
+	values   map[string]Series  // 已计算的变量
+	visiting map[string]bool    // 正在计算的变量, 用于检测循环引用
+}
+
+// Eval 计算公式
+//
+//	columns: K线数据, 列名为open/close/high/low/volume/amount, 长度必须一致
+//	params : 公式参数, 比如89K公式的N1
+//	outputs: 需要计算的输出变量, 为空时计算全部输出变量, 只计算依赖到的语句
+func (f *Formula) Eval(columns map[string]Series, params map[string]float64, outputs ...string) (*Result, error) {
+	e := &evaluator{
+		formula:  f,
+		length:   -1,
+		columns:  map[string]Series{},
+		params:   map[string]float64{},
+		values:   map[string]Series{},
+		visiting: map[string]bool{},
+	}
+	for k, v := range columns {
+		name := strings.ToLower(k)
+		if e.length >= 0 && len(v) != e.length {
+			return nil, fmt.Errorf("%w: 数据列%s长度%d, 期望%d", ErrArguments, k, len(v), e.length)
+		}
+		e.length = len(v)
+		e.columns[name] = v
+	}
+	if e.length < 0 {
+		e.length = 0
+	}
+	for k, v := range params {
+		e.params[strings.ToUpper(k)] = v
+	}
+	if len(outputs) == 0 {
+		outputs = f.Outputs()
+	}
+	result := &Result{Length: e.length, Outputs: map[string]Series{}}
+	for _, name := range outputs {
+		name = strings.ToUpper(name)
+		v, err := e.variable(name)
+		if err != nil {
+			return nil, err
+		}
+		result.Names = append(result.Names, name)
+		result.Outputs[name] = v
+	}
+	return result, nil
+}
+
+// 常量序列
+func (e *evaluator) constant(v float64) Series {
+	s := make(Series, e.length)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
+
+// 变量求值, 按 公式变量 > 参数 > 行情数据 的顺序查找
+func (e *evaluator) variable(name string) (Series, error) {
+	if v, ok := e.values[name]; ok {
+		return v, nil
+	}
+	if index, ok := e.formula.variables[name]; ok {
+		if e.visiting[name] {
+			return nil, fmt.Errorf("%w: %s", ErrCycle, name)
+		}
+		e.visiting[name] = true
+		stmt := e.formula.Statements[index]
+		if isDrawStatement(stmt) {
+			return nil, fmt.Errorf("%w: 第%d行, %s是绘图语句", ErrUnsupported, stmt.Line, name)
+		}
+		v, err := e.eval(stmt.expr)
+		delete(e.visiting, name)
+		if err != nil {
+			return nil, fmt.Errorf("第%d行, %s: %w", stmt.Line, name, err)
+		}
+		e.values[name] = v
+		return v, nil
+	}
+	if v, ok := e.params[name]; ok {
+		return e.constant(v), nil
+	}
+	if column, ok := columnAliases[name]; ok {
+		if v, found := e.columns[column]; found {
+			return v, nil
+		}
+	}
+	if v, ok := e.columns[strings.ToLower(name)]; ok {
+		return v, nil
+	}
+	switch name {
+	case "DRAWNULL":
+		return e.constant(math.NaN()), nil
+	case "CURRBARSCOUNT":
+		// 到最后一根K线的周期数, 最后一根K线为1
+		s := make(Series, e.length)
+		for i := range s {
+			s[i] = float64(e.length - i)
+		}
+		return s, nil
+	case "TOTALFZNUM":
+		return e.constant(totalMinutes), nil
+	case "FROMOPEN":
+		// 历史K线视为已收盘, 盘中计算时通过参数传入开盘以来的分钟数
+		return e.constant(totalMinutes), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUndefined, name)
+}
+
+// 是否绘图语句
+func isDrawStatement(stmt Statement) bool {
+	call, ok := stmt.expr.(*callNode)
+	return ok && drawFunctions[call.name]
+}
+
+func (e *evaluator) eval(n node) (Series, error) {
+	switch v := n.(type) {
+	case *numberNode:
+		return e.constant(v.value), nil
+	case *stringNode:
+		return nil, fmt.Errorf("%w: 字符串'%s'", ErrUnsupported, v.value)
+	case *identNode:
+		return e.variable(v.name)
+	case *unaryNode:
+		x, err := e.eval(v.x)
+		if err != nil {
+			return nil, err
+		}
+		if v.op == "+" {
+			return x, nil
+		}
+		s := make(Series, len(x))
+		for i := range x {
+			s[i] = -x[i]
+		}
+		return s, nil
+	case *binaryNode:
+		x, err := e.eval(v.x)
+		if err != nil {
+			return nil, err
+		}
+		y, err := e.eval(v.y)
+		if err != nil {
+			return nil, err
+		}
+		return binary(v.op, x, y)
+	case *callNode:
+		fn, ok := functions[v.name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupported, v.name)
+		}
+		if fn.args >= 0 && len(v.args) != fn.args {
+			return nil, fmt.Errorf("%w: %s需要%d个参数, 实际是%d个", ErrArguments, v.name, fn.args, len(v.args))
+		}
+		args := make([]Series, len(v.args))
+		for i, arg := range v.args {
+			s, err := e.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = s
+		}
+		return fn.call(args)
+	}
+	return nil, fmt.Errorf("%w: %T", ErrSyntax, n)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// 逻辑真, NaN视为假
+func isTrue(v float64) bool {
+	return v != 0 && !math.IsNaN(v)
+}
+
+func binary(op string, x, y Series) (Series, error) {
+	s := make(Series, len(x))
+	for i := range x {
+		a, b := x[i], y[i]
+		switch op {
+		case "+":
+			s[i] = a + b
+		case "-":
+			s[i] = a - b
+		case "*":
+			s[i] = a * b
+		case "/":
+			s[i] = a / b
+			if b == 0 {
+				s[i] = math.NaN()
+			}
+		case "=":
+			s[i] = boolValue(a == b)
+		case "<>", "!=":
+			s[i] = boolValue(a != b && !math.IsNaN(a) && !math.IsNaN(b))
+		case ">":
+			s[i] = boolValue(a > b)
+		case "<":
+			s[i] = boolValue(a < b)
+		case ">=":
+			s[i] = boolValue(a >= b)
+		case "<=":
+			s[i] = boolValue(a <= b)
+		case "AND":
+			s[i] = boolValue(isTrue(a) && isTrue(b))
+		case "OR":
+			s[i] = boolValue(isTrue(a) || isTrue(b))
+		default:
+			return nil, fmt.Errorf("%w: 运算符%s", ErrSyntax, op)
+		}
+	}
+	return s, nil
+}
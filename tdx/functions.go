@@ -0,0 +1,399 @@
+package tdx
+
+import (
+	"math"
+)
+
+// function 内置函数
+type function struct {
+	args int // 参数个数, -1表示不固定
+	call func(args []Series) (Series, error)
+}
+
+// 内置函数表
+var functions = map[string]function{
+	"MA":            {args: 2, call: func(a []Series) (Series, error) { return MA(a[0], a[1]), nil }},
+	"EMA":           {args: 2, call: func(a []Series) (Series, error) { return EMA(a[0], a[1]), nil }},
+	"SMA":           {args: 3, call: func(a []Series) (Series, error) { return SMA(a[0], a[1], a[2]), nil }},
+	"WMA":           {args: 2, call: func(a []Series) (Series, error) { return WMA(a[0], a[1]), nil }},
+	"REF":           {args: 2, call: func(a []Series) (Series, error) { return REF(a[0], a[1]), nil }},
+	"HHV":           {args: 2, call: func(a []Series) (Series, error) { return HHV(a[0], a[1]), nil }},
+	"LLV":           {args: 2, call: func(a []Series) (Series, error) { return LLV(a[0], a[1]), nil }},
+	"SUM":           {args: 2, call: func(a []Series) (Series, error) { return SUM(a[0], a[1]), nil }},
+	"COUNT":         {args: 2, call: func(a []Series) (Series, error) { return COUNT(a[0], a[1]), nil }},
+	"CROSS":         {args: 2, call: func(a []Series) (Series, error) { return CROSS(a[0], a[1]), nil }},
+	"BARSLAST":      {args: 1, call: func(a []Series) (Series, error) { return BARSLAST(a[0]), nil }},
+	"BARSLASTCOUNT": {args: 1, call: func(a []Series) (Series, error) { return BARSLASTCOUNT(a[0]), nil }},
+	"BARSSINCEN":    {args: 2, call: func(a []Series) (Series, error) { return BARSSINCEN(a[0], a[1]), nil }},
+	"BARSCOUNT":     {args: 1, call: func(a []Series) (Series, error) { return BARSCOUNT(a[0]), nil }},
+	"LONGCROSS":     {args: 3, call: func(a []Series) (Series, error) { return LONGCROSS(a[0], a[1], a[2]), nil }},
+	"FILTER":        {args: 2, call: func(a []Series) (Series, error) { return FILTER(a[0], a[1]), nil }},
+	"STDP":          {args: 2, call: func(a []Series) (Series, error) { return STDP(a[0], a[1]), nil }},
+	"EXPMA":         {args: 2, call: func(a []Series) (Series, error) { return EMA(a[0], a[1]), nil }},
+	"ZTPRICE":       {args: 2, call: func(a []Series) (Series, error) { return ZTPRICE(a[0], a[1]), nil }},
+	"IF":            {args: 3, call: func(a []Series) (Series, error) { return IF(a[0], a[1], a[2]), nil }},
+	"IFF":           {args: 3, call: func(a []Series) (Series, error) { return IF(a[0], a[1], a[2]), nil }},
+	"IFN":           {args: 3, call: func(a []Series) (Series, error) { return IF(a[0], a[2], a[1]), nil }},
+	"NOT":           {args: 1, call: func(a []Series) (Series, error) { return NOT(a[0]), nil }},
+	"ABS":           {args: 1, call: func(a []Series) (Series, error) { return apply(a[0], math.Abs), nil }},
+	"MAX":           {args: 2, call: func(a []Series) (Series, error) { return apply2(a[0], a[1], math.Max), nil }},
+	"MIN":           {args: 2, call: func(a []Series) (Series, error) { return apply2(a[0], a[1], math.Min), nil }},
+	"SQRT":          {args: 1, call: func(a []Series) (Series, error) { return apply(a[0], math.Sqrt), nil }},
+	"ATAN":          {args: 1, call: func(a []Series) (Series, error) { return apply(a[0], math.Atan), nil }},
+	"POW":           {args: 2, call: func(a []Series) (Series, error) { return apply2(a[0], a[1], math.Pow), nil }},
+}
+
+func apply(x Series, fn func(float64) float64) Series {
+	s := make(Series, len(x))
+	for i, v := range x {
+		s[i] = fn(v)
+	}
+	return s
+}
+
+func apply2(x, y Series, fn func(float64, float64) float64) Series {
+	s := make(Series, len(x))
+	for i := range x {
+		s[i] = fn(x[i], y[i])
+	}
+	return s
+}
+
+// 周期参数, 非法值返回-1
+func period(n Series, i int) int {
+	if i >= len(n) || math.IsNaN(n[i]) || n[i] < 0 {
+		return -1
+	}
+	return int(n[i])
+}
+
+// 周期窗口[start, i], n=0时从第一根K线开始
+func window(n Series, i int) (int, bool) {
+	p := period(n, i)
+	if p < 0 {
+		return 0, false
+	}
+	if p == 0 {
+		return 0, true
+	}
+	return max(0, i-p+1), true
+}
+
+// MA 简单移动平均, 不足N周期为NaN
+func MA(x, n Series) Series {
+	s := make(Series, len(x))
+	for i := range x {
+		p := period(n, i)
+		if p <= 0 || i+1 < p {
+			s[i] = math.NaN()
+			continue
+		}
+		sum := 0.0
+		for j := i - p + 1; j <= i; j++ {
+			sum += x[j]
+		}
+		s[i] = sum / float64(p)
+	}
+	return s
+}
+
+// 递归平均的公共部分, Y=(M*X+(N-M)*Y')/N, 从第一个有效值开始
+func recursiveAverage(x Series, weight func(i int) (float64, bool)) Series {
+	s := make(Series, len(x))
+	prev := math.NaN()
+	for i := range x {
+		w, ok := weight(i)
+		if !ok || math.IsNaN(x[i]) {
+			s[i] = math.NaN()
+			prev = math.NaN()
+			continue
+		}
+		if math.IsNaN(prev) {
+			prev = x[i]
+		} else {
+			prev = w*x[i] + (1-w)*prev
+		}
+		s[i] = prev
+	}
+	return s
+}
+
+// EMA 指数移动平均, Y=(2*X+(N-1)*Y')/(N+1)
+func EMA(x, n Series) Series {
+	return recursiveAverage(x, func(i int) (float64, bool) {
+		p := period(n, i)
+		if p <= 0 {
+			return 0, false
+		}
+		return 2 / float64(p+1), true
+	})
+}
+
+// SMA 移动平均, Y=(M*X+(N-M)*Y')/N
+func SMA(x, n, m Series) Series {
+	return recursiveAverage(x, func(i int) (float64, bool) {
+		p := period(n, i)
+		if p <= 0 || i >= len(m) || math.IsNaN(m[i]) {
+			return 0, false
+		}
+		return m[i] / float64(p), true
+	})
+}
+
+// WMA 加权移动平均, 越近的权重越大
+func WMA(x, n Series) Series {
+	s := make(Series, len(x))
+	for i := range x {
+		p := period(n, i)
+		if p <= 0 || i+1 < p {
+			s[i] = math.NaN()
+			continue
+		}
+		sum, weights := 0.0, 0.0
+		for j := 0; j < p; j++ {
+			w := float64(p - j)
+			sum += w * x[i-j]
+			weights += w
+		}
+		s[i] = sum / weights
+	}
+	return s
+}
+
+// REF 引用N周期前的值, N可以是序列
+func REF(x, n Series) Series {
+	s := make(Series, len(x))
+	for i := range x {
+		p := period(n, i)
+		if p < 0 || i-p < 0 {
+			s[i] = math.NaN()
+			continue
+		}
+		s[i] = x[i-p]
+	}
+	return s
+}
+
+// 窗口聚合
+func rolling(x, n Series, init float64, fn func(acc, v float64) float64) Series {
+	s := make(Series, len(x))
+	for i := range x {
+		start, ok := window(n, i)
+		if !ok {
+			s[i] = math.NaN()
+			continue
+		}
+		acc := init
+		for j := start; j <= i; j++ {
+			acc = fn(acc, x[j])
+		}
+		if math.IsInf(acc, 0) {
+			// 窗口内全部是无效值
+			acc = math.NaN()
+		}
+		s[i] = acc
+	}
+	return s
+}
+
+// HHV N周期内最高值, N=0表示从第一个有效值开始
+func HHV(x, n Series) Series {
+	return rolling(x, n, math.Inf(-1), func(acc, v float64) float64 {
+		if math.IsNaN(v) {
+			return acc
+		}
+		return math.Max(acc, v)
+	})
+}
+
+// LLV N周期内最低值, N=0表示从第一个有效值开始
+func LLV(x, n Series) Series {
+	return rolling(x, n, math.Inf(1), func(acc, v float64) float64 {
+		if math.IsNaN(v) {
+			return acc
+		}
+		return math.Min(acc, v)
+	})
+}
+
+// SUM N周期内累加, N=0表示从第一个有效值开始
+func SUM(x, n Series) Series {
+	return rolling(x, n, 0, func(acc, v float64) float64 {
+		if math.IsNaN(v) {
+			return acc
+		}
+		return acc + v
+	})
+}
+
+// COUNT N周期内满足条件的周期数
+func COUNT(x, n Series) Series {
+	return rolling(x, n, 0, func(acc, v float64) float64 {
+		if isTrue(v) {
+			return acc + 1
+		}
+		return acc
+	})
+}
+
+// CROSS A上穿B
+func CROSS(a, b Series) Series {
+	s := make(Series, len(a))
+	for i := 1; i < len(a); i++ {
+		s[i] = boolValue(a[i] > b[i] && a[i-1] <= b[i-1])
+	}
+	return s
+}
+
+// BARSLAST 上一次条件成立到当前的周期数, 从未成立为NaN
+func BARSLAST(x Series) Series {
+	s := make(Series, len(x))
+	last := -1
+	for i, v := range x {
+		if isTrue(v) {
+			last = i
+		}
+		if last < 0 {
+			s[i] = math.NaN()
+		} else {
+			s[i] = float64(i - last)
+		}
+	}
+	return s
+}
+
+// BARSLASTCOUNT 条件连续成立的周期数, 当前不成立为0
+func BARSLASTCOUNT(x Series) Series {
+	s := make(Series, len(x))
+	count := 0
+	for i, v := range x {
+		if isTrue(v) {
+			count++
+		} else {
+			count = 0
+		}
+		s[i] = float64(count)
+	}
+	return s
+}
+
+// BARSSINCEN N周期内第一次条件成立到当前的周期数, 不足N周期或者没有成立为0
+func BARSSINCEN(x, n Series) Series {
+	s := make(Series, len(x))
+	for i := range x {
+		p := period(n, i)
+		if p <= 0 || i+1 < p {
+			continue
+		}
+		for j := i - p + 1; j <= i; j++ {
+			if isTrue(x[j]) {
+				s[i] = float64(i - j)
+				break
+			}
+		}
+	}
+	return s
+}
+
+// BARSCOUNT 第一个有效数据到当前的周期数, 包含当前周期
+func BARSCOUNT(x Series) Series {
+	s := make(Series, len(x))
+	first := -1
+	for i, v := range x {
+		if first < 0 && !math.IsNaN(v) {
+			first = i
+		}
+		if first < 0 {
+			s[i] = math.NaN()
+		} else {
+			s[i] = float64(i - first + 1)
+		}
+	}
+	return s
+}
+
+// LONGCROSS A在N周期内都小于B, 本周期A上穿B
+func LONGCROSS(a, b, n Series) Series {
+	s := make(Series, len(a))
+	for i := range a {
+		p := period(n, i)
+		if p <= 0 || i < p || !(a[i] > b[i]) {
+			continue
+		}
+		below := true
+		for j := i - p; j < i; j++ {
+			if !(a[j] < b[j]) {
+				below = false
+				break
+			}
+		}
+		s[i] = boolValue(below)
+	}
+	return s
+}
+
+// FILTER 条件成立后, 之后N周期内的信号置0
+func FILTER(x, n Series) Series {
+	s := make(Series, len(x))
+	next := 0
+	for i, v := range x {
+		if i < next || !isTrue(v) {
+			continue
+		}
+		s[i] = 1
+		if p := period(n, i); p > 0 {
+			next = i + p + 1
+		}
+	}
+	return s
+}
+
+// STDP N周期总体标准差, 不足N周期为NaN
+func STDP(x, n Series) Series {
+	s := make(Series, len(x))
+	for i := range x {
+		p := period(n, i)
+		if p <= 0 || i+1 < p {
+			s[i] = math.NaN()
+			continue
+		}
+		mean := 0.0
+		for j := i - p + 1; j <= i; j++ {
+			mean += x[j]
+		}
+		mean /= float64(p)
+		variance := 0.0
+		for j := i - p + 1; j <= i; j++ {
+			variance += (x[j] - mean) * (x[j] - mean)
+		}
+		s[i] = math.Sqrt(variance / float64(p))
+	}
+	return s
+}
+
+// ZTPRICE 涨停价, 按涨跌幅比例计算后保留2位小数
+func ZTPRICE(x, ratio Series) Series {
+	return apply2(x, ratio, func(v, r float64) float64 {
+		return math.Round(v*(1+r)*100) / 100
+	})
+}
+
+// IF 条件成立取A, 否则取B
+func IF(cond, a, b Series) Series {
+	s := make(Series, len(cond))
+	for i, v := range cond {
+		if isTrue(v) {
+			s[i] = a[i]
+		} else {
+			s[i] = b[i]
+		}
+	}
+	return s
+}
+
+// NOT 逻辑非
+func NOT(x Series) Series {
+	return apply(x, func(v float64) float64 {
+		return boolValue(!isTrue(v))
+	})
+}
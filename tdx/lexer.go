@@ -0,0 +1,166 @@
+package tdx
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokenEOF       tokenKind = iota
+	tokenIdent               // 标识符, 变量/函数/绘图属性
+	tokenNumber              // 数字
+	tokenString              // 字符串, 单引号
+	tokenAssign              // :=
+	tokenOutput              // :
+	tokenComma               // ,
+	tokenSemicolon           // ;
+	tokenLParen              // (
+	tokenRParen              // )
+	tokenOperator            // + - * / > < >= <= = <> != AND OR
+)
+
+// token 词法单元
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "EOF"
+	}
+	return t.text
+}
+
+// 标识符的字符, 通达信允许中文和特殊符号作为变量名
+func isIdentRune(r rune, first bool) bool {
+	if r == '_' || unicode.IsLetter(r) || r > unicode.MaxASCII && !unicode.IsSpace(r) && r != '，' && r != '；' {
+		return true
+	}
+	if first {
+		return false
+	}
+	return unicode.IsDigit(r) || r == '%'
+}
+
+// tokenize 词法分析, 跳过{}注释
+func tokenize(source string) ([]token, error) {
+	runes := []rune(source)
+	var tokens []token
+	line := 1
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '\n':
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case r == '{':
+			// 注释
+			end := i + 1
+			for end < len(runes) && runes[end] != '}' {
+				if runes[end] == '\n' {
+					line++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("%w: 第%d行, 注释没有结束", ErrSyntax, line)
+			}
+			i = end + 1
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("%w: 第%d行, 字符串没有结束", ErrSyntax, line)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: string(runes[i+1 : end]), line: line})
+			i = end + 1
+		case unicode.IsDigit(r) || r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			// 数字开头的标识符, 比如 5日均线
+			if end < len(runes) && isIdentRune(runes[end], true) {
+				for end < len(runes) && isIdentRune(runes[end], false) {
+					end++
+				}
+				tokens = append(tokens, token{kind: tokenIdent, text: string(runes[i:end]), line: line})
+			} else {
+				tokens = append(tokens, token{kind: tokenNumber, text: string(runes[i:end]), line: line})
+			}
+			i = end
+		case isIdentRune(r, true):
+			end := i + 1
+			for end < len(runes) && isIdentRune(runes[end], false) {
+				end++
+			}
+			text := string(runes[i:end])
+			upper := strings.ToUpper(text)
+			if upper == "AND" || upper == "OR" {
+				tokens = append(tokens, token{kind: tokenOperator, text: upper, line: line})
+			} else {
+				tokens = append(tokens, token{kind: tokenIdent, text: text, line: line})
+			}
+			i = end
+		case r == ':':
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, token{kind: tokenAssign, text: ":=", line: line})
+				i += 2
+			} else {
+				tokens = append(tokens, token{kind: tokenOutput, text: ":", line: line})
+				i++
+			}
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", line: line})
+			i++
+		case r == ';':
+			tokens = append(tokens, token{kind: tokenSemicolon, text: ";", line: line})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", line: line})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", line: line})
+			i++
+		case r == '>' || r == '<' || r == '!':
+			text := string(r)
+			if i+1 < len(runes) && (runes[i+1] == '=' || r == '<' && runes[i+1] == '>') {
+				text += string(runes[i+1])
+			}
+			if text == "!" {
+				return nil, fmt.Errorf("%w: 第%d行, 无效的字符 !", ErrSyntax, line)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: text, line: line})
+			i += len([]rune(text))
+		case r == '=' || r == '+' || r == '-' || r == '*' || r == '/':
+			tokens = append(tokens, token{kind: tokenOperator, text: string(r), line: line})
+			i++
+		case r == '&' || r == '|':
+			// 兼容 && 和 ||
+			if i+1 < len(runes) && runes[i+1] == r {
+				op := "AND"
+				if r == '|' {
+					op = "OR"
+				}
+				tokens = append(tokens, token{kind: tokenOperator, text: op, line: line})
+				i += 2
+				continue
+			}
+			return nil, fmt.Errorf("%w: 第%d行, 无效的字符 %c", ErrSyntax, line, r)
+		default:
+			return nil, fmt.Errorf("%w: 第%d行, 无效的字符 %c", ErrSyntax, line, r)
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, line: line})
+	return tokens, nil
+}
@@ -0,0 +1,289 @@
+package tdx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrSyntax      = errors.New("公式语法错误")
+	ErrUnsupported = errors.New("公式不支持的函数")
+	ErrUndefined   = errors.New("公式未定义的变量")
+	ErrArguments   = errors.New("公式函数参数错误")
+	ErrCycle       = errors.New("公式变量循环引用")
+)
+
+// node 语法树节点
+type node interface{}
+
+type numberNode struct {
+	value float64
+}
+
+type stringNode struct {
+	value string
+}
+
+type identNode struct {
+	name string // 大写
+}
+
+type unaryNode struct {
+	op string
+	x  node
+}
+
+type binaryNode struct {
+	op   string
+	x, y node
+}
+
+type callNode struct {
+	name string // 大写
+	args []node
+}
+
+// Statement 公式语句
+type Statement struct {
+	Name       string   // 变量名, 为空时是绘图语句
+	Output     bool     // 是否输出变量, 即 NAME:EXPR
+	NoDraw     bool     // 是否有NODRAW属性
+	Attributes []string // 绘图属性, 比如COLORRED, LINETHICK2
+	Line       int      // 所在行
+	expr       node
+}
+
+// Formula 通达信公式
+type Formula struct {
+	Name       string // 公式名称, 一般是文件名
+	Statements []Statement
+	variables  map[string]int // 变量名到语句的索引
+}
+
+// Outputs 输出变量列表, 按定义顺序
+func (f *Formula) Outputs() []string {
+	var list []string
+	for _, v := range f.Statements {
+		if v.Output {
+			list = append(list, v.Name)
+		}
+	}
+	return list
+}
+
+// Defined 是否定义了变量
+func (f *Formula) Defined(name string) bool {
+	_, ok := f.variables[strings.ToUpper(name)]
+	return ok
+}
+
+// ParseFile 加载公式文件
+func ParseFile(filename string) (*Formula, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	formula, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	formula.Name = filename
+	return formula, nil
+}
+
+// Parse 解析公式源码
+func Parse(source string) (*Formula, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	formula := &Formula{variables: map[string]int{}}
+	for p.peek().kind != tokenEOF {
+		if p.peek().kind == tokenSemicolon {
+			p.next()
+			continue
+		}
+		stmt, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		if len(stmt.Name) > 0 {
+			if _, found := formula.variables[stmt.Name]; found {
+				return nil, fmt.Errorf("%w: 第%d行, 变量%s重复定义", ErrSyntax, stmt.Line, stmt.Name)
+			}
+			formula.variables[stmt.Name] = len(formula.Statements)
+		}
+		formula.Statements = append(formula.Statements, stmt)
+	}
+	return formula, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset < len(p.tokens) {
+		return p.tokens[p.pos+offset]
+	}
+	return p.tokens[len(p.tokens)-1]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	t := p.next()
+	if t.kind != kind {
+		return fmt.Errorf("%w: 第%d行, 期望%s, 实际是%s", ErrSyntax, t.line, text, t)
+	}
+	return nil
+}
+
+// statement := [IDENT (':'|':=')] expr {[','] attribute} ';'
+func (p *parser) statement() (Statement, error) {
+	stmt := Statement{Line: p.peek().line}
+	if p.peek().kind == tokenIdent && (p.peekAt(1).kind == tokenAssign || p.peekAt(1).kind == tokenOutput) {
+		stmt.Name = strings.ToUpper(p.next().text)
+		stmt.Output = p.next().kind == tokenOutput
+	}
+	expr, err := p.expression()
+	if err != nil {
+		return stmt, err
+	}
+	stmt.expr = expr
+	// 绘图属性, 逗号可以省略
+	for {
+		t := p.peek()
+		if t.kind == tokenComma {
+			p.next()
+			t = p.peek()
+		}
+		if t.kind == tokenSemicolon || t.kind == tokenEOF {
+			break
+		}
+		if t.kind != tokenIdent {
+			return stmt, fmt.Errorf("%w: 第%d行, 无效的绘图属性 %s", ErrSyntax, t.line, t)
+		}
+		p.next()
+		attribute := strings.ToUpper(t.text)
+		if attribute == "NODRAW" {
+			stmt.NoDraw = true
+		}
+		stmt.Attributes = append(stmt.Attributes, attribute)
+	}
+	if p.peek().kind == tokenSemicolon {
+		p.next()
+	}
+	return stmt, nil
+}
+
+// 运算符优先级, 从低到高
+var binaryPrecedence = map[string]int{
+	"OR":  1,
+	"AND": 2,
+	"=":   3, "<>": 3, "!=": 3, ">": 3, "<": 3, ">=": 3, "<=": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5,
+}
+
+func (p *parser) expression() (node, error) {
+	return p.binary(1)
+}
+
+func (p *parser) binary(precedence int) (node, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		level, ok := binaryPrecedence[t.text]
+		if t.kind != tokenOperator || !ok || level < precedence {
+			return x, nil
+		}
+		p.next()
+		y, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryNode{op: t.text, x: x, y: y}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	t := p.peek()
+	if t.kind == tokenOperator && (t.text == "-" || t.text == "+") {
+		p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: t.text, x: x}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 第%d行, 无效的数字 %s", ErrSyntax, t.line, t.text)
+		}
+		return &numberNode{value: v}, nil
+	case tokenString:
+		return &stringNode{value: t.text}, nil
+	case tokenLParen:
+		x, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	case tokenIdent:
+		name := strings.ToUpper(t.text)
+		if p.peek().kind != tokenLParen {
+			return &identNode{name: name}, nil
+		}
+		p.next()
+		call := &callNode{name: name}
+		if p.peek().kind == tokenRParen {
+			p.next()
+			return call, nil
+		}
+		for {
+			arg, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if p.peek().kind == tokenComma {
+				p.next()
+				continue
+			}
+			if err = p.expect(tokenRParen, ")"); err != nil {
+				return nil, err
+			}
+			return call, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: 第%d行, 无效的符号 %s", ErrSyntax, t.line, t)
+}
@@ -0,0 +1,196 @@
+package tdx
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func closeTo(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestParse(t *testing.T) {
+	source := `{注释}
+N:=3;
+MA3:MA(CLOSE,N),NODRAW;
+上穿:=CROSS(C,MA3) AND V>0;
+信号:IF(上穿,1,0),COLORRED LINETHICK2;
+DRAWICON(上穿,L,1);`
+	f, err := Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Statements) != 5 {
+		t.Fatalf("statements = %d, want 5", len(f.Statements))
+	}
+	outputs := f.Outputs()
+	if len(outputs) != 2 || outputs[0] != "MA3" || outputs[1] != "信号" {
+		t.Fatalf("outputs = %v", outputs)
+	}
+	if !f.Statements[1].NoDraw {
+		t.Error("MA3 should be NODRAW")
+	}
+	if attrs := f.Statements[3].Attributes; len(attrs) != 2 || attrs[1] != "LINETHICK2" {
+		t.Errorf("attributes = %v", attrs)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []string{
+		"A:=(CLOSE;",
+		"A:=CLOSE;A:=OPEN;",
+		"A:=CLOSE @ 1;",
+		"{注释",
+	}
+	for _, source := range tests {
+		if _, err := Parse(source); !errors.Is(err, ErrSyntax) {
+			t.Errorf("Parse(%q) error = %v, want ErrSyntax", source, err)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	columns := map[string]Series{
+		"close":  {1, 2, 3, 4, 5, 4, 3},
+		"high":   {2, 3, 4, 5, 6, 5, 4},
+		"low":    {0, 1, 2, 3, 4, 3, 2},
+		"volume": {10, 10, 10, 10, 10, 10, 10},
+	}
+	source := `MA3:MA(C,N);
+HH:HHV(H,3);
+LL:LLV(L,0);
+涨:=C>REF(C,1);
+CNT:COUNT(涨,3);
+距离:BARSLAST(C<REF(C,1));
+E:EMA(C,3);
+S:SMA(C,3,1);
+X:IF(C>=4,C,-C);
+Z:C/(C-C);`
+	f, err := Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := f.Eval(columns, map[string]float64{"n": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nan := math.NaN()
+	want := map[string]Series{
+		"MA3": {nan, nan, 2, 3, 4, 13.0 / 3, 4},
+		"HH":  {2, 3, 4, 5, 6, 6, 6},
+		"LL":  {0, 0, 0, 0, 0, 0, 0},
+		"CNT": {0, 1, 2, 3, 3, 2, 1},
+		"距离":  {nan, nan, nan, nan, nan, 0, 0},
+		"X":   {-1, -2, -3, 4, 5, 4, -3},
+		"Z":   {nan, nan, nan, nan, nan, nan, nan},
+	}
+	for name, expected := range want {
+		v, ok := result.Get(name)
+		if !ok {
+			t.Fatalf("missing output %s", name)
+		}
+		for i := range expected {
+			if !closeTo(v[i], expected[i]) {
+				t.Errorf("%s[%d] = %v, want %v", name, i, v[i], expected[i])
+			}
+		}
+	}
+	// EMA(C,3): Y=(2*X+2*Y')/4
+	if e := result.Last("E"); !closeTo(e, 3.515625) {
+		t.Errorf("EMA = %v, want 3.515625", e)
+	}
+	// SMA(C,3,1): Y=(X+2*Y')/3
+	if s := result.Last("S"); !closeTo(s, 3.3978052126200278) {
+		t.Errorf("SMA = %v", s)
+	}
+}
+
+func TestEvalBars(t *testing.T) {
+	columns := map[string]Series{
+		"close": {1, 2, 3, 2, 3, 4, 5},
+	}
+	source := `涨:=C>REF(C,1);
+连涨:BARSLASTCOUNT(涨);
+首涨:BARSSINCEN(涨,3);
+周期:BARSCOUNT(C);
+剩余:CURRBARSCOUNT;
+过滤:FILTER(涨,1);
+长穿:LONGCROSS(C,2.5,2);
+标准差:STDP(C,2);
+涨停:ZTPRICE(C,0.1);`
+	f, err := Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := f.Eval(columns, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nan := math.NaN()
+	want := map[string]Series{
+		"连涨":  {0, 1, 2, 0, 1, 2, 3},
+		"首涨":  {0, 0, 1, 2, 2, 1, 2},
+		"周期":  {1, 2, 3, 4, 5, 6, 7},
+		"剩余":  {7, 6, 5, 4, 3, 2, 1},
+		"过滤":  {0, 1, 0, 0, 1, 0, 1},
+		"长穿":  {0, 0, 1, 0, 0, 0, 0},
+		"标准差": {nan, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5},
+		"涨停":  {1.1, 2.2, 3.3, 2.2, 3.3, 4.4, 5.5},
+	}
+	for name, expected := range want {
+		v, ok := result.Get(name)
+		if !ok {
+			t.Fatalf("missing output %s", name)
+		}
+		for i := range expected {
+			if !closeTo(v[i], expected[i]) {
+				t.Errorf("%s[%d] = %v, want %v", name, i, v[i], expected[i])
+			}
+		}
+	}
+}
+
+func TestEvalLazy(t *testing.T) {
+	source := `A:CLOSE*2;
+B:UNKNOWN(CLOSE);`
+	f, err := Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := map[string]Series{"close": {1, 2}}
+	result, err := f.Eval(columns, nil, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Last("A") != 4 {
+		t.Errorf("A = %v, want 4", result.Last("A"))
+	}
+	if _, err = f.Eval(columns, nil); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestEvalError(t *testing.T) {
+	tests := []struct {
+		source string
+		err    error
+	}{
+		{"A:B+1;B:A+1;", ErrCycle},
+		{"A:FOO+1;", ErrUndefined},
+		{"A:MA(CLOSE);", ErrArguments},
+	}
+	columns := map[string]Series{"close": {1, 2, 3}}
+	for _, v := range tests {
+		f, err := Parse(v.source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = f.Eval(columns, nil); !errors.Is(err, v.err) {
+			t.Errorf("%s: error = %v, want %v", v.source, err, v.err)
+		}
+	}
+}
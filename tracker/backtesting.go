@@ -7,6 +7,7 @@ import (
 
 	"gitee.com/quant1x/data/exchange"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/config"
 	"gitee.com/quant1x/engine/factors"
 	"gitee.com/quant1x/engine/market"
@@ -92,7 +93,7 @@ func checkWideOffset(klines []factors.SecurityFeature, date string) (offset int)
 //  2. 生成CSV文件保存详细回测数据
 //  3. 计算并输出平均收益率、胜率等汇总指标
 func BackTesting(strategyNo uint64, countDays, countTopN int) {
-	currentlyDay := exchange.GetCurrentlyDay()
+	currentlyDay := clock.CurrentlyDay()
 	dates := exchange.TradingDateRange(exchange.MARKET_CH_FIRST_LISTTIME, currentlyDay)
 	scope := api.RangeFinite(-countDays)
 	s, e, err := scope.Limits(len(dates))
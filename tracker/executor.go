@@ -24,7 +24,9 @@ func ExecuteStrategy(model models.Strategy, barIndex *int) {
 		return
 	}
 	// 加载快照数据
-	models.SyncAllSnapshots(barIndex)
+	if err := models.SyncAllSnapshots(barIndex); err != nil {
+		logger.Errorf("同步快照失败: %+v", err)
+	}
 	// 计算市场情绪
 	MarketSentiment()
 	// 扫描板块
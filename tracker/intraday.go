@@ -0,0 +1,315 @@
+package tracker
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/data/level1/securities"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/datasource/base"
+	"gitee.com/quant1x/engine/models"
+	"gitee.com/quant1x/engine/storages"
+	"gitee.com/quant1x/gox/api"
+	"gitee.com/quant1x/gox/logger"
+	"gitee.com/quant1x/gox/progressbar"
+	"gitee.com/quant1x/gox/tags"
+	"gitee.com/quant1x/num"
+	"gitee.com/quant1x/pkg/tablewriter"
+)
+
+// IntradaySignalRecord 日内策略盘中的信号
+type IntradaySignalRecord struct {
+	Time         string  `name:"时间"`
+	SecurityCode string  `name:"证券代码"`
+	Name         string  `name:"证券名称"`
+	Action       string  `name:"动作"`
+	Price        float64 `name:"价格"`
+	Remark       string  `name:"备注"`
+}
+
+// IntradayTrade 日内策略回测的一笔交易
+type IntradayTrade struct {
+	SecurityCode string  `name:"证券代码" dataframe:"code"`
+	BuyTime      string  `name:"买入时间" dataframe:"buy_time"`
+	BuyPrice     float64 `name:"买入价" dataframe:"buy_price"`
+	SellTime     string  `name:"卖出时间" dataframe:"sell_time"`
+	SellPrice    float64 `name:"卖出价" dataframe:"sell_price"`
+	Return       float64 `name:"收益率%" dataframe:"return"`
+	Remark       string  `name:"备注" dataframe:"remark"`
+}
+
+// IntradaySummary 日内策略回测汇总
+type IntradaySummary struct {
+	Strategy    string  `name:"策略"`
+	Frequency   string  `name:"周期"`
+	Begin       string  `name:"开始日期"`
+	End         string  `name:"结束日期"`
+	Codes       int     `name:"证券数"`
+	Trades      int     `name:"交易笔数"`
+	WinRate     float64 `name:"胜率%"`
+	AvgReturn   float64 `name:"平均收益率%"`
+	TotalReturn float64 `name:"累计收益率%"`
+}
+
+var (
+	// 盘中每个日内策略一个K线合成器
+	__mapIntradayBuilders   = map[models.ModelKind]*models.IntradayBarBuilder{}
+	__mutexIntradayBuilders sync.Mutex
+)
+
+// 盘中的时刻, 当日的秒数, timestamp格式HH:MM:SS, 默认取时钟的当前时间
+func intradaySeconds(timestamp ...string) int {
+	tm := clock.Now()
+	if len(timestamp) > 0 {
+		if t, err := time.Parse(time.TimeOnly, timestamp[0]); err == nil {
+			tm = t
+		}
+	}
+	return tm.Hour()*3600 + tm.Minute()*60 + tm.Second()
+}
+
+func checkoutIntradayBuilder(model models.IntradayStrategy) (*models.IntradayBarBuilder, error) {
+	__mutexIntradayBuilders.Lock()
+	defer __mutexIntradayBuilders.Unlock()
+	builder, ok := __mapIntradayBuilders[model.Code()]
+	if ok {
+		return builder, nil
+	}
+	builder, err := models.NewIntradayBarBuilder(model.Frequency())
+	if err != nil {
+		return nil, err
+	}
+	__mapIntradayBuilders[model.Code()] = builder
+	return builder, nil
+}
+
+// 盘中用内存中的快照合成K线, K线收盘时执行日内策略并输出信号
+//
+//	信号只输出到控制台, 不会下单. flush为true时收盘全部正在合成的K线, 收盘之后调用
+func intradayTracker(model models.IntradayStrategy, tradeRule *config.StrategyParameter, flush bool, timestamp ...string) {
+	builder, err := checkoutIntradayBuilder(model)
+	if err != nil {
+		logger.Errorf("策略[%d]: %+v", model.Code(), err)
+		return
+	}
+	seconds := intradaySeconds(timestamp...)
+	var closed []string
+	for _, code := range tradeRule.StockList() {
+		securityCode := exchange.CorrectSecurityCode(code)
+		if exchange.AssertIndexBySecurityCode(securityCode) {
+			continue
+		}
+		v := models.GetTickFromMemory(securityCode)
+		if v == nil {
+			continue
+		}
+		snapshot := models.QuoteSnapshotFromProtocol(*v)
+		snapshot.SecurityCode = securityCode
+		if builder.Update(snapshot, seconds) {
+			closed = append(closed, securityCode)
+		}
+	}
+	closed = append(closed, builder.Expire(seconds)...)
+	records := evaluateIntradayBars(model, builder, closed)
+	if flush {
+		// 最后一根K线单独评估, 同一个证券可能刚收盘了上一根K线
+		records = append(records, evaluateIntradayBars(model, builder, builder.Flush())...)
+	}
+	if len(records) == 0 {
+		return
+	}
+	tbl := tablewriter.NewWriter(os.Stdout)
+	tbl.SetHeader(tags.GetHeadersByTags(IntradaySignalRecord{}))
+	for _, v := range records {
+		tbl.Append(tags.GetValuesByTags(v))
+	}
+	tbl.Render()
+}
+
+// 对刚收盘K线的证券执行日内策略, 返回非持有的信号
+func evaluateIntradayBars(model models.IntradayStrategy, builder *models.IntradayBarBuilder, closed []string) []IntradaySignalRecord {
+	var records []IntradaySignalRecord
+	for _, securityCode := range closed {
+		bars := builder.Bars(securityCode)
+		if len(bars) == 0 {
+			continue
+		}
+		bar := bars[len(bars)-1]
+		signal := model.OnBar(securityCode, bars)
+		if signal.Action == models.IntradayHold {
+			continue
+		}
+		price := signal.Price
+		if price <= 0 {
+			price = bar.Close
+		}
+		records = append(records, IntradaySignalRecord{
+			Time:         bar.Datetime,
+			SecurityCode: securityCode,
+			Name:         securities.GetStockName(securityCode),
+			Action:       signal.Action.String(),
+			Price:        num.Decimal(price),
+			Remark:       signal.Remark,
+		})
+	}
+	return records
+}
+
+// 日内策略的持仓
+type intradayPosition struct {
+	date  string
+	time  string
+	price float64
+}
+
+// 用一个证券的分钟K线回放日内策略, bars按时间升序, 只回放dates中的交易日
+//
+//	信号按K线收盘价成交, 买入当日不能卖出, 回测结束时仍然持有的按最后一根K线的收盘价平仓
+func simulateIntraday(model models.IntradayStrategy, securityCode string, bars []base.KLine, dates []string) []IntradayTrade {
+	if len(bars) == 0 || len(dates) == 0 {
+		return nil
+	}
+	var trades []IntradayTrade
+	var position *intradayPosition
+	var last base.KLine
+	closePosition := func(bar base.KLine, price float64, remark string) {
+		trades = append(trades, IntradayTrade{
+			SecurityCode: securityCode,
+			BuyTime:      position.time,
+			BuyPrice:     position.price,
+			SellTime:     bar.Datetime,
+			SellPrice:    price,
+			Return:       num.NetChangeRate(position.price, price),
+			Remark:       remark,
+		})
+		position = nil
+	}
+	begin, end := dates[0], dates[len(dates)-1]
+	for i := 0; i < len(bars); {
+		date := bars[i].Date
+		j := i
+		for j < len(bars) && bars[j].Date == date {
+			j++
+		}
+		day := bars[i:j]
+		i = j
+		if date < begin || date > end {
+			continue
+		}
+		for k := range day {
+			bar := day[k]
+			last = bar
+			signal := model.OnBar(securityCode, day[:k+1:k+1])
+			price := signal.Price
+			if price <= 0 {
+				price = bar.Close
+			}
+			switch {
+			case signal.Action == models.IntradayBuy && position == nil:
+				position = &intradayPosition{date: date, time: bar.Datetime, price: price}
+			case signal.Action == models.IntradaySell && position != nil && position.date < date:
+				closePosition(bar, price, signal.Remark)
+			}
+		}
+	}
+	if position != nil {
+		closePosition(last, last.Close, sellRemarkFinal)
+	}
+	return trades
+}
+
+// 汇总日内策略的交易
+func intradaySummary(trades []IntradayTrade) (summary IntradaySummary) {
+	summary.Trades = len(trades)
+	if len(trades) == 0 {
+		return
+	}
+	wins := 0
+	netValue := 1.00
+	for _, v := range trades {
+		if v.Return > 0 {
+			wins++
+		}
+		summary.AvgReturn += v.Return
+		netValue *= 1 + v.Return/100
+	}
+	summary.WinRate = num.Decimal(float64(wins) / float64(len(trades)) * 100)
+	summary.AvgReturn = num.Decimal(summary.AvgReturn / float64(len(trades)))
+	summary.TotalReturn = num.Decimal((netValue - 1) * 100)
+	return
+}
+
+// IntradayBackTesting 用缓存的分钟K线回测日内策略
+//
+//	分钟K线来自data.cache.kline配置的周期, 和策略的K线周期必须一致. securityCode为空时回测策略的全部标的
+func IntradayBackTesting(strategyNo uint64, countDays int, securityCode string) {
+	currentlyDay := clock.CurrentlyDay()
+	dates := exchange.TradingDateRange(exchange.MARKET_CH_FIRST_LISTTIME, currentlyDay)
+	scope := api.RangeFinite(-countDays)
+	s, e, err := scope.Limits(len(dates))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	dates = dates[s : e+1]
+	model, err := models.CheckoutIntradayStrategy(strategyNo)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	freq, _, err := models.IntradayFrequency(model.Frequency())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	tradeRule := config.GetStrategyParameterByCode(strategyNo)
+	if tradeRule == nil {
+		fmt.Printf("策略[%d]未配置或未启用\n", strategyNo)
+		return
+	}
+	codes := tradeRule.StockList()
+	if len(securityCode) > 0 {
+		codes = []string{securityCode}
+	}
+	summary := IntradaySummary{
+		Strategy:  model.Name(),
+		Frequency: freq,
+		Begin:     dates[0],
+		End:       dates[len(dates)-1],
+	}
+	var trades []IntradayTrade
+	bar := progressbar.NewBar(1, "执行[日内回测]", len(codes))
+	for _, code := range codes {
+		bar.Add(1)
+		code = exchange.CorrectSecurityCode(code)
+		klines := base.LoadKline(code, freq)
+		if len(klines) == 0 {
+			continue
+		}
+		summary.Codes++
+		trades = append(trades, simulateIntraday(model, code, klines, dates)...)
+	}
+	bar.Wait()
+	fmt.Println()
+	if summary.Codes == 0 {
+		fmt.Printf("没有%s的分钟K线, 需要在data.cache.kline中启用并更新\n", freq)
+		return
+	}
+	result := intradaySummary(trades)
+	summary.Trades, summary.WinRate, summary.AvgReturn, summary.TotalReturn = result.Trades, result.WinRate, result.AvgReturn, result.TotalReturn
+
+	filename := fmt.Sprintf("%s/intraday-%s-%s-trades.csv", storages.GetResultCachePath(), tradeRule.QmtStrategyName(), cache.Today())
+	if err := api.SlicesToCsv(filename, trades); err != nil {
+		logger.Errorf("保存成交记录失败: %+v", err)
+	}
+	tbl := tablewriter.NewWriter(os.Stdout)
+	tbl.SetHeader(tags.GetHeadersByTags(IntradaySummary{}))
+	tbl.Append(tags.GetValuesByTags(summary))
+	tbl.Render()
+	fmt.Printf("成交记录: %s\n", filename)
+}
@@ -0,0 +1,68 @@
+package tracker
+
+import (
+	"testing"
+
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/datasource/base"
+	"gitee.com/quant1x/engine/factors"
+	"gitee.com/quant1x/engine/models"
+	"gitee.com/quant1x/gox/concurrent"
+)
+
+// 收盘价突破当日开盘价1%买入, 跌破开盘价卖出
+type testBreakout struct{}
+
+func (testBreakout) Code() models.ModelKind { return 9999 }
+func (testBreakout) Name() string           { return "test-breakout" }
+func (testBreakout) OrderFlag() string      { return models.OrderFlagTick }
+func (testBreakout) Filter(config.RuleParameter, factors.QuoteSnapshot) error {
+	return nil
+}
+func (testBreakout) Sort([]factors.QuoteSnapshot) models.SortedStatus {
+	return models.SortNotRequired
+}
+func (testBreakout) Evaluate(string, *concurrent.TreeMap[string, models.ResultInfo]) {}
+func (testBreakout) Frequency() string                                               { return "5min" }
+func (testBreakout) OnBar(securityCode string, bars []base.KLine) models.IntradaySignal {
+	open := bars[0].Open
+	last := bars[len(bars)-1]
+	switch {
+	case last.Close > open*1.01:
+		return models.IntradaySignal{Action: models.IntradayBuy}
+	case last.Close < open:
+		return models.IntradaySignal{Action: models.IntradaySell, Remark: "break"}
+	}
+	return models.IntradaySignal{}
+}
+
+func TestSimulateIntraday(t *testing.T) {
+	bar := func(date, hm string, open, close float64) base.KLine {
+		return base.KLine{Date: date, Datetime: date + " " + hm + ":00", Open: open, Close: close}
+	}
+	bars := []base.KLine{
+		bar("2024-01-02", "09:35", 10.0, 10.0),
+		bar("2024-01-02", "09:40", 10.0, 10.2), // 买入
+		bar("2024-01-02", "09:45", 10.2, 9.9),  // 当日不能卖出
+		bar("2024-01-03", "09:35", 10.0, 10.0),
+		bar("2024-01-03", "09:40", 10.0, 9.8), // 卖出
+		bar("2024-01-04", "09:35", 10.0, 10.0),
+		bar("2024-01-04", "09:40", 10.0, 10.5), // 买入
+		bar("2024-01-05", "09:35", 11.0, 11.0), // 不在回测范围
+	}
+	dates := []string{"2024-01-02", "2024-01-03", "2024-01-04"}
+	trades := simulateIntraday(testBreakout{}, "sh600600", bars, dates)
+	if len(trades) != 2 {
+		t.Fatalf("trades = %+v", trades)
+	}
+	if trades[0].BuyTime != "2024-01-02 09:40:00" || trades[0].SellTime != "2024-01-03 09:40:00" || trades[0].Remark != "break" {
+		t.Fatalf("trades[0] = %+v", trades[0])
+	}
+	if trades[1].SellTime != "2024-01-04 09:40:00" || trades[1].Remark != sellRemarkFinal {
+		t.Fatalf("trades[1] = %+v", trades[1])
+	}
+	summary := intradaySummary(trades)
+	if summary.Trades != 2 || summary.WinRate != 0 {
+		t.Fatalf("summary = %+v", summary)
+	}
+}
@@ -0,0 +1,637 @@
+package tracker
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sort"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/factors"
+	"gitee.com/quant1x/engine/market"
+	"gitee.com/quant1x/engine/models"
+	"gitee.com/quant1x/engine/storages"
+	"gitee.com/quant1x/engine/trader"
+	"gitee.com/quant1x/gox/api"
+	"gitee.com/quant1x/gox/logger"
+	"gitee.com/quant1x/gox/progressbar"
+	"gitee.com/quant1x/gox/tags"
+	"gitee.com/quant1x/num"
+	"gitee.com/quant1x/pkg/tablewriter"
+)
+
+const (
+	annualTradingDays = 252 // 年化交易日数
+)
+
+// 卖出备注, 和117号策略保持一致
+const (
+	sellRemarkLastDay    = "LASTDAY"
+	sellRemarkTakeProfit = "TPR"
+	sellRemarkStopLoss   = "SLR"
+	sellRemarkGap        = "GAP"
+	sellRemarkFixedYield = "FIXEDYIELD"
+	sellRemarkFinal      = "FINAL"
+)
+
+// PortfolioTrade 组合回测成交记录
+type PortfolioTrade struct {
+	Date         string  `name:"日期" dataframe:"date"`
+	SecurityCode string  `name:"证券代码" dataframe:"code"`
+	Name         string  `name:"证券名称" dataframe:"name"`
+	Direction    string  `name:"方向" dataframe:"direction"`
+	Price        float64 `name:"成交价" dataframe:"price"`
+	Volume       int     `name:"成交量" dataframe:"volume"`
+	Amount       float64 `name:"成交额" dataframe:"amount"`
+	Fee          float64 `name:"费用" dataframe:"fee"`
+	Profit       float64 `name:"盈亏" dataframe:"profit"`
+	HoldingDays  int     `name:"持股天数" dataframe:"holding_days"`
+	Remark       string  `name:"备注" dataframe:"remark"`
+}
+
+// PortfolioEquity 组合每日权益
+type PortfolioEquity struct {
+	Date        string  `name:"日期" dataframe:"date"`
+	Cash        float64 `name:"可用资金" dataframe:"cash"`
+	MarketValue float64 `name:"持仓市值" dataframe:"market_value"`
+	TotalAsset  float64 `name:"总资产" dataframe:"total_asset"`
+	Positions   int     `name:"持仓数" dataframe:"positions"`
+	Turnover    float64 `name:"成交额" dataframe:"turnover"`
+	Return      float64 `name:"日收益率%" dataframe:"return"`
+	NetValue    float64 `name:"净值" dataframe:"net_value"`
+	Drawdown    float64 `name:"回撤%" dataframe:"drawdown"`
+}
+
+// PortfolioSummary 组合回测汇总
+type PortfolioSummary struct {
+	Strategy       string  `name:"策略"`
+	Begin          string  `name:"开始日期"`
+	End            string  `name:"结束日期"`
+	Days           int     `name:"交易日数"`
+	InitialCapital float64 `name:"初始资金"`
+	FinalAsset     float64 `name:"期末资产"`
+	TotalReturn    float64 `name:"累计收益率%"`
+	AnnualReturn   float64 `name:"年化收益率%"`
+	MaxDrawdown    float64 `name:"最大回撤%"`
+	Sharpe         float64 `name:"夏普比率"`
+	Turnover       float64 `name:"换手率"`
+	Trades         int     `name:"卖出笔数"`
+	WinRate        float64 `name:"胜率%"`
+}
+
+// PortfolioResult 组合回测结果
+type PortfolioResult struct {
+	Summary  PortfolioSummary
+	Equities []PortfolioEquity
+	Trades   []PortfolioTrade
+}
+
+// 模拟持仓
+type portfolioPosition struct {
+	SecurityCode string
+	Name         string
+	BuyDate      string  // 买入日期
+	BuyPrice     float64 // 买入价格
+	Cost         float64 // 买入总成本, 含费用
+	Volume       int     // 持仓数量
+	HoldingDays  int     // 已持有的交易日数
+	LastPrice    float64 // 最新价, 停牌时保持不变
+}
+
+// Portfolio 事件驱动的组合回测
+//
+//	按交易日推进, 每日依次触发: 开盘(持股天数) -> 买入 -> 卖出 -> 收盘(结算权益) -> 选股
+//	收盘后用当日的数据选股, 次日按开盘价买入, 避免用当日的收盘数据选股又按当日的价格成交
+//	资金分配复用实盘的trader.EvaluateFundsForSingleTarget, 费用复用trader的费用计算
+type Portfolio struct {
+	model          models.Strategy
+	tradeRule      config.StrategyParameter
+	sellRule       config.StrategyParameter
+	initialCapital float64
+	cash           float64
+	peak           float64
+	positions      map[string]*portfolioPosition
+	trades         []PortfolioTrade
+	equities       []PortfolioEquity
+	klines         *WideKLines
+	codes          []string
+	pending        []factors.QuoteSnapshot // 上一个交易日收盘选出的标的, 当日开盘买入
+	progress       func()                  // 每推进一个交易日回调一次
+}
+
+// NewPortfolio 创建组合回测
+//
+//	tradeRule 传值, 以便参数优化时修改规则而不影响全局配置
+func NewPortfolio(model models.Strategy, tradeRule config.StrategyParameter, capital float64) *Portfolio {
+	p := &Portfolio{
+		model:          model,
+		tradeRule:      tradeRule,
+		sellRule:       tradeRule,
+		initialCapital: capital,
+		cash:           capital,
+		peak:           capital,
+		positions:      map[string]*portfolioPosition{},
+		klines:         NewWideKLines(),
+	}
+	// 卖出规则优先取卖出策略的参数
+	if sellRule := config.GetStrategyParameterByCode(tradeRule.SellStrategy); sellRule != nil {
+		p.sellRule = *sellRule
+	}
+	return p
+}
+
+// WithKLines 和其它组合回测共用宽表K线缓存, 避免重复加载
+func (p *Portfolio) WithKLines(klines *WideKLines) *Portfolio {
+	p.klines = klines
+	return p
+}
+
+// WithProgress 每推进一个交易日回调一次, 用于刷新进度条
+func (p *Portfolio) WithProgress(progress func()) *Portfolio {
+	p.progress = progress
+	return p
+}
+
+// 获取证券在指定日期的宽表数据
+func (p *Portfolio) feature(securityCode, date string) (current, previous factors.SecurityFeature, ok bool) {
+	features := p.klines.Get(securityCode)
+	idx, found := slices.BinarySearchFunc(features, date, func(e factors.SecurityFeature, t string) int {
+		switch {
+		case e.Date < t:
+			return -1
+		case e.Date > t:
+			return 1
+		}
+		return 0
+	})
+	if !found {
+		return current, previous, false
+	}
+	current = features[idx]
+	if idx > 0 {
+		previous = features[idx-1]
+	}
+	return current, previous, true
+}
+
+func (p *Portfolio) securityName(securityCode, date string) string {
+	f10 := factors.GetL5F10(securityCode, date)
+	if f10 != nil {
+		return f10.SecurityName
+	}
+	return "unknown"
+}
+
+// Run 执行回测
+func (p *Portfolio) Run(dates []string) PortfolioResult {
+	var result PortfolioResult
+	for i := range dates {
+		result = p.runDay(dates, i)
+		if p.progress != nil {
+			p.progress()
+		}
+	}
+	return result
+}
+
+// 开盘, 非当日买入的持仓持股天数+1
+func (p *Portfolio) onMarketOpen(date string) {
+	for _, position := range p.positions {
+		if position.BuyDate < date {
+			position.HoldingDays++
+		}
+	}
+}
+
+// 持仓代码排序, 保证回测结果可重现
+func (p *Portfolio) holdingCodes() []string {
+	codes := api.Keys(p.positions)
+	sort.Strings(codes)
+	return codes
+}
+
+// 卖出, T+1, 当日买入的不能卖出
+func (p *Portfolio) onSell(date string) {
+	for _, securityCode := range p.holdingCodes() {
+		position := p.positions[securityCode]
+		if position.BuyDate >= date {
+			continue
+		}
+		feature, previous, ok := p.feature(securityCode, date)
+		if !ok || feature.Volume <= 0 {
+			// 停牌
+			continue
+		}
+		limitUp, limitDown := market.PriceLimit(securityCode, feature.LastClose)
+		// 一字跌停, 无法卖出
+		if feature.High <= limitDown {
+			continue
+		}
+		price, remark := p.evaluateSell(position, feature, previous)
+		if len(remark) == 0 {
+			continue
+		}
+		// 涨停不卖
+		if price >= limitUp {
+			continue
+		}
+		p.sell(date, position, price, remark)
+	}
+}
+
+// 评估卖出价格, 优先级: 止损 > 固定收益 > 止盈 > 跳空缺口 > 持股到期
+//
+//	日线无法区分盘中高低点的先后, 同时触发止盈和止损时按止损处理
+func (p *Portfolio) evaluateSell(position *portfolioPosition, feature, previous factors.SecurityFeature) (price float64, remark string) {
+	rule := p.sellRule
+	costPrice := position.BuyPrice
+	// 1. 止损
+	if rule.StopLossRatio < 0 {
+		stopLossPrice := num.Decimal(costPrice * (1 + rule.StopLossRatio/100))
+		if feature.Low <= stopLossPrice {
+			return min(feature.Open, stopLossPrice), sellRemarkStopLoss
+		}
+	}
+	// 2. 固定收益率
+	if rule.FixedYield > 0 {
+		fee := trader.EvaluatePriceForSell(position.SecurityCode, costPrice, position.Volume, rule.FixedYield)
+		if feature.High >= fee.Price {
+			return max(feature.Open, fee.Price), sellRemarkFixedYield
+		}
+	}
+	// 3. 止盈
+	if rule.TakeProfitRatio > 0 {
+		takeProfitPrice := num.Decimal(costPrice * (1 + rule.TakeProfitRatio/100))
+		if feature.High >= takeProfitPrice {
+			return max(feature.Open, takeProfitPrice), sellRemarkTakeProfit
+		}
+	}
+	// 4. 向下跳空缺口
+	if previous.Low > 0 && feature.High < previous.Low {
+		return feature.Open, sellRemarkGap
+	}
+	// 5. 持股到期, 收盘卖出
+	if position.HoldingDays >= max(p.tradeRule.HoldingPeriod, 1) {
+		return feature.Close, sellRemarkLastDay
+	}
+	return 0, ""
+}
+
+func (p *Portfolio) sell(date string, position *portfolioPosition, price float64, remark string) {
+	fee := trader.EvaluateFeeForSell(position.SecurityCode, price, position.Volume)
+	p.cash += fee.MarketValue
+	amount := price * float64(position.Volume)
+	p.trades = append(p.trades, PortfolioTrade{
+		Date:         date,
+		SecurityCode: position.SecurityCode,
+		Name:         position.Name,
+		Direction:    string(trader.SELL),
+		Price:        price,
+		Volume:       position.Volume,
+		Amount:       num.Decimal(amount),
+		Fee:          num.Decimal(amount - fee.MarketValue),
+		Profit:       num.Decimal(fee.MarketValue - position.Cost),
+		HoldingDays:  position.HoldingDays,
+		Remark:       remark,
+	})
+	delete(p.positions, position.SecurityCode)
+}
+
+// 收盘后用当日的数据筛选候选标的, 次日开盘买入
+func (p *Portfolio) candidates(date string) []factors.QuoteSnapshot {
+	var snapshots []factors.QuoteSnapshot
+	for _, securityCode := range p.codes {
+		if _, ok := p.positions[securityCode]; ok {
+			continue
+		}
+		feature, _, ok := p.feature(securityCode, date)
+		if !ok || feature.Volume <= 0 {
+			continue
+		}
+		snapshot := models.FeatureToSnapshot(feature, securityCode)
+		if p.model.Filter(p.tradeRule.Rules, snapshot) != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sortedStatus := p.model.Sort(snapshots)
+	if sortedStatus == models.SortDefault || sortedStatus == models.SortNotExecuted {
+		sort.Slice(snapshots, func(i, j int) bool {
+			a := snapshots[i]
+			b := snapshots[j]
+			if a.OpenTurnZ > b.OpenTurnZ {
+				return true
+			}
+			return a.OpenTurnZ == b.OpenTurnZ && a.OpeningChangeRate > b.OpeningChangeRate
+		})
+	}
+	return snapshots
+}
+
+// 收盘选股, 选出的标的在下一个交易日开盘买入
+func (p *Portfolio) onSelect(date string) {
+	p.pending = nil
+	quota := p.tradeRule.Total
+	if quota < 1 {
+		return
+	}
+	snapshots := p.candidates(date)
+	if len(snapshots) > quota {
+		snapshots = snapshots[:quota]
+	}
+	p.pending = snapshots
+}
+
+// 买入, 上一个交易日选出的标的按当日的开盘价成交
+func (p *Portfolio) onBuy(date string) {
+	snapshots := p.pending
+	p.pending = nil
+	if len(snapshots) == 0 {
+		return
+	}
+	// 非盘中策略, 按实际标的数分配资金, 和stockPoolMerge保持一致
+	quantityQuota := p.tradeRule.Total
+	if p.tradeRule.Flag != models.OrderFlagTick {
+		quantityQuota = len(snapshots)
+	}
+	// 用开盘前的资产评估可用资金
+	totalAsset := p.cash + p.marketValue(date, true)
+	theoreticalFund := trader.TheoreticalFund(totalAsset, p.cash)
+	singleFundsAvailable := trader.EvaluateFundsForSingleTarget(theoreticalFund, quantityQuota, p.tradeRule.Weight, p.tradeRule.FeeMax, p.tradeRule.FeeMin)
+	if singleFundsAvailable <= trader.InvalidFee {
+		return
+	}
+	for _, snapshot := range snapshots {
+		securityCode := snapshot.SecurityCode
+		if _, ok := p.positions[securityCode]; ok {
+			continue
+		}
+		feature, _, ok := p.feature(securityCode, date)
+		if !ok || feature.Volume <= 0 {
+			// 停牌
+			continue
+		}
+		price := feature.Open
+		limitUp, _ := market.PriceLimit(securityCode, feature.LastClose)
+		// 涨停开盘买不进
+		if price <= 0 || price >= limitUp {
+			continue
+		}
+		fee := trader.EvaluateFeeForBuy(securityCode, singleFundsAvailable, price)
+		if fee.Volume <= trader.InvalidVolume || fee.TotalFee > p.cash {
+			continue
+		}
+		p.cash -= fee.TotalFee
+		name := p.securityName(securityCode, date)
+		amount := price * float64(fee.Volume)
+		p.positions[securityCode] = &portfolioPosition{
+			SecurityCode: securityCode,
+			Name:         name,
+			BuyDate:      date,
+			BuyPrice:     price,
+			Cost:         fee.TotalFee,
+			Volume:       fee.Volume,
+			LastPrice:    price,
+		}
+		p.trades = append(p.trades, PortfolioTrade{
+			Date:         date,
+			SecurityCode: securityCode,
+			Name:         name,
+			Direction:    string(trader.BUY),
+			Price:        price,
+			Volume:       fee.Volume,
+			Amount:       num.Decimal(amount),
+			Fee:          num.Decimal(fee.TotalFee - amount),
+		})
+	}
+}
+
+// 计算持仓市值, beforeOpen为true时按昨收计算
+func (p *Portfolio) marketValue(date string, beforeOpen bool) float64 {
+	value := 0.00
+	for _, position := range p.positions {
+		if feature, _, ok := p.feature(position.SecurityCode, date); ok && feature.Close > 0 {
+			if beforeOpen {
+				position.LastPrice = feature.LastClose
+			} else {
+				position.LastPrice = feature.Close
+			}
+		}
+		value += position.LastPrice * float64(position.Volume)
+	}
+	return value
+}
+
+// 收盘, 结算当日权益
+func (p *Portfolio) onMarketClose(date string) {
+	marketValue := p.marketValue(date, false)
+	totalAsset := p.cash + marketValue
+	turnover := 0.00
+	for i := len(p.trades) - 1; i >= 0 && p.trades[i].Date == date; i-- {
+		turnover += p.trades[i].Amount
+	}
+	lastAsset := p.initialCapital
+	if n := len(p.equities); n > 0 {
+		lastAsset = p.equities[n-1].TotalAsset
+	}
+	p.peak = max(p.peak, totalAsset)
+	equity := PortfolioEquity{
+		Date:        date,
+		Cash:        num.Decimal(p.cash),
+		MarketValue: num.Decimal(marketValue),
+		TotalAsset:  num.Decimal(totalAsset),
+		Positions:   len(p.positions),
+		Turnover:    num.Decimal(turnover),
+		Return:      num.NetChangeRate(lastAsset, totalAsset),
+		NetValue:    totalAsset / p.initialCapital,
+		Drawdown:    num.NetChangeRate(p.peak, totalAsset),
+	}
+	p.equities = append(p.equities, equity)
+}
+
+// 回测结束, 按收盘价卖出全部持仓, 不计入权益曲线
+//
+//	T+1, 当日买入的持仓不能卖出, 继续持有, 按收盘的市值计入期末资产
+func (p *Portfolio) liquidate(date string) {
+	for _, securityCode := range p.holdingCodes() {
+		position := p.positions[securityCode]
+		if position.BuyDate >= date {
+			continue
+		}
+		p.sell(date, position, position.LastPrice, sellRemarkFinal)
+	}
+}
+
+func (p *Portfolio) summary(dates []string) PortfolioSummary {
+	s := PortfolioSummary{
+		Strategy:       fmt.Sprintf("%d:%s", p.model.Code(), p.model.Name()),
+		Days:           len(p.equities),
+		InitialCapital: p.initialCapital,
+		FinalAsset:     p.initialCapital,
+	}
+	if len(dates) > 0 {
+		s.Begin = dates[0]
+		s.End = dates[len(dates)-1]
+	}
+	if len(p.equities) == 0 {
+		return s
+	}
+	var netValues, returns []float64
+	turnover, assets := 0.00, 0.00
+	for _, v := range p.equities {
+		netValues = append(netValues, v.NetValue)
+		returns = append(returns, v.Return/100)
+		turnover += v.Turnover
+		assets += v.TotalAsset
+	}
+	s.FinalAsset = p.equities[len(p.equities)-1].TotalAsset
+	s.TotalReturn = num.NetChangeRate(p.initialCapital, s.FinalAsset)
+	s.AnnualReturn = 100 * (math.Pow(s.FinalAsset/p.initialCapital, float64(annualTradingDays)/float64(len(p.equities))) - 1)
+	s.MaxDrawdown = 100 * portfolioMaxDrawdown(netValues)
+	riskFree := config.TraderConfig().DailyRiskFreeRate(s.End) / 100
+	s.Sharpe = portfolioSharpe(returns, riskFree)
+	if assets > 0 {
+		// 换手率: 累计成交额 / 平均总资产
+		s.Turnover = turnover / (assets / float64(len(p.equities)))
+	}
+	wins := 0
+	for _, v := range p.trades {
+		if v.Direction != string(trader.SELL) {
+			continue
+		}
+		s.Trades++
+		if v.Profit > 0 {
+			wins++
+		}
+	}
+	if s.Trades > 0 {
+		s.WinRate = 100 * float64(wins) / float64(s.Trades)
+	}
+	return s
+}
+
+// 最大回撤, 返回值为负数, 如-0.15表示回撤15%
+func portfolioMaxDrawdown(netValues []float64) float64 {
+	peak := 0.00
+	drawdown := 0.00
+	for _, v := range netValues {
+		peak = max(peak, v)
+		if peak > 0 {
+			drawdown = min(drawdown, v/peak-1)
+		}
+	}
+	return drawdown
+}
+
+// 年化夏普比率, returns和riskFree都是日收益率
+func portfolioSharpe(returns []float64, riskFree float64) float64 {
+	n := len(returns)
+	if n < 2 {
+		return 0
+	}
+	mean := 0.00
+	for _, v := range returns {
+		mean += v - riskFree
+	}
+	mean /= float64(n)
+	variance := 0.00
+	for _, v := range returns {
+		d := v - riskFree - mean
+		variance += d * d
+	}
+	stddev := math.Sqrt(variance / float64(n-1))
+	if stddev == 0 {
+		return 0
+	}
+	return mean / stddev * math.Sqrt(annualTradingDays)
+}
+
+// PortfolioBackTesting 组合回测
+//
+// 参数:
+//
+//	strategyNo: 策略编号
+//	countDays: 回测天数
+//	capital: 初始资金
+//
+// 输出:
+//  1. 控制台输出汇总指标
+//  2. 权益曲线和成交记录分别输出到CSV文件
+func PortfolioBackTesting(strategyNo uint64, countDays int, capital float64) {
+	currentlyDay := clock.CurrentlyDay()
+	dates := exchange.TradingDateRange(exchange.MARKET_CH_FIRST_LISTTIME, currentlyDay)
+	scope := api.RangeFinite(-countDays)
+	s, e, err := scope.Limits(len(dates))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	dates = dates[s : e+1]
+	model, err := models.CheckoutStrategy(strategyNo)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	tradeRule := config.GetStrategyParameterByCode(strategyNo)
+	if tradeRule == nil {
+		fmt.Printf("策略[%d]未配置或未启用\n", strategyNo)
+		return
+	}
+	if capital <= 0 {
+		fmt.Println("初始资金必须大于0")
+		return
+	}
+	bar := progressbar.NewBar(1, "执行[组合回测]", len(dates))
+	portfolio := NewPortfolio(model, *tradeRule, capital).WithProgress(func() {
+		bar.Add(1)
+	})
+	result := portfolio.Run(dates)
+	bar.Wait()
+	fmt.Println()
+
+	today := cache.Today()
+	prefix := fmt.Sprintf("%s/portfolio-%s-%s", storages.GetResultCachePath(), tradeRule.QmtStrategyName(), today)
+	if err := api.SlicesToCsv(prefix+"-equity.csv", result.Equities); err != nil {
+		logger.Errorf("保存权益曲线失败: %+v", err)
+	}
+	if err := api.SlicesToCsv(prefix+"-trades.csv", result.Trades); err != nil {
+		logger.Errorf("保存成交记录失败: %+v", err)
+	}
+	tbl := tablewriter.NewWriter(os.Stdout)
+	tbl.SetHeader(tags.GetHeadersByTags(PortfolioSummary{}))
+	tbl.Append(tags.GetValuesByTags(result.Summary))
+	tbl.Render()
+	fmt.Printf("权益曲线: %s-equity.csv\n成交记录: %s-trades.csv\n", prefix, prefix)
+}
+
+// 推进第i个交易日, 先切换策略数据的缓存日期
+func (p *Portfolio) runDay(dates []string, i int) PortfolioResult {
+	factors.SwitchDate(dates[i])
+	return p.tradeDay(dates, i)
+}
+
+// 执行第i个交易日的撮合, 不切换缓存日期, 最后一日结束时平仓并返回结果
+func (p *Portfolio) tradeDay(dates []string, i int) PortfolioResult {
+	if i == 0 && p.codes == nil {
+		p.codes = p.tradeRule.StockList()
+	}
+	date := dates[i]
+	p.onMarketOpen(date)
+	p.onBuy(date)
+	p.onSell(date)
+	p.onMarketClose(date)
+	if i < len(dates)-1 {
+		p.onSelect(date)
+		return PortfolioResult{}
+	}
+	p.liquidate(date)
+	return PortfolioResult{
+		Summary:  p.summary(dates),
+		Equities: p.equities,
+		Trades:   p.trades,
+	}
+}
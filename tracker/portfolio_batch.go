@@ -0,0 +1,62 @@
+package tracker
+
+import (
+	"sync"
+
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/factors"
+	"gitee.com/quant1x/gox/api"
+	"gitee.com/quant1x/gox/coroutine"
+)
+
+// WideKLines 宽表K线缓存, 可以并发使用
+type WideKLines struct {
+	m    sync.RWMutex
+	data map[string][]factors.SecurityFeature
+}
+
+// NewWideKLines 创建宽表K线缓存
+func NewWideKLines() *WideKLines {
+	return &WideKLines{data: map[string][]factors.SecurityFeature{}}
+}
+
+// Get 获取证券的宽表K线, 没有缓存时从文件加载, 返回的数据不能修改
+func (k *WideKLines) Get(securityCode string) []factors.SecurityFeature {
+	k.m.RLock()
+	features, found := k.data[securityCode]
+	k.m.RUnlock()
+	if found {
+		return features
+	}
+	filename := cache.WideFilename(securityCode)
+	_ = api.CsvToSlices(filename, &features)
+	k.m.Lock()
+	k.data[securityCode] = features
+	k.m.Unlock()
+	return features
+}
+
+// RunPortfolios 同时执行多个组合回测, 返回的结果和portfolios对齐
+//
+//	策略读取的特征数据是按日期切换的全局缓存, 所以全部组合按交易日同步推进,
+//	同一个交易日内的组合并发执行, 并发数不超过workers
+func RunPortfolios(portfolios []*Portfolio, dates []string, workers int) []PortfolioResult {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]PortfolioResult, len(portfolios))
+	for i, date := range dates {
+		// 切换策略数据的缓存日期, 组合调用tradeDay不再切换, 避免并发Checkout缓存
+		factors.SwitchDate(date)
+		wg := coroutine.NewRollingWaitGroup(workers)
+		for j, portfolio := range portfolios {
+			wg.Add(1)
+			go func(waitGroup *coroutine.RollingWaitGroup, index int, p *Portfolio) {
+				defer waitGroup.Done()
+				results[index] = p.tradeDay(dates, i)
+			}(wg, j, portfolio)
+		}
+		wg.Wait()
+	}
+	return results
+}
@@ -0,0 +1,93 @@
+package tracker
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/factors"
+	"gitee.com/quant1x/engine/models"
+	"gitee.com/quant1x/engine/trader"
+	"gitee.com/quant1x/gox/concurrent"
+)
+
+func Test_portfolioMaxDrawdown(t *testing.T) {
+	netValues := []float64{1.00, 1.10, 0.99, 1.05, 1.20, 0.96}
+	drawdown := portfolioMaxDrawdown(netValues)
+	fmt.Println(drawdown)
+	if math.Abs(drawdown-(-0.20)) > 1e-9 {
+		t.Errorf("max drawdown = %f, want -0.20", drawdown)
+	}
+}
+
+func Test_portfolioSharpe(t *testing.T) {
+	returns := []float64{0.01, -0.005, 0.002, 0.015, -0.01}
+	sharpe := portfolioSharpe(returns, 0)
+	fmt.Println(sharpe)
+	if sharpe <= 0 {
+		t.Errorf("sharpe = %f, want > 0", sharpe)
+	}
+	if v := portfolioSharpe([]float64{0.01, 0.01}, 0); v != 0 {
+		t.Errorf("sharpe of constant returns = %f, want 0", v)
+	}
+}
+
+// 回测用的策略, 全部标的都通过
+type portfolioFixtureModel struct{}
+
+func (portfolioFixtureModel) Code() models.ModelKind { return 82 }
+
+func (portfolioFixtureModel) Name() string { return "fixture" }
+
+func (portfolioFixtureModel) OrderFlag() string { return models.OrderFlagTail }
+
+func (portfolioFixtureModel) Filter(config.RuleParameter, factors.QuoteSnapshot) error { return nil }
+
+func (portfolioFixtureModel) Sort([]factors.QuoteSnapshot) models.SortedStatus {
+	return models.SortNotExecuted
+}
+
+func (portfolioFixtureModel) Evaluate(string, *concurrent.TreeMap[string, models.ResultInfo]) {}
+
+func TestPortfolio_nextOpen(t *testing.T) {
+	const securityCode = "sh600000"
+	dates := []string{"2024-01-02", "2024-01-03", "2024-01-04", "2024-01-05"}
+	klines := NewWideKLines()
+	klines.data[securityCode] = []factors.SecurityFeature{
+		{Date: dates[0], LastClose: 10.00, Open: 10.00, High: 10.60, Low: 9.90, Close: 10.50, Volume: 1e6},
+		{Date: dates[1], LastClose: 10.50, Open: 10.80, High: 11.20, Low: 10.70, Close: 11.00, Volume: 1e6},
+		{Date: dates[2], LastClose: 11.00, Open: 11.00, High: 11.60, Low: 10.90, Close: 11.50, Volume: 1e6},
+		{Date: dates[3], LastClose: 11.50, Open: 11.40, High: 11.80, Low: 11.30, Close: 11.60, Volume: 1e6},
+	}
+	rule := config.StrategyParameter{Flag: models.OrderFlagTail, Total: 1, FeeMax: 20000, FeeMin: 1000, HoldingPeriod: 1}
+	p := NewPortfolio(portfolioFixtureModel{}, rule, 100000).WithKLines(klines)
+	p.codes = []string{securityCode}
+	var result PortfolioResult
+	for i := range dates {
+		result = p.tradeDay(dates, i)
+	}
+	for _, v := range result.Trades {
+		fmt.Printf("%+v\n", v)
+	}
+	if len(result.Trades) != 3 {
+		t.Fatalf("trades = %d, want 3", len(result.Trades))
+	}
+	// 首日收盘选股, 次日按开盘价买入
+	buy := result.Trades[0]
+	if buy.Direction != string(trader.BUY) || buy.Date != dates[1] || buy.Price != 10.80 {
+		t.Errorf("first trade = %+v, want buy at %s open 10.80", buy, dates[1])
+	}
+	sell := result.Trades[1]
+	if sell.Direction != string(trader.SELL) || sell.Date != dates[2] || sell.Remark != sellRemarkLastDay {
+		t.Errorf("second trade = %+v, want sell at %s", sell, dates[2])
+	}
+	// 最后一日买入的持仓不能平仓
+	last := result.Trades[2]
+	if last.Direction != string(trader.BUY) || last.Date != dates[3] || last.Price != 11.40 {
+		t.Errorf("last trade = %+v, want buy at %s open 11.40", last, dates[3])
+	}
+	if _, ok := p.positions[securityCode]; !ok {
+		t.Errorf("position bought on the last day was liquidated")
+	}
+}
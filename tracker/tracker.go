@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/config"
 	"gitee.com/quant1x/engine/factors"
 	"gitee.com/quant1x/engine/models"
@@ -16,49 +17,91 @@ import (
 )
 
 // Tracker 盘中跟踪
+//
+//	收盘集合竞价期间继续跟踪, 午间休市和收盘之后没有新的快照, 日内策略的K线按时间收盘
 func Tracker(strategyNumbers ...uint64) {
 	for {
-		updateInRealTime, status := exchange.CanUpdateInRealtime()
-		isTrading := updateInRealTime && (status == exchange.ExchangeTrading || status == exchange.ExchangeSuspend)
+		updateInRealTime, status := clock.CanUpdateInRealtime()
+		isTrading := updateInRealTime && (status == exchange.ExchangeTrading || status == exchange.ExchangeSuspend || status == exchange.ExchangeCallAuction)
 		if !runtime.Debug() && !isTrading {
-			// 非调试且非交易时段返回
+			// 非调试且非交易时段返回, 收盘最后一根日内K线
+			FlushIntradayBars(strategyNumbers)
 			return
 		}
 		if status == exchange.ExchangeSuspend {
+			// 午间休市, 上午最后一根日内K线过期收盘
+			TrackIntraday(strategyNumbers)
 			time.Sleep(time.Second * 1)
 			continue
 		}
 		barIndex := 1
-		models.SyncAllSnapshots(&barIndex)
-		//stockCodes := radar.ScanSectorForTick(barIndex)
-		for _, strategyNumber := range strategyNumbers {
-			model, err := models.CheckoutStrategy(strategyNumber)
-			if err != nil || model == nil {
-				continue
-			}
-			err = permissions.CheckPermission(model)
-			if err != nil {
-				logger.Error(err)
-				continue
-			}
-			strategyParameter := config.GetStrategyParameterByCode(strategyNumber)
-			if strategyParameter == nil {
-				continue
-			}
-			if strategyParameter.Session.IsTrading() {
-				snapshotTracker(&barIndex, model, strategyParameter)
-			} else {
-				if runtime.Debug() {
-					snapshotTracker(&barIndex, model, strategyParameter)
-				} else {
-					break
-				}
-			}
+		if err := models.SyncAllSnapshots(&barIndex); err != nil {
+			logger.Errorf("同步快照失败: %+v", err)
 		}
+		//stockCodes := radar.ScanSectorForTick(barIndex)
+		TrackSnapshots(&barIndex, strategyNumbers)
 		time.Sleep(time.Second * 1)
 	}
 }
 
+// TrackSnapshots 用内存中的快照执行一轮策略跟踪
+//
+//	timestamp是判断交易时段的时间, 格式HH:MM:SS, 默认是当前时间, 回放时传入虚拟时钟的时间.
+//	日内策略的K线在交易时段判断之前合成, 非交易时段也要按时间收盘上一个时段的最后一根K线
+func TrackSnapshots(barIndex *int, strategyNumbers []uint64, timestamp ...string) {
+	for _, strategyNumber := range strategyNumbers {
+		model, strategyParameter := checkoutTrackStrategy(strategyNumber)
+		if model == nil {
+			continue
+		}
+		// 日内策略, K线收盘时评估
+		if intraday, ok := model.(models.IntradayStrategy); ok {
+			intradayTracker(intraday, strategyParameter, false, timestamp...)
+		}
+		if strategyParameter.Session.IsTrading(timestamp...) || runtime.Debug() {
+			snapshotTracker(barIndex, model, strategyParameter)
+		}
+	}
+}
+
+// TrackIntraday 只执行日内策略, 收盘已经过期的K线
+func TrackIntraday(strategyNumbers []uint64, timestamp ...string) {
+	for _, strategyNumber := range strategyNumbers {
+		model, strategyParameter := checkoutTrackStrategy(strategyNumber)
+		if intraday, ok := model.(models.IntradayStrategy); ok {
+			intradayTracker(intraday, strategyParameter, false, timestamp...)
+		}
+	}
+}
+
+// FlushIntradayBars 收盘之后收盘日内策略全部正在合成的K线, 最后一根K线不等待过期
+func FlushIntradayBars(strategyNumbers []uint64, timestamp ...string) {
+	for _, strategyNumber := range strategyNumbers {
+		model, strategyParameter := checkoutTrackStrategy(strategyNumber)
+		if intraday, ok := model.(models.IntradayStrategy); ok {
+			intradayTracker(intraday, strategyParameter, true, timestamp...)
+		}
+	}
+}
+
+// 捡出盘中跟踪的策略, 策略不存在、没有权限或者没有配置时返回nil
+func checkoutTrackStrategy(strategyNumber uint64) (models.Strategy, *config.StrategyParameter) {
+	model, err := models.CheckoutStrategy(strategyNumber)
+	if err != nil || model == nil {
+		return nil, nil
+	}
+	err = permissions.CheckPermission(model)
+	if err != nil {
+		logger.Error(err)
+		return nil, nil
+	}
+	strategyParameter := config.GetStrategyParameterByCode(strategyNumber)
+	if strategyParameter == nil {
+		return nil, nil
+	}
+	return model, strategyParameter
+}
+
 func snapshotTracker(barIndex *int, model models.Strategy, tradeRule *config.StrategyParameter) {
 	if tradeRule == nil {
 		return
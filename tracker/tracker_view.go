@@ -3,9 +3,9 @@ package tracker
 import (
 	"fmt"
 	"os"
-	"time"
 
 	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/factors"
 	"gitee.com/quant1x/engine/models"
 	"gitee.com/quant1x/engine/storages"
@@ -15,8 +15,10 @@ import (
 )
 
 // OutputTable 输出表格
+//
+//	日期和时间取引擎的时钟, 回放时是虚拟时钟的日期
 func OutputTable(model models.Strategy, stockSnapshots []factors.QuoteSnapshot) {
-	today := exchange.IndexToday()
+	today := clock.Today()
 	dates := exchange.TradeRange(exchange.MARKET_CN_FIRST_DATE, today)
 	days := len(dates)
 	currentlyDay := dates[days-1]
@@ -24,7 +26,7 @@ func OutputTable(model models.Strategy, stockSnapshots []factors.QuoteSnapshot)
 	//todayIsTradeDay := false
 	if today == currentlyDay {
 		//todayIsTradeDay = true
-		now := time.Now()
+		now := clock.Now()
 		nowTime := now.Format(exchange.CN_SERVERTIME_FORMAT)
 		if nowTime < exchange.CN_TradingStartTime {
 			currentlyDay = dates[days-2]
@@ -51,27 +51,34 @@ func calculateTheoreticalFund() (theoretical, cash float64) {
 	acc_value = num.Decimal(acc_value)
 	can_use_amount = num.Decimal(can_use_amount)
 	// 4. 确定可用资金总量: 账户可以资金 + 当日可卖出的总市值 - 预留现金
-	can_use_cash := acc.Cash + can_use_amount - traderParameter.KeepCash
+	can_use_cash := acc.Cash + can_use_amount - traderParameter().KeepCash
 	// 5. 计算预留仓位, 给下一个交易日留position_ratio仓位
-	reserve_cash := num.Decimal(acc.TotalAsset * traderParameter.PositionRatio)
+	reserve_cash := num.Decimal(acc.TotalAsset * traderParameter().PositionRatio)
 	// 6. 计算当日可用仓位: 可用资金总量 - 预留资金总量
 	available := can_use_cash - reserve_cash
 	logger.Warnf("账户资金: 可用=%.02f, 市值=%.02f, 预留=%.02f, 可买=%.02f, 可卖=%.02f", acc.Cash, acc_value, reserve_cash, available, can_use_amount)
 	// 7. 如果当日可用金额大于资金账户的可用金额, 输出风险提示
 	if available > acc.Cash {
 		logger.Warnf("!!! 持仓占比[{}%], 已超过可总仓位的[{}%], 必须在收盘前择机降低仓位, 以免影响下一个交易日的买入操作 !!!", num.Decimal(100*(acc_value/acc.TotalAsset)),
-			num.Decimal(100*(1-traderParameter.PositionRatio)))
+			num.Decimal(100*(1-traderParameter().PositionRatio)))
 	}
 	// 8. 重新修订可用金额
-	available = (acc.TotalAsset - traderParameter.KeepCash) * traderParameter.PositionRatio
-	if available > acc.Cash {
-		available = acc.Cash
-	}
-	theoretical = available
+	theoretical = TheoreticalFund(acc.TotalAsset, acc.Cash)
 	cash = acc.Cash
 	return theoretical, cash
 }
 
+// TheoreticalFund 根据总资产和可用资金计算理论上可用的资金
+//
+//	(总资产 - 预留现金) * 仓位占比, 不超过可用资金
+func TheoreticalFund(totalAsset, cash float64) float64 {
+	available := (totalAsset - traderParameter().KeepCash) * traderParameter().PositionRatio
+	if available > cash {
+		available = cash
+	}
+	return available
+}
+
 // CalculateAvailableFundsForSingleTarget 计算一只股票的可动用资金量
 //
 // 参数:
@@ -86,15 +93,22 @@ func calculateTheoreticalFund() (theoretical, cash float64) {
 //	single_funds_available: 可动用资金量
 func CalculateAvailableFundsForSingleTarget(quantityQuota int, weight, feeMax, feeMin float64) float64 {
 	onceAccount.Do(lazyInitFundPool)
+	return EvaluateFundsForSingleTarget(accountTheoreticalFund, quantityQuota, weight, feeMax, feeMin)
+}
+
+// EvaluateFundsForSingleTarget 根据理论可用资金评估一只股票的可动用资金量
+//
+//	与账户无关, 实盘和回测共用同一套资金分配逻辑
+func EvaluateFundsForSingleTarget(theoreticalFund float64, quantityQuota int, weight, feeMax, feeMin float64) float64 {
 	if quantityQuota < 1 {
 		return InvalidFee
 	}
 	// 1. 检查可用资金
-	if accountTheoreticalFund <= InvalidFee {
+	if theoreticalFund <= InvalidFee {
 		return InvalidFee
 	}
 	// 2. 计算策略的可用资金, 总可用资金*策略权重
-	strategy_funds := accountTheoreticalFund * weight
+	strategy_funds := theoreticalFund * weight
 	single_funds_available := num.Decimal(strategy_funds / float64(quantityQuota))
 	// 3. 检查策略的可用资金范围
 	if single_funds_available > feeMax {
@@ -103,9 +117,9 @@ func CalculateAvailableFundsForSingleTarget(quantityQuota int, weight, feeMax, f
 		return InvalidFee
 	}
 	// 4. 检查可用资金的最大值和最小值
-	if single_funds_available > traderParameter.BuyAmountMax {
-		single_funds_available = traderParameter.BuyAmountMax
-	} else if single_funds_available < traderParameter.BuyAmountMin {
+	if single_funds_available > traderParameter().BuyAmountMax {
+		single_funds_available = traderParameter().BuyAmountMax
+	} else if single_funds_available < traderParameter().BuyAmountMin {
 		return InvalidFee
 	}
 	return single_funds_available
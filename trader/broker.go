@@ -0,0 +1,132 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/gox/logger"
+)
+
+// 内置交易通道
+const (
+	BrokerQmt   = "qmt"   // miniQMT代理
+	BrokerPaper = "paper" // 模拟盘
+)
+
+var (
+	ErrBrokerAlreadyExists = errors.New("交易通道已存在")
+	ErrBrokerNotFound      = errors.New("交易通道不存在")
+)
+
+// Broker 交易通道
+type Broker interface {
+	// QueryAccount 查询账户信息
+	QueryAccount() (*AccountDetail, error)
+	// QueryHolding 查询持仓
+	QueryHolding() ([]PositionDetail, error)
+	// QueryOrders 查询当日委托
+	QueryOrders() ([]OrderDetail, error)
+	// PlaceOrder 下委托订单, 返回订单ID
+	PlaceOrder(direction Direction, strategyName, orderRemark, securityCode string, priceType PriceType, price float64, volume int) (int, error)
+	// CancelOrder 撤单
+	CancelOrder(orderId int) error
+}
+
+// BrokerFactory 交易通道构造函数
+type BrokerFactory func(traderParameter config.TraderParameter) (Broker, error)
+
+var (
+	brokerMutex    sync.Mutex
+	mapBrokers            = map[string]BrokerFactory{}
+	currentBroker  Broker = nil
+	mapRoleBrokers        = map[config.TraderRole]string{
+		config.RoleDisable: BrokerQmt,
+		config.RolePython:  BrokerQmt,
+		config.RoleProxy:   BrokerQmt,
+		config.RoleManual:  BrokerQmt,
+	}
+)
+
+// RegisterBroker 注册交易通道
+func RegisterBroker(name string, factory BrokerFactory) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	brokerMutex.Lock()
+	defer brokerMutex.Unlock()
+	if _, ok := mapBrokers[name]; ok {
+		return ErrBrokerAlreadyExists
+	}
+	mapBrokers[name] = factory
+	return nil
+}
+
+// BrokerName 获取配置的交易通道名称
+//
+//	优先使用broker配置, 为空时按角色选择
+func BrokerName(traderParameter config.TraderParameter) string {
+	name := strings.ToLower(strings.TrimSpace(traderParameter.Broker))
+	if len(name) > 0 {
+		return name
+	}
+	name, ok := mapRoleBrokers[traderParameter.Role]
+	if !ok {
+		name = BrokerQmt
+	}
+	return name
+}
+
+// NewBroker 按名称创建交易通道
+func NewBroker(name string, traderParameter config.TraderParameter) (Broker, error) {
+	brokerMutex.Lock()
+	factory, ok := mapBrokers[strings.ToLower(strings.TrimSpace(name))]
+	brokerMutex.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBrokerNotFound, name)
+	}
+	return factory(traderParameter)
+}
+
+// GetBroker 获取当前的交易通道
+func GetBroker() Broker {
+	brokerMutex.Lock()
+	broker := currentBroker
+	brokerMutex.Unlock()
+	if broker != nil {
+		return broker
+	}
+	parameter := *traderParameter()
+	name := BrokerName(parameter)
+	broker, err := NewBroker(name, parameter)
+	if err != nil {
+		logger.Errorf("trader: 创建交易通道[%s]失败: %+v, 使用默认通道[%s]", name, err, BrokerQmt)
+		broker = newQmtBroker(parameter)
+	}
+	brokerMutex.Lock()
+	defer brokerMutex.Unlock()
+	if currentBroker == nil {
+		currentBroker = broker
+	}
+	return currentBroker
+}
+
+// SetBroker 替换当前的交易通道, 主要用于测试和模拟
+func SetBroker(broker Broker) {
+	brokerMutex.Lock()
+	defer brokerMutex.Unlock()
+	currentBroker = broker
+}
+
+// BrokerList 已注册的交易通道列表
+func BrokerList() []string {
+	brokerMutex.Lock()
+	defer brokerMutex.Unlock()
+	list := make([]string, 0, len(mapBrokers))
+	for name := range mapBrokers {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
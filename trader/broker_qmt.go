@@ -0,0 +1,152 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	urlpkg "net/url"
+	"strings"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/gox/http"
+	"gitee.com/quant1x/gox/logger"
+)
+
+func init() {
+	err := RegisterBroker(BrokerQmt, func(traderParameter config.TraderParameter) (Broker, error) {
+		return newQmtBroker(traderParameter), nil
+	})
+	if err != nil {
+		logger.Fatalf("%+v", err)
+	}
+}
+
+// miniQMT代理交易通道
+type qmtBroker struct {
+	urlAccount     string // 查询账户信息
+	urlHolding     string // 查询持仓信息
+	urlOrders      string // 查询委托
+	urlPlaceOrder  string // 委托
+	urlCancelOrder string // 撤单
+}
+
+func newQmtBroker(traderParameter config.TraderParameter) *qmtBroker {
+	// miniQMT代理服务器地址
+	//urlPrefixMiniQmtProxy = "http://10.211.55.3:18168/qmt"
+	urlPrefixMiniQmtProxy := traderParameter.ProxyUrl
+	// 查询前缀
+	urlPrefixForQuery := urlPrefixMiniQmtProxy + "/query"
+	// 交易前缀
+	urlPrefixForTrade := urlPrefixMiniQmtProxy + "/trade"
+	return &qmtBroker{
+		urlAccount:     urlPrefixForQuery + "/asset",
+		urlHolding:     urlPrefixForQuery + "/holding",
+		urlOrders:      urlPrefixForQuery + "/order",
+		urlPlaceOrder:  urlPrefixForTrade + "/order",
+		urlCancelOrder: urlPrefixForTrade + "/cancel",
+	}
+}
+
+// QueryAccount 查询账户信息
+func (b *qmtBroker) QueryAccount() (*AccountDetail, error) {
+	data, err := http.Post(b.urlAccount, "")
+	if err != nil {
+		logger.Errorf("trader: 查询账户异常: %+v", err)
+		return nil, err
+	}
+	var detail AccountDetail
+	err = json.Unmarshal(data, &detail)
+	if err != nil {
+		logger.Errorf("trader: 解析json异常: %+v", err)
+		return nil, err
+	}
+	return &detail, nil
+}
+
+// QueryHolding 查询持仓
+func (b *qmtBroker) QueryHolding() ([]PositionDetail, error) {
+	data, err := http.Post(b.urlHolding, "")
+	if err != nil {
+		logger.Errorf("trader: 查询持仓异常: %+v", err)
+		return nil, err
+	}
+	var detail []PositionDetail
+	err = json.Unmarshal(data, &detail)
+	if err != nil {
+		logger.Errorf("trader: 解析json异常: %+v", err)
+		return nil, err
+	}
+	return detail, nil
+}
+
+// QueryOrders 查询当日委托
+func (b *qmtBroker) QueryOrders() ([]OrderDetail, error) {
+	data, err := http.Post(b.urlOrders, "")
+	if err != nil {
+		logger.Errorf("trader: 查询委托异常: %+v", err)
+		return nil, err
+	}
+	var detail []OrderDetail
+	err = json.Unmarshal(data, &detail)
+	if err != nil {
+		logger.Errorf("trader: 解析json异常: %+v", err)
+		return nil, err
+	}
+	return detail, nil
+}
+
+// CancelOrder 撤单
+func (b *qmtBroker) CancelOrder(orderId int) error {
+	params := urlpkg.Values{
+		"order_id": {fmt.Sprintf("%d", orderId)},
+	}
+	body := params.Encode()
+	logger.Infof("trader-cancel: %s", body)
+	data, err := http.Post(b.urlCancelOrder, body)
+	if err != nil {
+		logger.Errorf("trader-cancel: 撤单操作异常: %+v", err)
+		return err
+	}
+	var detail OrderResult
+	err = json.Unmarshal(data, &detail)
+	if err != nil {
+		logger.Errorf("trader-cancel: 解析json异常: %+v", err)
+		return err
+	}
+	logger.Infof("trader-cancel: %s, response: status=%d", body, detail.Status)
+	return nil
+}
+
+// PlaceOrder 直接下单(透传)
+func (b *qmtBroker) PlaceOrder(direction Direction, strategyName, orderRemark, securityCode string, priceType PriceType, price float64, volume int) (int, error) {
+	params := urlpkg.Values{
+		"direction":  {direction.String()},
+		"code":       {qmtStockCode(securityCode)},
+		"price_type": {fmt.Sprintf("%d", priceType)},
+		"price":      {fmt.Sprintf("%f", price)},
+		"volume":     {fmt.Sprintf("%d", volume)},
+		"strategy":   {strategyName},
+		"remark":     {orderRemark},
+	}
+	body := params.Encode()
+	logger.Infof("trader-order: %s", body)
+	data, err := http.Post(b.urlPlaceOrder, body)
+	if err != nil {
+		logger.Errorf("trader-order: 下单操作异常: %+v", err)
+		return InvalidOrderId, err
+	}
+	var detail OrderResult
+	err = json.Unmarshal(data, &detail)
+	if err != nil {
+		logger.Errorf("trader-order: 解析json异常: %+v", err)
+		return InvalidOrderId, err
+	}
+	logger.Infof("trade-order: %s, response: order_id=%d", body, detail.OrderId)
+	return detail.OrderId, nil
+}
+
+// 证券代码转成QMT格式, 例如 600000.SH
+func qmtStockCode(securityCode string) string {
+	_, mflag, symbol := exchange.DetectMarket(securityCode)
+	return fmt.Sprintf("%s.%s", symbol, strings.ToUpper(mflag))
+}
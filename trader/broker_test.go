@@ -0,0 +1,63 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gitee.com/quant1x/engine/config"
+)
+
+type mockBroker struct {
+	orders []OrderDetail
+}
+
+func (m *mockBroker) QueryAccount() (*AccountDetail, error) {
+	return &AccountDetail{TotalAsset: 100000, Cash: 100000}, nil
+}
+
+func (m *mockBroker) QueryHolding() ([]PositionDetail, error) {
+	return nil, nil
+}
+
+func (m *mockBroker) QueryOrders() ([]OrderDetail, error) {
+	return m.orders, nil
+}
+
+func (m *mockBroker) PlaceOrder(direction Direction, strategyName, orderRemark, securityCode string, priceType PriceType, price float64, volume int) (int, error) {
+	orderId := len(m.orders) + 1
+	m.orders = append(m.orders, OrderDetail{OrderId: orderId, StockCode: qmtStockCode(securityCode), Price: price, OrderVolume: volume})
+	return orderId, nil
+}
+
+func (m *mockBroker) CancelOrder(orderId int) error {
+	return nil
+}
+
+func TestBrokerRegistry(t *testing.T) {
+	fmt.Println(BrokerList())
+	if err := RegisterBroker(BrokerQmt, nil); !errors.Is(err, ErrBrokerAlreadyExists) {
+		t.Errorf("register qmt again, err = %v", err)
+	}
+	if name := BrokerName(config.TraderParameter{Role: config.RoleProxy}); name != BrokerQmt {
+		t.Errorf("broker name = %s, want %s", name, BrokerQmt)
+	}
+	if name := BrokerName(config.TraderParameter{Broker: " Paper "}); name != BrokerPaper {
+		t.Errorf("broker name = %s, want %s", name, BrokerPaper)
+	}
+	if _, err := NewBroker("unknown", config.TraderParameter{}); !errors.Is(err, ErrBrokerNotFound) {
+		t.Errorf("new unknown broker, err = %v", err)
+	}
+}
+
+func TestSetBroker(t *testing.T) {
+	broker := &mockBroker{}
+	SetBroker(broker)
+	defer SetBroker(nil)
+	orderId, err := DirectOrder(BUY, "test", "mock", "sh600000", FIX_PRICE, 10.00, 100)
+	fmt.Println(orderId, err)
+	orders, _ := QueryOrders()
+	if len(orders) != 1 || orders[0].StockCode != "600000.SH" {
+		t.Errorf("orders = %+v", orders)
+	}
+}
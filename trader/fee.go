@@ -105,9 +105,9 @@ func calculate_transaction_fee(direction Direction, price float64, volume int, a
 	// 1. 印花税, 按照成交金额计算, 买入没有, 卖出, 0.1%
 	_stamp_duty_fee := amount
 	if direction == BUY {
-		_stamp_duty_fee *= traderParameter.StampDutyRateForBuy
+		_stamp_duty_fee *= traderParameter().StampDutyRateForBuy
 	} else if direction == SELL {
-		_stamp_duty_fee *= traderParameter.StampDutyRateForSell
+		_stamp_duty_fee *= traderParameter().StampDutyRateForSell
 	} else {
 		return InvalidFee, 0, 0, 0, 0
 	}
@@ -115,17 +115,17 @@ func calculate_transaction_fee(direction Direction, price float64, volume int, a
 		_stamp_duty_fee = num.Decimal(_stamp_duty_fee)
 	}
 	// 2. 过户费, 按照股票数量, 双向, 0.06%
-	_transfer_fee := vol * traderParameter.TransferRate
+	_transfer_fee := vol * traderParameter().TransferRate
 	if align {
 		_transfer_fee = num.Decimal(_transfer_fee)
 	}
 	// 3. 券商佣金, 按照成交金额计算, 双向, 0.025%
-	_commission_fee := amount * traderParameter.CommissionRate
+	_commission_fee := amount * traderParameter().CommissionRate
 	if align {
 		_commission_fee = num.Decimal(_commission_fee)
 	}
-	if align && _commission_fee < traderParameter.CommissionMin {
-		_commission_fee = traderParameter.CommissionMin
+	if align && _commission_fee < traderParameter().CommissionMin {
+		_commission_fee = traderParameter().CommissionMin
 	}
 	// 4. 股票市值
 	_marketValue := amount
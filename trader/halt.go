@@ -0,0 +1,232 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/gox/api"
+	"gitee.com/quant1x/gox/logger"
+	"gitee.com/quant1x/pkg/tablewriter"
+)
+
+const (
+	haltFilename = "halt.csv"
+)
+
+// HaltScope 暂停交易的范围
+type HaltScope = string
+
+const (
+	HaltGlobal   HaltScope = "global"   // 全局暂停
+	HaltStrategy HaltScope = "strategy" // 暂停指定策略
+	HaltBuy      HaltScope = "buy"      // 暂停买入
+	HaltSell     HaltScope = "sell"     // 暂停卖出
+)
+
+// RiskRuleHalt 暂停交易的风控规则名称
+const RiskRuleHalt = "halt"
+
+var (
+	ErrHaltScope    = errors.New("无效的暂停范围")
+	ErrHaltNotFound = errors.New("没有找到暂停记录")
+)
+
+// HaltRecord 暂停交易记录
+type HaltRecord struct {
+	Scope      string `name:"范围" dataframe:"scope"`
+	StrategyId uint64 `name:"策略ID" dataframe:"strategy_id"`
+	Reason     string `name:"原因" dataframe:"reason"`
+	CreateTime string `name:"时间" dataframe:"create_time"`
+}
+
+// 记录是否匹配指定方向和策略的委托
+func (r HaltRecord) match(direction Direction, strategyName string) bool {
+	switch r.Scope {
+	case HaltGlobal:
+		return true
+	case HaltStrategy:
+		return strategyName == config.QmtStrategyNameFromId(r.StrategyId)
+	case HaltBuy:
+		return direction == BUY
+	case HaltSell:
+		return direction == SELL
+	}
+	return false
+}
+
+func (r HaltRecord) String() string {
+	if r.Scope == HaltStrategy {
+		return fmt.Sprintf("%s[%d]", r.Scope, r.StrategyId)
+	}
+	return r.Scope
+}
+
+var (
+	haltMutex   sync.RWMutex
+	haltList    []HaltRecord
+	haltModTime time.Time
+)
+
+func getHaltFilename() string {
+	return path.Join(cache.GetRootPath(), haltFilename)
+}
+
+// 加载暂停记录, 文件有变化时重新加载, 其它进程的halt/resume命令可以即时生效
+func loadHaltList() []HaltRecord {
+	filename := getHaltFilename()
+	stat, err := os.Stat(filename)
+	haltMutex.RLock()
+	if err != nil || !stat.ModTime().After(haltModTime) {
+		list := haltList
+		haltMutex.RUnlock()
+		if err != nil {
+			return nil
+		}
+		return list
+	}
+	haltMutex.RUnlock()
+	var list []HaltRecord
+	_ = api.CsvToSlices(filename, &list)
+	haltMutex.Lock()
+	haltList = list
+	haltModTime = stat.ModTime()
+	haltMutex.Unlock()
+	return list
+}
+
+func saveHaltList(list []HaltRecord) error {
+	filename := getHaltFilename()
+	if err := api.CheckFilepath(filename, true); err != nil {
+		return err
+	}
+	return api.SlicesToCsv(filename, list)
+}
+
+// HaltedFor 检查指定方向和策略的委托是否被暂停, 返回匹配的暂停记录
+func HaltedFor(direction Direction, strategyName string) *HaltRecord {
+	for _, v := range loadHaltList() {
+		if v.match(direction, strategyName) {
+			return &v
+		}
+	}
+	return nil
+}
+
+// 风控规则: 暂停交易, 不受风控开关影响
+func riskCheckHalt(order RiskOrder) *RiskRejection {
+	record := HaltedFor(order.Direction, order.StrategyName)
+	if record == nil {
+		return nil
+	}
+	return &RiskRejection{Rule: RiskRuleHalt, Message: fmt.Sprintf("交易已暂停: %s, %s", record, record.Reason)}
+}
+
+// Halt 暂停交易, 并撤销范围内未完成的委托
+func Halt(scope HaltScope, strategyId uint64, reason string) error {
+	record := HaltRecord{
+		Scope:      scope,
+		StrategyId: strategyId,
+		Reason:     reason,
+		CreateTime: clock.Now().Format(cache.TimeStampMilli),
+	}
+	if scope != HaltStrategy {
+		record.StrategyId = 0
+	}
+	switch scope {
+	case HaltGlobal, HaltStrategy, HaltBuy, HaltSell:
+	default:
+		return ErrHaltScope
+	}
+	list := loadHaltList()
+	exists := false
+	for _, v := range list {
+		if v.Scope == record.Scope && v.StrategyId == record.StrategyId {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		list = append(list, record)
+		if err := saveHaltList(list); err != nil {
+			return err
+		}
+	}
+	logger.Warnf("trader-halt: 暂停交易 %s, %s", record, reason)
+	return cancelOrdersForHalt(record)
+}
+
+// 撤销暂停范围内未完成的委托
+func cancelOrdersForHalt(record HaltRecord) error {
+	orders, err := QueryOrders()
+	if err != nil {
+		return err
+	}
+	for _, v := range orders {
+		state := orderStateFromStatus(v.OrderStatus, v.TradedVolume)
+		if state != OrderStateSubmitted && state != OrderStatePartiallyFilled {
+			continue
+		}
+		direction := BUY
+		if v.OrderType == STOCK_SELL {
+			direction = SELL
+		}
+		if !record.match(direction, v.StrategyName) {
+			continue
+		}
+		if err := CancelOrder(v.OrderId); err != nil {
+			logger.Errorf("trader-halt: 撤单失败, order_id=%d, %s: %+v", v.OrderId, v.StockCode, err)
+			continue
+		}
+		logger.Warnf("trader-halt: 已撤单, order_id=%d, %s", v.OrderId, v.StockCode)
+	}
+	return nil
+}
+
+// Resume 恢复交易
+func Resume(scope HaltScope, strategyId uint64) error {
+	if scope != HaltStrategy {
+		strategyId = 0
+	}
+	list := loadHaltList()
+	var remains []HaltRecord
+	for _, v := range list {
+		if v.Scope == scope && v.StrategyId == strategyId {
+			continue
+		}
+		remains = append(remains, v)
+	}
+	if len(remains) == len(list) {
+		return ErrHaltNotFound
+	}
+	logger.Warnf("trader-halt: 恢复交易 %s", HaltRecord{Scope: scope, StrategyId: strategyId})
+	return saveHaltList(remains)
+}
+
+// ResumeAll 恢复全部交易
+func ResumeAll() error {
+	logger.Warnf("trader-halt: 恢复全部交易")
+	return saveHaltList([]HaltRecord{})
+}
+
+// GetHaltList 输出暂停交易列表
+func GetHaltList() {
+	list := loadHaltList()
+	fmt.Println("暂停交易列表, 总数：", len(list))
+	if len(list) == 0 {
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"序号", "范围", "策略ID", "原因", "时间"})
+	for i, v := range list {
+		table.Append([]string{strconv.Itoa(i + 1), v.Scope, strconv.FormatUint(v.StrategyId, 10), v.Reason, v.CreateTime})
+	}
+	table.Render()
+}
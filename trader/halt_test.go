@@ -0,0 +1,32 @@
+package trader
+
+import (
+	"testing"
+
+	"gitee.com/quant1x/engine/config"
+)
+
+func TestHaltRecordMatch(t *testing.T) {
+	strategyName := config.QmtStrategyNameFromId(1)
+	tests := []struct {
+		record    HaltRecord
+		direction Direction
+		strategy  string
+		want      bool
+	}{
+		{HaltRecord{Scope: HaltGlobal}, BUY, strategyName, true},
+		{HaltRecord{Scope: HaltGlobal}, SELL, strategyName, true},
+		{HaltRecord{Scope: HaltBuy}, BUY, strategyName, true},
+		{HaltRecord{Scope: HaltBuy}, SELL, strategyName, false},
+		{HaltRecord{Scope: HaltSell}, SELL, strategyName, true},
+		{HaltRecord{Scope: HaltSell}, BUY, strategyName, false},
+		{HaltRecord{Scope: HaltStrategy, StrategyId: 1}, BUY, strategyName, true},
+		{HaltRecord{Scope: HaltStrategy, StrategyId: 2}, BUY, strategyName, false},
+		{HaltRecord{Scope: "unknown"}, BUY, strategyName, false},
+	}
+	for _, v := range tests {
+		if got := v.record.match(v.direction, v.strategy); got != v.want {
+			t.Errorf("%s match(%s, %s) = %v, want %v", v.record, v.direction, v.strategy, got, v.want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/logger"
 )
@@ -54,7 +55,7 @@ func lazyLoadHoldingOrder() {
 	}
 	// 3. 重新评估持仓范围, 有可能存在日期没有成交的可能
 	firstDate := dates[0]
-	lastTradeDate := exchange.LastTradeDate()
+	lastTradeDate := clock.LastTradeDate()
 	dates = exchange.TradingDateRange(firstDate, lastTradeDate)
 	// 反转日期切片
 	slices.Reverse(dates)
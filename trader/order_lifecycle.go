@@ -0,0 +1,378 @@
+package trader
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/gox/api"
+	"gitee.com/quant1x/gox/logger"
+)
+
+// OrderState 订单生命周期状态
+type OrderState = string
+
+const (
+	OrderStatePending         OrderState = "pending"          // 待提交, 本地已创建
+	OrderStateSubmitted       OrderState = "submitted"        // 已提交, 券商已受理
+	OrderStatePartiallyFilled OrderState = "partially_filled" // 部分成交
+	OrderStateFilled          OrderState = "filled"           // 全部成交
+	OrderStateCancelled       OrderState = "cancelled"        // 已撤单, 包括部成部撤
+	OrderStateRejected        OrderState = "rejected"         // 拒单, 本地风控或者提交失败
+	OrderStateJunk            OrderState = "junk"             // 废单, 券商或交易所拒绝
+)
+
+// 状态迁移表, 终态没有后续状态
+var mapOrderStateTransitions = map[OrderState][]OrderState{
+	OrderStatePending:         {OrderStateSubmitted, OrderStateRejected, OrderStateJunk},
+	OrderStateSubmitted:       {OrderStatePartiallyFilled, OrderStateFilled, OrderStateCancelled, OrderStateJunk},
+	OrderStatePartiallyFilled: {OrderStatePartiallyFilled, OrderStateFilled, OrderStateCancelled},
+}
+
+// OrderStateCanTransition 判断状态是否可以迁移
+func OrderStateCanTransition(from, to OrderState) bool {
+	for _, v := range mapOrderStateTransitions[from] {
+		if v == to {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderStateIsOpen 订单是否未结束
+func OrderStateIsOpen(state OrderState) bool {
+	return state == OrderStatePending || state == OrderStateSubmitted || state == OrderStatePartiallyFilled
+}
+
+// 券商委托状态转订单生命周期状态, 无法识别的返回空
+func orderStateFromStatus(status OrderStatus, tradedVolume int) OrderState {
+	switch status {
+	case ORDER_UNREPORTED, ORDER_WAIT_REPORTING, ORDER_REPORTED, ORDER_REPORTED_CANCEL:
+		return OrderStateSubmitted
+	case ORDER_PART_SUCC, ORDER_PARTSUCC_CANCEL:
+		return OrderStatePartiallyFilled
+	case ORDER_SUCCEEDED:
+		return OrderStateFilled
+	case ORDER_PART_CANCEL, ORDER_CANCELED:
+		return OrderStateCancelled
+	case ORDER_JUNK:
+		return OrderStateJunk
+	}
+	if tradedVolume > 0 {
+		return OrderStatePartiallyFilled
+	}
+	return ""
+}
+
+// OrderRecord 订单生命周期记录
+type OrderRecord struct {
+	Date         string  `name:"交易日期" dataframe:"date"`
+	LocalId      string  `name:"本地编号" dataframe:"local_id"`
+	OrderId      int     `name:"订单ID" dataframe:"order_id"`
+	StrategyName string  `name:"策略名称" dataframe:"strategy_name"`
+	OrderRemark  string  `name:"委托备注" dataframe:"order_remark"`
+	SecurityCode string  `name:"证券代码" dataframe:"security_code"`
+	Direction    string  `name:"交易方向" dataframe:"direction"`
+	PriceType    int     `name:"报价类型" dataframe:"price_type"`
+	Price        float64 `name:"委托价格" dataframe:"price"`
+	Volume       int     `name:"委托量" dataframe:"volume"`
+	TradedPrice  float64 `name:"成交均价" dataframe:"traded_price"`
+	TradedVolume int     `name:"成交数量" dataframe:"traded_volume"`
+	State        string  `name:"订单状态" dataframe:"state"`
+	BrokerStatus int     `name:"委托状态" dataframe:"broker_status"`
+	Reason       string  `name:"原因" dataframe:"reason"`
+	CreateTime   string  `name:"创建时间" dataframe:"create_time"`
+	CancelTime   string  `name:"撤单时间" dataframe:"cancel_time"`
+	UpdateTime   string  `name:"更新时间" dataframe:"update_time"`
+}
+
+// 状态迁移, 非法迁移记录日志并忽略
+func (r *OrderRecord) transition(state OrderState, reason string) bool {
+	if r.State == state && state != OrderStatePartiallyFilled {
+		return false
+	}
+	if !OrderStateCanTransition(r.State, state) {
+		logger.Warnf("trader-order: %s, order_id=%d, 非法的状态迁移 %s -> %s", r.LocalId, r.OrderId, r.State, state)
+		return false
+	}
+	r.State = state
+	if len(reason) > 0 {
+		r.Reason = reason
+	}
+	r.UpdateTime = clock.Now().Format(cache.TimeStampMilli)
+	return true
+}
+
+// GetOrderLifecycleFilename 获得订单生命周期文件名
+//
+//	qmt/账户id/lifecycle.yyyy-mm-dd
+func GetOrderLifecycleFilename(date ...string) string {
+	var tradeDate string
+	if len(date) > 0 {
+		tradeDate = exchange.FixTradeDate(date[0])
+	} else {
+		tradeDate = clock.CurrentlyDay()
+	}
+	return filepath.Join(traderQmtOrderPath, "lifecycle."+tradeDate)
+}
+
+// 当日订单簿
+type orderBook struct {
+	mutex   sync.Mutex
+	date    string
+	records []*OrderRecord
+}
+
+var (
+	todayOrders orderBook
+	submitMutex sync.Mutex // 风控检查、创建订单记录和提交券商串行执行, 防止并发的重复委托, 核对委托时也要持有
+)
+
+// 切换到当前交易日, 日期变化时重新加载
+func (b *orderBook) checkout() {
+	date := clock.CurrentlyDay()
+	if b.date == date {
+		return
+	}
+	var list []OrderRecord
+	_ = api.CsvToSlices(GetOrderLifecycleFilename(date), &list)
+	b.records = make([]*OrderRecord, 0, len(list))
+	for i := range list {
+		b.records = append(b.records, &list[i])
+	}
+	b.date = date
+}
+
+func (b *orderBook) save() {
+	list := make([]OrderRecord, 0, len(b.records))
+	for _, v := range b.records {
+		list = append(list, *v)
+	}
+	if err := api.SlicesToCsv(GetOrderLifecycleFilename(b.date), list); err != nil {
+		logger.Errorf("trader-order: 保存订单状态异常: %+v", err)
+	}
+}
+
+func (b *orderBook) findByOrderId(orderId int) *OrderRecord {
+	for _, v := range b.records {
+		if v.OrderId == orderId && orderId != InvalidOrderId {
+			return v
+		}
+	}
+	return nil
+}
+
+func (b *orderBook) newRecord(direction Direction, strategyName, orderRemark, securityCode string, priceType PriceType, price float64, volume int) *OrderRecord {
+	now := clock.Now().Format(cache.TimeStampMilli)
+	record := &OrderRecord{
+		Date:         b.date,
+		LocalId:      fmt.Sprintf("%s-%04d", b.date, len(b.records)+1),
+		OrderId:      InvalidOrderId,
+		StrategyName: strategyName,
+		OrderRemark:  orderRemark,
+		SecurityCode: exchange.CorrectSecurityCode(securityCode),
+		Direction:    direction.String(),
+		PriceType:    priceType,
+		Price:        price,
+		Volume:       volume,
+		State:        OrderStatePending,
+		CreateTime:   now,
+		UpdateTime:   now,
+	}
+	b.records = append(b.records, record)
+	return record
+}
+
+// 提交委托, 记录订单的完整生命周期: pending -> submitted | rejected
+//
+//	提交券商之前必须通过交易前风控检查. 提交期间一直持有submitMutex, 直到订单ID写回记录,
+//	防止核对委托时找不到本地记录而重复登记同一个委托
+func submitOrder(direction Direction, strategyName, orderRemark, securityCode string, priceType PriceType, price float64, volume int) (int, error) {
+	submitMutex.Lock()
+	defer submitMutex.Unlock()
+	rejection := CheckRisk(RiskOrder{
+		Direction:    direction,
+		StrategyName: strategyName,
+		SecurityCode: securityCode,
+		Price:        price,
+		Volume:       volume,
+	})
+	todayOrders.mutex.Lock()
+	todayOrders.checkout()
+	record := todayOrders.newRecord(direction, strategyName, orderRemark, securityCode, priceType, price, volume)
+	if rejection != nil {
+		record.transition(OrderStateRejected, rejection.Error())
+		todayOrders.save()
+		todayOrders.mutex.Unlock()
+		return InvalidOrderId, rejection
+	}
+	todayOrders.save()
+	todayOrders.mutex.Unlock()
+
+	orderId, err := GetBroker().PlaceOrder(direction, strategyName, orderRemark, securityCode, priceType, price, volume)
+
+	todayOrders.mutex.Lock()
+	defer todayOrders.mutex.Unlock()
+	if err != nil || orderId < 0 {
+		reason := "提交失败"
+		if err != nil {
+			reason = err.Error()
+		}
+		record.transition(OrderStateRejected, reason)
+	} else {
+		record.OrderId = orderId
+		record.transition(OrderStateSubmitted, "")
+	}
+	todayOrders.save()
+	return orderId, err
+}
+
+// GetTodayOrderRecords 获取当日的订单生命周期记录
+func GetTodayOrderRecords() []OrderRecord {
+	todayOrders.mutex.Lock()
+	defer todayOrders.mutex.Unlock()
+	todayOrders.checkout()
+	list := make([]OrderRecord, 0, len(todayOrders.records))
+	for _, v := range todayOrders.records {
+		list = append(list, *v)
+	}
+	return list
+}
+
+// GetOrderRecordList 获取指定日期的订单生命周期记录
+func GetOrderRecordList(date string) []OrderRecord {
+	var list []OrderRecord
+	_ = api.CsvToSlices(GetOrderLifecycleFilename(date), &list)
+	return list
+}
+
+// ReconcileOrders 用券商的当日委托核对订单状态
+//
+//	成交增量合并到持仓, 非本系统提交的委托也纳入订单簿
+func ReconcileOrders() error {
+	list, err := QueryOrders()
+	if err != nil {
+		return err
+	}
+	// 等待正在提交的委托写回订单ID
+	submitMutex.Lock()
+	defer submitMutex.Unlock()
+	todayOrders.mutex.Lock()
+	defer todayOrders.mutex.Unlock()
+	todayOrders.checkout()
+	changed := false
+	merged := false
+	for _, v := range list {
+		record := todayOrders.findByOrderId(v.OrderId)
+		if record == nil {
+			direction := BUY
+			if v.OrderType == STOCK_SELL {
+				direction = SELL
+			}
+			record = todayOrders.newRecord(direction, v.StrategyName, v.OrderRemark, v.SecurityCode(), v.PriceType, v.Price, v.OrderVolume)
+			record.OrderId = v.OrderId
+			record.transition(OrderStateSubmitted, "")
+			changed = true
+		}
+		// 1. 成交增量合并到持仓
+		delta := v.TradedVolume - record.TradedVolume
+		if delta > 0 {
+			amount := v.TradedPrice*float64(v.TradedVolume) - record.TradedPrice*float64(record.TradedVolume)
+			order := v
+			order.TradedVolume = delta
+			order.TradedPrice = amount / float64(delta)
+			if mergeOrderToPosition(order) {
+				merged = true
+			}
+			record.TradedVolume = v.TradedVolume
+			record.TradedPrice = v.TradedPrice
+			changed = true
+		}
+		if record.BrokerStatus != v.OrderStatus {
+			record.BrokerStatus = v.OrderStatus
+			changed = true
+		}
+		// 2. 状态迁移
+		state := orderStateFromStatus(v.OrderStatus, v.TradedVolume)
+		if len(state) > 0 && state != record.State {
+			if record.transition(state, v.StatusMessage) {
+				changed = true
+			}
+		}
+	}
+	if changed {
+		todayOrders.save()
+	}
+	if merged {
+		CacheSync()
+	}
+	return nil
+}
+
+// 成交增量合并到持仓
+func mergeOrderToPosition(order OrderDetail) bool {
+	periodicOnce.Do(lazyLoadLocalPositions)
+	securityCode := order.SecurityCode()
+	position, found := mapPositions.Get(securityCode)
+	if !found {
+		position = &Position{
+			AccountType:  order.AccountType,
+			AccountId:    order.AccountId,
+			SecurityCode: securityCode,
+		}
+	}
+	ok := position.MergeFromOrder(order)
+	if ok {
+		mapPositions.Put(securityCode, position)
+	}
+	return ok
+}
+
+// CancelStaleOrders 撤销超时未成交的买入委托
+//
+//	仅在撤单时段内执行, 超时时间由TraderParameter.CancelTimeout配置, 单位秒, 0为不自动撤单
+func CancelStaleOrders() {
+	timeout := time.Duration(traderParameter().CancelTimeout) * time.Second
+	if timeout <= 0 || !traderParameter().CancelSession.IsTrading() {
+		return
+	}
+	todayOrders.mutex.Lock()
+	todayOrders.checkout()
+	var stale []*OrderRecord
+	now := clock.Now()
+	for _, v := range todayOrders.records {
+		if v.Direction != BUY.String() || v.OrderId == InvalidOrderId || len(v.CancelTime) > 0 {
+			continue
+		}
+		if v.State != OrderStateSubmitted && v.State != OrderStatePartiallyFilled {
+			continue
+		}
+		createTime, err := time.ParseInLocation(cache.TimeStampMilli, v.CreateTime, time.Local)
+		if err != nil || now.Sub(createTime) < timeout {
+			continue
+		}
+		stale = append(stale, v)
+	}
+	todayOrders.mutex.Unlock()
+	if len(stale) == 0 {
+		return
+	}
+	for _, v := range stale {
+		err := CancelOrder(v.OrderId)
+		if err != nil {
+			logger.Errorf("trader-order: %s, order_id=%d, 超时撤单失败: %+v", v.SecurityCode, v.OrderId, err)
+			continue
+		}
+		logger.Warnf("trader-order: %s, order_id=%d, 超时未成交, 已撤单", v.SecurityCode, v.OrderId)
+		todayOrders.mutex.Lock()
+		v.CancelTime = now.Format(cache.TimeStampMilli)
+		todayOrders.mutex.Unlock()
+	}
+	todayOrders.mutex.Lock()
+	todayOrders.save()
+	todayOrders.mutex.Unlock()
+}
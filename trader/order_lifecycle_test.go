@@ -0,0 +1,57 @@
+package trader
+
+import "testing"
+
+func TestOrderStateTransition(t *testing.T) {
+	tests := []struct {
+		from, to OrderState
+		want     bool
+	}{
+		{OrderStatePending, OrderStateSubmitted, true},
+		{OrderStatePending, OrderStateRejected, true},
+		{OrderStateSubmitted, OrderStatePartiallyFilled, true},
+		{OrderStatePartiallyFilled, OrderStateFilled, true},
+		{OrderStatePartiallyFilled, OrderStateCancelled, true},
+		{OrderStateSubmitted, OrderStatePending, false},
+		{OrderStateFilled, OrderStateCancelled, false},
+		{OrderStateJunk, OrderStateSubmitted, false},
+	}
+	for _, v := range tests {
+		if got := OrderStateCanTransition(v.from, v.to); got != v.want {
+			t.Errorf("%s -> %s = %t, want %t", v.from, v.to, got, v.want)
+		}
+	}
+}
+
+func Test_orderStateFromStatus(t *testing.T) {
+	tests := []struct {
+		status OrderStatus
+		traded int
+		want   OrderState
+	}{
+		{ORDER_REPORTED, 0, OrderStateSubmitted},
+		{ORDER_PART_SUCC, 100, OrderStatePartiallyFilled},
+		{ORDER_SUCCEEDED, 100, OrderStateFilled},
+		{ORDER_PART_CANCEL, 100, OrderStateCancelled},
+		{ORDER_JUNK, 0, OrderStateJunk},
+		{ORDER_UNKNOWN, 0, ""},
+	}
+	for _, v := range tests {
+		if got := orderStateFromStatus(v.status, v.traded); got != v.want {
+			t.Errorf("status %d = %s, want %s", v.status, got, v.want)
+		}
+	}
+}
+
+func TestOrderRecordTransition(t *testing.T) {
+	record := OrderRecord{State: OrderStatePending}
+	if !record.transition(OrderStateSubmitted, "") {
+		t.Fatal("pending -> submitted failed")
+	}
+	if record.transition(OrderStatePending, "") {
+		t.Fatal("submitted -> pending should be rejected")
+	}
+	if !record.transition(OrderStateJunk, "价格超出涨停") || record.Reason != "价格超出涨停" {
+		t.Fatalf("record = %+v", record)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/gox/api"
 )
 
@@ -16,7 +17,7 @@ func GetOrderFilename(date ...string) string {
 	if len(date) > 0 {
 		tradeDate = exchange.FixTradeDate(date[0])
 	} else {
-		tradeDate = exchange.LastTradeDate()
+		tradeDate = clock.LastTradeDate()
 	}
 	filename := filepath.Join(traderQmtOrderPath, "orders."+tradeDate)
 	return filename
@@ -0,0 +1,544 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/data/level1/quotes"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/engine/datasource"
+	"gitee.com/quant1x/engine/market"
+	"gitee.com/quant1x/engine/models"
+	"gitee.com/quant1x/gox/api"
+	"gitee.com/quant1x/gox/logger"
+	"gitee.com/quant1x/num"
+)
+
+func init() {
+	err := RegisterBroker(BrokerPaper, func(traderParameter config.TraderParameter) (Broker, error) {
+		return NewPaperBroker(PaperDefaultCapital), nil
+	})
+	if err != nil {
+		logger.Fatalf("%+v", err)
+	}
+}
+
+// 模拟盘默认参数
+const (
+	PaperDefaultCapital   = float64(1000000.00) // 默认初始资金
+	paperDefaultAccountId = "paper"             // 未配置资金账号时的默认账号
+	paperOrderTimeFormat  = "2006-01-02 15:04:05"
+)
+
+var (
+	ErrPaperOrderNotFound = errors.New("委托不存在")
+	ErrPaperOrderFinished = errors.New("委托已结束, 不能撤单")
+)
+
+// PaperQuoteSource 模拟盘行情来源, 实时或者回放
+type PaperQuoteSource func(securityCode string) *quotes.Snapshot
+
+// 默认行情来源: 优先实时快照, 失败时取内存中的快照缓存
+func paperLiveQuote(securityCode string) *quotes.Snapshot {
+	list, err := datasource.GetAdapter().Snapshots([]string{securityCode})
+	if err == nil && len(list) > 0 {
+		return &list[0]
+	}
+	return models.GetTickFromMemory(securityCode)
+}
+
+// PaperAccount 模拟账户
+type PaperAccount struct {
+	AccountId      string  `name:"资金账户"`
+	Date           string  `name:"交易日期"`
+	InitialCapital float64 `name:"初始资金"`
+	Cash           float64 `name:"可用"`
+	FrozenCash     float64 `name:"冻结"`
+	NextOrderId    int     `name:"订单序号"`
+}
+
+// PaperBroker 模拟盘
+//
+//	接口和miniQMT代理保持一致, 委托用实时或者回放的快照撮合
+//	买入冻结资金, 卖出冻结股份, 当日买入次日可卖(T+1), 未成交的委托收盘后作废
+//	状态保存在 qmt/paper/账户id 目录下
+type PaperBroker struct {
+	mutex     sync.Mutex
+	path      string
+	account   PaperAccount
+	positions map[string]*PositionDetail
+	orders    []OrderDetail
+	quote     PaperQuoteSource
+	consumed  map[paperLevelKey]int // 同一个快照的买一卖一已经成交的股数
+}
+
+// 快照的一档盘口, 快照的时间或者价格变化后盘口的量重新计算
+type paperLevelKey struct {
+	securityCode string
+	orderType    int
+	serverTime   string
+	price        float64
+}
+
+// NewPaperBroker 创建模拟盘, 如果本地有状态则加载, capital仅在首次创建时有效
+func NewPaperBroker(capital float64) *PaperBroker {
+	accountId := traderParameter().AccountId
+	if len(accountId) == 0 {
+		accountId = paperDefaultAccountId
+	}
+	path := filepath.Join(cache.GetQmtCachePath(), "paper", accountId)
+	return newPaperBroker(path, accountId, capital)
+}
+
+func newPaperBroker(path, accountId string, capital float64) *PaperBroker {
+	b := &PaperBroker{
+		path:      path,
+		positions: map[string]*PositionDetail{},
+		quote:     paperLiveQuote,
+		consumed:  map[paperLevelKey]int{},
+	}
+	b.load(accountId, capital)
+	return b
+}
+
+// SetQuoteSource 设置行情来源
+func (b *PaperBroker) SetQuoteSource(source PaperQuoteSource) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if source != nil {
+		b.quote = source
+	}
+}
+
+func (b *PaperBroker) accountFilename() string {
+	return filepath.Join(b.path, "account.csv")
+}
+
+func (b *PaperBroker) positionsFilename() string {
+	return filepath.Join(b.path, "positions.csv")
+}
+
+func (b *PaperBroker) ordersFilename(date string) string {
+	return filepath.Join(b.path, "orders."+date)
+}
+
+// 加载本地状态
+func (b *PaperBroker) load(accountId string, capital float64) {
+	var accounts []PaperAccount
+	_ = api.CsvToSlices(b.accountFilename(), &accounts)
+	if len(accounts) > 0 {
+		b.account = accounts[0]
+	} else {
+		if capital <= 0 {
+			capital = PaperDefaultCapital
+		}
+		b.account = PaperAccount{
+			AccountId:      accountId,
+			Date:           clock.CurrentlyDay(),
+			InitialCapital: capital,
+			Cash:           capital,
+			NextOrderId:    1,
+		}
+	}
+	var positions []PositionDetail
+	_ = api.CsvToSlices(b.positionsFilename(), &positions)
+	for i := range positions {
+		position := positions[i]
+		b.positions[exchange.CorrectSecurityCode(position.StockCode)] = &position
+	}
+	_ = api.CsvToSlices(b.ordersFilename(b.account.Date), &b.orders)
+	b.rollover()
+}
+
+// 保存状态
+func (b *PaperBroker) save() {
+	if err := api.SlicesToCsv(b.accountFilename(), []PaperAccount{b.account}); err != nil {
+		logger.Errorf("paper: 保存账户异常: %+v", err)
+	}
+	codes := api.Keys(b.positions)
+	slices.Sort(codes)
+	positions := make([]PositionDetail, 0, len(codes))
+	for _, securityCode := range codes {
+		positions = append(positions, *b.positions[securityCode])
+	}
+	if err := api.SlicesToCsv(b.positionsFilename(), positions); err != nil {
+		logger.Errorf("paper: 保存持仓异常: %+v", err)
+	}
+	if err := api.SlicesToCsv(b.ordersFilename(b.account.Date), b.orders); err != nil {
+		logger.Errorf("paper: 保存委托异常: %+v", err)
+	}
+}
+
+// 日切: 作废前一交易日未成交的委托, 持仓全部转为可卖
+func (b *PaperBroker) rollover() {
+	today := clock.CurrentlyDay()
+	if b.account.Date >= today {
+		return
+	}
+	for i := range b.orders {
+		if paperOrderIsOpen(b.orders[i]) {
+			b.cancel(&b.orders[i], "收盘作废")
+		}
+	}
+	// 保存前一交易日的委托
+	_ = api.SlicesToCsv(b.ordersFilename(b.account.Date), b.orders)
+	b.orders = nil
+	for _, position := range b.positions {
+		position.CanUseVolume = position.Volume
+		position.YesterdayVolume = position.Volume
+		position.FrozenVolume = 0
+		position.OnRoadVolume = 0
+	}
+	b.account.Date = today
+	b.save()
+}
+
+func paperOrderIsOpen(order OrderDetail) bool {
+	return order.OrderStatus == ORDER_REPORTED || order.OrderStatus == ORDER_WAIT_REPORTING || order.OrderStatus == ORDER_PART_SUCC
+}
+
+// 更新持仓市值
+func (b *PaperBroker) markToMarket() float64 {
+	marketValue := 0.00
+	for securityCode, position := range b.positions {
+		if v := b.quote(securityCode); v != nil && v.Price > 0 {
+			position.MarketValue = num.Decimal(v.Price * float64(position.Volume))
+		}
+		marketValue += position.MarketValue
+	}
+	return marketValue
+}
+
+// QueryAccount 查询账户信息
+func (b *PaperBroker) QueryAccount() (*AccountDetail, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.rollover()
+	b.match()
+	marketValue := b.markToMarket()
+	detail := AccountDetail{
+		TotalAsset:  num.Decimal(b.account.Cash + b.account.FrozenCash + marketValue),
+		Cash:        num.Decimal(b.account.Cash),
+		MarketValue: num.Decimal(marketValue),
+		FrozenCash:  num.Decimal(b.account.FrozenCash),
+	}
+	return &detail, nil
+}
+
+// QueryHolding 查询持仓
+func (b *PaperBroker) QueryHolding() ([]PositionDetail, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.rollover()
+	b.match()
+	b.markToMarket()
+	codes := api.Keys(b.positions)
+	slices.Sort(codes)
+	list := make([]PositionDetail, 0, len(codes))
+	for _, securityCode := range codes {
+		list = append(list, *b.positions[securityCode])
+	}
+	return list, nil
+}
+
+// QueryOrders 查询当日委托
+func (b *PaperBroker) QueryOrders() ([]OrderDetail, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.rollover()
+	b.match()
+	return slices.Clone(b.orders), nil
+}
+
+// PlaceOrder 下委托订单
+func (b *PaperBroker) PlaceOrder(direction Direction, strategyName, orderRemark, securityCode string, priceType PriceType, price float64, volume int) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.rollover()
+	securityCode = exchange.CorrectSecurityCode(securityCode)
+	orderType := ORDER_UNKNOWN
+	if direction == BUY {
+		orderType = STOCK_BUY
+	} else if direction == SELL {
+		orderType = STOCK_SELL
+	}
+	order := OrderDetail{
+		AccountType:  SECURITY_ACCOUNT,
+		AccountId:    b.account.AccountId,
+		OrderTime:    clock.Now().Format(paperOrderTimeFormat),
+		StockCode:    qmtStockCode(securityCode),
+		OrderType:    orderType,
+		Price:        price,
+		PriceType:    priceType,
+		OrderVolume:  volume,
+		OrderId:      b.account.NextOrderId,
+		OrderSysid:   fmt.Sprintf("%d", b.account.NextOrderId),
+		OrderStatus:  ORDER_REPORTED,
+		StrategyName: strategyName,
+		OrderRemark:  orderRemark,
+	}
+	b.account.NextOrderId++
+	if reason := b.accept(&order); len(reason) > 0 {
+		order.OrderStatus = ORDER_JUNK
+		order.StatusMessage = reason
+		logger.Warnf("paper: 废单, code=%s, price=%.2f, volume=%d, %s", securityCode, price, volume, reason)
+	}
+	b.orders = append(b.orders, order)
+	b.match()
+	b.save()
+	return order.OrderId, nil
+}
+
+// 委托检查并冻结资金或股份, 返回废单原因
+func (b *PaperBroker) accept(order *OrderDetail) string {
+	securityCode := order.SecurityCode()
+	if order.OrderType != STOCK_BUY && order.OrderType != STOCK_SELL {
+		return "交易方向无效"
+	}
+	if order.OrderVolume <= 0 || (order.OrderType == STOCK_BUY && !paperBuyVolumeIsValid(securityCode, order.OrderVolume)) {
+		return "委托数量无效"
+	}
+	if order.PriceType != FIX_PRICE && order.PriceType != LATEST_PRICE {
+		return "不支持的报价类型"
+	}
+	v := b.quote(securityCode)
+	if v == nil || v.State != quotes.SECURITY_TRADE_STATE_NORMAL {
+		return "证券不可交易"
+	}
+	if order.PriceType == LATEST_PRICE {
+		order.Price = v.Price
+	}
+	if order.Price <= 0 {
+		return "委托价格无效"
+	}
+	// 涨跌停
+	limitUp, limitDown := market.PriceLimit(securityCode, v.LastClose)
+	if order.Price > limitUp || order.Price < limitDown {
+		return "超出涨跌停价格"
+	}
+	// 价格笼子, 以最新价为基准价格
+	basePrice := v.Price
+	if basePrice <= 0 {
+		basePrice = v.LastClose
+	}
+	if order.OrderType == STOCK_BUY {
+		priceCage := max(basePrice*(1+validDeclarationPriceRange), basePrice+minimumPriceFluctuationUnit)
+		if order.Price > num.Decimal(priceCage) {
+			return "超出价格笼子"
+		}
+		frozen, _, _, _, _ := calculate_transaction_fee(BUY, order.Price, order.OrderVolume, true)
+		if frozen > b.account.Cash {
+			return "可用资金不足"
+		}
+		b.account.Cash -= frozen
+		b.account.FrozenCash += frozen
+	} else {
+		priceCage := min(basePrice*(1-validDeclarationPriceRange), basePrice-minimumPriceFluctuationUnit)
+		if order.Price < num.Decimal(priceCage) {
+			return "超出价格笼子"
+		}
+		position, ok := b.positions[securityCode]
+		if !ok || position.CanUseVolume < order.OrderVolume {
+			return "可卖数量不足"
+		}
+		position.CanUseVolume -= order.OrderVolume
+		position.FrozenVolume += order.OrderVolume
+	}
+	return ""
+}
+
+// Match 撮合全部未成交的委托
+func (b *PaperBroker) Match() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.rollover()
+	if b.match() > 0 {
+		b.save()
+	}
+}
+
+// 买入数量是否符合板块的申报规则
+//
+//	科创板最少200股, 超过部分以1股递增; 北交所最少100股, 超过部分以1股递增; 其它板块是100股的整数倍
+func paperBuyVolumeIsValid(securityCode string, volume int) bool {
+	switch {
+	case strings.HasPrefix(securityCode, "sh688") || strings.HasPrefix(securityCode, "sh689"):
+		return volume >= 200
+	case strings.HasPrefix(securityCode, "bj"):
+		return volume >= 100
+	default:
+		return volume >= 100 && volume%100 == 0
+	}
+}
+
+// 撮合, 买入以卖一价成交, 卖出以买一价成交
+//
+//	每次成交不超过卖一或买一的挂单量, 同一个快照的盘口被之前的委托吃掉的量要扣除, 剩余的数量继续挂单, 部分成交
+func (b *PaperBroker) match() (count int) {
+	if b.consumed == nil {
+		b.consumed = map[paperLevelKey]int{}
+	}
+	for i := range b.orders {
+		order := &b.orders[i]
+		if !paperOrderIsOpen(*order) {
+			continue
+		}
+		securityCode := order.SecurityCode()
+		v := b.quote(securityCode)
+		if v == nil || v.State != quotes.SECURITY_TRADE_STATE_NORMAL {
+			continue
+		}
+		price, levelVolume := v.Bid1, v.BidVol1
+		if order.OrderType == STOCK_BUY {
+			price, levelVolume = v.Ask1, v.AskVol1
+			// 涨停无卖盘不能成交
+			if price <= 0 || order.Price < price {
+				continue
+			}
+		} else {
+			// 跌停无买盘不能成交
+			if price <= 0 || order.Price > price {
+				continue
+			}
+		}
+		key := paperLevelKey{securityCode: securityCode, orderType: order.OrderType, serverTime: v.ServerTime, price: price}
+		// 盘口的量单位是手
+		available := levelVolume*100 - b.consumed[key]
+		volume := min(order.OrderVolume-order.TradedVolume, available)
+		if volume <= 0 {
+			continue
+		}
+		b.consumed[key] += volume
+		if order.OrderType == STOCK_BUY {
+			b.fillBuy(order, price, volume)
+		} else {
+			b.fillSell(order, price, volume)
+		}
+		count++
+	}
+	return
+}
+
+// 买入冻结的资金, 按委托价格计算, 数量为0时不冻结
+func paperFrozenCash(price float64, volume int) float64 {
+	if volume <= 0 {
+		return 0
+	}
+	frozen, _, _, _, _ := calculate_transaction_fee(BUY, price, volume, true)
+	return frozen
+}
+
+// 成交volume股的金额, 买入是含费用的成本, 卖出是扣除费用的净额
+//
+//	traded是之前已经成交的数量, 部分成交按累计数量计算差额, 最低佣金整笔委托只收一次
+func paperFillAmount(direction Direction, price float64, traded, volume int) float64 {
+	amount := func(n int) float64 {
+		if n <= 0 {
+			return 0
+		}
+		total, _, _, _, value := calculate_transaction_fee(direction, price, n, true)
+		if direction == BUY {
+			return total
+		}
+		return value
+	}
+	return amount(traded+volume) - amount(traded)
+}
+
+func (b *PaperBroker) fillBuy(order *OrderDetail, price float64, volume int) {
+	remaining := order.OrderVolume - order.TradedVolume
+	frozen := paperFrozenCash(order.Price, remaining) - paperFrozenCash(order.Price, remaining-volume)
+	cost := paperFillAmount(BUY, price, order.TradedVolume, volume)
+	b.account.FrozenCash -= frozen
+	b.account.Cash += frozen - cost
+	securityCode := order.SecurityCode()
+	position, ok := b.positions[securityCode]
+	if !ok {
+		position = &PositionDetail{
+			AccountType: SECURITY_ACCOUNT,
+			AccountId:   b.account.AccountId,
+			StockCode:   order.StockCode,
+			OpenPrice:   price,
+		}
+		b.positions[securityCode] = position
+	}
+	// 成本价含费用
+	position.AvgPrice = num.Decimal((position.AvgPrice*float64(position.Volume) + cost) / float64(position.Volume+volume))
+	position.Volume += volume
+	position.OnRoadVolume += volume
+	position.MarketValue = num.Decimal(price * float64(position.Volume))
+	b.filled(order, price, volume)
+}
+
+func (b *PaperBroker) fillSell(order *OrderDetail, price float64, volume int) {
+	amount := paperFillAmount(SELL, price, order.TradedVolume, volume)
+	b.account.Cash += amount
+	securityCode := order.SecurityCode()
+	if position, ok := b.positions[securityCode]; ok {
+		position.FrozenVolume -= volume
+		position.Volume -= volume
+		position.MarketValue = num.Decimal(price * float64(position.Volume))
+		if position.Volume <= 0 {
+			delete(b.positions, securityCode)
+		}
+	}
+	b.filled(order, price, volume)
+}
+
+// 记录成交, 成交均价按数量加权, 全部成交之前是部成状态
+func (b *PaperBroker) filled(order *OrderDetail, price float64, volume int) {
+	order.TradedPrice = num.Decimal((order.TradedPrice*float64(order.TradedVolume) + price*float64(volume)) / float64(order.TradedVolume+volume))
+	order.TradedVolume += volume
+	if order.TradedVolume >= order.OrderVolume {
+		order.OrderStatus = ORDER_SUCCEEDED
+	} else {
+		order.OrderStatus = ORDER_PART_SUCC
+	}
+	logger.Infof("paper: 成交, order_id=%d, code=%s, price=%.2f, volume=%d, traded=%d/%d", order.OrderId, order.StockCode, price, volume, order.TradedVolume, order.OrderVolume)
+}
+
+// CancelOrder 撤单
+func (b *PaperBroker) CancelOrder(orderId int) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.rollover()
+	b.match()
+	idx := slices.IndexFunc(b.orders, func(v OrderDetail) bool {
+		return v.OrderId == orderId
+	})
+	if idx < 0 {
+		return ErrPaperOrderNotFound
+	}
+	order := &b.orders[idx]
+	if !paperOrderIsOpen(*order) {
+		return ErrPaperOrderFinished
+	}
+	b.cancel(order, "撤单")
+	b.save()
+	return nil
+}
+
+// 撤销委托, 解冻资金或股份
+func (b *PaperBroker) cancel(order *OrderDetail, message string) {
+	volume := order.OrderVolume - order.TradedVolume
+	if order.OrderType == STOCK_BUY {
+		frozen := paperFrozenCash(order.Price, volume)
+		b.account.FrozenCash -= frozen
+		b.account.Cash += frozen
+	} else if position, ok := b.positions[order.SecurityCode()]; ok {
+		position.FrozenVolume -= volume
+		position.CanUseVolume += volume
+	}
+	order.OrderStatus = ORDER_CANCELED
+	if order.TradedVolume > 0 {
+		order.OrderStatus = ORDER_PART_CANCEL
+	}
+	order.StatusMessage = message
+}
@@ -0,0 +1,77 @@
+package trader
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gitee.com/quant1x/gox/logger"
+)
+
+const (
+	paperMatchInterval = 3 * time.Second // 后台撮合间隔
+)
+
+// ServePaperBroker 启动模拟盘服务
+//
+//	路由和miniQMT代理保持一致, 把TraderParameter.ProxyUrl指向 http://addr/qmt 即可
+func ServePaperBroker(addr string, broker *PaperBroker) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/qmt/query/asset", func(w http.ResponseWriter, r *http.Request) {
+		detail, err := broker.QueryAccount()
+		paperResponse(w, detail, err)
+	})
+	mux.HandleFunc("/qmt/query/holding", func(w http.ResponseWriter, r *http.Request) {
+		list, err := broker.QueryHolding()
+		paperResponse(w, list, err)
+	})
+	mux.HandleFunc("/qmt/query/order", func(w http.ResponseWriter, r *http.Request) {
+		list, err := broker.QueryOrders()
+		paperResponse(w, list, err)
+	})
+	mux.HandleFunc("/qmt/trade/order", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		price, _ := strconv.ParseFloat(r.Form.Get("price"), 64)
+		priceType, _ := strconv.Atoi(r.Form.Get("price_type"))
+		volume, _ := strconv.Atoi(r.Form.Get("volume"))
+		orderId, err := broker.PlaceOrder(Direction(r.Form.Get("direction")), r.Form.Get("strategy"), r.Form.Get("remark"),
+			r.Form.Get("code"), priceType, price, volume)
+		result := OrderResult{OrderId: orderId}
+		if err != nil {
+			result.Status = http.StatusBadRequest
+			result.Message = err.Error()
+		}
+		paperResponse(w, result, nil)
+	})
+	mux.HandleFunc("/qmt/trade/cancel", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		orderId, _ := strconv.Atoi(r.Form.Get("order_id"))
+		result := OrderResult{OrderId: orderId}
+		if err := broker.CancelOrder(orderId); err != nil {
+			result.Status = http.StatusBadRequest
+			result.Message = err.Error()
+		}
+		paperResponse(w, result, nil)
+	})
+	// 后台定时撮合挂单
+	go func() {
+		ticker := time.NewTicker(paperMatchInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			broker.Match()
+		}
+	}()
+	logger.Infof("paper: 模拟盘服务启动, 监听地址: %s", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+func paperResponse(w http.ResponseWriter, v any, err error) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(ProxyResult{Status: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,144 @@
+package trader
+
+import (
+	"fmt"
+	"testing"
+
+	"gitee.com/quant1x/data/level1/quotes"
+)
+
+func newTestPaperBroker(t *testing.T, quote *quotes.Snapshot) *PaperBroker {
+	b := &PaperBroker{
+		path:      t.TempDir(),
+		positions: map[string]*PositionDetail{},
+		consumed:  map[paperLevelKey]int{},
+		quote: func(securityCode string) *quotes.Snapshot {
+			return quote
+		},
+	}
+	b.load(paperDefaultAccountId, 100000.00)
+	return b
+}
+
+func TestPaperBroker(t *testing.T) {
+	quote := &quotes.Snapshot{
+		State:     quotes.SECURITY_TRADE_STATE_NORMAL,
+		LastClose: 10.00,
+		Price:     10.00,
+		Bid1:      9.99,
+		BidVol1:   100,
+		Ask1:      10.01,
+		AskVol1:   100,
+	}
+	b := newTestPaperBroker(t, quote)
+	code := "sh600000"
+	// 超出价格笼子
+	orderId, _ := b.PlaceOrder(BUY, "test", "cage", code, FIX_PRICE, 10.50, 1000)
+	orders, _ := b.QueryOrders()
+	fmt.Println(orders)
+	if orders[0].OrderId != orderId || orders[0].OrderStatus != ORDER_JUNK {
+		t.Fatalf("order status = %d, want %d", orders[0].OrderStatus, ORDER_JUNK)
+	}
+	// 挂单, 冻结资金
+	orderId, _ = b.PlaceOrder(BUY, "test", "buy", code, FIX_PRICE, 10.00, 1000)
+	acc, _ := b.QueryAccount()
+	fmt.Println(acc)
+	if acc.FrozenCash <= 0 {
+		t.Fatalf("frozen cash = %f, want > 0", acc.FrozenCash)
+	}
+	// 卖一价下来, 成交
+	quote.Ask1 = 10.00
+	b.Match()
+	holding, _ := b.QueryHolding()
+	fmt.Println(holding)
+	if len(holding) != 1 || holding[0].Volume != 1000 || holding[0].CanUseVolume != 0 {
+		t.Fatalf("holding = %+v", holding)
+	}
+	// T+1, 当日买入不可卖
+	_, _ = b.PlaceOrder(SELL, "test", "sell", code, FIX_PRICE, 9.99, 1000)
+	orders, _ = b.QueryOrders()
+	if last := orders[len(orders)-1]; last.OrderStatus != ORDER_JUNK {
+		t.Fatalf("sell status = %d, want %d", last.OrderStatus, ORDER_JUNK)
+	}
+	// 日切
+	b.account.Date = "2000-01-01"
+	b.rollover()
+	orderId, _ = b.PlaceOrder(SELL, "test", "sell", code, FIX_PRICE, 9.99, 1000)
+	holding, _ = b.QueryHolding()
+	acc, _ = b.QueryAccount()
+	fmt.Println(orderId, holding, acc)
+	if len(holding) != 0 || acc.FrozenCash != 0 {
+		t.Fatalf("holding = %+v, account = %+v", holding, acc)
+	}
+}
+
+func TestPaperBrokerPartialFill(t *testing.T) {
+	quote := &quotes.Snapshot{
+		State:      quotes.SECURITY_TRADE_STATE_NORMAL,
+		ServerTime: "09:31:00.000",
+		LastClose:  10.00,
+		Price:      10.00,
+		Bid1:       9.99,
+		BidVol1:    10,
+		Ask1:       10.00,
+		AskVol1:    3,
+	}
+	b := newTestPaperBroker(t, quote)
+	orderId, _ := b.PlaceOrder(BUY, "test", "buy", "sh600000", FIX_PRICE, 10.00, 500)
+	orders, _ := b.QueryOrders()
+	// 卖一只有3手, 部分成交, 同一个快照重复撮合不能再成交
+	if order := orders[0]; order.OrderId != orderId || order.OrderStatus != ORDER_PART_SUCC || order.TradedVolume != 300 {
+		t.Fatalf("order = %+v", order)
+	}
+	b.Match()
+	if orders, _ = b.QueryOrders(); orders[0].TradedVolume != 300 {
+		t.Fatalf("traded volume = %d, want 300", orders[0].TradedVolume)
+	}
+	// 新的快照, 剩余的数量成交
+	quote.ServerTime = "09:31:03.000"
+	b.Match()
+	orders, _ = b.QueryOrders()
+	if order := orders[0]; order.OrderStatus != ORDER_SUCCEEDED || order.TradedVolume != 500 {
+		t.Fatalf("order = %+v", order)
+	}
+	acc, _ := b.QueryAccount()
+	if acc.FrozenCash != 0 {
+		t.Fatalf("frozen cash = %f, want 0", acc.FrozenCash)
+	}
+	// 部分成交后撤单
+	quote.AskVol1 = 1
+	quote.ServerTime = "09:31:06.000"
+	orderId, _ = b.PlaceOrder(BUY, "test", "buy", "sh600000", FIX_PRICE, 10.00, 300)
+	if err := b.CancelOrder(orderId); err != nil {
+		t.Fatal(err)
+	}
+	orders, _ = b.QueryOrders()
+	if order := orders[1]; order.OrderStatus != ORDER_PART_CANCEL || order.TradedVolume != 100 {
+		t.Fatalf("order = %+v", order)
+	}
+	if acc, _ = b.QueryAccount(); acc.FrozenCash != 0 {
+		t.Fatalf("frozen cash = %f, want 0", acc.FrozenCash)
+	}
+}
+
+func Test_paperBuyVolumeIsValid(t *testing.T) {
+	tests := []struct {
+		securityCode string
+		volume       int
+		want         bool
+	}{
+		{"sh600000", 100, true},
+		{"sh600000", 150, false},
+		{"sz300750", 200, true},
+		{"sh688981", 200, true},
+		{"sh688981", 201, true},
+		{"sh688981", 100, false},
+		{"bj830799", 101, true},
+		{"bj830799", 99, false},
+	}
+	for _, tt := range tests {
+		if got := paperBuyVolumeIsValid(tt.securityCode, tt.volume); got != tt.want {
+			t.Errorf("paperBuyVolumeIsValid(%s, %d) = %t, want %t", tt.securityCode, tt.volume, got, tt.want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"gitee.com/quant1x/data/exchange"
 	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
 	"gitee.com/quant1x/engine/models"
 	"gitee.com/quant1x/gox/api"
 	"gitee.com/quant1x/gox/concurrent"
@@ -57,7 +58,7 @@ func (p *Position) Sync(other PositionDetail) bool {
 	}
 	if len(p.CreateTime) == 0 && p.YesterdayVolume > 0 {
 		// 如果创建时间等于空且昨夜拥股大于0, 则持股日期往前推一天
-		today := exchange.Today()
+		today := clock.Today()
 		dates := exchange.LastNDate(today, 1)
 		frontDate := dates[0] + " 00:00:00"
 		p.CreateTime = frontDate
@@ -110,8 +111,12 @@ func (p *Position) MergeFromOrder(order OrderDetail) bool {
 			p.OpenPrice = (openValue - orderValue) / float64(p.Volume)
 		}
 	}
-	// 5. 更新市值
-	p.MarketValue = snapshot.Price * float64(p.Volume)
+	// 5. 更新市值, 没有快照时用成交价
+	lastPrice := order.TradedPrice
+	if snapshot != nil {
+		lastPrice = snapshot.Price
+	}
+	p.MarketValue = lastPrice * float64(p.Volume)
 	// 6. 修改 更新时间
 	p.UpdateTime = order.OrderTime
 	return true
@@ -131,7 +136,7 @@ func getPositionsPath() string {
 
 // 持仓缓存文件名
 func positionsFilename() string {
-	filename := fmt.Sprintf("%s/%s-%s", getPositionsPath(), traderParameter.AccountId, qmtPositionsFilename)
+	filename := fmt.Sprintf("%s/%s-%s", getPositionsPath(), traderParameter().AccountId, qmtPositionsFilename)
 	return filename
 }
 
@@ -0,0 +1,63 @@
+package trader
+
+import (
+	"os"
+	"path/filepath"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/models"
+)
+
+const (
+	replayAccountId = "replay" // 回放的模拟账号
+)
+
+// ReplaySandboxPath 回放沙箱的目录, qmt/replay/日期
+func ReplaySandboxPath(date string) string {
+	date = exchange.FixTradeDate(date)
+	return filepath.Join(cache.GetQmtCachePath(), replayAccountId, date)
+}
+
+// UseReplaySandbox 切换到回放的沙箱, 返回沙箱中的模拟盘和恢复现场的函数
+//
+//	委托记录、风控基准和模拟盘的状态都保存在 qmt/replay/日期 目录下, 每次回放从空账户开始,
+//	委托用内存中的快照撮合, 回放不会影响实盘和模拟盘
+func UseReplaySandbox(date string, capital float64) (*PaperBroker, func()) {
+	path := ReplaySandboxPath(date)
+	_ = os.RemoveAll(path)
+	broker := newPaperBroker(path, replayAccountId, capital)
+	broker.SetQuoteSource(models.GetTickFromMemory)
+	restore := UseSandbox(path, broker)
+	return broker, restore
+}
+
+// UseSandbox 切换到指定目录的沙箱, 返回恢复现场的函数
+//
+//	委托记录和风控基准保存在path目录下, 交易通道切换成broker, 回放和单元测试不会影响实盘的状态
+func UseSandbox(path string, broker Broker) func() {
+	brokerMutex.Lock()
+	previousBroker := currentBroker
+	brokerMutex.Unlock()
+	previousPath := traderQmtOrderPath
+
+	traderQmtOrderPath = path
+	resetTradingState()
+	SetBroker(broker)
+	return func() {
+		traderQmtOrderPath = previousPath
+		resetTradingState()
+		SetBroker(previousBroker)
+	}
+}
+
+// 清除内存中的当日订单簿和风控基准, 下次使用时从当前目录重新加载
+func resetTradingState() {
+	todayOrders.mutex.Lock()
+	todayOrders.date = ""
+	todayOrders.records = nil
+	todayOrders.mutex.Unlock()
+	riskDailyMutex.Lock()
+	riskDaily = RiskDaily{}
+	riskDailyMutex.Unlock()
+}
@@ -0,0 +1,383 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gitee.com/quant1x/data/exchange"
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/engine/config"
+	"gitee.com/quant1x/gox/api"
+	"gitee.com/quant1x/gox/logger"
+)
+
+// 内置风控规则名称
+const (
+	RiskRuleDailyLoss        = "max_daily_loss"        // 当日最大亏损
+	RiskRuleSingleExposure   = "max_single_exposure"   // 单一标的最大占比
+	RiskRuleStrategyExposure = "max_strategy_exposure" // 单一策略最大占比
+	RiskRuleOrderRate        = "max_orders_per_minute" // 每分钟最大委托数
+	RiskRuleDuplicate        = "duplicate_order"       // 重复委托
+	RiskRuleAccount          = "account"               // 账户信息不可用
+)
+
+var (
+	ErrRiskRuleAlreadyExists = errors.New("风控规则已存在")
+)
+
+// RiskRejection 风控拒单原因
+type RiskRejection struct {
+	Rule    string // 规则名称
+	Message string // 拒单说明
+}
+
+func (r *RiskRejection) Error() string {
+	return fmt.Sprintf("risk[%s]: %s", r.Rule, r.Message)
+}
+
+// RiskOrder 待检查的委托
+type RiskOrder struct {
+	Direction    Direction
+	StrategyName string
+	SecurityCode string
+	Price        float64
+	Volume       int
+}
+
+// Amount 委托金额
+func (o RiskOrder) Amount() float64 {
+	return o.Price * float64(o.Volume)
+}
+
+// RiskContext 风控检查的上下文, 账户和持仓按需查询, 一次检查只查询一次
+type RiskContext struct {
+	Parameter config.RiskParameter
+	Records   []OrderRecord // 当日订单生命周期记录
+	Now       time.Time
+	account   *AccountDetail
+	holdings  []PositionDetail
+	loaded    bool
+	err       error
+}
+
+func (c *RiskContext) load() error {
+	if c.loaded {
+		return c.err
+	}
+	c.loaded = true
+	c.account, c.err = QueryAccount()
+	if c.err != nil {
+		return c.err
+	}
+	c.holdings, c.err = QueryHolding()
+	return c.err
+}
+
+// Account 账户信息
+func (c *RiskContext) Account() (*AccountDetail, error) {
+	err := c.load()
+	return c.account, err
+}
+
+// Holdings 持仓信息
+func (c *RiskContext) Holdings() ([]PositionDetail, error) {
+	err := c.load()
+	return c.holdings, err
+}
+
+// RiskRule 风控规则, 返回nil表示通过
+type RiskRule func(ctx *RiskContext, order RiskOrder) *RiskRejection
+
+type riskRuleEntry struct {
+	name string
+	rule RiskRule
+}
+
+var (
+	riskMutex sync.RWMutex
+	riskRules []riskRuleEntry
+)
+
+func init() {
+	_ = RegisterRiskRule(RiskRuleDuplicate, riskCheckDuplicate)
+	_ = RegisterRiskRule(RiskRuleOrderRate, riskCheckOrderRate)
+	_ = RegisterRiskRule(RiskRuleDailyLoss, riskCheckDailyLoss)
+	_ = RegisterRiskRule(RiskRuleSingleExposure, riskCheckSingleExposure)
+	_ = RegisterRiskRule(RiskRuleStrategyExposure, riskCheckStrategyExposure)
+}
+
+// RegisterRiskRule 注册风控规则, 按注册顺序检查
+func RegisterRiskRule(name string, rule RiskRule) error {
+	riskMutex.Lock()
+	defer riskMutex.Unlock()
+	for _, v := range riskRules {
+		if v.name == name {
+			return ErrRiskRuleAlreadyExists
+		}
+	}
+	riskRules = append(riskRules, riskRuleEntry{name: name, rule: rule})
+	return nil
+}
+
+// CheckRisk 交易前风控检查
+func CheckRisk(order RiskOrder) *RiskRejection {
+	order.SecurityCode = exchange.CorrectSecurityCode(order.SecurityCode)
+	if rejection := riskCheckHalt(order); rejection != nil {
+		logger.Warnf("trader-risk: %s %s %s, %s", order.StrategyName, order.Direction, order.SecurityCode, rejection.Error())
+		return rejection
+	}
+	parameter := traderParameter().Risk
+	if !parameter.Enable {
+		return nil
+	}
+	ctx := &RiskContext{
+		Parameter: parameter,
+		Records:   GetTodayOrderRecords(),
+		Now:       clock.Now(),
+	}
+	riskMutex.RLock()
+	rules := make([]riskRuleEntry, len(riskRules))
+	copy(rules, riskRules)
+	riskMutex.RUnlock()
+	for _, v := range rules {
+		if rejection := v.rule(ctx, order); rejection != nil {
+			if len(rejection.Rule) == 0 {
+				rejection.Rule = v.name
+			}
+			logger.Warnf("trader-risk: %s %s %s price=%.2f volume=%d, %s", order.StrategyName, order.Direction, order.SecurityCode, order.Price, order.Volume, rejection.Error())
+			return rejection
+		}
+	}
+	return nil
+}
+
+// 委托是否占用额度, 拒单和废单不占用
+func riskRecordIsEffective(record OrderRecord) bool {
+	return record.State != OrderStateRejected && record.State != OrderStateJunk
+}
+
+// 重复委托: 同一策略同一标的同方向, 未结束或者间隔时间内的委托
+func riskCheckDuplicate(ctx *RiskContext, order RiskOrder) *RiskRejection {
+	window := time.Duration(ctx.Parameter.DuplicateSeconds) * time.Second
+	if window <= 0 {
+		return nil
+	}
+	for _, v := range ctx.Records {
+		if !riskRecordIsEffective(v) || v.SecurityCode != order.SecurityCode || v.Direction != order.Direction.String() || v.StrategyName != order.StrategyName {
+			continue
+		}
+		if OrderStateIsOpen(v.State) {
+			return &RiskRejection{Message: fmt.Sprintf("存在未完成的委托[%s]", v.LocalId)}
+		}
+		createTime, err := time.ParseInLocation(cache.TimeStampMilli, v.CreateTime, time.Local)
+		if err == nil && ctx.Now.Sub(createTime) < window {
+			return &RiskRejection{Message: fmt.Sprintf("%d秒内重复委托[%s]", ctx.Parameter.DuplicateSeconds, v.LocalId)}
+		}
+	}
+	return nil
+}
+
+// 每分钟最大委托数
+func riskCheckOrderRate(ctx *RiskContext, order RiskOrder) *RiskRejection {
+	limit := ctx.Parameter.MaxOrdersPerMinute
+	if limit <= 0 {
+		return nil
+	}
+	count := 0
+	for _, v := range ctx.Records {
+		if v.State == OrderStateRejected {
+			continue
+		}
+		createTime, err := time.ParseInLocation(cache.TimeStampMilli, v.CreateTime, time.Local)
+		if err == nil && ctx.Now.Sub(createTime) < time.Minute {
+			count++
+		}
+	}
+	if count >= limit {
+		return &RiskRejection{Message: fmt.Sprintf("最近1分钟已委托%d笔, 上限%d笔", count, limit)}
+	}
+	return nil
+}
+
+// 当日最大亏损, 只限制买入
+func riskCheckDailyLoss(ctx *RiskContext, order RiskOrder) *RiskRejection {
+	limit := ctx.Parameter.MaxDailyLoss
+	if limit <= 0 || order.Direction != BUY {
+		return nil
+	}
+	account, err := ctx.Account()
+	if err != nil || account == nil || account.TotalAsset <= 0 {
+		return &RiskRejection{Rule: RiskRuleAccount, Message: fmt.Sprintf("无法获取账户信息: %v", err)}
+	}
+	openingAsset := riskOpeningAsset(account.TotalAsset)
+	lossRatio := 100 * (openingAsset - account.TotalAsset) / openingAsset
+	if lossRatio >= limit {
+		return &RiskRejection{Message: fmt.Sprintf("当日亏损%.2f%%, 超过上限%.2f%%", lossRatio, limit)}
+	}
+	return nil
+}
+
+// 单一标的最大占比, 只限制买入
+func riskCheckSingleExposure(ctx *RiskContext, order RiskOrder) *RiskRejection {
+	limit := ctx.Parameter.MaxSingleExposure
+	if limit <= 0 || order.Direction != BUY {
+		return nil
+	}
+	account, err := ctx.Account()
+	if err != nil || account == nil || account.TotalAsset <= 0 {
+		return &RiskRejection{Rule: RiskRuleAccount, Message: fmt.Sprintf("无法获取账户信息: %v", err)}
+	}
+	holdings, _ := ctx.Holdings()
+	exposure := order.Amount()
+	for _, v := range holdings {
+		if exchange.CorrectSecurityCode(v.StockCode) == order.SecurityCode {
+			exposure += v.MarketValue
+		}
+	}
+	// 未成交的买入委托也计入
+	for _, v := range ctx.Records {
+		if v.SecurityCode == order.SecurityCode && v.Direction == BUY.String() && OrderStateIsOpen(v.State) {
+			exposure += v.Price * float64(v.Volume-v.TradedVolume)
+		}
+	}
+	ratio := 100 * exposure / account.TotalAsset
+	if ratio > limit {
+		return &RiskRejection{Message: fmt.Sprintf("%s占总资产%.2f%%, 超过上限%.2f%%", order.SecurityCode, ratio, limit)}
+	}
+	return nil
+}
+
+// 单一策略最大占比, 按策略当日有效买入委托金额计算, 只限制买入
+func riskCheckStrategyExposure(ctx *RiskContext, order RiskOrder) *RiskRejection {
+	limit := ctx.Parameter.MaxStrategyExposure
+	if limit <= 0 || order.Direction != BUY {
+		return nil
+	}
+	account, err := ctx.Account()
+	if err != nil || account == nil || account.TotalAsset <= 0 {
+		return &RiskRejection{Rule: RiskRuleAccount, Message: fmt.Sprintf("无法获取账户信息: %v", err)}
+	}
+	exposure := order.Amount()
+	for _, v := range ctx.Records {
+		if v.StrategyName != order.StrategyName || v.Direction != BUY.String() || !riskRecordIsEffective(v) {
+			continue
+		}
+		if v.State == OrderStateCancelled {
+			// 撤单只计算已成交部分
+			exposure += v.TradedPrice * float64(v.TradedVolume)
+		} else {
+			exposure += v.Price * float64(v.Volume)
+		}
+	}
+	ratio := 100 * exposure / account.TotalAsset
+	if ratio > limit {
+		return &RiskRejection{Message: fmt.Sprintf("策略%s当日买入占总资产%.2f%%, 超过上限%.2f%%", order.StrategyName, ratio, limit)}
+	}
+	return nil
+}
+
+// RiskDaily 每日风控基准
+type RiskDaily struct {
+	Date         string  `name:"交易日期" dataframe:"date"`
+	OpeningAsset float64 `name:"开盘总资产" dataframe:"opening_asset"`
+	ClosingAsset float64 `name:"收盘总资产" dataframe:"closing_asset"`
+}
+
+const (
+	riskOpeningSnapshotDeadline = "09:15:00" // 集合竞价开始之前记录开盘总资产
+)
+
+var (
+	riskDailyMutex sync.Mutex
+	riskDaily      RiskDaily
+)
+
+func riskDailyFilename(date string) string {
+	return filepath.Join(traderQmtOrderPath, "risk."+date)
+}
+
+func loadRiskDaily(date string) RiskDaily {
+	var list []RiskDaily
+	_ = api.CsvToSlices(riskDailyFilename(date), &list)
+	if len(list) > 0 {
+		return list[0]
+	}
+	return RiskDaily{Date: date}
+}
+
+func saveRiskDaily(daily RiskDaily) {
+	if err := api.SlicesToCsv(riskDailyFilename(daily.Date), []RiskDaily{daily}); err != nil {
+		logger.Errorf("trader-risk: 保存风控基准异常: %+v", err)
+	}
+}
+
+// RiskBaseline 查询指定日期记录的风控资产基准
+func RiskBaseline(date string) RiskDaily {
+	riskDailyMutex.Lock()
+	defer riskDailyMutex.Unlock()
+	return loadRiskDaily(exchange.FixTradeDate(date))
+}
+
+// SnapshotRiskBaseline 记录风控的资产基准
+//
+//	集合竞价之前记录当日的开盘总资产, 收盘之后记录当日的收盘总资产, 其它时间不记录.
+//	当日没有开盘快照时, 用上一个交易日的收盘总资产作为基准
+func SnapshotRiskBaseline() error {
+	if !clock.DateIsTradingDay() {
+		return nil
+	}
+	timestamp := clock.Timestamp()
+	opening := timestamp < riskOpeningSnapshotDeadline
+	if !opening && timestamp < exchange.CN_TradingStopTime {
+		return nil
+	}
+	account, err := QueryAccount()
+	if err != nil {
+		return err
+	}
+	if account == nil || account.TotalAsset <= 0 {
+		return fmt.Errorf("trader-risk: 总资产无效")
+	}
+	riskDailyMutex.Lock()
+	defer riskDailyMutex.Unlock()
+	date := clock.CurrentlyDay()
+	daily := loadRiskDaily(date)
+	if opening {
+		daily.OpeningAsset = account.TotalAsset
+	} else {
+		daily.ClosingAsset = account.TotalAsset
+	}
+	saveRiskDaily(daily)
+	riskDaily = daily
+	return nil
+}
+
+// 当日开盘总资产
+//
+//	优先取集合竞价之前的快照, 其次取上一个交易日的收盘总资产, 都没有时才用当前的总资产, 并记录下来,
+//	重启后从文件恢复, 不会因为重启或者盘中第一次委托而改变当日亏损的基准
+func riskOpeningAsset(totalAsset float64) float64 {
+	riskDailyMutex.Lock()
+	defer riskDailyMutex.Unlock()
+	date := clock.CurrentlyDay()
+	if riskDaily.Date == date && riskDaily.OpeningAsset > 0 {
+		return riskDaily.OpeningAsset
+	}
+	daily := loadRiskDaily(date)
+	if daily.OpeningAsset <= 0 {
+		if dates := exchange.LastNDate(date, 1); len(dates) > 0 {
+			daily.OpeningAsset = loadRiskDaily(dates[0]).ClosingAsset
+		}
+		if daily.OpeningAsset <= 0 {
+			logger.Warnf("trader-risk: 没有开盘前的总资产, 用当前的总资产%.2f作为当日亏损的基准", totalAsset)
+			daily.OpeningAsset = totalAsset
+		}
+		saveRiskDaily(daily)
+	}
+	riskDaily = daily
+	return riskDaily.OpeningAsset
+}
@@ -0,0 +1,72 @@
+package trader
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gitee.com/quant1x/engine/cache"
+	"gitee.com/quant1x/engine/clock"
+	"gitee.com/quant1x/engine/config"
+)
+
+func TestRiskRules(t *testing.T) {
+	now := time.Now()
+	ctx := &RiskContext{
+		Parameter: config.RiskParameter{
+			Enable:             true,
+			MaxOrdersPerMinute: 2,
+			DuplicateSeconds:   60,
+		},
+		Records: []OrderRecord{
+			{LocalId: "1", StrategyName: "S1", SecurityCode: "sh600000", Direction: BUY.String(), State: OrderStateFilled, CreateTime: now.Add(-10 * time.Second).Format(cache.TimeStampMilli)},
+			{LocalId: "2", StrategyName: "S1", SecurityCode: "sz000001", Direction: BUY.String(), State: OrderStateSubmitted, CreateTime: now.Add(-2 * time.Minute).Format(cache.TimeStampMilli)},
+		},
+		Now: now,
+	}
+	order := RiskOrder{Direction: BUY, StrategyName: "S1", SecurityCode: "sh600000", Price: 10, Volume: 100}
+	rejection := riskCheckDuplicate(ctx, order)
+	fmt.Println(rejection)
+	if rejection == nil {
+		t.Error("duplicate order in window should be rejected")
+	}
+	order.SecurityCode = "sz000001"
+	if rejection = riskCheckDuplicate(ctx, order); rejection == nil {
+		t.Error("duplicate order while open should be rejected")
+	}
+	order.SecurityCode = "sh600519"
+	if rejection = riskCheckDuplicate(ctx, order); rejection != nil {
+		t.Errorf("unexpected rejection: %v", rejection)
+	}
+	if rejection = riskCheckOrderRate(ctx, order); rejection != nil {
+		t.Errorf("unexpected rejection: %v", rejection)
+	}
+	ctx.Parameter.MaxOrdersPerMinute = 1
+	if rejection = riskCheckOrderRate(ctx, order); rejection == nil {
+		t.Error("order rate limit should be rejected")
+	}
+}
+
+func Test_riskOpeningAsset(t *testing.T) {
+	previousPath := traderQmtOrderPath
+	traderQmtOrderPath = t.TempDir()
+	previousClock := clock.Set(clock.Fixed(time.Date(2024, 1, 3, 10, 30, 0, 0, time.Local)))
+	defer func() {
+		traderQmtOrderPath = previousPath
+		clock.Set(previousClock)
+		riskDaily = RiskDaily{}
+	}()
+	riskDaily = RiskDaily{}
+	// 上一个交易日收盘记录的总资产
+	saveRiskDaily(RiskDaily{Date: "2024-01-02", ClosingAsset: 100000})
+	openingAsset := riskOpeningAsset(95000)
+	fmt.Println(openingAsset)
+	if openingAsset != 100000 {
+		t.Errorf("opening asset = %.2f, want the previous closing asset 100000", openingAsset)
+	}
+	// 重启后从文件恢复, 不用盘中的总资产
+	riskDaily = RiskDaily{}
+	if v := riskOpeningAsset(90000); v != 100000 {
+		t.Errorf("opening asset after restart = %.2f, want 100000", v)
+	}
+}
@@ -1,43 +1,42 @@
 package trader
 
 import (
-	"encoding/json"
-	"fmt"
-	urlpkg "net/url"
 	"path/filepath"
-	"strings"
+	"sync/atomic"
 
 	"gitee.com/quant1x/data/exchange"
 	"gitee.com/quant1x/engine/cache"
 	"gitee.com/quant1x/engine/config"
 	"gitee.com/quant1x/engine/models"
-	"gitee.com/quant1x/gox/http"
-	"gitee.com/quant1x/gox/logger"
 )
 
 var (
-	traderParameter = config.TraderConfig()
-	// miniQMT代理服务器地址
-	//urlPrefixMiniQmtProxy = "http://10.211.55.3:18168/qmt"
-	urlPrefixMiniQmtProxy = traderParameter.ProxyUrl
-	// 查询前缀
-	urlPrefixForQuery = urlPrefixMiniQmtProxy + "/query"
-	// 交易前缀
-	urlPrefixForTrade = urlPrefixMiniQmtProxy + "/trade"
-	// 查询账户信息
-	urlAccount = urlPrefixForQuery + "/asset"
-	// 查询持仓信息
-	urlHolding = urlPrefixForQuery + "/holding"
-	// 查询委托
-	urlOrders = urlPrefixForQuery + "/order"
-	// 委托
-	urlPlaceOrder = urlPrefixForTrade + "/order"
-	// 撤单
-	urlCancelOrder = urlPrefixForTrade + "/cancel"
-	// qmt账户数据路径: qmt/账户id
-	traderQmtOrderPath = filepath.Join(cache.GetQmtCachePath(), traderParameter.AccountId)
+	// 交易参数, 重新加载配置文件时刷新
+	__traderParameter atomic.Pointer[config.TraderParameter]
+	// qmt账户数据路径: qmt/账户id, 启动时确定
+	traderQmtOrderPath = filepath.Join(cache.GetQmtCachePath(), config.TraderConfig().AccountId)
 )
 
+func init() {
+	config.OnReload(func() {
+		refreshTraderParameter()
+	})
+}
+
+func refreshTraderParameter() *config.TraderParameter {
+	parameter := config.TraderConfig()
+	__traderParameter.Store(&parameter)
+	return &parameter
+}
+
+// 当前的交易参数, 不能修改
+func traderParameter() *config.TraderParameter {
+	if parameter := __traderParameter.Load(); parameter != nil {
+		return parameter
+	}
+	return refreshTraderParameter()
+}
+
 // Direction 交易方向
 type Direction string
 
@@ -118,72 +117,22 @@ func (d OrderDetail) SecurityCode() string {
 
 // QueryAccount 查询账户信息
 func QueryAccount() (*AccountDetail, error) {
-	data, err := http.Post(urlAccount, "")
-	if err != nil {
-		logger.Errorf("trader: 查询账户异常: %+v", err)
-		return nil, err
-	}
-	var detail AccountDetail
-	err = json.Unmarshal(data, &detail)
-	if err != nil {
-		logger.Errorf("trader: 解析json异常: %+v", err)
-		return nil, err
-	}
-	return &detail, nil
+	return GetBroker().QueryAccount()
 }
 
 // QueryHolding 查询持仓
 func QueryHolding() ([]PositionDetail, error) {
-	data, err := http.Post(urlHolding, "")
-	if err != nil {
-		logger.Errorf("trader: 查询持仓异常: %+v", err)
-		return nil, err
-	}
-	var detail []PositionDetail
-	err = json.Unmarshal(data, &detail)
-	if err != nil {
-		logger.Errorf("trader: 解析json异常: %+v", err)
-		return nil, err
-	}
-	return detail, nil
+	return GetBroker().QueryHolding()
 }
 
 // QueryOrders 查询当日委托
 func QueryOrders() ([]OrderDetail, error) {
-	data, err := http.Post(urlOrders, "")
-	if err != nil {
-		logger.Errorf("trader: 查询委托异常: %+v", err)
-		return nil, err
-	}
-	var detail []OrderDetail
-	err = json.Unmarshal(data, &detail)
-	if err != nil {
-		logger.Errorf("trader: 解析json异常: %+v", err)
-		return nil, err
-	}
-	return detail, nil
+	return GetBroker().QueryOrders()
 }
 
 // CancelOrder 撤单
 func CancelOrder(orderId int) error {
-	params := urlpkg.Values{
-		"order_id": {fmt.Sprintf("%d", orderId)},
-	}
-	body := params.Encode()
-	logger.Infof("trader-cancel: %s", body)
-	data, err := http.Post(urlCancelOrder, body)
-	if err != nil {
-		logger.Errorf("trader-cancel: 撤单操作异常: %+v", err)
-		return err
-	}
-	var detail OrderResult
-	err = json.Unmarshal(data, &detail)
-	if err != nil {
-		logger.Errorf("trader-cancel: 解析json异常: %+v", err)
-		return err
-	}
-	logger.Infof("trader-cancel: %s, response: status=%d", body, detail.Status)
-	return nil
+	return GetBroker().CancelOrder(orderId)
 }
 
 // PlaceOrder 下委托订单
@@ -195,31 +144,7 @@ func PlaceOrder(direction Direction, model models.Strategy, securityCode string,
 
 // 直接下单(透传)
 func DirectOrder(direction Direction, strategyName, orderRemark, securityCode string, priceType PriceType, price float64, volume int) (int, error) {
-	_, mflag, symbol := exchange.DetectMarket(securityCode)
-	params := urlpkg.Values{
-		"direction":  {direction.String()},
-		"code":       {fmt.Sprintf("%s.%s", symbol, strings.ToUpper(mflag))},
-		"price_type": {fmt.Sprintf("%d", priceType)},
-		"price":      {fmt.Sprintf("%f", price)},
-		"volume":     {fmt.Sprintf("%d", volume)},
-		"strategy":   {strategyName},
-		"remark":     {orderRemark},
-	}
-	body := params.Encode()
-	logger.Infof("trader-order: %s", body)
-	data, err := http.Post(urlPlaceOrder, body)
-	if err != nil {
-		logger.Errorf("trader-order: 下单操作异常: %+v", err)
-		return -1, err
-	}
-	var detail OrderResult
-	err = json.Unmarshal(data, &detail)
-	if err != nil {
-		logger.Errorf("trader-order: 解析json异常: %+v", err)
-		return -1, err
-	}
-	logger.Infof("trade-order: %s, response: order_id=%d", body, detail.OrderId)
-	return detail.OrderId, nil
+	return submitOrder(direction, strategyName, orderRemark, securityCode, priceType, price, volume)
 }
 
 // 计算策略标的的可用资金